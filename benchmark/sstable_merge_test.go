@@ -0,0 +1,90 @@
+package benchmark
+
+import (
+	"encoding/binary"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thomasjungblut/go-sstables/recordio"
+	"github.com/thomasjungblut/go-sstables/skiplist"
+	"github.com/thomasjungblut/go-sstables/sstables"
+)
+
+func BenchmarkSSTableMerge(b *testing.B) {
+	benchmarks := []struct {
+		name        string
+		bufferDepth int
+	}{
+		{"Serial", 0},
+		{"BufferDepth64", 64},
+		{"BufferDepth1024", 1024},
+	}
+
+	const numTables = 8
+	const recordsPerTable = 32 * 1024
+	cmp := skiplist.BytesComparator{}
+	value := randomRecordOfSize(1024)
+
+	var inputDirs []string
+	for i := 0; i < numTables; i++ {
+		tmpDir, err := os.MkdirTemp("", "sstable_BenchMergeInput")
+		assert.Nil(b, err)
+		inputDirs = append(inputDirs, tmpDir)
+
+		writer, err := sstables.NewSSTableStreamWriter(sstables.WriteBasePath(tmpDir), sstables.WithKeyComparator(cmp),
+			sstables.DataCompressionType(recordio.CompressionTypeSnappy))
+		assert.Nil(b, err)
+		assert.Nil(b, writer.Open())
+		for j := 0; j < recordsPerTable; j++ {
+			// interleaves the keys of all tables
+			k := make([]byte, 4)
+			binary.BigEndian.PutUint32(k, uint32(j*numTables+i))
+			assert.Nil(b, writer.WriteNext(k, value))
+		}
+		assert.Nil(b, writer.Close())
+	}
+
+	defer func() {
+		for _, dir := range inputDirs {
+			assert.Nil(b, os.RemoveAll(dir))
+		}
+	}()
+
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			for n := 0; n < b.N; n++ {
+				b.StopTimer()
+				var readers []sstables.SSTableReaderI
+				var iterators []sstables.SSTableMergeIteratorContext
+				for i, dir := range inputDirs {
+					reader, err := sstables.NewSSTableReader(sstables.ReadBasePath(dir), sstables.SkipHashCheckOnLoad())
+					assert.Nil(b, err)
+					readers = append(readers, reader)
+					it, err := reader.Scan()
+					assert.Nil(b, err)
+					iterators = append(iterators, sstables.NewMergeIteratorContext(i, it))
+				}
+
+				outDir, err := os.MkdirTemp("", "sstable_BenchMergeOutput")
+				assert.Nil(b, err)
+				writer, err := sstables.NewSSTableStreamWriter(sstables.WriteBasePath(outDir), sstables.WithKeyComparator(cmp))
+				assert.Nil(b, err)
+				assert.Nil(b, writer.Open())
+				b.StartTimer()
+
+				merger := sstables.NewSSTableMerger(cmp, sstables.MergeBufferDepth(bm.bufferDepth))
+				assert.Nil(b, merger.Merge(iterators, writer))
+				assert.Nil(b, writer.Close())
+
+				b.StopTimer()
+				for _, reader := range readers {
+					assert.Nil(b, reader.Close())
+				}
+				assert.Nil(b, os.RemoveAll(outDir))
+				b.SetBytes(int64(numTables * recordsPerTable * len(value)))
+				b.StartTimer()
+			}
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"math/rand"
 	"os"
+	"runtime"
 	"testing"
 	"time"
 
@@ -42,6 +43,7 @@ var loadTypeBenchmarks = []struct {
 		ReadBufferSize: 4096,
 	}},
 	{"slice", &sstables.SliceKeyIndexLoader{ReadBufferSize: 4096}},
+	{"arena", &sstables.ArenaKeyIndexLoader{ReadBufferSize: 4096}},
 	{"map", &sstables.MapKeyIndexLoader[[20]byte]{ReadBufferSize: 4096, Mapper: &sstables.Byte20KeyMapper{}}},
 	{"disk", &sstables.DiskIndexLoader{}},
 }
@@ -61,6 +63,43 @@ func BenchmarkSSTableScanDefault(b *testing.B) {
 	}
 }
 
+func BenchmarkSSTableScanReadAhead(b *testing.B) {
+	tmpDir, err := os.MkdirTemp("", "sstable_BenchReadAhead")
+	require.NoError(b, err)
+	defer func() { require.NoError(b, os.RemoveAll(tmpDir)) }()
+
+	writeSSTableWithSize(b, 1024*1024*512, tmpDir, cmp)
+
+	for _, bm := range []struct {
+		name           string
+		readAheadBytes int
+	}{
+		{"none", 0},
+		{"1mb", 1024 * 1024},
+		{"8mb", 1024 * 1024 * 8},
+		{"32mb", 1024 * 1024 * 32},
+	} {
+		b.Run(bm.name, func(b *testing.B) {
+			fullScanTable(b, tmpDir, cmp, nil, sstables.ReadAhead(bm.readAheadBytes))
+		})
+	}
+}
+
+func BenchmarkSSTableScanReuseScanBuffers(b *testing.B) {
+	tmpDir, err := os.MkdirTemp("", "sstable_BenchReuseScanBuffers")
+	require.NoError(b, err)
+	defer func() { require.NoError(b, os.RemoveAll(tmpDir)) }()
+
+	writeSSTableWithSize(b, 1024*1024*512, tmpDir, cmp)
+
+	b.Run("allocating", func(b *testing.B) {
+		fullScanTable(b, tmpDir, cmp, nil)
+	})
+	b.Run("reusing", func(b *testing.B) {
+		fullScanTable(b, tmpDir, cmp, nil, sstables.ReadReuseScanBuffers())
+	})
+}
+
 func BenchmarkSSTableRandomReadDefault(b *testing.B) {
 	for _, bm := range sizeBasedBenchmarks {
 		b.Run(bm.name, func(b *testing.B) {
@@ -157,8 +196,12 @@ func writeSSTableWithSize(b *testing.B, sizeBytes int, tmpDir string, cmp skipli
 	return keys
 }
 
-func fullScanTable(b *testing.B, tmpDir string, cmp skiplist.Comparator[[]byte], loader sstables.IndexLoader) {
+func fullScanTable(b *testing.B, tmpDir string, cmp skiplist.Comparator[[]byte], loader sstables.IndexLoader, extraOpts ...sstables.ReadOption) {
 	for i := 0; i < b.N; i++ {
+		var memBefore, memAfter runtime.MemStats
+		runtime.GC()
+		runtime.ReadMemStats(&memBefore)
+
 		loadStart := time.Now()
 		opts := []sstables.ReadOption{
 			sstables.ReadBasePath(tmpDir),
@@ -168,11 +211,15 @@ func fullScanTable(b *testing.B, tmpDir string, cmp skiplist.Comparator[[]byte],
 		if loader != nil {
 			opts = append(opts, sstables.ReadIndexLoader(loader))
 		}
+		opts = append(opts, extraOpts...)
 
 		reader, err := sstables.NewSSTableReader(opts...)
 		require.NoError(b, err)
 
 		loadEnd := time.Now().Sub(loadStart)
+		runtime.GC()
+		runtime.ReadMemStats(&memAfter)
+		b.ReportMetric(float64(memAfter.HeapAlloc)-float64(memBefore.HeapAlloc), "index_heap_bytes")
 		b.ReportMetric(float64(loadEnd.Milliseconds()), "load_time_ms")
 		b.ReportMetric(float64(loadEnd.Nanoseconds())/float64(reader.MetaData().NumRecords), "load_time_ns/record")
 		b.ReportMetric(float64(reader.MetaData().IndexBytes), "index_bytes")
@@ -2,6 +2,7 @@ package benchmark
 
 import (
 	"encoding/binary"
+	"fmt"
 	"github.com/stretchr/testify/assert"
 	"github.com/thomasjungblut/go-sstables/memstore"
 	"github.com/thomasjungblut/go-sstables/skiplist"
@@ -61,3 +62,79 @@ func BenchmarkSSTableMemstoreFlush(b *testing.B) {
 		})
 	}
 }
+
+func BenchmarkSSTableWritePathKeys(b *testing.B) {
+	benchmarks := []struct {
+		name            string
+		restartInterval int
+	}{
+		{"FullKeys", 0},
+		{"PrefixCompressed", 16},
+	}
+
+	cmp := skiplist.BytesComparator{}
+	value := randomRecordOfSize(128)
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			var indexBytes uint64
+			for n := 0; n < b.N; n++ {
+				tmpDir, err := os.MkdirTemp("", "sstable_BenchWritePathKeys")
+				assert.Nil(b, err)
+
+				writer, err := sstables.NewSSTableStreamWriter(sstables.WriteBasePath(tmpDir),
+					sstables.WithKeyComparator(cmp), sstables.IndexKeyPrefixCompression(bm.restartInterval))
+				assert.Nil(b, err)
+				assert.Nil(b, writer.Open())
+				for i := 0; i < 100_000; i++ {
+					assert.Nil(b, writer.WriteNext([]byte(fmt.Sprintf("/tenants/%03d/buckets/%05d/objects/%08d", i/10_000, i/100, i)), value))
+				}
+				assert.Nil(b, writer.Close())
+
+				reader, err := sstables.NewSSTableReader(sstables.ReadBasePath(tmpDir))
+				assert.Nil(b, err)
+				indexBytes = reader.MetaData().IndexBytes
+				assert.Nil(b, reader.Close())
+				assert.Nil(b, os.RemoveAll(tmpDir))
+			}
+			b.ReportMetric(float64(indexBytes), "index_bytes")
+		})
+	}
+}
+
+func BenchmarkSSTableWriteOrderCheck(b *testing.B) {
+	benchmarks := []struct {
+		name string
+		opts []sstables.WriterOption
+	}{
+		{"Checked", nil},
+		{"UnsafeSkipOrderCheck", []sstables.WriterOption{sstables.UnsafeSkipOrderCheck()}},
+	}
+
+	cmp := skiplist.BytesComparator{}
+	value := randomRecordOfSize(16)
+	keys := make([][]byte, 100_000)
+	for i := range keys {
+		// a long shared prefix makes the comparison of consecutive keys as expensive as it gets
+		keys[i] = []byte(fmt.Sprintf("/tenants/000/buckets/00000/objects/%08d", i))
+	}
+
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			for n := 0; n < b.N; n++ {
+				tmpDir, err := os.MkdirTemp("", "sstable_BenchWriteOrderCheck")
+				assert.Nil(b, err)
+
+				opts := append([]sstables.WriterOption{sstables.WriteBasePath(tmpDir), sstables.WithKeyComparator(cmp)}, bm.opts...)
+				writer, err := sstables.NewSSTableStreamWriter(opts...)
+				assert.Nil(b, err)
+				assert.Nil(b, writer.Open())
+				for _, k := range keys {
+					assert.Nil(b, writer.WriteNext(k, value))
+				}
+				assert.Nil(b, writer.Close())
+				assert.Nil(b, os.RemoveAll(tmpDir))
+			}
+			b.SetBytes(int64(len(keys) * (len(keys[0]) + len(value))))
+		})
+	}
+}
@@ -2,6 +2,8 @@ package memstore
 
 import (
 	"errors"
+	"fmt"
+	"sync/atomic"
 
 	"github.com/thomasjungblut/go-sstables/skiplist"
 	"github.com/thomasjungblut/go-sstables/sstables"
@@ -12,6 +14,10 @@ var KeyNotFound = errors.New("key not found")
 var KeyTombstoned = errors.New("key was tombstoned")
 var KeyNil = errors.New("key was nil")
 var ValueNil = errors.New("value was nil")
+var Immutable = errors.New("memstore is immutable")
+var SnapshotInProgress = errors.New("a snapshot was not released yet")
+var DuplicateKey = errors.New("duplicate key")
+var KeyOutOfOrder = errors.New("key out of order")
 
 // noinspection GoNameStartsWithPackageName
 type MemStoreI interface {
@@ -41,6 +47,8 @@ type MemStoreI interface {
 	// EstimatedSizeInBytes returns a rough estimate of size in bytes of this MemStore
 	EstimatedSizeInBytes() uint64
 	// Flush flushes the current memstore to disk as an SSTable, error if unsuccessful. This excludes tombstoned keys.
+	// A bloom filter is sized for the number of keys written, unless sstables.BloomExpectedNumberOfElements is passed
+	// explicitly.
 	Flush(opts ...sstables.WriterOption) error
 	// FlushWithTombstones flushes the current memstore to disk as an SSTable, error if unsuccessful.
 	// This includes tombstoned keys and writes their values as nil.
@@ -53,6 +61,34 @@ type MemStoreI interface {
 	Size() int
 }
 
+// MergeI is implemented by memstores that can combine a value with the existing one of its key, like MemStore.
+type MergeI interface {
+	// AddOrMerge inserts when the key does not exist yet (or is tombstoned), otherwise the value is replaced by the
+	// result of combine(existing, incoming). This allows to accumulate counters or sets in memory before flushing.
+	// Neither nil key nor values are allowed, KeyNil and ValueNil will be returned accordingly. ValueNil is also
+	// returned when the combine function returns nil, the existing value is kept in that case.
+	AddOrMerge(key []byte, value []byte, combine func(existing []byte, incoming []byte) []byte) error
+}
+
+// IteratorStartingAtI is implemented by memstores that can start iterating at a given key, like MemStore.
+type IteratorStartingAtI interface {
+	// IteratorStartingAt returns an iterator over the current memstore starting at the first key that is greater or
+	// equal to the given key. In contrast to SStableIterator, tombstoned keys are skipped.
+	IteratorStartingAt(key []byte) sstables.SSTableIteratorI
+}
+
+// SnapshotI is implemented by memstores that can be flushed while they accept writes, like MemStore.
+type SnapshotI interface {
+	// SnapshotForFlush freezes the current content into an immutable memstore that can be flushed concurrently, while
+	// this memstore continues to accept writes on a fresh backing structure. Until ReleaseSnapshot is called, Contains,
+	// IsTombstoned, Get and the iterators see the union of both, where newer writes take precedence. Size,
+	// EstimatedSizeInBytes and Flush only cover the writes after the snapshot was taken.
+	// Mutating the snapshot returns Immutable, taking another snapshot before releasing returns SnapshotInProgress.
+	SnapshotForFlush() (MemStoreI, error)
+	// ReleaseSnapshot removes the snapshot from the read path, usually after it was successfully flushed.
+	ReleaseSnapshot()
+}
+
 type ValueStruct struct {
 	// when deleting, we're simply tomb-stoning the key by setting value = nil, which also saves memory
 	value *[]byte
@@ -65,7 +101,17 @@ func (v ValueStruct) GetValue() []byte {
 type MemStore struct {
 	skipListMap   skiplist.MapI[[]byte, ValueStruct]
 	estimatedSize uint64
-	comparator    skiplist.BytesComparator
+	// liveKeys is the number of keys that are not tombstoned, see Len
+	liveKeys   int
+	comparator skiplist.BytesComparator
+
+	flushThreshold   uint64
+	onFlushThreshold func(MemStoreI)
+	aboveThreshold   bool
+
+	// snapshot is the frozen content of a SnapshotForFlush that is still visible to reads
+	snapshot  atomic.Pointer[MemStore]
+	immutable bool
 }
 
 func (m *MemStore) Add(key []byte, value []byte) error {
@@ -76,6 +122,9 @@ func (m *MemStore) Contains(key []byte) bool {
 	element, err := m.skipListMap.Get(key)
 	// we can return false if we didn't find it by error, or when the key is tomb-stoned
 	if errors.Is(err, skiplist.NotFound) {
+		if snapshot := m.snapshot.Load(); snapshot != nil {
+			return snapshot.Contains(key)
+		}
 		return false
 	}
 	if *element.value == nil {
@@ -87,6 +136,9 @@ func (m *MemStore) Contains(key []byte) bool {
 func (m *MemStore) IsTombstoned(key []byte) bool {
 	exist := m.skipListMap.Contains(key)
 	if !exist {
+		if snapshot := m.snapshot.Load(); snapshot != nil {
+			return snapshot.IsTombstoned(key)
+		}
 		return false
 	}
 	element, err := m.skipListMap.Get(key)
@@ -103,6 +155,9 @@ func (m *MemStore) Get(key []byte) ([]byte, error) {
 	element, err := m.skipListMap.Get(key)
 	// we can return false if we didn't find it by error, or when the key is tomb-stoned
 	if errors.Is(err, skiplist.NotFound) {
+		if snapshot := m.snapshot.Load(); snapshot != nil {
+			return snapshot.Get(key)
+		}
 		return nil, KeyNotFound
 	}
 	val := *element.value
@@ -125,21 +180,55 @@ func upsertInternal(m *MemStore, key []byte, value []byte, errorIfKeyExist bool)
 		return ValueNil
 	}
 
+	if m.immutable {
+		return Immutable
+	}
+
 	element, err := m.skipListMap.Get(key)
 	if !errors.Is(err, skiplist.NotFound) {
 		if *element.value != nil && errorIfKeyExist {
 			return KeyAlreadyExists
 		}
+		if *element.value == nil {
+			m.liveKeys++
+		}
 		prevLen := len(*element.value)
 		*element.value = value
 		m.estimatedSize = m.estimatedSize - uint64(prevLen) + uint64(len(value))
 	} else {
+		if snapshot := m.snapshot.Load(); snapshot != nil && errorIfKeyExist && snapshot.Contains(key) {
+			return KeyAlreadyExists
+		}
 		m.skipListMap.Insert(key, ValueStruct{value: &value})
 		m.estimatedSize += uint64(len(key)) + uint64(len(value))
+		m.liveKeys++
 	}
+	m.checkFlushThreshold()
 	return nil
 }
 
+func (m *MemStore) AddOrMerge(key []byte, value []byte, combine func(existing []byte, incoming []byte) []byte) error {
+	if key == nil {
+		return KeyNil
+	}
+
+	if value == nil {
+		return ValueNil
+	}
+
+	existing, err := m.Get(key)
+	if err != nil {
+		return upsertInternal(m, key, value, false)
+	}
+
+	merged := combine(existing, value)
+	if merged == nil {
+		return ValueNil
+	}
+
+	return upsertInternal(m, key, merged, false)
+}
+
 func (m *MemStore) Delete(key []byte) error {
 	return deleteInternal(m, key, true)
 }
@@ -149,22 +238,41 @@ func (m *MemStore) DeleteIfExists(key []byte) error {
 }
 
 func deleteInternal(m *MemStore, key []byte, errorIfKeyNotFound bool) error {
+	if m.immutable {
+		return Immutable
+	}
+
 	element, err := m.skipListMap.Get(key)
 	if errors.Is(err, skiplist.NotFound) {
+		// keys that only exist in the snapshot need to be shadowed by a tombstone
+		if snapshot := m.snapshot.Load(); snapshot != nil && snapshot.Contains(key) {
+			return m.Tombstone(key)
+		}
 		if errorIfKeyNotFound {
 			return KeyNotFound
 		}
 	} else {
+		if *element.value != nil {
+			m.liveKeys--
+		}
 		m.estimatedSize -= uint64(len(*element.value))
 		*element.value = nil
+		m.checkFlushThreshold()
 	}
 
 	return nil
 }
 
 func (m *MemStore) Tombstone(key []byte) error {
+	if m.immutable {
+		return Immutable
+	}
+
 	element, err := m.skipListMap.Get(key)
 	if !errors.Is(err, skiplist.NotFound) {
+		if *element.value != nil {
+			m.liveKeys--
+		}
 		prevLen := len(*element.value)
 		*element.value = nil
 		m.estimatedSize = m.estimatedSize - uint64(prevLen)
@@ -174,9 +282,54 @@ func (m *MemStore) Tombstone(key []byte) error {
 		m.skipListMap.Insert(key, v)
 		m.estimatedSize += uint64(len(key))
 	}
+	m.checkFlushThreshold()
+	return nil
+}
+
+// Remove physically removes the key from the memstore, including a tombstone, returns a KeyNotFound error if the key
+// does not exist. In contrast to Delete, nothing is left behind that shadows the key in older tables or in the snapshot
+// of SnapshotForFlush, so use it only when the memstore is the single source of the key.
+func (m *MemStore) Remove(key []byte) error {
+	if m.immutable {
+		return Immutable
+	}
+
+	deleter, ok := m.skipListMap.(skiplist.DeleteI[[]byte, ValueStruct])
+	if !ok {
+		return errors.New("memstore: backing skip list does not support removing keys")
+	}
+
+	element, removed := deleter.Delete(key)
+	if !removed {
+		return KeyNotFound
+	}
+
+	if *element.value != nil {
+		m.liveKeys--
+	}
+	m.estimatedSize -= uint64(len(key)) + uint64(len(*element.value))
+	m.checkFlushThreshold()
 	return nil
 }
 
+// checkFlushThreshold invokes the flush callback once the estimated size crosses the threshold. The callback is armed
+// again only after the size dropped below the threshold, for example through deletes.
+func (m *MemStore) checkFlushThreshold() {
+	if m.onFlushThreshold == nil {
+		return
+	}
+
+	if m.EstimatedSizeInBytes() < m.flushThreshold {
+		m.aboveThreshold = false
+		return
+	}
+
+	if !m.aboveThreshold {
+		m.aboveThreshold = true
+		m.onFlushThreshold(m)
+	}
+}
+
 func (m *MemStore) EstimatedSizeInBytes() uint64 {
 	// we account for ~15% overhead
 	return uint64(1.15 * float32(m.estimatedSize))
@@ -186,16 +339,40 @@ func (m *MemStore) Size() int {
 	return m.skipListMap.Size()
 }
 
+// Len returns the number of keys that are not tombstoned in constant time, which is the number of keys Flush writes.
+// Like Size, it only covers the writes after the last SnapshotForFlush.
+func (m *MemStore) Len() int {
+	return m.liveKeys
+}
+
 func (m *MemStore) Flush(writerOptions ...sstables.WriterOption) error {
-	return flushMemstore(m, false, writerOptions...)
+	return flushMemstore(m, false, nil, writerOptions...)
 }
 
 func (m *MemStore) FlushWithTombstones(writerOptions ...sstables.WriterOption) error {
-	return flushMemstore(m, true, writerOptions...)
+	return flushMemstore(m, true, nil, writerOptions...)
+}
+
+// FlushWithResolver works like Flush, but resolves keys that the memstore unexpectedly returns more than once instead
+// of failing with DuplicateKey. resolve is called with the value that was returned first and the one after it, nil for
+// tombstones, and returns the value to keep, where nil keeps a tombstone.
+func (m *MemStore) FlushWithResolver(resolve func(key []byte, first []byte, second []byte) []byte, writerOptions ...sstables.WriterOption) error {
+	return flushMemstore(m, false, resolve, writerOptions...)
 }
 
-func flushMemstore(m *MemStore, includeTombstones bool, writerOptions ...sstables.WriterOption) (err error) {
-	writerOptions = append(writerOptions, sstables.WithKeyComparator(m.comparator))
+// flushMemstore writes all keys of the memstore in order. Every key must be strictly greater than the previous one,
+// duplicates fail with DuplicateKey unless a resolver is supplied, keys that go backwards always fail with KeyOutOfOrder.
+func flushMemstore(m *MemStore, includeTombstones bool, resolve func(key []byte, first []byte, second []byte) []byte,
+	writerOptions ...sstables.WriterOption) (err error) {
+	// the bloom filter is sized for the actual number of entries, unless the caller explicitly overrides it
+	numKeys := m.Len()
+	if includeTombstones {
+		numKeys = m.Size()
+	}
+	bloomSize := sstables.BloomExpectedNumberOfElements(uint64(max(1, numKeys)))
+	writerOptions = append([]sstables.WriterOption{bloomSize}, writerOptions...)
+	// the order is already checked below, before the keys reach the writer
+	writerOptions = append(writerOptions, sstables.WithKeyComparator(m.comparator), sstables.UnsafeSkipOrderCheck())
 	writer, err := sstables.NewSSTableStreamWriter(writerOptions...)
 	if err != nil {
 		return err
@@ -210,6 +387,16 @@ func flushMemstore(m *MemStore, includeTombstones bool, writerOptions ...sstable
 		err = errors.Join(err, writer.Close())
 	}()
 
+	write := func(k []byte, v []byte) error {
+		// do not write tombstones to the final file
+		if v == nil && !includeTombstones {
+			return nil
+		}
+		return writer.WriteNext(k, v)
+	}
+
+	// the previous key is only written once the next one was checked, so duplicates can still be resolved
+	var prevKey, prevValue []byte
 	it, _ := m.skipListMap.Iterator()
 	for {
 		k, v, err := it.Next()
@@ -220,29 +407,102 @@ func flushMemstore(m *MemStore, includeTombstones bool, writerOptions ...sstable
 			return err
 		}
 
-		if includeTombstones {
-			if err := writer.WriteNext(k, *v.value); err != nil {
-				return err
-			}
-		} else {
-			// do not write tombstones to the final file
-			if *v.value != nil {
-				if err := writer.WriteNext(k, *v.value); err != nil {
-					return err
+		if prevKey != nil {
+			c := m.comparator.Compare(k, prevKey)
+			if c == 0 {
+				if resolve == nil {
+					return fmt.Errorf("error while flushing memstore at key %v: %w", k, DuplicateKey)
 				}
+				prevValue = resolve(k, prevValue, *v.value)
+				continue
+			}
+			if c < 0 {
+				return fmt.Errorf("error while flushing memstore, key %v came after %v: %w", k, prevKey, KeyOutOfOrder)
+			}
+			if err := write(prevKey, prevValue); err != nil {
+				return err
 			}
 		}
+		prevKey, prevValue = k, *v.value
 	}
 
+	if prevKey != nil {
+		return write(prevKey, prevValue)
+	}
 	return nil
 }
 
+func (m *MemStore) SnapshotForFlush() (MemStoreI, error) {
+	if m.immutable {
+		return nil, Immutable
+	}
+
+	if m.snapshot.Load() != nil {
+		return nil, SnapshotInProgress
+	}
+
+	snapshot := &MemStore{
+		skipListMap:   m.skipListMap,
+		estimatedSize: m.estimatedSize,
+		liveKeys:      m.liveKeys,
+		comparator:    m.comparator,
+		immutable:     true,
+	}
+
+	m.skipListMap = skiplist.NewSkipListMap[[]byte, ValueStruct](m.comparator)
+	m.estimatedSize = 0
+	m.liveKeys = 0
+	m.aboveThreshold = false
+	m.snapshot.Store(snapshot)
+
+	return snapshot, nil
+}
+
+func (m *MemStore) ReleaseSnapshot() {
+	m.snapshot.Store(nil)
+}
+
 func (m *MemStore) SStableIterator() sstables.SSTableIteratorI {
-	it, _ := m.skipListMap.Iterator()
-	return &SkipListSStableIterator{iterator: it}
+	return &SkipListSStableIterator{iterator: m.iterator(nil)}
+}
+
+func (m *MemStore) IteratorStartingAt(key []byte) sstables.SSTableIteratorI {
+	return &SkipListSStableIterator{iterator: m.iterator(key), skipTombstones: true}
+}
+
+// iterator returns the keys starting at the given key, all of them for nil. The keys of a pending snapshot are merged
+// in, where the newer writes win.
+func (m *MemStore) iterator(start []byte) skiplist.IteratorI[[]byte, ValueStruct] {
+	iterate := func(list skiplist.MapI[[]byte, ValueStruct]) skiplist.IteratorI[[]byte, ValueStruct] {
+		if start == nil {
+			it, _ := list.Iterator()
+			return it
+		}
+		it, _ := list.IteratorStartingAt(start)
+		return it
+	}
+
+	it := iterate(m.skipListMap)
+	if snapshot := m.snapshot.Load(); snapshot != nil {
+		return &snapshotIterator{newer: it, older: iterate(snapshot.skipListMap), comparator: m.comparator}
+	}
+	return it
 }
 
 func NewMemStore() MemStoreI {
 	cmp := skiplist.BytesComparator{}
 	return &MemStore{skipListMap: skiplist.NewSkipListMap[[]byte, ValueStruct](cmp), comparator: cmp}
 }
+
+// NewMemStoreWithFlushThreshold creates a new memstore that synchronously invokes onThreshold, before returning from
+// the mutating call, as soon as EstimatedSizeInBytes reaches thresholdBytes. The callback fires at most once per
+// crossing, this allows to rotate to a new memstore and flush the full one without polling its size after every Add.
+func NewMemStoreWithFlushThreshold(thresholdBytes uint64, onThreshold func(MemStoreI)) MemStoreI {
+	cmp := skiplist.BytesComparator{}
+	return &MemStore{
+		skipListMap:      skiplist.NewSkipListMap[[]byte, ValueStruct](cmp),
+		comparator:       cmp,
+		flushThreshold:   thresholdBytes,
+		onFlushThreshold: onThreshold,
+	}
+}
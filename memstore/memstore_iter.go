@@ -0,0 +1,38 @@
+package memstore
+
+import (
+	"iter"
+)
+
+// All returns the keys and values of the current memstore in ascending key order for a range-over-func loop:
+// for k, v := range ms.All() {}. Tombstoned keys are skipped, like in IteratorStartingAt. Use SStableIterator to
+// include the tombstones, as Flush does.
+func (m *MemStore) All() iter.Seq2[[]byte, []byte] {
+	return func(yield func([]byte, []byte) bool) {
+		it := m.iterator(nil)
+		for {
+			key, val, err := it.Next()
+			// the skip list iterator only fails once it's done
+			if err != nil {
+				return
+			}
+			if *val.value == nil {
+				continue
+			}
+			if !yield(key, *val.value) {
+				return
+			}
+		}
+	}
+}
+
+// Keys returns the keys of All, tombstoned keys are skipped.
+func (m *MemStore) Keys() iter.Seq[[]byte] {
+	return func(yield func([]byte) bool) {
+		for key := range m.All() {
+			if !yield(key) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package memstore
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemStoreAllAndKeys(t *testing.T) {
+	m := newMemStoreTest()
+	for i := byte(0); i < 10; i++ {
+		require.NoError(t, m.Add([]byte{i}, []byte{i + 1}))
+	}
+	require.NoError(t, m.Delete([]byte{3}))
+	require.NoError(t, m.Tombstone([]byte{42}))
+	require.NoError(t, m.Upsert([]byte{5}, []byte{}))
+
+	var keys [][]byte
+	var values [][]byte
+	for k, v := range m.All() {
+		keys = append(keys, k)
+		values = append(values, v)
+	}
+	assert.Equal(t, [][]byte{{0}, {1}, {2}, {4}, {5}, {6}, {7}, {8}, {9}}, keys)
+	assert.Equal(t, [][]byte{{1}, {2}, {3}, {5}, {}, {7}, {8}, {9}, {10}}, values)
+
+	var onlyKeys [][]byte
+	for k := range m.Keys() {
+		onlyKeys = append(onlyKeys, k)
+	}
+	assert.Equal(t, keys, onlyKeys)
+
+	// breaking out of the loop stops the iteration
+	var firstKeys [][]byte
+	for k := range m.Keys() {
+		if len(firstKeys) == 2 {
+			break
+		}
+		firstKeys = append(firstKeys, k)
+	}
+	assert.Equal(t, [][]byte{{0}, {1}}, firstKeys)
+
+	// the flush path still sees the tombstones
+	it := m.SStableIterator()
+	n := 0
+	for _, _, err := it.Next(); err == nil; _, _, err = it.Next() {
+		n++
+	}
+	assert.Equal(t, 11, n)
+}
+
+func TestMemStoreAllEmpty(t *testing.T) {
+	m := newMemStoreTest()
+	for range m.All() {
+		assert.Fail(t, "empty memstore returned a record")
+	}
+}
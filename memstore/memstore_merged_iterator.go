@@ -0,0 +1,66 @@
+package memstore
+
+import (
+	"errors"
+
+	"github.com/thomasjungblut/go-sstables/skiplist"
+	"github.com/thomasjungblut/go-sstables/sstables"
+)
+
+// ErrDeleted is returned by GetFromStack when the latest value of a key is a tombstone, it is the same sentinel as
+// KeyTombstoned.
+var ErrDeleted = KeyTombstoned
+
+// GetFromStack returns the latest value of the key across the memstore and the readers, which must be ordered from the
+// newest to the oldest table like in NewMergedIterator. The lookup stops at the first source that knows the key, tables
+// without the key are mostly skipped through their bloom filter. A tombstone in the memstore, or a nil value written by
+// FlushWithTombstones, returns ErrDeleted. KeyNotFound is returned when no source contains the key.
+func GetFromStack(key []byte, ms MemStoreI, readers ...sstables.SSTableReaderI) ([]byte, error) {
+	if ms != nil {
+		val, err := ms.Get(key)
+		if err == nil || !errors.Is(err, KeyNotFound) {
+			return val, err
+		}
+	}
+
+	for _, reader := range readers {
+		val, err := reader.Get(key)
+		if err != nil {
+			if errors.Is(err, sstables.ErrKeyNotFound) {
+				continue
+			}
+			return nil, err
+		}
+		if val == nil {
+			return nil, ErrDeleted
+		}
+		return val, nil
+	}
+
+	return nil, KeyNotFound
+}
+
+// NewMergedIterator returns an iterator over the union of the given memstore and sstables, which is the read path of
+// an LSM tree. The readers must be ordered from the newest to the oldest table. Every key is returned exactly once in
+// ascending order with its latest value: the memstore wins over all tables, including a snapshot that wasn't released
+// yet, and newer tables win over older ones. Keys whose latest value is a tombstone are skipped, for tables these are
+// the nil values written by FlushWithTombstones.
+// The memstore is not copied, it must not be mutated while the iterator is in use.
+func NewMergedIterator(ms MemStoreI, readers []sstables.SSTableReaderI) (sstables.SSTableIteratorI, error) {
+	// the context is the priority of a source, ScanReduceLatestWins picks the value of the highest one
+	var iterators []sstables.SSTableMergeIteratorContext
+	for i, reader := range readers {
+		scanner, err := reader.Scan()
+		if err != nil {
+			return nil, err
+		}
+		iterators = append(iterators, sstables.NewMergeIteratorContext(len(readers)-1-i, scanner))
+	}
+
+	// the iterator of the memstore already includes a pending snapshot
+	iterators = append(iterators, sstables.NewMergeIteratorContext(len(readers), ms.SStableIterator()))
+
+	// the merge iterator omits keys whose reduced value is nil, in contrast to ScanReduceLatestWinsSkipTombstones this
+	// keeps empty values
+	return sstables.NewSSTableMerger(skiplist.BytesComparator{}).MergeCompactIterator(iterators, sstables.ScanReduceLatestWins)
+}
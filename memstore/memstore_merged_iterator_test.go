@@ -0,0 +1,148 @@
+package memstore
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thomasjungblut/go-sstables/sstables"
+)
+
+func flushTestTable(t *testing.T, kvs map[string]string, tombstones ...string) sstables.SSTableReaderI {
+	m := newMemStoreTest()
+	for k, v := range kvs {
+		require.NoError(t, m.Upsert([]byte(k), []byte(v)))
+	}
+	for _, k := range tombstones {
+		require.NoError(t, m.Tombstone([]byte(k)))
+	}
+
+	tmpDir, err := os.MkdirTemp("", "memstore_merged")
+	require.NoError(t, err)
+	t.Cleanup(func() { assert.Nil(t, os.RemoveAll(tmpDir)) })
+	require.NoError(t, m.FlushWithTombstones(sstables.WriteBasePath(tmpDir)))
+
+	reader, err := sstables.NewSSTableReader(sstables.ReadBasePath(tmpDir))
+	require.NoError(t, err)
+	t.Cleanup(func() { closeReader(t, reader) })
+	return reader
+}
+
+func collectMerged(t *testing.T, it sstables.SSTableIteratorI) map[string]string {
+	result := map[string]string{}
+	var prev []byte
+	for {
+		k, v, err := it.Next()
+		if errors.Is(err, sstables.Done) {
+			break
+		}
+		require.NoError(t, err)
+		if prev != nil {
+			require.Less(t, string(prev), string(k))
+		}
+		prev = k
+		result[string(k)] = string(v)
+	}
+	return result
+}
+
+func TestMergedIterator(t *testing.T) {
+	older := flushTestTable(t, map[string]string{"a": "old", "b": "old", "c": "old", "d": "old"})
+	newer := flushTestTable(t, map[string]string{"b": "new", "e": "new", "f": "new"}, "c")
+
+	m := newMemStoreTest()
+	require.NoError(t, m.Upsert([]byte("a"), []byte("mem")))
+	require.NoError(t, m.Upsert([]byte("c"), []byte("mem")))
+	require.NoError(t, m.Upsert([]byte("g"), []byte{}))
+	require.NoError(t, m.Tombstone([]byte("e")))
+	require.NoError(t, m.Tombstone([]byte("x")))
+
+	it, err := NewMergedIterator(m, []sstables.SSTableReaderI{newer, older})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"a": "mem",
+		"b": "new",
+		"c": "mem",
+		"d": "old",
+		"f": "new",
+		"g": "",
+	}, collectMerged(t, it))
+}
+
+func TestMergedIteratorTableTombstones(t *testing.T) {
+	older := flushTestTable(t, map[string]string{"a": "old", "b": "old"})
+	newer := flushTestTable(t, map[string]string{"c": "new"}, "a")
+
+	it, err := NewMergedIterator(newMemStoreTest(), []sstables.SSTableReaderI{newer, older})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"b": "old", "c": "new"}, collectMerged(t, it))
+
+	// the order of the readers matters, here the older table wins
+	it, err = NewMergedIterator(newMemStoreTest(), []sstables.SSTableReaderI{older, newer})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"a": "old", "b": "old", "c": "new"}, collectMerged(t, it))
+}
+
+func TestMergedIteratorWithSnapshot(t *testing.T) {
+	table := flushTestTable(t, map[string]string{"a": "table", "b": "table", "c": "table"})
+
+	m := newMemStoreTest()
+	require.NoError(t, m.Upsert([]byte("a"), []byte("snapshot")))
+	require.NoError(t, m.Upsert([]byte("b"), []byte("snapshot")))
+	_, err := m.SnapshotForFlush()
+	require.NoError(t, err)
+	require.NoError(t, m.Upsert([]byte("b"), []byte("mem")))
+	require.NoError(t, m.Delete([]byte("a")))
+
+	it, err := NewMergedIterator(m, []sstables.SSTableReaderI{table})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"b": "mem", "c": "table"}, collectMerged(t, it))
+
+	m.ReleaseSnapshot()
+	it, err = NewMergedIterator(m, []sstables.SSTableReaderI{table})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"b": "mem", "c": "table"}, collectMerged(t, it))
+}
+
+func TestMergedIteratorOnlyMemStore(t *testing.T) {
+	m := newMemStoreTest()
+	it, err := NewMergedIterator(m, nil)
+	require.NoError(t, err)
+	assert.Empty(t, collectMerged(t, it))
+
+	require.NoError(t, m.Upsert([]byte("a"), []byte("1")))
+	it, err = NewMergedIterator(m, nil)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"a": "1"}, collectMerged(t, it))
+}
+
+func TestGetFromStack(t *testing.T) {
+	older := flushTestTable(t, map[string]string{"a": "old", "b": "old", "c": "old", "d": "old"})
+	newer := flushTestTable(t, map[string]string{"b": "new", "e": ""}, "c")
+
+	m := newMemStoreTest()
+	require.NoError(t, m.Upsert([]byte("a"), []byte("mem")))
+	require.NoError(t, m.Tombstone([]byte("d")))
+
+	for k, expected := range map[string]string{"a": "mem", "b": "new", "e": ""} {
+		val, err := GetFromStack([]byte(k), m, newer, older)
+		require.NoError(t, err)
+		assert.Equal(t, []byte(expected), val)
+	}
+
+	_, err := GetFromStack([]byte("c"), m, newer, older)
+	assert.ErrorIs(t, err, ErrDeleted)
+	_, err = GetFromStack([]byte("d"), m, newer, older)
+	assert.ErrorIs(t, err, ErrDeleted)
+	_, err = GetFromStack([]byte("x"), m, newer, older)
+	assert.ErrorIs(t, err, KeyNotFound)
+
+	// the first hit wins, so the order of the readers matters
+	val, err := GetFromStack([]byte("c"), nil, older, newer)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("old"), val)
+	_, err = GetFromStack([]byte("a"), newMemStoreTest())
+	assert.ErrorIs(t, err, KeyNotFound)
+}
@@ -8,16 +8,70 @@ import (
 
 type SkipListSStableIterator struct {
 	iterator skiplist.IteratorI[[]byte, ValueStruct]
+	// skipTombstones omits tombstoned keys, instead of returning them with a nil value
+	skipTombstones bool
 }
 
 func (s SkipListSStableIterator) Next() ([]byte, []byte, error) {
-	key, val, err := s.iterator.Next()
-	if err != nil {
-		if errors.Is(err, skiplist.Done) {
-			return nil, nil, sstables.Done
-		} else {
-			return nil, nil, err
+	for {
+		key, val, err := s.iterator.Next()
+		if err != nil {
+			if errors.Is(err, skiplist.Done) {
+				return nil, nil, sstables.Done
+			} else {
+				return nil, nil, err
+			}
 		}
+		if s.skipTombstones && *val.value == nil {
+			continue
+		}
+		return key, *val.value, nil
+	}
+}
+
+// snapshotIterator merges the iterator of a memstore with the one of its pending snapshot, both starting at the same
+// key. On equal keys the memstore wins, including its tombstones.
+type snapshotIterator struct {
+	newer      skiplist.IteratorI[[]byte, ValueStruct]
+	older      skiplist.IteratorI[[]byte, ValueStruct]
+	comparator skiplist.BytesComparator
+
+	started            bool
+	newerKey, olderKey []byte
+	newerVal, olderVal ValueStruct
+	newerErr, olderErr error
+}
+
+func (s *snapshotIterator) Next() ([]byte, ValueStruct, error) {
+	if !s.started {
+		s.started = true
+		s.newerKey, s.newerVal, s.newerErr = s.newer.Next()
+		s.olderKey, s.olderVal, s.olderErr = s.older.Next()
 	}
-	return key, *val.value, nil
+
+	newerDone := errors.Is(s.newerErr, skiplist.Done)
+	olderDone := errors.Is(s.olderErr, skiplist.Done)
+	if s.newerErr != nil && !newerDone {
+		return nil, ValueStruct{}, s.newerErr
+	}
+	if s.olderErr != nil && !olderDone {
+		return nil, ValueStruct{}, s.olderErr
+	}
+	if newerDone && olderDone {
+		return nil, ValueStruct{}, skiplist.Done
+	}
+
+	if olderDone || !newerDone && s.comparator.Compare(s.newerKey, s.olderKey) <= 0 {
+		key, val := s.newerKey, s.newerVal
+		// the older version of the same key is shadowed
+		if !olderDone && s.comparator.Compare(key, s.olderKey) == 0 {
+			s.olderKey, s.olderVal, s.olderErr = s.older.Next()
+		}
+		s.newerKey, s.newerVal, s.newerErr = s.newer.Next()
+		return key, val, nil
+	}
+
+	key, val := s.olderKey, s.olderVal
+	s.olderKey, s.olderVal, s.olderErr = s.older.Next()
+	return key, val, nil
 }
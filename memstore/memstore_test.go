@@ -1,15 +1,23 @@
 package memstore
 
 import (
+	"bytes"
 	"errors"
+	"fmt"
 	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/steakknife/bloomfilter"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/thomasjungblut/go-sstables/skiplist"
 	"github.com/thomasjungblut/go-sstables/sstables"
 )
 
+var _ MergeI = (*MemStore)(nil)
+var _ IteratorStartingAtI = (*MemStore)(nil)
+
 func TestMemStoreAddHappyPath(t *testing.T) {
 	m := newMemStoreTest()
 	assert.False(t, m.Contains([]byte("a")))
@@ -71,6 +79,65 @@ func TestMemStoreUpsertUpdatesOnExist(t *testing.T) {
 	assert.Equal(t, []byte("aVal2"), *kv.value)
 }
 
+func TestMemStoreAddOrMerge(t *testing.T) {
+	m := newMemStoreTest()
+	concat := func(existing []byte, incoming []byte) []byte {
+		return append(append([]byte{}, existing...), incoming...)
+	}
+
+	err := m.AddOrMerge([]byte("a"), []byte("x"), concat)
+	assert.Nil(t, err)
+	val, err := m.Get([]byte("a"))
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("x"), val)
+	assert.Equal(t, 1, m.Size())
+
+	err = m.AddOrMerge([]byte("a"), []byte("yz"), concat)
+	assert.Nil(t, err)
+	val, err = m.Get([]byte("a"))
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("xyz"), val)
+	assert.Equal(t, 1, m.Size())
+	// key of 1 byte, value of 3 bytes
+	assert.Equal(t, uint64(4), m.estimatedSize)
+
+	// a tombstoned key is treated as absent
+	assert.Nil(t, m.Delete([]byte("a")))
+	err = m.AddOrMerge([]byte("a"), []byte("b"), concat)
+	assert.Nil(t, err)
+	val, err = m.Get([]byte("a"))
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("b"), val)
+	assert.Equal(t, uint64(2), m.estimatedSize)
+}
+
+func TestMemStoreAddOrMergeShrinkingValue(t *testing.T) {
+	m := newMemStoreTest()
+	first := func(existing []byte, incoming []byte) []byte {
+		return existing[:1]
+	}
+
+	assert.Nil(t, m.AddOrMerge(make([]byte, 20), make([]byte, 100), first))
+	assert.Equal(t, uint64(138), m.EstimatedSizeInBytes())
+	assert.Nil(t, m.AddOrMerge(make([]byte, 20), make([]byte, 100), first))
+	assert.Equal(t, uint64(24), m.EstimatedSizeInBytes())
+}
+
+func TestMemStoreAddOrMergeNils(t *testing.T) {
+	m := newMemStoreTest()
+	toNil := func(existing []byte, incoming []byte) []byte {
+		return nil
+	}
+
+	assert.Equal(t, KeyNil, m.AddOrMerge(nil, []byte("a"), toNil))
+	assert.Equal(t, ValueNil, m.AddOrMerge([]byte("a"), nil, toNil))
+	assert.Nil(t, m.AddOrMerge([]byte("a"), []byte("a"), toNil))
+	assert.Equal(t, ValueNil, m.AddOrMerge([]byte("a"), []byte("b"), toNil))
+	val, err := m.Get([]byte("a"))
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("a"), val)
+}
+
 func TestMemStoreDeleteTombstones(t *testing.T) {
 	m := newMemStoreTest()
 	err := m.Upsert([]byte("a"), []byte("aVal"))
@@ -162,6 +229,188 @@ func TestMemStoreSizeEstimates(t *testing.T) {
 	assert.Equal(t, 1, m.Size())
 }
 
+func TestMemStoreFlushThreshold(t *testing.T) {
+	var fired []MemStoreI
+	m := NewMemStoreWithFlushThreshold(100, func(store MemStoreI) {
+		fired = append(fired, store)
+	})
+
+	assert.Nil(t, m.Add(make([]byte, 10), make([]byte, 50)))
+	assert.Empty(t, fired)
+	assert.Nil(t, m.Add(make([]byte, 11), make([]byte, 50)))
+	require.Equal(t, 1, len(fired))
+	assert.Same(t, m, fired[0])
+
+	// staying above the threshold does not fire again
+	assert.Nil(t, m.Add(make([]byte, 12), make([]byte, 50)))
+	assert.Nil(t, m.Upsert(make([]byte, 12), make([]byte, 60)))
+	assert.Equal(t, 1, len(fired))
+
+	// dropping below and crossing again fires once more
+	assert.Nil(t, m.Delete(make([]byte, 11)))
+	assert.Nil(t, m.Delete(make([]byte, 12)))
+	assert.Less(t, m.EstimatedSizeInBytes(), uint64(100))
+	assert.Equal(t, 1, len(fired))
+	assert.Nil(t, m.Upsert(make([]byte, 11), make([]byte, 50)))
+	assert.Equal(t, 2, len(fired))
+}
+
+func TestMemStoreFlushThresholdRotation(t *testing.T) {
+	var full []MemStoreI
+	var current MemStoreI
+	rotate := func(store MemStoreI) {
+		full = append(full, store)
+	}
+	current = NewMemStoreWithFlushThreshold(40, rotate)
+
+	for i := 0; i < 10; i++ {
+		assert.Nil(t, current.Add([]byte{byte(i)}, make([]byte, 20)))
+		if len(full) > 0 && full[len(full)-1] == current {
+			current = NewMemStoreWithFlushThreshold(40, rotate)
+		}
+	}
+
+	assert.Equal(t, 5, len(full))
+	for _, store := range full {
+		assert.Equal(t, 2, store.Size())
+	}
+}
+
+func TestMemStoreSnapshotUnionReads(t *testing.T) {
+	m := newMemStoreTest()
+	assert.Nil(t, m.Add([]byte("a"), []byte("aVal")))
+	assert.Nil(t, m.Add([]byte("b"), []byte("bVal")))
+	assert.Nil(t, m.Add([]byte("c"), []byte("cVal")))
+
+	snapshot, err := m.SnapshotForFlush()
+	require.Nil(t, err)
+	assert.Equal(t, 3, snapshot.Size())
+	assert.Equal(t, 0, m.Size())
+	assert.Equal(t, uint64(0), m.EstimatedSizeInBytes())
+
+	_, err = m.SnapshotForFlush()
+	assert.Equal(t, SnapshotInProgress, err)
+
+	// newer writes shadow the snapshot
+	assert.Equal(t, KeyAlreadyExists, m.Add([]byte("a"), []byte("aVal2")))
+	assert.Nil(t, m.Upsert([]byte("a"), []byte("aVal2")))
+	assert.Nil(t, m.Delete([]byte("b")))
+	assert.Nil(t, m.Add([]byte("d"), []byte("dVal")))
+
+	val, err := m.Get([]byte("a"))
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("aVal2"), val)
+	_, err = m.Get([]byte("b"))
+	assert.Equal(t, KeyTombstoned, err)
+	assert.True(t, m.IsTombstoned([]byte("b")))
+	assert.False(t, m.Contains([]byte("b")))
+	val, err = m.Get([]byte("c"))
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("cVal"), val)
+	assert.True(t, m.Contains([]byte("d")))
+
+	// the snapshot is unchanged and immutable
+	val, err = snapshot.Get([]byte("a"))
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("aVal"), val)
+	assert.True(t, snapshot.Contains([]byte("b")))
+	assert.False(t, snapshot.Contains([]byte("d")))
+	assert.Equal(t, Immutable, snapshot.Add([]byte("e"), []byte("eVal")))
+	assert.Equal(t, Immutable, snapshot.Upsert([]byte("a"), []byte("eVal")))
+	assert.Equal(t, Immutable, snapshot.Delete([]byte("a")))
+	assert.Equal(t, Immutable, snapshot.Tombstone([]byte("a")))
+	_, err = snapshot.(SnapshotI).SnapshotForFlush()
+	assert.Equal(t, Immutable, err)
+
+	m.ReleaseSnapshot()
+	assert.False(t, m.Contains([]byte("c")))
+	_, err = m.SnapshotForFlush()
+	assert.Nil(t, err)
+}
+
+func TestMemStoreSnapshotIterators(t *testing.T) {
+	m := newMemStoreTest()
+	for _, k := range []string{"a", "b", "c", "e"} {
+		assert.Nil(t, m.Add([]byte(k), []byte(k+"Val")))
+	}
+	_, err := m.SnapshotForFlush()
+	require.Nil(t, err)
+	assert.Nil(t, m.Upsert([]byte("a"), []byte("aVal2")))
+	assert.Nil(t, m.Delete([]byte("b")))
+	assert.Nil(t, m.Add([]byte("d"), []byte("dVal")))
+	assert.Nil(t, m.Add([]byte("f"), []byte("fVal")))
+
+	// the newer writes win over the snapshot, tombstones included
+	it := m.SStableIterator()
+	for _, kv := range [][]string{{"a", "aVal2"}, {"b", ""}, {"c", "cVal"}, {"d", "dVal"}, {"e", "eVal"}, {"f", "fVal"}} {
+		k, v, err := it.Next()
+		require.Nil(t, err)
+		assert.Equal(t, []byte(kv[0]), k)
+		if kv[1] == "" {
+			assert.Nil(t, v)
+		} else {
+			assert.Equal(t, []byte(kv[1]), v)
+		}
+	}
+	_, _, err = it.Next()
+	assert.Equal(t, sstables.Done, err)
+
+	it = m.IteratorStartingAt([]byte("b"))
+	for _, k := range []string{"c", "d", "e", "f"} {
+		key, _, err := it.Next()
+		require.Nil(t, err)
+		assert.Equal(t, []byte(k), key)
+	}
+	_, _, err = it.Next()
+	assert.Equal(t, sstables.Done, err)
+
+	var all []string
+	for k, v := range m.All() {
+		all = append(all, string(k)+"="+string(v))
+	}
+	assert.Equal(t, []string{"a=aVal2", "c=cVal", "d=dVal", "e=eVal", "f=fVal"}, all)
+
+	m.ReleaseSnapshot()
+	var keys []string
+	for k := range m.Keys() {
+		keys = append(keys, string(k))
+	}
+	assert.Equal(t, []string{"a", "d", "f"}, keys)
+}
+
+func TestMemStoreSnapshotFlushWhileWriting(t *testing.T) {
+	m := newMemStoreTest()
+	for i := 0; i < 1000; i++ {
+		assert.Nil(t, m.Add([]byte(fmt.Sprintf("%05d", i)), []byte{1}))
+	}
+
+	snapshot, err := m.SnapshotForFlush()
+	require.Nil(t, err)
+
+	tmpDir, err := os.MkdirTemp("", "memstore_snapshot")
+	require.Nil(t, err)
+	defer func() { require.Nil(t, os.RemoveAll(tmpDir)) }()
+
+	flushErr := make(chan error)
+	go func() {
+		flushErr <- snapshot.Flush(sstables.WriteBasePath(tmpDir))
+	}()
+
+	for i := 1000; i < 2000; i++ {
+		assert.Nil(t, m.Add([]byte(fmt.Sprintf("%05d", i)), []byte{2}))
+		assert.True(t, m.Contains([]byte(fmt.Sprintf("%05d", i-1000))))
+	}
+	require.Nil(t, <-flushErr)
+	m.ReleaseSnapshot()
+
+	reader, err := sstables.NewSSTableReader(sstables.ReadBasePath(tmpDir))
+	require.Nil(t, err)
+	defer func() { require.Nil(t, reader.Close()) }()
+	assert.Equal(t, uint64(1000), reader.MetaData().NumRecords)
+	assert.Equal(t, []byte("00999"), reader.MetaData().MaxKey)
+	assert.Equal(t, 1000, m.Size())
+}
+
 func TestMemStoreFlush(t *testing.T) {
 	m := newMemStoreTest()
 	err := m.Upsert([]byte("akey"), []byte("aval"))
@@ -195,6 +444,123 @@ func TestMemStoreFlush(t *testing.T) {
 	assert.Equal(t, sstables.NotFound, err)
 }
 
+func TestMemStoreFlushSizesBloomFilter(t *testing.T) {
+	m := newMemStoreTest()
+	for i := 0; i < 10000; i++ {
+		require.NoError(t, m.Add([]byte(fmt.Sprintf("key%05d", i)), []byte("val")))
+	}
+
+	for expectedElements, opts := range map[uint64][]sstables.WriterOption{
+		10000: {sstables.EnableBloomFilter()},
+		50:    {sstables.EnableBloomFilter(), sstables.BloomExpectedNumberOfElements(50)},
+	} {
+		tmpDir, err := os.MkdirTemp("", "memstore_flush_bloom")
+		require.NoError(t, err)
+
+		require.NoError(t, m.Flush(append(opts, sstables.WriteBasePath(tmpDir))...))
+		filter, _, err := bloomfilter.ReadFile(filepath.Join(tmpDir, sstables.BloomFileName))
+		require.NoError(t, err)
+		expected, err := bloomfilter.NewOptimal(expectedElements, 0.01)
+		require.NoError(t, err)
+		assert.Equal(t, expected.M(), filter.M())
+		require.NoError(t, os.RemoveAll(tmpDir))
+	}
+}
+
+func TestMemStoreLen(t *testing.T) {
+	m := newMemStoreTest()
+	assert.Equal(t, 0, m.Len())
+	require.Nil(t, m.Add([]byte("a"), []byte{1}))
+	require.Nil(t, m.Add([]byte("b"), []byte{1}))
+	require.Nil(t, m.Upsert([]byte("b"), []byte{2}))
+	assert.Equal(t, 2, m.Len())
+
+	concat := func(existing []byte, incoming []byte) []byte {
+		return append(append([]byte{}, existing...), incoming...)
+	}
+	require.Nil(t, m.AddOrMerge([]byte("b"), []byte{3}, concat))
+	require.Nil(t, m.AddOrMerge([]byte("c"), []byte{3}, concat))
+	assert.Equal(t, 3, m.Len())
+
+	// deleting and tombstoning a key twice only counts once, tombstones still count towards Size
+	require.Nil(t, m.Delete([]byte("a")))
+	require.Nil(t, m.DeleteIfExists([]byte("a")))
+	require.Nil(t, m.Tombstone([]byte("b")))
+	require.Nil(t, m.Tombstone([]byte("b")))
+	require.Nil(t, m.Tombstone([]byte("d")))
+	assert.Equal(t, 1, m.Len())
+	assert.Equal(t, 4, m.Size())
+
+	// adding a tombstoned key makes it live again
+	require.Nil(t, m.Add([]byte("a"), []byte{4}))
+	require.Nil(t, m.AddOrMerge([]byte("b"), []byte{5}, concat))
+	assert.Equal(t, 3, m.Len())
+
+	snapshot, err := m.SnapshotForFlush()
+	require.Nil(t, err)
+	assert.Equal(t, 3, snapshot.(*MemStore).Len())
+	assert.Equal(t, 0, m.Len())
+}
+
+func TestMemStoreRemove(t *testing.T) {
+	m := newMemStoreTest()
+	require.Nil(t, m.Add([]byte("a"), []byte("aVal")))
+	require.Nil(t, m.Add([]byte("b"), []byte("bVal")))
+	require.Nil(t, m.Tombstone([]byte("c")))
+
+	require.Nil(t, m.Remove([]byte("a")))
+	assert.False(t, m.Contains([]byte("a")))
+	assert.False(t, m.IsTombstoned([]byte("a")))
+	_, err := m.Get([]byte("a"))
+	assert.Equal(t, KeyNotFound, err)
+	assert.Equal(t, KeyNotFound, m.Remove([]byte("a")))
+
+	// tombstones are removed as well
+	require.Nil(t, m.Remove([]byte("c")))
+	assert.False(t, m.IsTombstoned([]byte("c")))
+
+	assert.Equal(t, 1, m.Size())
+	assert.Equal(t, 1, m.Len())
+	assert.Equal(t, uint64(len("b")+len("bVal")), m.estimatedSize)
+
+	it := m.SStableIterator()
+	k, _, err := it.Next()
+	require.Nil(t, err)
+	assert.Equal(t, []byte("b"), k)
+	_, _, err = it.Next()
+	assert.Equal(t, sstables.Done, err)
+
+	snapshot, err := m.SnapshotForFlush()
+	require.Nil(t, err)
+	assert.Equal(t, Immutable, snapshot.(*MemStore).Remove([]byte("b")))
+}
+
+func TestMemStoreFlushBloomFilterSizedForLiveKeys(t *testing.T) {
+	m := newMemStoreTest()
+	for i := 0; i < 1000; i++ {
+		require.NoError(t, m.Add([]byte(fmt.Sprintf("key%05d", i)), []byte("val")))
+	}
+	for i := 0; i < 900; i++ {
+		require.NoError(t, m.Delete([]byte(fmt.Sprintf("key%05d", i))))
+	}
+
+	for expectedElements, flush := range map[uint64]func(...sstables.WriterOption) error{
+		100:  m.Flush,
+		1000: m.FlushWithTombstones,
+	} {
+		tmpDir, err := os.MkdirTemp("", "memstore_flush_bloom")
+		require.NoError(t, err)
+
+		require.NoError(t, flush(sstables.EnableBloomFilter(), sstables.WriteBasePath(tmpDir)))
+		filter, _, err := bloomfilter.ReadFile(filepath.Join(tmpDir, sstables.BloomFileName))
+		require.NoError(t, err)
+		expected, err := bloomfilter.NewOptimal(expectedElements, 0.01)
+		require.NoError(t, err)
+		assert.Equal(t, expected.M(), filter.M())
+		require.NoError(t, os.RemoveAll(tmpDir))
+	}
+}
+
 func TestMemStoreFlushTombStonesIgnore(t *testing.T) {
 	m := newMemStoreTest()
 	err := m.Upsert([]byte("akey"), []byte("aval"))
@@ -345,6 +711,35 @@ func TestMemStoreSStableIteratorWithTombstones(t *testing.T) {
 	assert.Equal(t, sstables.Done, err)
 }
 
+func TestMemStoreIteratorStartingAt(t *testing.T) {
+	m := newMemStoreTest()
+	for _, prefix := range []string{"a", "b", "c", "d", "e"} {
+		assert.Nil(t, m.Upsert([]byte(prefix+"key"), []byte(prefix+"val")))
+	}
+	assert.Nil(t, m.Delete([]byte("ckey")))
+	assert.Nil(t, m.Tombstone([]byte("dkey")))
+
+	for start, expected := range map[string][]string{
+		"":     {"a", "b", "e"},
+		"akey": {"a", "b", "e"},
+		"b":    {"b", "e"},
+		"bkey": {"b", "e"},
+		"c":    {"e"},
+		"ekey": {"e"},
+		"f":    {},
+	} {
+		it := m.IteratorStartingAt([]byte(start))
+		for _, e := range expected {
+			actualKey, actualValue, err := it.Next()
+			require.Nil(t, err)
+			assert.Equal(t, e+"key", string(actualKey))
+			assert.Equal(t, e+"val", string(actualValue))
+		}
+		_, _, err := it.Next()
+		assert.Equal(t, sstables.Done, err)
+	}
+}
+
 func TestMemStoreTombstoneExistingKey(t *testing.T) {
 	m := newMemStoreTest()
 	assert.Equal(t, 0, m.Size())
@@ -374,6 +769,99 @@ func TestMemStoreTombstoneNotExistingKey(t *testing.T) {
 	assert.Equal(t, KeyTombstoned, err)
 }
 
+func TestMemStoreFlushDuplicateKey(t *testing.T) {
+	m := newMemStoreWithBrokenComparator(insertEqualKeysAfter{})
+	m.skipListMap.Insert([]byte("a"), ValueStruct{value: &[]byte{1}})
+	m.skipListMap.Insert([]byte("b"), ValueStruct{value: &[]byte{2}})
+	m.skipListMap.Insert([]byte("b"), ValueStruct{value: &[]byte{3}})
+	m.skipListMap.Insert([]byte("c"), ValueStruct{value: &[]byte{4}})
+
+	tmpDir, err := os.MkdirTemp("", "memstore_duplicate")
+	require.Nil(t, err)
+	defer func() { require.Nil(t, os.RemoveAll(tmpDir)) }()
+
+	err = m.Flush(sstables.WriteBasePath(tmpDir))
+	assert.ErrorIs(t, err, DuplicateKey)
+	assert.ErrorContains(t, err, "at key [98]")
+
+	resolvedDir, err := os.MkdirTemp("", "memstore_resolved")
+	require.Nil(t, err)
+	defer func() { require.Nil(t, os.RemoveAll(resolvedDir)) }()
+	err = m.FlushWithResolver(func(key []byte, first []byte, second []byte) []byte {
+		assert.Equal(t, []byte("b"), key)
+		return append(append([]byte{}, first...), second...)
+	}, sstables.WriteBasePath(resolvedDir))
+	require.Nil(t, err)
+
+	reader, err := sstables.NewSSTableReader(sstables.ReadBasePath(resolvedDir))
+	require.Nil(t, err)
+	defer closeReader(t, reader)
+	assert.Equal(t, uint64(3), reader.MetaData().NumRecords)
+	v, err := reader.Get([]byte("b"))
+	require.Nil(t, err)
+	assert.Equal(t, []byte{2, 3}, v)
+	v, err = reader.Get([]byte("c"))
+	require.Nil(t, err)
+	assert.Equal(t, []byte{4}, v)
+}
+
+func TestMemStoreFlushResolvesToTombstone(t *testing.T) {
+	m := newMemStoreWithBrokenComparator(insertEqualKeysAfter{})
+	m.skipListMap.Insert([]byte("a"), ValueStruct{value: &[]byte{1}})
+	m.skipListMap.Insert([]byte("a"), ValueStruct{value: &[]byte{2}})
+
+	tmpDir, err := os.MkdirTemp("", "memstore_duplicate")
+	require.Nil(t, err)
+	defer func() { require.Nil(t, os.RemoveAll(tmpDir)) }()
+
+	err = m.FlushWithResolver(func(key []byte, first []byte, second []byte) []byte {
+		return nil
+	}, sstables.WriteBasePath(tmpDir))
+	require.Nil(t, err)
+
+	reader, err := sstables.NewSSTableReader(sstables.ReadBasePath(tmpDir))
+	require.Nil(t, err)
+	defer closeReader(t, reader)
+	assert.Equal(t, uint64(0), reader.MetaData().NumRecords)
+}
+
+func TestMemStoreFlushKeyOutOfOrder(t *testing.T) {
+	m := newMemStoreWithBrokenComparator(descendingComparator{})
+	m.skipListMap.Insert([]byte("a"), ValueStruct{value: &[]byte{1}})
+	m.skipListMap.Insert([]byte("b"), ValueStruct{value: &[]byte{2}})
+
+	tmpDir, err := os.MkdirTemp("", "memstore_order")
+	require.Nil(t, err)
+	defer func() { require.Nil(t, os.RemoveAll(tmpDir)) }()
+
+	err = m.FlushWithResolver(func(key []byte, first []byte, second []byte) []byte {
+		return second
+	}, sstables.WriteBasePath(tmpDir))
+	assert.ErrorIs(t, err, KeyOutOfOrder)
+}
+
+// insertEqualKeysAfter never considers two keys equal, so the skip list inserts duplicates after the existing key
+type insertEqualKeysAfter struct{}
+
+func (insertEqualKeysAfter) Compare(a []byte, b []byte) int {
+	if c := bytes.Compare(a, b); c != 0 {
+		return c
+	}
+	return 1
+}
+
+type descendingComparator struct{}
+
+func (descendingComparator) Compare(a []byte, b []byte) int {
+	return bytes.Compare(b, a)
+}
+
+// newMemStoreWithBrokenComparator returns a memstore whose skip list is ordered by cmp, while the flush still checks
+// the order with the bytes comparator
+func newMemStoreWithBrokenComparator(cmp skiplist.Comparator[[]byte]) *MemStore {
+	return &MemStore{skipListMap: skiplist.NewSkipListMap[[]byte, ValueStruct](cmp), comparator: skiplist.BytesComparator{}}
+}
+
 func closeReader(t *testing.T, reader sstables.SSTableReaderI) {
 	func() { assert.Nil(t, reader.Close()) }()
 }
@@ -6,7 +6,6 @@ import (
 
 	"io"
 
-	pool "capnproto.org/go/capnp/v3/exp/bufferpool"
 	"github.com/thomasjungblut/go-sstables/recordio/compressor"
 )
 
@@ -14,9 +13,93 @@ type Header struct {
 	compressionType int
 	compressor      compressor.CompressionI
 	fileVersion     uint32
+	// hasSchemaID is true when the header is followed by a schema id, see SchemaID
+	hasSchemaID bool
+	schemaID    uint32
+	// hasFooter is true when the file ends with a footer index, see FooterIndex
+	hasFooter bool
+	// flags are the flags of a v4 header, always zero for older versions
+	flags uint32
+}
+
+// size returns the number of bytes of the header including the flags and the schema id
+func (h *Header) size() uint64 {
+	if h.hasSchemaID {
+		return FileHeaderWithSchemaIDSizeBytes
+	}
+	if h.fileVersion >= Version4 {
+		return FileHeaderV4SizeBytes
+	}
+	return FileHeaderSizeBytes
+}
+
+// readFileHeader reads the whole file header: the version and compression type, the flags of v4 headers and the
+// schema id if there is one.
+func readFileHeader(r io.Reader) (*Header, error) {
+	buf := make([]byte, FileHeaderSizeBytes)
+	_, err := io.ReadFull(r, buf)
+	if err != nil {
+		return nil, fmt.Errorf("error while reading header bytes: %w", err)
+	}
+
+	header, err := readFileHeaderFromBuffer(buf)
+	if err != nil {
+		return nil, err
+	}
+
+	if header.fileVersion >= Version4 {
+		flags := buf[:FileHeaderV4SizeBytes-FileHeaderSizeBytes]
+		_, err = io.ReadFull(r, flags)
+		if err != nil {
+			return nil, fmt.Errorf("error while reading header flags: %w", err)
+		}
+		err = header.readFlagsFromBuffer(flags)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if header.hasSchemaID {
+		schemaID := buf[:FileHeaderWithSchemaIDSizeBytes-FileHeaderV4SizeBytes]
+		_, err = io.ReadFull(r, schemaID)
+		if err != nil {
+			return nil, fmt.Errorf("error while reading schema id: %w", err)
+		}
+		err = header.readSchemaIDFromBuffer(schemaID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return header, nil
+}
+
+// readFlagsFromBuffer parses the flags that follow a v4 header, unknown flags are rejected as the file could use a
+// feature that this version can't read
+func (h *Header) readFlagsFromBuffer(buffer []byte) error {
+	if len(buffer) != FileHeaderV4SizeBytes-FileHeaderSizeBytes {
+		return fmt.Errorf("header flags buffer size mismatch, expected %d but was %d", FileHeaderV4SizeBytes-FileHeaderSizeBytes, len(buffer))
+	}
+	h.flags = binary.LittleEndian.Uint32(buffer)
+	if h.flags&^fileHeaderFlagsKnown != 0 {
+		return fmt.Errorf("unknown header flags %#x", h.flags)
+	}
+	h.hasSchemaID = h.flags&fileHeaderFlagSchemaID != 0
+	h.hasFooter = h.flags&fileHeaderFlagFooter != 0
+	return nil
+}
+
+// readSchemaIDFromBuffer parses the schema id that follows a v4 header with the fileHeaderFlagSchemaID
+func (h *Header) readSchemaIDFromBuffer(buffer []byte) error {
+	if len(buffer) != FileHeaderWithSchemaIDSizeBytes-FileHeaderV4SizeBytes {
+		return fmt.Errorf("schema id buffer size mismatch, expected %d but was %d", FileHeaderWithSchemaIDSizeBytes-FileHeaderV4SizeBytes, len(buffer))
+	}
+	h.schemaID = binary.LittleEndian.Uint32(buffer)
+	return nil
 }
 
 var MagicNumberMismatchErr = fmt.Errorf("magic number mismatch")
+var NotARecordBoundaryErr = fmt.Errorf("offset is not at a record boundary")
 
 func readFileHeaderFromBuffer(buffer []byte) (*Header, error) {
 	if len(buffer) != FileHeaderSizeBytes {
@@ -79,57 +162,71 @@ func readRecordHeaderV2(r io.ByteReader) (uint64, uint64, error) {
 	return payloadSizeUncompressed, payloadSizeCompressed, nil
 }
 
-func readRecordHeaderV3(r io.ByteReader) (uint64, uint64, bool, error) {
+// readRecordHeaderV3 returns the uncompressed and the compressed payload size and the flags byte of the record
+func readRecordHeaderV3(r io.ByteReader) (uint64, uint64, byte, error) {
 	// TODO(thomas): V4 will need some kind of CRC hash to ensure that this header is valid
 	// currently we can read a valid magic number inside some data chunk, and a totally invalid
 	// remainder of the header e.g. a nil record bit or an uncompressed run-length that's huge and going past EOF.
 
 	magicNumber, err := binary.ReadUvarint(r)
 	if err != nil {
-		return 0, 0, false, err
+		return 0, 0, 0, err
 	}
 	if magicNumber != MagicNumberSeparatorLong {
-		return 0, 0, false, MagicNumberMismatchErr
+		return 0, 0, 0, MagicNumberMismatchErr
 	}
 
-	recordNil, err := r.ReadByte()
+	flags, err := r.ReadByte()
 	if err != nil {
-		return 0, 0, false, err
+		return 0, 0, 0, err
+	}
+	if flags&^recordFlagsKnown != 0 {
+		return 0, 0, 0, fmt.Errorf("unknown record flags %#x", flags)
 	}
 
 	payloadSizeUncompressed, err := binary.ReadUvarint(r)
 	if err != nil {
-		return 0, 0, false, err
+		return 0, 0, 0, err
 	}
 
 	payloadSizeCompressed, err := binary.ReadUvarint(r)
 	if err != nil {
-		return 0, 0, false, err
+		return 0, 0, 0, err
 	}
 
-	return payloadSizeUncompressed, payloadSizeCompressed, recordNil == 1, nil
+	return payloadSizeUncompressed, payloadSizeCompressed, flags, nil
 }
 
-func allocateRecordBuffer(header *Header, payloadSizeUncompressed uint64, payloadSizeCompressed uint64) (uint64, []byte) {
+// isCompressed returns whether the payload of a record with the given flags needs to be decompressed
+func isCompressed(header *Header, flags byte) bool {
+	return header.compressor != nil && flags&recordFlagUncompressed == 0
+}
+
+func allocateRecordBuffer(compressed bool, payloadSizeUncompressed uint64, payloadSizeCompressed uint64) (uint64, []byte) {
 	expectedBytesRead := payloadSizeUncompressed
-	if header.compressor != nil {
+	if compressed {
 		expectedBytesRead = payloadSizeCompressed
 	}
 
 	return expectedBytesRead, make([]byte, expectedBytesRead)
 }
 
-func allocateRecordBufferPooled(bufferPool *pool.Pool, header *Header, payloadSizeUncompressed uint64, payloadSizeCompressed uint64) (uint64, []byte) {
+func allocateRecordBufferPooled(bufferPool BufferPool, compressed bool, payloadSizeUncompressed uint64, payloadSizeCompressed uint64) (uint64, []byte) {
 	expectedBytesRead := payloadSizeUncompressed
-	if header.compressor != nil {
+	if compressed {
 		expectedBytesRead = payloadSizeCompressed
 	}
 
 	return expectedBytesRead, bufferPool.Get(int(expectedBytesRead))
 }
 
-func copyBuf(b []byte) []byte {
-	bx := make([]byte, len(b))
-	copy(bx, b)
-	return bx
+// copyInto copies b into dst, which is only reallocated when its capacity is too small. A nil dst always allocates,
+// so empty records stay distinguishable from nil records.
+func copyInto(dst []byte, b []byte) []byte {
+	if dst == nil || cap(dst) < len(b) {
+		dst = make([]byte, len(b))
+	}
+	dst = dst[:len(b)]
+	copy(dst, b)
+	return dst
 }
@@ -1,5 +1,7 @@
 package compressor
 
+import "io"
+
 type CompressionI interface {
 	// Compress compresses the given record of bytes
 	Compress(record []byte) ([]byte, error)
@@ -15,3 +17,15 @@ type CompressionI interface {
 	// Thus, it's important to use the returned buffer value.
 	DecompressWithBuf(buf []byte, destinationBuffer []byte) ([]byte, error)
 }
+
+// StreamingCompressionI is implemented by compressors that can compress and decompress a record incrementally, without
+// holding it in memory as a whole. The streamed payload is the same as the one of Compress, so a streamed payload can
+// be read with Decompress and a payload of Compress can be read with NewDecompressReader.
+type StreamingCompressionI interface {
+	// NewCompressWriter returns a writer that compresses everything written to it into w, the payload is complete once
+	// the writer is closed. The writer may seek back in w to update the start of the payload, it always seeks back to
+	// the end of the payload before Close returns.
+	NewCompressWriter(w io.WriteSeeker) (io.WriteCloser, error)
+	// NewDecompressReader returns a reader over the decompressed payload that is read from r
+	NewDecompressReader(r io.Reader) (io.Reader, error)
+}
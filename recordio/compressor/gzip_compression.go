@@ -3,25 +3,37 @@ package compressor
 import (
 	"bytes"
 	"compress/gzip"
+	"io"
 )
 
 type GzipCompressor struct {
+	// Level is the gzip compression level, it's only used when LevelSet is true so that zero can select
+	// gzip.NoCompression. Otherwise, gzip.DefaultCompression is used.
+	Level    int
+	LevelSet bool
 }
 
 func (c *GzipCompressor) Compress(record []byte) ([]byte, error) {
 	var buf bytes.Buffer
-	return compressWithBytesBuffer(record, &buf)
+	return compressWithBytesBuffer(record, &buf, c.level())
 }
 
 func (c *GzipCompressor) CompressWithBuf(record []byte, destinationBuffer []byte) ([]byte, error) {
 	// we have to set the length of the buffer (keeping capacity) to make sure gzip doesn't append
 	destinationBuffer = destinationBuffer[:0]
 	buf := bytes.NewBuffer(destinationBuffer)
-	return compressWithBytesBuffer(record, buf)
+	return compressWithBytesBuffer(record, buf, c.level())
 }
 
-func compressWithBytesBuffer(record []byte, buf *bytes.Buffer) ([]byte, error) {
-	writer, err := gzip.NewWriterLevel(buf, gzip.DefaultCompression)
+func (c *GzipCompressor) level() int {
+	if !c.LevelSet {
+		return gzip.DefaultCompression
+	}
+	return c.Level
+}
+
+func compressWithBytesBuffer(record []byte, buf *bytes.Buffer, level int) ([]byte, error) {
+	writer, err := gzip.NewWriterLevel(buf, level)
 	if err != nil {
 		return nil, err
 	}
@@ -67,3 +79,11 @@ func (c *GzipCompressor) DecompressWithBuf(buf []byte, destinationBuffer []byte)
 
 	return resultBuffer.Bytes(), nil
 }
+
+func (c *GzipCompressor) NewCompressWriter(w io.WriteSeeker) (io.WriteCloser, error) {
+	return gzip.NewWriterLevel(w, c.level())
+}
+
+func (c *GzipCompressor) NewDecompressReader(r io.Reader) (io.Reader, error) {
+	return gzip.NewReader(r)
+}
@@ -1,8 +1,11 @@
 package compressor
 
 import (
-	"github.com/stretchr/testify/assert"
+	"compress/gzip"
+	"strings"
 	"testing"
+
+	"github.com/stretchr/testify/assert"
 )
 
 func TestSimpleGzipCompression(t *testing.T) {
@@ -49,3 +52,18 @@ func TestSimpleGzipCompressionWithLargerBuffer(t *testing.T) {
 	assert.Equal(t, 33, len(compressedBytes))
 	decompressAndCheck(t, &comp, compressedBytes, data, 9)
 }
+
+func TestGzipCompressionLevel(t *testing.T) {
+	data := []byte(strings.Repeat("some data", 100))
+
+	defaultLevel, err := (&GzipCompressor{}).Compress(data)
+	assert.Nil(t, err)
+	// zero is only gzip.NoCompression when the level is set explicitly
+	noCompression := &GzipCompressor{Level: gzip.NoCompression, LevelSet: true}
+	stored, err := noCompression.Compress(data)
+	assert.Nil(t, err)
+	assert.Less(t, len(defaultLevel), len(data))
+	assert.Greater(t, len(stored), len(data))
+
+	decompressAndCheck(t, noCompression, stored, string(data), len(data))
+}
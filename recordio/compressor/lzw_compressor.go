@@ -3,6 +3,7 @@ package compressor
 import (
 	"bytes"
 	"compress/lzw"
+	"io"
 )
 
 type LzwCompressor struct {
@@ -61,3 +62,11 @@ func (l LzwCompressor) DecompressWithBuf(buf []byte, destinationBuffer []byte) (
 
 	return resultBuffer.Bytes(), nil
 }
+
+func (l LzwCompressor) NewCompressWriter(w io.WriteSeeker) (io.WriteCloser, error) {
+	return lzw.NewWriter(w, lzw.LSB, 8), nil
+}
+
+func (l LzwCompressor) NewDecompressReader(r io.Reader) (io.Reader, error) {
+	return lzw.NewReader(r, lzw.LSB, 8), nil
+}
@@ -0,0 +1,245 @@
+package compressor
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"io"
+
+	"github.com/golang/snappy"
+)
+
+// snappyMaxBlockSize is the size of the blocks snappy.Encode splits its input into. Copies never reach back past the
+// start of their block, so a block can be encoded on its own and a stream only needs to keep the last block around.
+const snappyMaxBlockSize = 65536
+
+// snappyMaxLength is the largest payload the snappy block format can encode
+const snappyMaxLength = 0xffffffff
+
+// snappyLengthSizeBytes is the size of the length prefix of streamed payloads, it's padded to fit any length
+const snappyLengthSizeBytes = binary.MaxVarintLen32
+
+const (
+	snappyTagLiteral = 0x00
+	snappyTagCopy1   = 0x01
+	snappyTagCopy2   = 0x02
+	snappyTagCopy4   = 0x03
+)
+
+func (c *SnappyCompressor) NewCompressWriter(w io.WriteSeeker) (io.WriteCloser, error) {
+	start, err := w.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, err
+	}
+
+	sw := &snappyStreamWriter{w: w, start: start, end: start + snappyLengthSizeBytes}
+	// the length is only known once the writer is closed, until then the prefix is a placeholder
+	_, err = w.Write(putSnappyLength(make([]byte, snappyLengthSizeBytes), 0))
+	if err != nil {
+		return nil, err
+	}
+	return sw, nil
+}
+
+func (c *SnappyCompressor) NewDecompressReader(r io.Reader) (io.Reader, error) {
+	br := bufio.NewReader(r)
+	length, err := binary.ReadUvarint(br)
+	if err != nil {
+		return nil, unexpectedEOF(err)
+	}
+	if length > snappyMaxLength {
+		return nil, snappy.ErrCorrupt
+	}
+
+	return &snappyStreamReader{r: br, remaining: length}, nil
+}
+
+// putSnappyLength encodes the length of the payload like binary.PutUvarint, but always uses snappyLengthSizeBytes
+// bytes by setting the continuation bit on the leading bytes, so it can be overwritten in place.
+func putSnappyLength(buf []byte, x uint64) []byte {
+	for i := 0; i < snappyLengthSizeBytes-1; i++ {
+		buf[i] = byte(x) | 0x80
+		x >>= 7
+	}
+	buf[snappyLengthSizeBytes-1] = byte(x)
+	return buf[:snappyLengthSizeBytes]
+}
+
+// snappyStreamWriter writes the same block format as snappy.Encode, by encoding every snappyMaxBlockSize bytes of the
+// input on their own. The length prefix is updated once the writer is closed.
+type snappyStreamWriter struct {
+	w       io.WriteSeeker
+	start   int64
+	end     int64
+	length  uint64
+	block   []byte
+	encoded []byte
+	closed  bool
+}
+
+func (s *snappyStreamWriter) Write(p []byte) (int, error) {
+	if s.closed {
+		return 0, errors.New("snappy stream writer is already closed")
+	}
+	if s.length+uint64(len(p)) > snappyMaxLength {
+		return 0, snappy.ErrTooLarge
+	}
+
+	written := 0
+	for len(p) > 0 {
+		n := min(len(p), snappyMaxBlockSize-len(s.block))
+		s.block = append(s.block, p[:n]...)
+		p = p[n:]
+		if len(s.block) == snappyMaxBlockSize {
+			err := s.flushBlock()
+			if err != nil {
+				return written, err
+			}
+		}
+		written += n
+		s.length += uint64(n)
+	}
+	return written, nil
+}
+
+func (s *snappyStreamWriter) flushBlock() error {
+	s.encoded = snappy.Encode(s.encoded[:cap(s.encoded)], s.block)
+	// every block is encoded with its own length prefix, which is dropped in favor of the one of the whole payload
+	_, n := binary.Uvarint(s.encoded)
+	written, err := s.w.Write(s.encoded[n:])
+	s.end += int64(written)
+	s.block = s.block[:0]
+	return err
+}
+
+func (s *snappyStreamWriter) Close() error {
+	if s.closed {
+		return errors.New("snappy stream writer is already closed")
+	}
+	s.closed = true
+
+	if len(s.block) > 0 {
+		err := s.flushBlock()
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err := s.w.Seek(s.start, io.SeekStart)
+	if err != nil {
+		return err
+	}
+	_, err = s.w.Write(putSnappyLength(make([]byte, snappyLengthSizeBytes), s.length))
+	if err != nil {
+		return err
+	}
+	_, err = s.w.Seek(s.end, io.SeekStart)
+	return err
+}
+
+// snappyStreamReader decodes the snappy block format element by element. It keeps the last snappyMaxBlockSize
+// decoded bytes for the copies, the payload is never decoded as a whole.
+type snappyStreamReader struct {
+	r         *bufio.Reader
+	remaining uint64
+	// window contains the decoded bytes, the ones from pos on were not returned yet
+	window []byte
+	pos    int
+	err    error
+}
+
+func (s *snappyStreamReader) Read(p []byte) (int, error) {
+	for s.pos == len(s.window) {
+		if s.err != nil {
+			return 0, s.err
+		}
+		if s.remaining == 0 {
+			return 0, io.EOF
+		}
+		s.err = s.decodeNext()
+	}
+
+	n := copy(p, s.window[s.pos:])
+	s.pos += n
+	return n, nil
+}
+
+// decodeNext decodes the next literal or copy into the window
+func (s *snappyStreamReader) decodeNext() error {
+	if len(s.window) >= 2*snappyMaxBlockSize {
+		s.window = append(s.window[:0], s.window[len(s.window)-snappyMaxBlockSize:]...)
+		s.pos = len(s.window)
+	}
+
+	tag, err := s.r.ReadByte()
+	if err != nil {
+		return unexpectedEOF(err)
+	}
+
+	var length, offset uint64
+	var extra [4]byte
+	switch tag & 0x03 {
+	case snappyTagLiteral:
+		length = uint64(tag >> 2)
+		if length >= 60 {
+			n := int(length) - 59
+			_, err = io.ReadFull(s.r, extra[:n])
+			length = uint64(binary.LittleEndian.Uint32(extra[:]))
+		}
+		length++
+	case snappyTagCopy1:
+		length = 4 + uint64(tag>>2)&0x7
+		extra[0], err = s.r.ReadByte()
+		offset = uint64(tag&0xe0)<<3 | uint64(extra[0])
+	case snappyTagCopy2:
+		length = 1 + uint64(tag>>2)
+		_, err = io.ReadFull(s.r, extra[:2])
+		offset = uint64(binary.LittleEndian.Uint16(extra[:2]))
+	case snappyTagCopy4:
+		length = 1 + uint64(tag>>2)
+		_, err = io.ReadFull(s.r, extra[:4])
+		offset = uint64(binary.LittleEndian.Uint32(extra[:4]))
+	}
+	if err != nil {
+		return unexpectedEOF(err)
+	}
+	if length > s.remaining {
+		return snappy.ErrCorrupt
+	}
+
+	start := len(s.window)
+	if tag&0x03 == snappyTagLiteral {
+		s.window = append(s.window, make([]byte, length)...)
+		_, err = io.ReadFull(s.r, s.window[start:])
+		if err != nil {
+			return unexpectedEOF(err)
+		}
+	} else {
+		if offset == 0 || offset > uint64(start) {
+			return snappy.ErrCorrupt
+		}
+		// the copy can overlap with the bytes it produces, so it's done in steps of at most offset bytes
+		for copied := uint64(0); copied < length; {
+			from := uint64(len(s.window)) - offset
+			n := min(length-copied, offset)
+			s.window = append(s.window, s.window[from:from+n]...)
+			copied += n
+		}
+	}
+
+	s.remaining -= length
+	if s.remaining == 0 {
+		// like snappy.Decode, there must not be anything left after the last element
+		if _, err := s.r.ReadByte(); !errors.Is(err, io.EOF) {
+			return snappy.ErrCorrupt
+		}
+	}
+	return nil
+}
+
+func unexpectedEOF(err error) error {
+	if errors.Is(err, io.EOF) {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
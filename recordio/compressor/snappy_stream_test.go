@@ -0,0 +1,112 @@
+package compressor
+
+import (
+	"bytes"
+	"io"
+	"math/rand"
+	"testing"
+
+	"github.com/golang/snappy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memWriteSeeker is an in-memory io.WriteSeeker that starts with the given prefix
+type memWriteSeeker struct {
+	buf []byte
+	pos int
+}
+
+func (m *memWriteSeeker) Write(p []byte) (int, error) {
+	if end := m.pos + len(p); end > len(m.buf) {
+		m.buf = append(m.buf, make([]byte, end-len(m.buf))...)
+	}
+	m.pos += copy(m.buf[m.pos:], p)
+	return len(p), nil
+}
+
+func (m *memWriteSeeker) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekCurrent:
+		offset += int64(m.pos)
+	case io.SeekEnd:
+		offset += int64(len(m.buf))
+	}
+	m.pos = int(offset)
+	return offset, nil
+}
+
+// streamTestRecord returns compressible data with matches across the snappy block boundaries
+func streamTestRecord(size int) []byte {
+	rnd := rand.New(rand.NewSource(42))
+	record := make([]byte, size)
+	for i := range record {
+		if i > 1000 && rnd.Intn(4) > 0 {
+			record[i] = record[i-1000+rnd.Intn(10)]
+		} else {
+			record[i] = byte(rnd.Intn(16))
+		}
+	}
+	return record
+}
+
+func TestStreamingCompressionRoundTrip(t *testing.T) {
+	compressors := []CompressionI{&SnappyCompressor{}, &GzipCompressor{}, LzwCompressor{}}
+	for _, c := range compressors {
+		for _, size := range []int{0, 1, 100, snappyMaxBlockSize, snappyMaxBlockSize + 1, 5*snappyMaxBlockSize + 17} {
+			record := streamTestRecord(size)
+			sc := c.(StreamingCompressionI)
+
+			out := &memWriteSeeker{buf: []byte("prefix"), pos: 6}
+			w, err := sc.NewCompressWriter(out)
+			require.NoError(t, err)
+			// uneven writes to cross the block boundaries at different places
+			for rest := record; len(rest) > 0; {
+				n := min(len(rest), 7777)
+				written, err := w.Write(rest[:n])
+				require.NoError(t, err)
+				assert.Equal(t, n, written)
+				rest = rest[n:]
+			}
+			require.NoError(t, w.Close())
+			assert.Equal(t, len(out.buf), out.pos)
+			assert.Equal(t, []byte("prefix"), out.buf[:6])
+
+			// the streamed payload is the same format as the one of Compress
+			decompressed, err := c.Decompress(out.buf[6:])
+			require.NoError(t, err)
+			assert.Equal(t, len(record), len(decompressed))
+			assert.True(t, bytes.Equal(record, decompressed))
+
+			compressed, err := c.Compress(record)
+			require.NoError(t, err)
+			r, err := sc.NewDecompressReader(bytes.NewReader(compressed))
+			require.NoError(t, err)
+			streamed, err := io.ReadAll(r)
+			require.NoError(t, err)
+			assert.True(t, bytes.Equal(record, streamed))
+		}
+	}
+}
+
+func TestSnappyStreamReaderCorrupt(t *testing.T) {
+	c := &SnappyCompressor{}
+	compressed, err := c.Compress(streamTestRecord(3 * snappyMaxBlockSize))
+	require.NoError(t, err)
+
+	r, err := c.NewDecompressReader(bytes.NewReader(compressed[:len(compressed)/2]))
+	require.NoError(t, err)
+	_, err = io.ReadAll(r)
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+
+	r, err = c.NewDecompressReader(bytes.NewReader(append(compressed, 0)))
+	require.NoError(t, err)
+	_, err = io.ReadAll(r)
+	assert.ErrorIs(t, err, snappy.ErrCorrupt)
+
+	// a copy that reaches back before the start of the payload
+	r, err = c.NewDecompressReader(bytes.NewReader([]byte{4, snappyTagCopy2, 5, 0}))
+	require.NoError(t, err)
+	_, err = io.ReadAll(r)
+	assert.ErrorIs(t, err, snappy.ErrCorrupt)
+}
@@ -0,0 +1,68 @@
+package recordio
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// preloadChunkBytes is the size of the reads of LoadIntoPageCache and MMapReader.Preload
+const preloadChunkBytes = 1024 * 1024
+
+// DropFromPageCache syncs the file at the given path to disk and advises the kernel that its pages are not needed
+// anymore with posix_fadvise(POSIX_FADV_DONTNEED), so that a large file that was just written doesn't push out other
+// cached data. This is only a hint and a no-op on operating systems other than Linux.
+func DropFromPageCache(path string) (err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("error while opening '%s' to drop it from the page cache: %w", path, err)
+	}
+
+	defer func() {
+		err = errors.Join(err, f.Close())
+	}()
+
+	// dirty pages can't be dropped, they need to be written first
+	err = f.Sync()
+	if err != nil {
+		return fmt.Errorf("error while syncing '%s' to drop it from the page cache: %w", path, err)
+	}
+
+	err = fadvise(f, fadviseDontNeed)
+	if err != nil {
+		return fmt.Errorf("error while dropping '%s' from the page cache: %w", path, err)
+	}
+	return nil
+}
+
+// LoadIntoPageCache reads the file at the given path sequentially, so that its pages are cached by the kernel before
+// they are read with random access. It returns the number of bytes read, along with the error of ctx when it was done
+// before the end of the file.
+func LoadIntoPageCache(ctx context.Context, path string) (n uint64, err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, fmt.Errorf("error while opening '%s' to load it into the page cache: %w", path, err)
+	}
+
+	defer func() {
+		err = errors.Join(err, f.Close())
+	}()
+
+	buf := make([]byte, preloadChunkBytes)
+	for {
+		if err := ctx.Err(); err != nil {
+			return n, err
+		}
+
+		read, err := f.Read(buf)
+		n += uint64(read)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return n, nil
+			}
+			return n, fmt.Errorf("error while loading '%s' into the page cache: %w", path, err)
+		}
+	}
+}
@@ -0,0 +1,22 @@
+//go:build linux && (amd64 || arm64 || riscv64 || ppc64le || loong64)
+
+package recordio
+
+import (
+	"os"
+	"syscall"
+)
+
+const (
+	fadviseSequential = 2
+	fadviseDontNeed   = 4
+)
+
+// fadvise calls posix_fadvise for the whole file
+func fadvise(f *os.File, advice int) error {
+	_, _, errno := syscall.Syscall6(syscall.SYS_FADVISE64, f.Fd(), 0, 0, uintptr(advice), 0, 0)
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
@@ -0,0 +1,17 @@
+//go:build !(linux && (amd64 || arm64 || riscv64 || ppc64le || loong64))
+
+package recordio
+
+import (
+	"os"
+)
+
+const (
+	fadviseSequential = 2
+	fadviseDontNeed   = 4
+)
+
+// fadvise is a no-op, posix_fadvise is only called on Linux
+func fadvise(_ *os.File, _ int) error {
+	return nil
+}
@@ -0,0 +1,82 @@
+package recordio
+
+import (
+	"context"
+	"io"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDropFromPageCache(t *testing.T) {
+	writer := newOpenedWriter(t)
+	defer removeFileWriterFile(t, writer)
+	_, err := writer.Write(ascendingBytes(4096))
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+
+	require.NoError(t, DropFromPageCache(writer.file.Name()))
+
+	// the advice doesn't change the content of the file
+	reader, err := NewFileReaderWithPath(writer.file.Name())
+	require.NoError(t, err)
+	require.NoError(t, reader.Open())
+	defer func() { require.NoError(t, reader.Close()) }()
+	buf, err := reader.ReadNext()
+	require.NoError(t, err)
+	assert.Equal(t, ascendingBytes(4096), buf)
+}
+
+func TestDropFromPageCacheMissingFile(t *testing.T) {
+	require.Error(t, DropFromPageCache(filepath.Join(t.TempDir(), "missing")))
+}
+
+func TestReaderAdviseSequential(t *testing.T) {
+	writer := newOpenedWriter(t)
+	defer removeFileWriterFile(t, writer)
+	for i := 0; i < 100; i++ {
+		_, err := writer.Write(ascendingBytes(i))
+		require.NoError(t, err)
+	}
+	require.NoError(t, writer.Close())
+
+	reader, err := NewFileReader(ReaderPath(writer.file.Name()), ReaderAdviseSequential())
+	require.NoError(t, err)
+	require.NoError(t, reader.Open())
+	defer func() { require.NoError(t, reader.Close()) }()
+	for i := 0; i < 100; i++ {
+		buf, err := reader.ReadNext()
+		require.NoError(t, err)
+		assert.Equal(t, ascendingBytes(i), buf)
+	}
+	_, err = reader.ReadNext()
+	assert.ErrorIs(t, err, io.EOF)
+}
+
+func TestLoadIntoPageCache(t *testing.T) {
+	writer := newOpenedWriter(t)
+	defer removeFileWriterFile(t, writer)
+	// larger than a single chunk
+	for i := 0; i < 3; i++ {
+		_, err := writer.Write(ascendingBytes(preloadChunkBytes / 2))
+		require.NoError(t, err)
+	}
+	require.NoError(t, writer.Close())
+
+	n, err := LoadIntoPageCache(context.Background(), writer.file.Name())
+	require.NoError(t, err)
+	assert.Equal(t, writer.Size(), n)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	n, err = LoadIntoPageCache(ctx, writer.file.Name())
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, uint64(0), n)
+}
+
+func TestLoadIntoPageCacheMissingFile(t *testing.T) {
+	_, err := LoadIntoPageCache(context.Background(), filepath.Join(t.TempDir(), "missing"))
+	require.Error(t, err)
+}
@@ -18,6 +18,13 @@ type FileReader struct {
 	header        *Header
 	reader        ByteReaderResetCount
 	bufferPool    *pool.Pool
+	// readAhead is only set with ReaderReadAheadBytes
+	readAhead      *readAheadReader
+	readAheadBytes int
+	// footer is only set for files with a complete footer index, the records end where it starts
+	footer *footerIndex
+	// tail positions the reader at the last tail records on Open, see ReaderTail
+	tail int
 }
 
 func (r *FileReader) Open() error {
@@ -30,41 +37,77 @@ func (r *FileReader) Open() error {
 	}
 
 	// try to read the file header
-	bytes := make([]byte, FileHeaderSizeBytes)
-	numRead, err := io.ReadFull(r.reader, bytes)
+	var err error
+	r.header, err = readFileHeader(r.reader)
 	if err != nil {
-		return fmt.Errorf("error while reading header bytes of '%s': %w", r.file.Name(), err)
+		return fmt.Errorf("error while parsing header of '%s': %w", r.file.Name(), err)
 	}
 
-	if numRead != len(bytes) {
-		return fmt.Errorf("not enough bytes found in the header, expected %d but were %d", len(bytes), numRead)
-	}
+	r.currentOffset = r.header.size()
 
-	r.header, err = readFileHeaderFromBuffer(bytes)
-	if err != nil {
-		return fmt.Errorf("error while parsing header of '%s': %w", r.file.Name(), err)
+	if r.header.hasFooter {
+		stat, err := r.file.Stat()
+		if err != nil {
+			return fmt.Errorf("error while getting the size of '%s': %w", r.file.Name(), err)
+		}
+		r.footer, err = readFooter(r.file, uint64(stat.Size()), r.header.size())
+		if err != nil {
+			return fmt.Errorf("error while reading footer of '%s': %w", r.file.Name(), err)
+		}
 	}
 
-	r.currentOffset = uint64(len(bytes))
-
 	r.bufferPool = pool.NewPool(1024, 20)
 	r.open = true
 
+	if r.tail > 0 {
+		err = r.seekToTail(r.tail)
+		if err != nil {
+			return fmt.Errorf("error while seeking to the last %d records of '%s': %w", r.tail, r.file.Name(), err)
+		}
+	}
+
 	return nil
 }
 
+// atFooter returns true when all records before the footer index were read
+func (r *FileReader) atFooter() bool {
+	return r.footer != nil && r.currentOffset >= r.footer.recordsEnd
+}
+
+// SchemaID returns the schema id of the file header, see SchemaIDReaderI.
+func (r *FileReader) SchemaID() (uint32, bool) {
+	if r.header == nil {
+		return 0, false
+	}
+	return r.header.schemaID, r.header.hasSchemaID
+}
+
+// Offset returns the offset of the next record in the file, which is the number of bytes that were consumed so far.
+func (r *FileReader) Offset() uint64 {
+	return r.currentOffset
+}
+
 func (r *FileReader) ReadNext() ([]byte, error) {
+	return r.ReadNextInto(nil)
+}
+
+// ReadNextInto reads the next record into dst, see IntoReaderI. Nil records return nil.
+func (r *FileReader) ReadNextInto(dst []byte) ([]byte, error) {
 	if !r.open || r.closed {
 		return nil, fmt.Errorf("file reader for '%s' was either not opened yet or is closed already", r.file.Name())
 	}
 
 	if r.header.fileVersion == Version1 {
-		return readNextV1(r)
+		return readNextV1(r, dst)
 	} else if r.header.fileVersion == Version2 {
-		return readNextV2(r)
+		return readNextV2(r, dst)
 	} else {
+		if r.atFooter() {
+			return nil, io.EOF
+		}
+
 		start := r.reader.Count()
-		payloadSizeUncompressed, payloadSizeCompressed, recordNil, err := readRecordHeaderV3(r.reader)
+		payloadSizeUncompressed, payloadSizeCompressed, flags, err := readRecordHeaderV3(r.reader)
 		if err != nil {
 			// due to the use of blocked writes in DirectIO, we need to test whether the remainder of the file contains only zeros.
 			// This would indicate a properly written file and the actual end - and not a malformed record.
@@ -83,19 +126,29 @@ func (r *FileReader) ReadNext() ([]byte, error) {
 				return nil, io.EOF
 			}
 
+			// a header that ends with the file was torn by a crash during the write, not the clean end of the file
+			if errors.Is(err, io.EOF) && r.reader.Count() > start {
+				err = io.ErrUnexpectedEOF
+			}
+
 			return nil, fmt.Errorf("error while parsing record header of '%s': %w", r.file.Name(), err)
 		}
 
-		if recordNil {
+		if flags&recordFlagNil != 0 {
 			r.currentOffset = r.currentOffset + (r.reader.Count() - start)
 			return nil, nil
 		}
 
-		expectedBytesRead, pooledRecordBuffer := allocateRecordBufferPooled(r.bufferPool, r.header, payloadSizeUncompressed, payloadSizeCompressed)
+		compressed := isCompressed(r.header, flags)
+		expectedBytesRead, pooledRecordBuffer := allocateRecordBufferPooled(r.bufferPool, compressed, payloadSizeUncompressed, payloadSizeCompressed)
 		defer r.bufferPool.Put(pooledRecordBuffer)
 
 		numRead, err := io.ReadFull(r.reader, pooledRecordBuffer)
 		if err != nil {
+			// the header was read already, so a missing payload is always torn
+			if errors.Is(err, io.EOF) {
+				err = io.ErrUnexpectedEOF
+			}
 			return nil, fmt.Errorf("error while reading into record buffer of '%s': %w", r.file.Name(), err)
 		}
 
@@ -105,7 +158,7 @@ func (r *FileReader) ReadNext() ([]byte, error) {
 
 		// why not just r.currentOffset = r.reader.count? we could've skipped something in between which makes the counts inconsistent
 		r.currentOffset = r.currentOffset + (r.reader.Count() - start)
-		if r.header.compressor != nil {
+		if compressed {
 			pooledDecompressionBuffer := r.bufferPool.Get(int(payloadSizeUncompressed))
 			defer r.bufferPool.Put(pooledDecompressionBuffer)
 
@@ -114,12 +167,12 @@ func (r *FileReader) ReadNext() ([]byte, error) {
 				return nil, err
 			}
 
-			return copyBuf(buf), nil
+			return copyInto(dst, buf), nil
 		}
 
-		// TODO(thomas): copyBuf is a huge performance bottleneck, just returning the pooled buffer will
-		// immediately unlock 1.5x-2x more throughput
-		return copyBuf(pooledRecordBuffer), nil
+		// TODO(thomas): copying is a huge performance bottleneck, just returning the pooled buffer will
+		// immediately unlock 1.5x-2x more throughput, ReadNextInto at least avoids the allocation
+		return copyInto(dst, pooledRecordBuffer), nil
 	}
 }
 
@@ -133,19 +186,29 @@ func (r *FileReader) SkipNext() error {
 	} else if r.header.fileVersion == Version2 {
 		return SkipNextV2(r)
 	} else {
+		if r.atFooter() {
+			return fmt.Errorf("error while reading record header of '%s': %w", r.file.Name(), io.EOF)
+		}
+
 		start := r.reader.Count()
-		payloadSizeUncompressed, payloadSizeCompressed, _, err := readRecordHeaderV3(r.reader)
+		payloadSizeUncompressed, payloadSizeCompressed, flags, err := readRecordHeaderV3(r.reader)
 		if err != nil {
 			return fmt.Errorf("error while reading record header of '%s': %w", r.file.Name(), err)
 		}
 
 		expectedBytesSkipped := payloadSizeUncompressed
-		if r.header.compressor != nil {
+		if isCompressed(r.header, flags) {
 			expectedBytesSkipped = payloadSizeCompressed
 		}
 
+		// nil records only consist of their header, even though a compressed size might have been recorded
+		if flags&recordFlagNil != 0 {
+			expectedBytesSkipped = 0
+		}
+
 		// here we have to add the header to the offset too, otherwise we will seek not far enough
 		expectedOffset := int64(r.currentOffset + expectedBytesSkipped + (r.reader.Count() - start))
+		r.stopReadAhead()
 		newOffset, err := r.file.Seek(expectedOffset, 0)
 		if err != nil {
 			return fmt.Errorf("error while seeking to offset %d in '%s': %w", expectedOffset, r.file.Name(), err)
@@ -155,13 +218,23 @@ func (r *FileReader) SkipNext() error {
 			return fmt.Errorf("seeking in '%s' did not return expected offset %d, it was %d", r.file.Name(), expectedOffset, newOffset)
 		}
 
-		r.reader.Reset(r.file)
+		r.resetReader()
 		r.currentOffset = uint64(newOffset)
 	}
 
 	return nil
 }
 
+func (r *FileReader) Skip(n int) error {
+	for i := 0; i < n; i++ {
+		if err := r.SkipNext(); err != nil {
+			return fmt.Errorf("error while skipping record %d of %d in '%s': %w", i+1, n, r.file.Name(), err)
+		}
+	}
+
+	return nil
+}
+
 // SkipNextV1 is legacy support path for non-vint compressed V1
 func SkipNextV1(r *FileReader) error {
 	headerBuf := r.bufferPool.Get(RecordHeaderSizeBytesV1V2)
@@ -188,6 +261,7 @@ func SkipNextV1(r *FileReader) error {
 	}
 
 	expectedOffset := int64(r.currentOffset + expectedBytesSkipped)
+	r.stopReadAhead()
 	newOffset, err := r.file.Seek(expectedOffset, 0)
 	if err != nil {
 		return fmt.Errorf("error while seeking to offset %d in '%s': %w", expectedOffset, r.file.Name(), err)
@@ -198,7 +272,7 @@ func SkipNextV1(r *FileReader) error {
 	}
 
 	// reset the buffered reader after the seek
-	r.reader.Reset(r.file)
+	r.resetReader()
 
 	r.currentOffset = r.currentOffset + expectedBytesSkipped
 	return nil
@@ -218,6 +292,7 @@ func SkipNextV2(r *FileReader) error {
 
 	// here we have to add the header to the offset too, otherwise we will seek not far enough
 	expectedOffset := int64(r.currentOffset + expectedBytesSkipped + (r.reader.Count() - start))
+	r.stopReadAhead()
 	newOffset, err := r.file.Seek(expectedOffset, 0)
 	if err != nil {
 		return fmt.Errorf("error while seeking to offset %d in '%s': %w", expectedOffset, r.file.Name(), err)
@@ -227,7 +302,7 @@ func SkipNextV2(r *FileReader) error {
 		return fmt.Errorf("seeking in '%s' did not return expected offset %d, it was %d", r.file.Name(), expectedOffset, newOffset)
 	}
 
-	r.reader.Reset(r.file)
+	r.resetReader()
 	r.currentOffset = uint64(newOffset)
 	return nil
 }
@@ -235,11 +310,30 @@ func SkipNextV2(r *FileReader) error {
 func (r *FileReader) Close() error {
 	r.closed = true
 	r.open = false
+	// the read ahead needs to stop before the file is closed underneath it
+	r.stopReadAhead()
 	return r.file.Close()
 }
 
+// stopReadAhead waits until the read ahead stopped reading the file, which is required before seeking or closing it
+func (r *FileReader) stopReadAhead() {
+	if r.readAhead != nil {
+		_ = r.readAhead.Close()
+	}
+}
+
+// resetReader continues reading at the current file offset, the read ahead is restarted from there
+func (r *FileReader) resetReader() {
+	if r.readAheadBytes > 0 {
+		r.readAhead = newReadAheadReader(r.file, r.readAheadBytes)
+		r.reader.Reset(r.readAhead)
+		return
+	}
+	r.reader.Reset(r.file)
+}
+
 // legacy support path for non-vint compressed V1
-func readNextV1(r *FileReader) ([]byte, error) {
+func readNextV1(r *FileReader, dst []byte) ([]byte, error) {
 	headerBuf := r.bufferPool.Get(RecordHeaderSizeBytesV1V2)
 	defer r.bufferPool.Put(headerBuf)
 
@@ -258,7 +352,7 @@ func readNextV1(r *FileReader) ([]byte, error) {
 		return nil, fmt.Errorf("error while parsing record header of '%s': %w", r.file.Name(), err)
 	}
 
-	expectedBytesRead, recordBuffer := allocateRecordBuffer(r.header, payloadSizeUncompressed, payloadSizeCompressed)
+	expectedBytesRead, recordBuffer := allocateRecordBuffer(r.header.compressor != nil, payloadSizeUncompressed, payloadSizeCompressed)
 	numRead, err = io.ReadFull(r.reader, recordBuffer)
 	if err != nil {
 		return nil, fmt.Errorf("error while reading into record buffer of '%s': %w", r.file.Name(), err)
@@ -276,10 +370,13 @@ func readNextV1(r *FileReader) ([]byte, error) {
 	}
 
 	r.currentOffset = r.currentOffset + expectedBytesRead
+	if dst != nil {
+		return copyInto(dst, recordBuffer), nil
+	}
 	return recordBuffer, nil
 }
 
-func readNextV2(r *FileReader) ([]byte, error) {
+func readNextV2(r *FileReader, dst []byte) ([]byte, error) {
 	start := r.reader.Count()
 	payloadSizeUncompressed, payloadSizeCompressed, err := readRecordHeaderV2(r.reader)
 	if err != nil {
@@ -303,7 +400,7 @@ func readNextV2(r *FileReader) ([]byte, error) {
 		return nil, fmt.Errorf("error while parsing record header of '%s': %w", r.file.Name(), err)
 	}
 
-	expectedBytesRead, pooledRecordBuffer := allocateRecordBufferPooled(r.bufferPool, r.header, payloadSizeUncompressed, payloadSizeCompressed)
+	expectedBytesRead, pooledRecordBuffer := allocateRecordBufferPooled(r.bufferPool, r.header.compressor != nil, payloadSizeUncompressed, payloadSizeCompressed)
 	defer r.bufferPool.Put(pooledRecordBuffer)
 
 	numRead, err := io.ReadFull(r.reader, pooledRecordBuffer)
@@ -328,16 +425,14 @@ func readNextV2(r *FileReader) ([]byte, error) {
 			returnSlice = nil
 		} else {
 			// we do a defensive copy here not to leak the pooled slice
-			returnSlice = make([]byte, len(decompressedRecord))
-			copy(returnSlice, decompressedRecord)
+			returnSlice = copyInto(dst, decompressedRecord)
 		}
 	} else {
 		if pooledRecordBuffer == nil {
 			returnSlice = nil
 		} else {
 			// we do a defensive copy here not to leak the pooled slice
-			returnSlice = make([]byte, len(pooledRecordBuffer))
-			copy(returnSlice, pooledRecordBuffer)
+			returnSlice = copyInto(dst, pooledRecordBuffer)
 		}
 	}
 
@@ -352,6 +447,9 @@ type FileReaderOptions struct {
 	file            *os.File
 	bufferSizeBytes int
 	factory         IOFactory
+	readAheadBytes  int
+	adviseSeq       bool
+	tail            int
 }
 
 type FileReaderOption func(*FileReaderOptions)
@@ -387,6 +485,22 @@ func ReaderBufferSizeBytes(p int) FileReaderOption {
 	}
 }
 
+// ReaderReadAheadBytes reads up to the given number of bytes ahead of the current record in a separate goroutine, so
+// the next records are already in memory while the current one is decompressed. Disabled by default with zero.
+func ReaderReadAheadBytes(n int) FileReaderOption {
+	return func(args *FileReaderOptions) {
+		args.readAheadBytes = n
+	}
+}
+
+// ReaderAdviseSequential advises the kernel with posix_fadvise(POSIX_FADV_SEQUENTIAL) that the file is read from start
+// to end, which increases its read-ahead. This is only a hint and a no-op on operating systems other than Linux.
+func ReaderAdviseSequential() FileReaderOption {
+	return func(args *FileReaderOptions) {
+		args.adviseSeq = true
+	}
+}
+
 // NewFileReader creates a new reader with the given options, either Path or File must be supplied, compression is optional.
 func NewFileReader(readerOptions ...FileReaderOption) (ReaderI, error) {
 	opts := &FileReaderOptions{
@@ -404,18 +518,36 @@ func NewFileReader(readerOptions ...FileReaderOption) (ReaderI, error) {
 		return nil, errors.New("NewFileReader: either os.File or string path must be supplied, never both")
 	}
 
+	if opts.tail < 0 {
+		return nil, fmt.Errorf("NewFileReader: unexpected number of tail records, was: %d", opts.tail)
+	}
+
 	f, r, err := opts.factory.CreateNewReader(opts.path, opts.bufferSizeBytes)
 	if err != nil {
 		return nil, err
 	}
 
-	return &FileReader{
-		file:          f,
-		reader:        r,
-		open:          false,
-		closed:        false,
-		currentOffset: 0,
-	}, nil
+	if opts.adviseSeq {
+		if err := fadvise(f, fadviseSequential); err != nil {
+			return nil, errors.Join(fmt.Errorf("error while advising sequential reads of '%s': %w", opts.path, err), f.Close())
+		}
+	}
+
+	reader := &FileReader{
+		file:           f,
+		reader:         r,
+		open:           false,
+		closed:         false,
+		currentOffset:  0,
+		readAheadBytes: opts.readAheadBytes,
+		tail:           opts.tail,
+	}
+
+	if opts.readAheadBytes > 0 {
+		reader.resetReader()
+	}
+
+	return reader, nil
 }
 
 // NewFileReaderWithPath creates a new recordio file reader that can read RecordIO files at the given path.
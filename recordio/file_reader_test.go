@@ -37,6 +37,23 @@ func TestReaderHappyPathMultiRecord(t *testing.T) {
 	readNextExpectEOF(t, reader)
 }
 
+func TestReaderOffset(t *testing.T) {
+	path := "test_files/v3_compat/recordio_UncompressedWriterMultiRecord_asc"
+	reader, err := newOpenedTestReader(t, path)
+	require.NoError(t, err)
+	defer closeFileReader(t, reader)
+
+	assert.Equal(t, uint64(FileHeaderSizeBytes), reader.Offset())
+	for expectedLen := 0; expectedLen < 255; expectedLen++ {
+		_, err := reader.ReadNext()
+		require.NoError(t, err)
+	}
+	readNextExpectEOF(t, reader)
+	stat, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(stat.Size()), reader.Offset())
+}
+
 func TestReaderHappyPathMultiRecordSnappyCompressed(t *testing.T) {
 	reader, err := newOpenedTestReader(t, "test_files/v3_compat/recordio_SnappyWriterMultiRecord_asc")
 	require.NoError(t, err)
@@ -102,14 +119,78 @@ func TestReaderHappyPathSkipAllMultiRecord(t *testing.T) {
 	readNextExpectEOF(t, reader)
 }
 
+func TestReaderSkipN(t *testing.T) {
+	for _, file := range []string{
+		"test_files/v3_compat/recordio_UncompressedWriterMultiRecord_asc",
+		"test_files/v3_compat/recordio_SnappyWriterMultiRecord_asc",
+		"test_files/v2_compat/recordio_SnappyWriterMultiRecord_asc",
+		"test_files/v1_compat/recordio_SnappyWriterMultiRecord_asc",
+	} {
+		t.Run(file, func(t *testing.T) {
+			reader, err := newOpenedTestReader(t, file)
+			require.NoError(t, err)
+			defer closeFileReader(t, reader)
+
+			require.NoError(t, reader.Skip(0))
+			require.NoError(t, reader.Skip(10))
+			buf, err := reader.ReadNext()
+			require.NoError(t, err)
+			assertAscendingBytes(t, buf, 10)
+
+			require.NoError(t, reader.Skip(243))
+			buf, err = reader.ReadNext()
+			require.NoError(t, err)
+			assertAscendingBytes(t, buf, 254)
+
+			err = reader.Skip(1)
+			require.ErrorIs(t, err, io.EOF)
+		})
+	}
+}
+
+func TestReaderSkipNPastEOF(t *testing.T) {
+	reader, err := newOpenedTestReader(t, "test_files/v3_compat/recordio_UncompressedWriterMultiRecord_asc")
+	require.NoError(t, err)
+	defer closeFileReader(t, reader)
+
+	err = reader.Skip(300)
+	require.ErrorIs(t, err, io.EOF)
+	require.ErrorContains(t, err, "skipping record 256 of 300")
+}
+
+func TestReaderSkipNilRecordsCompressed(t *testing.T) {
+	writer, err := newCompressedTestWriter(CompressionTypeSnappy)
+	require.NoError(t, err)
+	require.NoError(t, writer.Open())
+	defer removeFileWriterFile(t, writer)
+
+	for i := 0; i < 10; i++ {
+		_, err := writer.Write(nil)
+		require.NoError(t, err)
+		_, err = writer.Write(ascendingBytes(i + 1))
+		require.NoError(t, err)
+	}
+	require.NoError(t, writer.Close())
+
+	reader := newReaderOnTopOfWriter(t, writer)
+	defer closeFileReader(t, reader)
+	for i := 0; i < 10; i++ {
+		require.NoError(t, reader.Skip(1))
+		buf, err := reader.ReadNext()
+		require.NoError(t, err)
+		assertAscendingBytes(t, buf, i+1)
+	}
+	readNextExpectEOF(t, reader)
+}
+
 func TestReaderVersionMismatchV0(t *testing.T) {
 	reader := newTestReader("test_files/v3_compat/recordio_UncompressedSingleRecord_v0", t)
-	expectErrorStringOnOpen(t, reader, "version mismatch, expected a value from 1 to 3 but was 0")
+	expectErrorStringOnOpen(t, reader, "version mismatch, expected a value from 1 to 4 but was 0")
 }
 
 func TestReaderVersionMismatchV256(t *testing.T) {
 	reader := newTestReader("test_files/v3_compat/recordio_UncompressedSingleRecord_v256", t)
-	expectErrorStringOnOpen(t, reader, "version mismatch, expected a value from 1 to 3 but was 256")
+	expectErrorStringOnOpen(t, reader, "version mismatch, expected a value from 1 to 4 but was 256")
 }
 
 func TestReaderCompressionGzipHeader(t *testing.T) {
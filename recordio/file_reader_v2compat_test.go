@@ -103,12 +103,12 @@ func TestReaderHappyPathSkipAllMultiRecordV2(t *testing.T) {
 
 func TestReaderV2VersionMismatchV0(t *testing.T) {
 	reader := newTestReader("test_files/v2_compat/recordio_UncompressedSingleRecord_v0", t)
-	expectErrorStringOnOpen(t, reader, "version mismatch, expected a value from 1 to 3 but was 0")
+	expectErrorStringOnOpen(t, reader, "version mismatch, expected a value from 1 to 4 but was 0")
 }
 
 func TestReaderV2VersionMismatchV256(t *testing.T) {
 	reader := newTestReader("test_files/v2_compat/recordio_UncompressedSingleRecord_v256", t)
-	expectErrorStringOnOpen(t, reader, "version mismatch, expected a value from 1 to 3 but was 256")
+	expectErrorStringOnOpen(t, reader, "version mismatch, expected a value from 1 to 4 but was 256")
 }
 
 func TestReaderCompressionGzipHeaderV2(t *testing.T) {
@@ -13,10 +13,12 @@ import (
 )
 
 // FileWriter defines a binary file format (little endian).
-// The file header has a 32 bit version number and a 32 bit compression type enum according to the table above.
+// The file header has a 32 bit version number and a 32 bit compression type enum according to the table above. v4
+// headers are followed by 32 bit flags and, if flagged, the 32 bit schema id, see CurrentVersion for the compatibility.
 // Each record written in the file follows the following format (sequentially):
 // - MagicNumber (encoding/binary/Uvarint) to separate records from each other.
-// - single byte set to 1 if the record is supposed to be nil. Otherwise, 0.
+// - single flags byte, the bit 1 is set if the record is supposed to be nil and the bit 2 is set if the payload is
+// stored uncompressed in a file with compression (see MinCompressSizeBytes). Otherwise, 0.
 // - Uncompressed data payload size (encoding/binary/Uvarint).
 // - Compressed data payload size (encoding/binary/Uvarint), or 0 if the data is not compressed.
 // - Payload as plain bytes, possibly compressed
@@ -34,13 +36,28 @@ type FileWriter struct {
 	headerOffset  uint64
 
 	compressionType    int
+	compressionLevel   int
 	compressor         compressor.CompressionI
 	recordHeaderCache  []byte
 	bufferPool         *pool.Pool
 	alignedBlockWrites bool
+	appendMode         bool
+	// minCompressSizeBytes stores smaller records uncompressed, see MinCompressSizeBytes
+	minCompressSizeBytes int
+	// hasSchemaID writes the schemaID into the file header, see SchemaID
+	hasSchemaID bool
+	schemaID    uint32
+	// activeRecord is the streaming record that is currently written, if any
+	activeRecord *fileRecordWriter
+	// footerInterval writes the offset of every footerInterval-th record into the footer index, see FooterIndex
+	footerInterval int
+	footerOffsets  []uint64
+	numRecords     uint64
 }
 
 var DirectIOSyncWriteErr = errors.New("currently not supporting directIO with sync writing")
+var DirectIOStreamingWriteErr = errors.New("currently not supporting directIO with streaming writes")
+var DirectIOFlushErr = errors.New("currently not supporting directIO with flushing")
 
 func (w *FileWriter) Open() error {
 	if w.open {
@@ -51,19 +68,31 @@ func (w *FileWriter) Open() error {
 		return fmt.Errorf("file writer for '%s' is already closed", w.file.Name())
 	}
 
-	offset, err := writeFileHeader(w)
-	if err != nil {
-		return fmt.Errorf("writing header in file at '%s' failed with %w", w.file.Name(), err)
+	var offset int
+	var err error
+	if w.appendMode {
+		offset, err = seekToAppendOffset(w)
+		if err != nil {
+			return fmt.Errorf("preparing append in file at '%s' failed with %w", w.file.Name(), err)
+		}
+	}
+
+	// a new or empty file always needs a header, also when appending
+	if offset == 0 {
+		offset, err = writeFileHeader(w)
+		if err != nil {
+			return fmt.Errorf("writing header in file at '%s' failed with %w", w.file.Name(), err)
+		}
 	}
 
-	w.compressor, err = NewCompressorForType(w.compressionType)
+	w.compressor, err = newCompressorWithLevel(w.compressionType, w.compressionLevel)
 	if err != nil {
 		return fmt.Errorf("creating compressor with type '%d' in file at '%s' failed with %w", w.compressionType, w.file.Name(), err)
 	}
 
 	w.currentOffset = uint64(offset)
 	w.largestOffset = w.currentOffset
-	w.headerOffset = w.currentOffset
+	w.headerOffset = uint64(len(w.fileHeader()))
 	w.open = true
 	w.recordHeaderCache = make([]byte, RecordHeaderV3MaxSizeBytes)
 	w.bufferPool = pool.NewPool(1024, 20)
@@ -80,8 +109,139 @@ func (w *FileWriter) Open() error {
 	return nil
 }
 
+// seekToAppendOffset validates the header of an existing file and reads all of its records to find the end of the last
+// complete record. A torn record at the end of the file, for example from a crash, is truncated and the writer is
+// positioned at that offset. An error is returned for corrupted records that are followed by more data. Returns zero when the file is empty and a new header needs to be written.
+func seekToAppendOffset(w *FileWriter) (int, error) {
+	stat, err := w.file.Stat()
+	if err != nil {
+		return 0, err
+	}
+
+	if stat.Size() == 0 {
+		return 0, nil
+	}
+
+	r, err := NewFileReaderWithPath(w.file.Name())
+	if err != nil {
+		return 0, err
+	}
+	reader := r.(*FileReader)
+
+	err = reader.Open()
+	if err != nil {
+		return 0, errors.Join(err, reader.Close())
+	}
+
+	if reader.header.compressionType != w.compressionType {
+		return 0, errors.Join(fmt.Errorf("compression type mismatch, file has %d but writer was configured with %d",
+			reader.header.compressionType, w.compressionType), reader.Close())
+	}
+
+	if reader.header.hasSchemaID != w.hasSchemaID || reader.header.schemaID != w.schemaID {
+		return 0, errors.Join(fmt.Errorf("schema id mismatch, file has %d (set: %t) but writer was configured with %d (set: %t)",
+			reader.header.schemaID, reader.header.hasSchemaID, w.schemaID, w.hasSchemaID), reader.Close())
+	}
+
+	if reader.header.hasFooter != (w.footerInterval > 0) {
+		return 0, errors.Join(fmt.Errorf("footer index mismatch, file has one: %t but writer was configured with one: %t",
+			reader.header.hasFooter, w.footerInterval > 0), reader.Close())
+	}
+
+	// the remaining flags and with them the version need to match as well
+	flags := w.fileHeaderFlags()
+	if reader.header.fileVersion != fileHeaderVersion(flags) {
+		return 0, errors.Join(fmt.Errorf("can only append to version %d, but file has version %d",
+			fileHeaderVersion(flags), reader.header.fileVersion), reader.Close())
+	}
+
+	if reader.header.flags != flags {
+		return 0, errors.Join(fmt.Errorf("header flags mismatch, file has %#x but writer was configured with %#x",
+			reader.header.flags, flags), reader.Close())
+	}
+
+	// everything after the last complete record is truncated, a torn record as well as the footer index, which is
+	// rebuilt from the records
+	validOffset, _, err := validRecordsEnd(reader, w.trackFooterOffset)
+	err = errors.Join(err, reader.Close())
+	if err != nil {
+		return 0, err
+	}
+
+	if uint64(stat.Size()) > validOffset {
+		err = w.file.Truncate(int64(validOffset))
+		if err != nil {
+			return 0, fmt.Errorf("failed to truncate torn records at offset %d: %w", validOffset, err)
+		}
+	}
+
+	newOffset, err := w.bufWriter.Seek(int64(validOffset), io.SeekStart)
+	if err != nil {
+		return 0, err
+	}
+
+	return int(newOffset), nil
+}
+
+// validRecordsEnd reads all records of the opened reader and returns the offset after the last complete one, the
+// offset of every record is passed to onRecord. Only a record that runs into the end of the file was torn by a crash,
+// torn is true in that case. Any other error is corruption followed by more data, which must never be truncated.
+func validRecordsEnd(reader *FileReader, onRecord func(offset uint64)) (end uint64, torn bool, err error) {
+	for {
+		end = reader.currentOffset
+		_, err = reader.ReadNext()
+		if errors.Is(err, io.EOF) {
+			return end, false, nil
+		}
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			return end, true, nil
+		}
+		if err != nil {
+			return end, false, fmt.Errorf("corrupted record at offset %d: %w", end, err)
+		}
+		onRecord(end)
+	}
+}
+
+// TruncateTornRecord removes the torn record at the end of the file at the given path, which a crash during a write
+// leaves behind, the same way Append does before appending. Files that end with a complete record or footer index are
+// not changed. Returns whether the file was truncated, corrupted records that are followed by more data are errors.
+func TruncateTornRecord(path string) (bool, error) {
+	stat, err := os.Stat(path)
+	if err != nil {
+		return false, err
+	}
+
+	if stat.Size() == 0 {
+		return false, nil
+	}
+
+	r, err := NewFileReaderWithPath(path)
+	if err != nil {
+		return false, err
+	}
+	reader := r.(*FileReader)
+
+	err = reader.Open()
+	if err != nil {
+		return false, errors.Join(err, reader.Close())
+	}
+
+	end, torn, err := validRecordsEnd(reader, func(uint64) {})
+	err = errors.Join(err, reader.Close())
+	if err != nil || !torn {
+		return false, err
+	}
+
+	err = os.Truncate(path, int64(end))
+	if err != nil {
+		return false, fmt.Errorf("failed to truncate torn record at offset %d: %w", end, err)
+	}
+	return true, nil
+}
+
 func writeFileHeader(writer *FileWriter) (int, error) {
-	written, err := writer.bufWriter.Write(fileHeaderAsByteSlice(uint32(writer.compressionType)))
+	written, err := writer.bufWriter.Write(writer.fileHeader())
 	if err != nil {
 		return 0, err
 	}
@@ -89,11 +249,54 @@ func writeFileHeader(writer *FileWriter) (int, error) {
 	return written, nil
 }
 
-func fileHeaderAsByteSlice(compressionType uint32) []byte {
-	// 4 byte version number, 4 byte compression code = 8 bytes
-	bytes := make([]byte, 8)
-	binary.LittleEndian.PutUint32(bytes[0:4], CurrentVersion)
+// fileHeader returns the header for the options of the writer, see fileHeaderAsByteSlice
+func (w *FileWriter) fileHeader() []byte {
+	header := fileHeaderAsByteSlice(uint32(w.compressionType), w.fileHeaderFlags())
+	if w.hasSchemaID {
+		header = binary.LittleEndian.AppendUint32(header, w.schemaID)
+	}
+	return header
+}
+
+// fileHeaderFlags returns the flags of the v4 header, zero when the writer doesn't need a v4 header
+func (w *FileWriter) fileHeaderFlags() uint32 {
+	flags := fileHeaderFlags(w.compressionType, w.minCompressSizeBytes, w.hasSchemaID)
+	if w.footerInterval > 0 {
+		flags |= fileHeaderFlagFooter
+	}
+	return flags
+}
+
+// fileHeaderFlags returns the flags of the v4 header for the given options, the footer is only known to the FileWriter
+func fileHeaderFlags(compressionType int, minCompressSizeBytes int, hasSchemaID bool) uint32 {
+	var flags uint32
+	if compressionType != CompressionTypeNone && minCompressSizeBytes > 0 {
+		flags |= fileHeaderFlagUncompressedRecords
+	}
+	if hasSchemaID {
+		flags |= fileHeaderFlagSchemaID
+	}
+	return flags
+}
+
+// fileHeaderVersion returns the version of a header with the given flags, only headers with flags need to be v4
+func fileHeaderVersion(flags uint32) uint32 {
+	if flags != 0 {
+		return Version4
+	}
+	return Version3
+}
+
+// fileHeaderAsByteSlice encodes the 4 byte version number and the 4 byte compression code, v4 headers are followed by
+// the 4 byte flags = 8 or 12 bytes
+func fileHeaderAsByteSlice(compressionType uint32, flags uint32) []byte {
+	bytes := make([]byte, FileHeaderSizeBytes, FileHeaderV4SizeBytes)
+	version := fileHeaderVersion(flags)
+	binary.LittleEndian.PutUint32(bytes[0:4], version)
 	binary.LittleEndian.PutUint32(bytes[4:8], compressionType)
+	if version >= Version4 {
+		bytes = binary.LittleEndian.AppendUint32(bytes, flags)
+	}
 	return bytes
 }
 
@@ -132,13 +335,9 @@ func writeRecordHeaderV2(writer *FileWriter, payloadSizeUncompressed uint64, pay
 	return written, nil
 }
 
-func fillRecordHeaderV3(bytes []byte, payloadSizeUncompressed uint64, payloadSizeCompressed uint64, recordNil bool) []byte {
+func fillRecordHeaderV3(bytes []byte, payloadSizeUncompressed uint64, payloadSizeCompressed uint64, flags byte) []byte {
 	off := binary.PutUvarint(bytes, MagicNumberSeparatorLong)
-	if recordNil {
-		bytes[off] = 1
-	} else {
-		bytes[off] = 0
-	}
+	bytes[off] = flags
 	off += 1
 	off += binary.PutUvarint(bytes[off:], payloadSizeUncompressed)
 	off += binary.PutUvarint(bytes[off:], payloadSizeCompressed)
@@ -146,8 +345,8 @@ func fillRecordHeaderV3(bytes []byte, payloadSizeUncompressed uint64, payloadSiz
 	return bytes[:off]
 }
 
-func writeRecordHeaderV3(writer *FileWriter, payloadSizeUncompressed uint64, payloadSizeCompressed uint64, recordNil bool) (int, error) {
-	header := fillRecordHeaderV3(writer.recordHeaderCache, payloadSizeUncompressed, payloadSizeCompressed, recordNil)
+func writeRecordHeaderV3(writer *FileWriter, payloadSizeUncompressed uint64, payloadSizeCompressed uint64, flags byte) (int, error) {
+	header := fillRecordHeaderV3(writer.recordHeaderCache, payloadSizeUncompressed, payloadSizeCompressed, flags)
 	written, err := writer.bufWriter.Write(header)
 	if err != nil {
 		return 0, err
@@ -156,17 +355,33 @@ func writeRecordHeaderV3(writer *FileWriter, payloadSizeUncompressed uint64, pay
 	return written, nil
 }
 
+// recordFlags returns the flags of the record header, records smaller than minCompressSizeBytes are stored uncompressed
+func recordFlags(record []byte, c compressor.CompressionI, minCompressSizeBytes int) byte {
+	if record == nil {
+		return recordFlagNil
+	}
+	if c != nil && len(record) < minCompressSizeBytes {
+		return recordFlagUncompressed
+	}
+	return 0
+}
+
 // Write appends a record of bytes, returns the current offset this item was written to
 func (w *FileWriter) Write(record []byte) (uint64, error) {
 	if !w.open || w.closed {
 		return 0, errors.New("writer was either not opened yet or is closed already")
 	}
 
+	if w.activeRecord != nil {
+		return 0, fmt.Errorf("can't write while a streaming record is being written in file at '%s'", w.file.Name())
+	}
+
 	recordToWrite := record
 	uncompressedSize := uint64(len(recordToWrite))
 	compressedSize := uint64(0)
+	flags := recordFlags(record, w.compressor, w.minCompressSizeBytes)
 
-	if w.compressor != nil {
+	if w.compressor != nil && flags&recordFlagUncompressed == 0 {
 		poolBuffer := w.bufferPool.Get(int(uncompressedSize))
 		defer w.bufferPool.Put(poolBuffer)
 
@@ -179,13 +394,14 @@ func (w *FileWriter) Write(record []byte) (uint64, error) {
 	}
 
 	prevOffset := w.currentOffset
-	headerBytesWritten, err := writeRecordHeaderV3(w, uncompressedSize, compressedSize, record == nil)
+	headerBytesWritten, err := writeRecordHeaderV3(w, uncompressedSize, compressedSize, flags)
 	if err != nil {
 		return 0, fmt.Errorf("failed to write record header in file at '%s' failed with %w", w.file.Name(), err)
 	}
 
 	if record == nil {
 		w.currentOffset = prevOffset + uint64(headerBytesWritten)
+		w.trackFooterOffset(prevOffset)
 		return prevOffset, nil
 	}
 
@@ -200,9 +416,28 @@ func (w *FileWriter) Write(record []byte) (uint64, error) {
 
 	w.currentOffset = prevOffset + uint64(headerBytesWritten) + uint64(recordBytesWritten)
 	w.largestOffset = max(w.largestOffset, w.currentOffset)
+	w.trackFooterOffset(prevOffset)
 	return prevOffset, nil
 }
 
+// trackFooterOffset records the offset of every footerInterval-th record for the footer index
+func (w *FileWriter) trackFooterOffset(offset uint64) {
+	if w.footerInterval <= 0 {
+		return
+	}
+	if w.numRecords%uint64(w.footerInterval) == 0 {
+		w.footerOffsets = append(w.footerOffsets, offset)
+	}
+	w.numRecords++
+}
+
+// truncateFooterOffsets removes the offsets of records at or after the given offset, which are overwritten or discarded
+func (w *FileWriter) truncateFooterOffsets(offset uint64) {
+	for len(w.footerOffsets) > 0 && w.footerOffsets[len(w.footerOffsets)-1] >= offset {
+		w.footerOffsets = w.footerOffsets[:len(w.footerOffsets)-1]
+	}
+}
+
 // WriteSync appends a record of bytes and forces a disk sync, returns the current offset this item was written to.
 // When directIO is enabled however, we can't write misaligned blocks and immediately returns DirectIOSyncWriteErr
 func (w *FileWriter) WriteSync(record []byte) (uint64, error) {
@@ -228,18 +463,56 @@ func (w *FileWriter) WriteSync(record []byte) (uint64, error) {
 	return offset, nil
 }
 
+// Flush writes the buffered records to the file without syncing it, so they are visible to readers of the file.
+// When directIO is enabled however, we can't write misaligned blocks and immediately returns DirectIOFlushErr
+func (w *FileWriter) Flush() error {
+	if !w.open || w.closed {
+		return errors.New("writer was either not opened yet or is closed already")
+	}
+	if w.alignedBlockWrites {
+		return DirectIOFlushErr
+	}
+
+	err := w.bufWriter.Flush()
+	if err != nil {
+		return fmt.Errorf("failed to flush file at '%s' failed with %w", w.file.Name(), err)
+	}
+	return nil
+}
+
 func (w *FileWriter) Close() error {
 	w.closed = true
 	w.open = false
+	// an incomplete streaming record is discarded by truncating the file at its start
+	if w.activeRecord != nil {
+		w.largestOffset = max(w.largestOffset, w.currentOffset)
+		w.currentOffset = w.activeRecord.offset
+		w.activeRecord = nil
+	}
+
+	end := w.currentOffset
+	if w.footerInterval > 0 {
+		footer := marshalFooter(w.currentOffset, w.footerInterval, w.footerOffsets)
+		_, err := w.bufWriter.Seek(int64(w.currentOffset), io.SeekStart)
+		if err != nil {
+			return fmt.Errorf("failed to seek to footer in file at '%s' failed with %w", w.file.Name(), err)
+		}
+		_, err = w.bufWriter.Write(footer)
+		if err != nil {
+			return fmt.Errorf("failed to write footer in file at '%s' failed with %w", w.file.Name(), err)
+		}
+		end += uint64(len(footer))
+	}
+
 	err := w.bufWriter.Flush()
 	if err != nil {
 		return fmt.Errorf("failed to flush close in file at '%s' failed with %w", w.file.Name(), err)
 	}
 
-	// when we have previously written past the currentOffset because of seeks, we need to truncate the file again to
+	// when we have previously written past the end because of seeks, we need to truncate the file again to
 	// avoid reading partial records
-	if w.largestOffset > w.currentOffset {
-		err = w.file.Truncate(int64(w.currentOffset))
+	if w.largestOffset > end {
+		err = w.file.Truncate(int64(end))
 		if err != nil {
 			return fmt.Errorf("failed to truncate file at '%s' failed with %w", w.file.Name(), err)
 		}
@@ -257,6 +530,9 @@ func (w *FileWriter) Size() uint64 {
 }
 
 func (w *FileWriter) Seek(offset uint64) error {
+	if w.activeRecord != nil {
+		return fmt.Errorf("can't seek while a streaming record is being written in file at '%s'", w.file.Name())
+	}
 	if offset < w.headerOffset {
 		return fmt.Errorf("can't seek into the header range, supplied: %d header: %d", offset, w.headerOffset)
 	}
@@ -270,17 +546,24 @@ func (w *FileWriter) Seek(offset uint64) error {
 	}
 	w.largestOffset = max(w.largestOffset, w.currentOffset)
 	w.currentOffset = uint64(newOffset)
+	w.truncateFooterOffsets(w.currentOffset)
 	return nil
 }
 
 // options
 
 type FileWriterOptions struct {
-	path            string
-	file            *os.File
-	compressionType int
-	bufferSizeBytes int
-	enableDirectIO  bool
+	path             string
+	file             *os.File
+	compressionType  int
+	compressionLevel int
+	bufferSizeBytes  int
+	enableDirectIO   bool
+	append           bool
+	minCompressSize  int
+	hasSchemaID      bool
+	schemaID         uint32
+	footerInterval   int
 }
 
 type FileWriterOption func(*FileWriterOptions)
@@ -309,6 +592,46 @@ func CompressionType(p int) FileWriterOption {
 	}
 }
 
+// CompressionLevel sets the level of compression types that support levels, which is only CompressionTypeGZIP with
+// the levels of compress/gzip currently, where zero is gzip.NoCompression. It's ignored by other types and defaults to
+// DefaultCompressionLevel. Readers don't need to know the level.
+func CompressionLevel(level int) FileWriterOption {
+	return func(args *FileWriterOptions) {
+		args.compressionLevel = level
+	}
+}
+
+// MinCompressSizeBytes stores records smaller than n bytes uncompressed, even when a CompressionType is set. Small
+// records compress poorly, skipping them saves CPU and often space. Every record header flags whether its payload is
+// compressed, readers handle mixed files transparently. Compressed files written with this option have a v4 header,
+// see CurrentVersion. Disabled by default with zero, it has no effect without compression.
+func MinCompressSizeBytes(n int) FileWriterOption {
+	return func(args *FileWriterOptions) {
+		args.minCompressSize = n
+	}
+}
+
+// SchemaID embeds the given id in the file header, for example to declare which schema the records follow, so a generic
+// reader can dispatch on it. Readers return it through SchemaIDReaderI before reading any records. Files written with a
+// schema id have a v4 header, see CurrentVersion. Appending requires the same id.
+func SchemaID(id uint32) FileWriterOption {
+	return func(args *FileWriterOptions) {
+		args.hasSchemaID = true
+		args.schemaID = id
+	}
+}
+
+// FooterIndex writes the offset of every n-th record into an index at the end of the file when the writer is closed,
+// so the last records can be read without scanning the file from the start, see ReaderTail. The index takes
+// FooterEntrySizeBytes per n records plus a trailer of FooterTrailerSizeBytes, for example about 8 KiB for a million
+// records with n = 1000. Files that were never closed have no index and are read from the start. All readers stop
+// at the index, files with an index have a v4 header, see CurrentVersion. Appending requires the option as well, the index is rebuilt on Close. FooterIndex can't be used with DirectIO.
+func FooterIndex(n int) FileWriterOption {
+	return func(args *FileWriterOptions) {
+		args.footerInterval = n
+	}
+}
+
 // BufferSizeBytes sets the write buffer size, by default it uses DefaultBufferSize.
 // This is the internal memory buffer before it's written to disk.
 func BufferSizeBytes(p int) FileWriterOption {
@@ -325,14 +648,25 @@ func DirectIO() FileWriterOption {
 	}
 }
 
+// Append opens an existing file to continue writing after its last record, instead of overwriting it from the start.
+// The file needs to be written with the current version and the same compression type, Size will include the existing
+// records. Trailing bytes that don't form a complete record, for example from a crash during a write, are truncated.
+// Files that don't exist or are empty are initialized like without this option. Append can't be used with DirectIO.
+func Append() FileWriterOption {
+	return func(args *FileWriterOptions) {
+		args.append = true
+	}
+}
+
 // NewFileWriter creates a new writer with the given options, either Path or File must be supplied, compression is optional.
 func NewFileWriter(writerOptions ...FileWriterOption) (WriterI, error) {
 	opts := &FileWriterOptions{
-		path:            "",
-		file:            nil,
-		compressionType: CompressionTypeNone,
-		bufferSizeBytes: DefaultBufferSize,
-		enableDirectIO:  false,
+		path:             "",
+		file:             nil,
+		compressionType:  CompressionTypeNone,
+		compressionLevel: DefaultCompressionLevel,
+		bufferSizeBytes:  DefaultBufferSize,
+		enableDirectIO:   false,
 	}
 
 	for _, writeOption := range writerOptions {
@@ -347,6 +681,22 @@ func NewFileWriter(writerOptions ...FileWriterOption) (WriterI, error) {
 		opts.path = opts.file.Name()
 	}
 
+	if opts.append && opts.enableDirectIO {
+		return nil, errors.New("NewFileWriter: Append is not supported with DirectIO")
+	}
+
+	if opts.footerInterval < 0 {
+		return nil, fmt.Errorf("NewFileWriter: unexpected footer index interval, was: %d", opts.footerInterval)
+	}
+
+	if opts.footerInterval > 0 && opts.enableDirectIO {
+		return nil, errors.New("NewFileWriter: FooterIndex is not supported with DirectIO")
+	}
+
+	if opts.minCompressSize < 0 {
+		return nil, fmt.Errorf("NewFileWriter: unexpected min compress size, was: %d", opts.minCompressSize)
+	}
+
 	var factory ReaderWriterCloserFactory
 	if opts.enableDirectIO {
 		factory = DirectIOFactory{}
@@ -366,7 +716,17 @@ func NewFileWriter(writerOptions ...FileWriterOption) (WriterI, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to create new Writer at '%s' failed with %w", opts.path, err)
 	}
-	return newCompressedFileWriterWithFile(file, writer, opts.compressionType, opts.enableDirectIO)
+	w, err := newCompressedFileWriterWithFile(file, writer, opts.compressionType, opts.enableDirectIO)
+	if err != nil {
+		return nil, err
+	}
+	w.(*FileWriter).appendMode = opts.append
+	w.(*FileWriter).compressionLevel = opts.compressionLevel
+	w.(*FileWriter).minCompressSizeBytes = opts.minCompressSize
+	w.(*FileWriter).hasSchemaID = opts.hasSchemaID
+	w.(*FileWriter).schemaID = opts.schemaID
+	w.(*FileWriter).footerInterval = opts.footerInterval
+	return w, nil
 }
 
 // creates a new writer with the given os.File, with the desired compression
@@ -378,6 +738,7 @@ func newCompressedFileWriterWithFile(file *os.File, bufWriter WriteSeekerCloserF
 		open:               false,
 		closed:             false,
 		compressionType:    compType,
+		compressionLevel:   DefaultCompressionLevel,
 		currentOffset:      0,
 	}, nil
 }
@@ -1,10 +1,15 @@
 package recordio
 
 import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
 	"errors"
+	"fmt"
 	"io"
 	"math/rand"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -143,6 +148,208 @@ func TestWriterOpenNonEmptyFile(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestWriterAppend(t *testing.T) {
+	for _, compType := range []int{CompressionTypeNone, CompressionTypeSnappy} {
+		writer, err := newCompressedTestWriter(compType)
+		require.NoError(t, err)
+		require.NoError(t, writer.Open())
+		defer removeFileWriterFile(t, writer)
+		for i := 0; i < 5; i++ {
+			_, err := writer.Write(ascendingBytes(i))
+			require.NoError(t, err)
+		}
+		require.NoError(t, writer.Close())
+		sizeBefore := writer.Size()
+
+		w, err := NewFileWriter(Path(writer.file.Name()), CompressionType(compType), Append())
+		require.NoError(t, err)
+		appender := w.(*FileWriter)
+		require.NoError(t, appender.Open())
+		assert.Equal(t, sizeBefore, appender.Size())
+		for i := 5; i < 10; i++ {
+			offset, err := appender.Write(ascendingBytes(i))
+			require.NoError(t, err)
+			assert.Greater(t, offset, sizeBefore-1)
+		}
+		_, err = appender.Write(nil)
+		require.NoError(t, err)
+		require.NoError(t, appender.Close())
+
+		stat, err := os.Stat(writer.file.Name())
+		require.NoError(t, err)
+		assert.Equal(t, int64(appender.Size()), stat.Size())
+
+		reader := newReaderOnTopOfWriter(t, writer)
+		for i := 0; i < 10; i++ {
+			readNextExpectAscendingBytesOfLen(t, reader, i)
+		}
+		buf, err := reader.ReadNext()
+		require.NoError(t, err)
+		assert.Nil(t, buf)
+		readNextExpectEOF(t, reader)
+		closeFileReader(t, reader)
+	}
+}
+
+func TestWriterAppendTruncatesTornRecord(t *testing.T) {
+	writer := newOpenedWriter(t)
+	defer removeFileWriterFile(t, writer)
+	_, err := writer.Write(ascendingBytes(10))
+	require.NoError(t, err)
+	tornOffset, err := writer.Write(ascendingBytes(20))
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+
+	// simulate a crash in the middle of the last record
+	require.NoError(t, os.Truncate(writer.file.Name(), int64(tornOffset+5)))
+
+	w, err := NewFileWriter(Path(writer.file.Name()), Append())
+	require.NoError(t, err)
+	appender := w.(*FileWriter)
+	require.NoError(t, appender.Open())
+	assert.Equal(t, tornOffset, appender.Size())
+	offset, err := appender.Write(ascendingBytes(30))
+	require.NoError(t, err)
+	assert.Equal(t, tornOffset, offset)
+	require.NoError(t, appender.Close())
+
+	reader := newReaderOnTopOfWriter(t, writer)
+	defer closeFileReader(t, reader)
+	readNextExpectAscendingBytesOfLen(t, reader, 10)
+	readNextExpectAscendingBytesOfLen(t, reader, 30)
+	readNextExpectEOF(t, reader)
+}
+
+func TestWriterAppendTruncatesTornRecordHeader(t *testing.T) {
+	writer := newOpenedWriter(t)
+	defer removeFileWriterFile(t, writer)
+	_, err := writer.Write(ascendingBytes(10))
+	require.NoError(t, err)
+	tornOffset, err := writer.Write(ascendingBytes(20))
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+
+	// the crash happened right after the magic number of the last record
+	require.NoError(t, os.Truncate(writer.file.Name(), int64(tornOffset+1)))
+
+	w, err := NewFileWriter(Path(writer.file.Name()), Append())
+	require.NoError(t, err)
+	require.NoError(t, w.Open())
+	assert.Equal(t, tornOffset, w.Size())
+	require.NoError(t, w.Close())
+}
+
+func TestTruncateTornRecord(t *testing.T) {
+	for _, tornBytes := range []int{len(MagicNumberSeparatorLongBytes), len(MagicNumberSeparatorLongBytes) + 1, 8} {
+		writer := newOpenedWriter(t)
+		_, err := writer.Write(ascendingBytes(10))
+		require.NoError(t, err)
+		tornOffset, err := writer.Write(ascendingBytes(20))
+		require.NoError(t, err)
+		require.NoError(t, writer.Close())
+
+		truncated, err := TruncateTornRecord(writer.file.Name())
+		require.NoError(t, err)
+		assert.False(t, truncated)
+
+		require.NoError(t, os.Truncate(writer.file.Name(), int64(tornOffset)+int64(tornBytes)))
+		truncated, err = TruncateTornRecord(writer.file.Name())
+		require.NoError(t, err)
+		assert.True(t, truncated)
+		stat, err := os.Stat(writer.file.Name())
+		require.NoError(t, err)
+		assert.Equal(t, int64(tornOffset), stat.Size())
+		removeFileWriterFile(t, writer)
+	}
+
+	// the footer index after the last record is not torn
+	path := filepath.Join(t.TempDir(), "footer.rio")
+	w, err := NewFileWriter(Path(path), FooterIndex(1))
+	require.NoError(t, err)
+	require.NoError(t, w.Open())
+	_, err = w.Write(ascendingBytes(10))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	truncated, err := TruncateTornRecord(path)
+	require.NoError(t, err)
+	assert.False(t, truncated)
+}
+
+func TestWriterAppendRejectsCorruptionBeforeMoreRecords(t *testing.T) {
+	writer := newOpenedWriter(t)
+	defer removeFileWriterFile(t, writer)
+	var offsets []uint64
+	for i := 0; i < 5; i++ {
+		offset, err := writer.Write(ascendingBytes(10))
+		require.NoError(t, err)
+		offsets = append(offsets, offset)
+	}
+	require.NoError(t, writer.Close())
+
+	// corrupt the magic number of the second record, the valid records after it must not be truncated
+	f, err := os.OpenFile(writer.file.Name(), os.O_RDWR, 0666)
+	require.NoError(t, err)
+	_, err = f.WriteAt([]byte{0xff, 0xff, 0xff}, int64(offsets[1]))
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+	before, err := os.Stat(writer.file.Name())
+	require.NoError(t, err)
+
+	w, err := NewFileWriter(Path(writer.file.Name()), Append())
+	require.NoError(t, err)
+	err = w.Open()
+	assert.ErrorContains(t, err, fmt.Sprintf("corrupted record at offset %d", offsets[1]))
+
+	after, err := os.Stat(writer.file.Name())
+	require.NoError(t, err)
+	assert.Equal(t, before.Size(), after.Size())
+}
+
+func TestWriterAppendEmptyAndMissingFile(t *testing.T) {
+	tmpFile, err := os.CreateTemp("", "recordio_AppendWriter")
+	require.NoError(t, err)
+	defer closeCleanFile(t, tmpFile)
+
+	for i := 0; i < 2; i++ {
+		w, err := NewFileWriter(Path(tmpFile.Name()), Append())
+		require.NoError(t, err)
+		require.NoError(t, w.Open())
+		offset, err := w.Write(ascendingBytes(3))
+		require.NoError(t, err)
+		if i == 0 {
+			assert.Equal(t, uint64(FileHeaderSizeBytes), offset)
+		}
+		require.NoError(t, w.Close())
+	}
+
+	reader, err := newOpenedTestReader(t, tmpFile.Name())
+	require.NoError(t, err)
+	defer closeFileReader(t, reader)
+	readNextExpectAscendingBytesOfLen(t, reader, 3)
+	readNextExpectAscendingBytesOfLen(t, reader, 3)
+	readNextExpectEOF(t, reader)
+}
+
+func TestWriterAppendValidatesHeader(t *testing.T) {
+	writer := singleWrite(t)
+	defer removeFileWriterFile(t, writer)
+
+	w, err := NewFileWriter(Path(writer.file.Name()), CompressionType(CompressionTypeSnappy), Append())
+	require.NoError(t, err)
+	err = w.Open()
+	assert.ErrorContains(t, err, "compression type mismatch")
+	require.NoError(t, w.Close())
+
+	w, err = NewFileWriter(Path("test_files/v2_compat/recordio_UncompressedSingleRecord"), Append())
+	require.NoError(t, err)
+	err = w.Open()
+	assert.ErrorContains(t, err, "can only append to version 3, but file has version 2")
+	require.NoError(t, w.Close())
+
+	_, err = NewFileWriter(Path(writer.file.Name()), Append(), DirectIO())
+	assert.Error(t, err)
+}
+
 func TestWriterDoublePathFileInit(t *testing.T) {
 	tmpFile, err := os.CreateTemp("", "recordio_UncompressedWriter")
 	require.Nil(t, err)
@@ -219,6 +426,52 @@ func TestWriterNotAllowsSyncsWithDirectIO(t *testing.T) {
 	require.NoError(t, w.Open())
 	_, err = w.WriteSync([]byte{1})
 	require.ErrorIs(t, err, DirectIOSyncWriteErr)
+	require.ErrorIs(t, w.(FlushI).Flush(), DirectIOFlushErr)
+}
+
+func TestWriterFlush(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "flush.rio")
+	w, err := NewFileWriter(Path(path), CompressionType(CompressionTypeSnappy))
+	require.NoError(t, err)
+	require.Error(t, w.(FlushI).Flush())
+	require.NoError(t, w.Open())
+
+	for i := 0; i < 10; i++ {
+		_, err = w.Write(randomRecordOfSize(100))
+		require.NoError(t, err)
+	}
+	stat, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Less(t, uint64(stat.Size()), w.Size())
+
+	require.NoError(t, w.(FlushI).Flush())
+	stat, err = os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, w.Size(), uint64(stat.Size()))
+
+	require.NoError(t, w.Close())
+	require.Error(t, w.(FlushI).Flush())
+}
+
+func TestWriterNotAllowsStreamingWithDirectIO(t *testing.T) {
+	ok, err := IsDirectIOAvailable()
+	require.NoError(t, err)
+	if !ok {
+		t.Skip("directio not available here")
+		return
+	}
+
+	tmpFile, err := os.CreateTemp("", "recordio_WriterNotAllowsStreamingWithDirectIO")
+	require.Nil(t, err)
+	defer closeCleanFile(t, tmpFile)
+
+	w, err := NewFileWriter(Path(tmpFile.Name()), DirectIO())
+	require.NoError(t, err)
+	defer closeOpenClosable(t, w)
+
+	require.NoError(t, w.Open())
+	_, err = w.(StreamingWriterI).WriteStreaming()
+	require.ErrorIs(t, err, DirectIOStreamingWriteErr)
 }
 
 func TestWriterSeekHappyPath(t *testing.T) {
@@ -333,6 +586,299 @@ func TestWriterSeekLongerReplacementWrite(t *testing.T) {
 	readNextExpectEOF(t, reader)
 }
 
+func TestWriterGzipCompressionLevel(t *testing.T) {
+	record := make([]byte, 4096)
+	for i := range record {
+		record[i] = byte(i % 7)
+	}
+
+	var sizes []uint64
+	for _, level := range []int{gzip.NoCompression, gzip.HuffmanOnly, gzip.BestSpeed, gzip.BestCompression} {
+		tmpFile, err := os.CreateTemp("", "recordio_GzipLevelWriter")
+		require.NoError(t, err)
+		w, err := NewFileWriter(File(tmpFile), CompressionType(CompressionTypeGZIP), CompressionLevel(level))
+		require.NoError(t, err)
+		writer := w.(*FileWriter)
+		defer removeFileWriterFile(t, writer)
+
+		require.NoError(t, writer.Open())
+		_, err = writer.Write(record)
+		require.NoError(t, err)
+		require.NoError(t, writer.Close())
+		sizes = append(sizes, writer.Size())
+
+		// the reader detects the compression from the header, the level isn't needed
+		reader := newReaderOnTopOfWriter(t, writer)
+		buf, err := reader.ReadNext()
+		require.NoError(t, err)
+		assert.Equal(t, record, buf)
+		readNextExpectEOF(t, reader)
+		require.NoError(t, reader.Close())
+	}
+	// zero selects gzip.NoCompression, which stores the record as is
+	assert.Greater(t, sizes[0], uint64(len(record)))
+	assert.Greater(t, sizes[1], sizes[3])
+}
+
+func TestWriterInvalidGzipCompressionLevel(t *testing.T) {
+	tmpFile, err := os.CreateTemp("", "recordio_GzipLevelWriter")
+	require.NoError(t, err)
+	w, err := NewFileWriter(File(tmpFile), CompressionType(CompressionTypeGZIP), CompressionLevel(42))
+	require.NoError(t, err)
+	defer removeFileWriterFile(t, w.(*FileWriter))
+	require.ErrorContains(t, w.Open(), "unsupported gzip compression level 42")
+
+	_, err = NewSizeEstimatorWithCompressionLevel(CompressionTypeGZIP, 42)
+	require.Error(t, err)
+}
+
+func TestWriterMinCompressSizeBytes(t *testing.T) {
+	tmpFile, err := os.CreateTemp("", "recordio_MinCompressSizeWriter")
+	require.NoError(t, err)
+	w, err := NewFileWriter(File(tmpFile), CompressionType(CompressionTypeSnappy), MinCompressSizeBytes(100))
+	require.NoError(t, err)
+	writer := w.(*FileWriter)
+	defer removeFileWriterFile(t, writer)
+	require.NoError(t, writer.Open())
+
+	small := []byte("a small record that is not worth compressing")
+	records := [][]byte{small, nil, {}, ascendingBytes(4096), ascendingBytes(99), ascendingBytes(100)}
+	var offsets []uint64
+	for _, record := range records {
+		offset, err := writer.Write(record)
+		require.NoError(t, err)
+		offsets = append(offsets, offset)
+	}
+	require.NoError(t, writer.Close())
+
+	// the small record is stored as plain bytes, while the large one is compressed
+	content, err := os.ReadFile(tmpFile.Name())
+	require.NoError(t, err)
+	assert.True(t, bytes.Contains(content, small))
+	assert.Less(t, len(content), 4096)
+	// readers from before v4 reject the file instead of decompressing the small record
+	assert.Equal(t, Version4, binary.LittleEndian.Uint32(content[0:4]))
+	assert.Equal(t, fileHeaderFlagUncompressedRecords, binary.LittleEndian.Uint32(content[FileHeaderSizeBytes:FileHeaderV4SizeBytes]))
+	assert.Equal(t, uint64(FileHeaderV4SizeBytes), offsets[0])
+
+	reader := newReaderOnTopOfWriter(t, writer)
+	for _, record := range records {
+		buf, err := reader.ReadNext()
+		require.NoError(t, err)
+		assert.Equal(t, record, buf)
+	}
+	readNextExpectEOF(t, reader)
+	require.NoError(t, reader.Close())
+
+	reader = newReaderOnTopOfWriter(t, writer)
+	require.NoError(t, reader.SkipNext())
+	require.NoError(t, reader.SkipNext())
+	readNextExpectAscendingBytesOfLen(t, reader, 0)
+	require.NoError(t, reader.SkipNext())
+	readNextExpectAscendingBytesOfLen(t, reader, 99)
+	require.NoError(t, reader.Close())
+
+	mmapReader := newOpenedTestMMapReader(t, tmpFile.Name())
+	defer closeMMapReader(t, mmapReader)
+	for i, record := range records {
+		buf, err := mmapReader.ReadNextAt(offsets[i])
+		require.NoError(t, err)
+		assert.Equal(t, record, buf)
+	}
+}
+
+func TestWriterMinCompressSizeBytesRejectsUnknownFlags(t *testing.T) {
+	tmpFile, err := os.CreateTemp("", "recordio_MinCompressSizeWriter")
+	require.NoError(t, err)
+	w, err := NewFileWriter(File(tmpFile), CompressionType(CompressionTypeSnappy), MinCompressSizeBytes(100))
+	require.NoError(t, err)
+	writer := w.(*FileWriter)
+	defer removeFileWriterFile(t, writer)
+	require.NoError(t, writer.Open())
+	_, err = writer.Write(ascendingBytes(10))
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+
+	content, err := os.ReadFile(tmpFile.Name())
+	require.NoError(t, err)
+	// the flags byte follows the magic number of the first record
+	content[FileHeaderV4SizeBytes+len(MagicNumberSeparatorLongBytes)] = 1 << 7
+	require.NoError(t, os.WriteFile(tmpFile.Name(), content, 0666))
+
+	reader := newReaderOnTopOfWriter(t, writer)
+	defer func() { require.NoError(t, reader.Close()) }()
+	_, err = reader.ReadNext()
+	assert.ErrorContains(t, err, "unknown record flags 0x80")
+}
+
+func TestWriterMinCompressSizeBytesHeaderVersion(t *testing.T) {
+	// without the option, or without compression, there are no uncompressed records and the file stays v3
+	for _, opts := range [][]FileWriterOption{
+		{CompressionType(CompressionTypeSnappy)},
+		{CompressionType(CompressionTypeNone), MinCompressSizeBytes(100)},
+	} {
+		path := filepath.Join(t.TempDir(), "v3.rio")
+		w, err := NewFileWriter(append(opts, Path(path))...)
+		require.NoError(t, err)
+		require.NoError(t, w.Open())
+		require.NoError(t, w.Close())
+
+		content, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, Version3, binary.LittleEndian.Uint32(content[0:4]))
+		assert.Equal(t, FileHeaderSizeBytes, len(content))
+
+		// appending with uncompressed records would mix them into a file older readers can read
+		w, err = NewFileWriter(Path(path), Append(), CompressionType(CompressionTypeSnappy), MinCompressSizeBytes(100))
+		require.NoError(t, err)
+		assert.Error(t, w.Open())
+	}
+
+	path := filepath.Join(t.TempDir(), "v4.rio")
+	w, err := NewFileWriter(Path(path), CompressionType(CompressionTypeSnappy), MinCompressSizeBytes(100))
+	require.NoError(t, err)
+	require.NoError(t, w.Open())
+	require.NoError(t, w.Close())
+
+	w, err = NewFileWriter(Path(path), Append(), CompressionType(CompressionTypeSnappy), MinCompressSizeBytes(100))
+	require.NoError(t, err)
+	require.NoError(t, w.Open())
+	assert.Equal(t, uint64(FileHeaderV4SizeBytes), w.Size())
+	require.NoError(t, w.Close())
+
+	w, err = NewFileWriter(Path(path), Append(), CompressionType(CompressionTypeSnappy))
+	require.NoError(t, err)
+	assert.ErrorContains(t, w.Open(), "can only append to version 3, but file has version 4")
+
+	// unknown flags are from a newer version and rejected
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	binary.LittleEndian.PutUint32(content[FileHeaderSizeBytes:], 1<<20)
+	require.NoError(t, os.WriteFile(path, content, 0666))
+	reader, err := NewFileReaderWithPath(path)
+	require.NoError(t, err)
+	assert.ErrorContains(t, reader.Open(), "unknown header flags")
+}
+
+func TestWriterInvalidMinCompressSizeBytes(t *testing.T) {
+	_, err := NewFileWriter(Path("some_path"), MinCompressSizeBytes(-1))
+	assert.Error(t, err)
+	_, err = NewSizeEstimatorWithOptions(MinCompressSizeBytes(-1))
+	assert.Error(t, err)
+}
+
+func TestWriterSchemaID(t *testing.T) {
+	for _, compType := range []int{CompressionTypeNone, CompressionTypeSnappy} {
+		tmpFile, err := os.CreateTemp("", "recordio_SchemaIDWriter")
+		require.NoError(t, err)
+		w, err := NewFileWriter(File(tmpFile), CompressionType(compType), SchemaID(0xCAFE))
+		require.NoError(t, err)
+		writer := w.(*FileWriter)
+		require.NoError(t, writer.Open())
+		offset, err := writer.Write(ascendingBytes(10))
+		require.NoError(t, err)
+		assert.Equal(t, uint64(FileHeaderWithSchemaIDSizeBytes), offset)
+		require.NoError(t, writer.Close())
+
+		reader := newReaderOnTopOfWriter(t, writer)
+		id, ok := reader.SchemaID()
+		assert.True(t, ok)
+		assert.Equal(t, uint32(0xCAFE), id)
+		readNextExpectAscendingBytesOfLen(t, reader, 10)
+		readNextExpectEOF(t, reader)
+		require.NoError(t, reader.Close())
+
+		mmapReader := newOpenedTestMMapReader(t, tmpFile.Name())
+		id, ok = mmapReader.SchemaID()
+		assert.True(t, ok)
+		assert.Equal(t, uint32(0xCAFE), id)
+		buf, err := mmapReader.ReadNextAt(offset)
+		require.NoError(t, err)
+		assert.Equal(t, ascendingBytes(10), buf)
+		closeMMapReader(t, mmapReader)
+
+		// appending requires the same schema id
+		_, err = NewFileWriter(Path(tmpFile.Name()), CompressionType(compType), Append())
+		require.NoError(t, err)
+		w, err = NewFileWriter(Path(tmpFile.Name()), CompressionType(compType), SchemaID(1), Append())
+		require.NoError(t, err)
+		assert.Error(t, w.Open())
+		w, err = NewFileWriter(Path(tmpFile.Name()), CompressionType(compType), SchemaID(0xCAFE), Append())
+		require.NoError(t, err)
+		require.NoError(t, w.Open())
+		_, err = w.Write(ascendingBytes(5))
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+
+		reader = newReaderOnTopOfWriter(t, writer)
+		readNextExpectAscendingBytesOfLen(t, reader, 10)
+		readNextExpectAscendingBytesOfLen(t, reader, 5)
+		readNextExpectEOF(t, reader)
+		require.NoError(t, reader.Close())
+		removeFileWriterFile(t, writer)
+	}
+}
+
+func TestWriterFileHeaderFlags(t *testing.T) {
+	for _, tc := range []struct {
+		opts    []FileWriterOption
+		version uint32
+		flags   uint32
+	}{
+		{nil, Version3, 0},
+		{[]FileWriterOption{CompressionType(CompressionTypeSnappy)}, Version3, 0},
+		{[]FileWriterOption{SchemaID(7)}, Version4, fileHeaderFlagSchemaID},
+		{[]FileWriterOption{FooterIndex(10)}, Version4, fileHeaderFlagFooter},
+		{[]FileWriterOption{CompressionType(CompressionTypeSnappy), MinCompressSizeBytes(10), SchemaID(7), FooterIndex(10)},
+			Version4, fileHeaderFlagUncompressedRecords | fileHeaderFlagSchemaID | fileHeaderFlagFooter},
+	} {
+		path := filepath.Join(t.TempDir(), "flags.rio")
+		w, err := NewFileWriter(append(tc.opts, Path(path))...)
+		require.NoError(t, err)
+		require.NoError(t, w.Open())
+		require.NoError(t, w.Close())
+
+		content, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, tc.version, binary.LittleEndian.Uint32(content[0:4]))
+		// the compression type never carries any flags
+		assert.LessOrEqual(t, binary.LittleEndian.Uint32(content[4:8]), uint32(CompressionTypeLzw))
+
+		reader, err := NewFileReaderWithPath(path)
+		require.NoError(t, err)
+		require.NoError(t, reader.Open())
+		assert.Equal(t, tc.flags, reader.(*FileReader).header.flags)
+		assert.Equal(t, uint64(w.(*FileWriter).headerOffset), reader.(*FileReader).header.size())
+		require.NoError(t, reader.Close())
+	}
+
+	// flags in the compression type of a v3 header are not a known compression
+	path := filepath.Join(t.TempDir(), "v3.rio")
+	header := fileHeaderAsByteSlice(CompressionTypeNone|1<<31, 0)
+	require.NoError(t, os.WriteFile(path, header, 0666))
+	reader, err := NewFileReaderWithPath(path)
+	require.NoError(t, err)
+	assert.ErrorContains(t, reader.Open(), "unknown compression type")
+}
+
+func TestWriterWithoutSchemaID(t *testing.T) {
+	writer, err := newUncompressedTestWriter()
+	require.NoError(t, err)
+	defer removeFileWriterFile(t, writer)
+	require.NoError(t, writer.Open())
+	require.NoError(t, writer.Close())
+
+	reader := newReaderOnTopOfWriter(t, writer)
+	_, ok := reader.SchemaID()
+	assert.False(t, ok)
+	require.NoError(t, reader.Close())
+
+	mmapReader := newOpenedTestMMapReader(t, writer.file.Name())
+	defer closeMMapReader(t, mmapReader)
+	_, ok = mmapReader.SchemaID()
+	assert.False(t, ok)
+}
+
 func newUncompressedTestWriter() (*FileWriter, error) {
 	tmpFile, err := os.CreateTemp("", "recordio_UncompressedWriter")
 	if err != nil {
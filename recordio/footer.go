@@ -0,0 +1,112 @@
+package recordio
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+)
+
+// FooterMagicNumber ends the trailer of a footer index, it tells a complete footer from a file that was never closed
+const FooterMagicNumber uint32 = 0x46544f52
+
+// FooterTrailerSizeBytes is the size of the fixed trailer at the end of a footer index: 8 byte offset of the footer,
+// 4 byte record interval, 4 byte crc32 and the 4 byte magic number = 20 bytes
+const FooterTrailerSizeBytes = 20
+
+// FooterEntrySizeBytes is the size of a single record offset in the footer index
+const FooterEntrySizeBytes = 8
+
+var FooterCorruptErr = errors.New("footer index corrupt")
+
+// footerIndex contains the offsets of every interval-th record, starting with the first one. The records of the file
+// end where the footer starts.
+type footerIndex struct {
+	recordsEnd uint64
+	interval   uint32
+	offsets    []uint64
+}
+
+// marshalFooter encodes the offsets, followed by the trailer. The checksum covers the offsets and the trailer up to
+// the checksum itself.
+func marshalFooter(recordsEnd uint64, interval int, offsets []uint64) []byte {
+	buf := make([]byte, 0, len(offsets)*FooterEntrySizeBytes+FooterTrailerSizeBytes)
+	for _, offset := range offsets {
+		buf = binary.LittleEndian.AppendUint64(buf, offset)
+	}
+	buf = binary.LittleEndian.AppendUint64(buf, recordsEnd)
+	buf = binary.LittleEndian.AppendUint32(buf, uint32(interval))
+	buf = binary.LittleEndian.AppendUint32(buf, crc32.ChecksumIEEE(buf))
+	return binary.LittleEndian.AppendUint32(buf, FooterMagicNumber)
+}
+
+// readFooter reads the footer index at the end of a file of the given size, whose records start at recordsStart.
+// Returns nil when the file has no complete footer, for example when its writer was never closed, and an error wrapping
+// FooterCorruptErr when the footer doesn't match its checksum.
+func readFooter(r io.ReaderAt, size uint64, recordsStart uint64) (*footerIndex, error) {
+	if size < recordsStart+FooterTrailerSizeBytes {
+		return nil, nil
+	}
+
+	trailer := make([]byte, FooterTrailerSizeBytes)
+	_, err := r.ReadAt(trailer, int64(size-FooterTrailerSizeBytes))
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("error while reading footer trailer: %w", err)
+	}
+
+	if binary.LittleEndian.Uint32(trailer[16:20]) != FooterMagicNumber {
+		return nil, nil
+	}
+
+	footer := &footerIndex{
+		recordsEnd: binary.LittleEndian.Uint64(trailer[0:8]),
+		interval:   binary.LittleEndian.Uint32(trailer[8:12]),
+	}
+	footerEnd := size - FooterTrailerSizeBytes
+	if footer.recordsEnd < recordsStart || footer.recordsEnd > footerEnd ||
+		(footerEnd-footer.recordsEnd)%FooterEntrySizeBytes != 0 || footer.interval == 0 {
+		return nil, fmt.Errorf("unexpected footer bounds, records end at %d in a file of %d bytes: %w", footer.recordsEnd, size, FooterCorruptErr)
+	}
+
+	buf := make([]byte, footerEnd-footer.recordsEnd+FooterTrailerSizeBytes)
+	_, err = r.ReadAt(buf, int64(footer.recordsEnd))
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("error while reading footer index: %w", err)
+	}
+
+	checksumStart := len(buf) - 8
+	if crc32.ChecksumIEEE(buf[:checksumStart]) != binary.LittleEndian.Uint32(buf[checksumStart:checksumStart+4]) {
+		return nil, fmt.Errorf("checksum mismatch: %w", FooterCorruptErr)
+	}
+
+	footer.offsets = make([]uint64, (footerEnd-footer.recordsEnd)/FooterEntrySizeBytes)
+	for i := range footer.offsets {
+		footer.offsets[i] = binary.LittleEndian.Uint64(buf[i*FooterEntrySizeBytes:])
+	}
+	return footer, nil
+}
+
+// limitedFile ends the wrapped file at the given size, so readers of files with a footer index only see the records
+type limitedFile struct {
+	randomAccessFile
+	size int
+}
+
+func (f limitedFile) ReadAt(p []byte, off int64) (int, error) {
+	if off >= int64(f.size) {
+		return 0, io.EOF
+	}
+	if off+int64(len(p)) > int64(f.size) {
+		n, err := f.randomAccessFile.ReadAt(p[:int64(f.size)-off], off)
+		if err == nil {
+			err = io.EOF
+		}
+		return n, err
+	}
+	return f.randomAccessFile.ReadAt(p, off)
+}
+
+func (f limitedFile) Len() int {
+	return f.size
+}
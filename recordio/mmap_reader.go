@@ -3,21 +3,48 @@ package recordio
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"io"
+	"os"
 
 	"golang.org/x/exp/mmap"
 
 	pool "capnproto.org/go/capnp/v3/exp/bufferpool"
 )
 
+// randomAccessFile is the source of a MMapReader, usually a memory mapped file
+type randomAccessFile interface {
+	io.ReaderAt
+	Len() int
+	Close() error
+}
+
+// unownedFile reads an already opened file with pread and leaves closing it to its owner
+type unownedFile struct {
+	file *os.File
+	size int
+}
+
+func (f unownedFile) ReadAt(p []byte, off int64) (int, error) {
+	return f.file.ReadAt(p, off)
+}
+
+func (f unownedFile) Len() int {
+	return f.size
+}
+
+func (f unownedFile) Close() error {
+	return nil
+}
+
 type MMapReader struct {
-	mmapReader *mmap.ReaderAt
+	mmapReader randomAccessFile
 	header     *Header
 	open       bool
 	closed     bool
-	bufferPool *pool.Pool
+	bufferPool BufferPool
 	path       string
 
 	seekLen int
@@ -32,30 +59,64 @@ func (r *MMapReader) Open() error {
 		return fmt.Errorf("mmap reader for '%s' is already closed", r.path)
 	}
 
-	buf := make([]byte, FileHeaderSizeBytes)
-	numRead, err := r.mmapReader.ReadAt(buf, 0)
+	header, err := readFileHeader(io.NewSectionReader(r.mmapReader, 0, int64(r.mmapReader.Len())))
 	if err != nil {
-		return fmt.Errorf("failed reading at offset 0 in mmap reader for '%s': %w", r.path, err)
-	}
-	if numRead != len(buf) {
-		return fmt.Errorf("not enough bytes in the header found, expected %d but were %d in mmap reader at '%s'", len(buf), numRead, r.path)
+		return fmt.Errorf("failed reading header in mmap reader for '%s': %w", r.path, err)
 	}
 
-	header, err := readFileHeaderFromBuffer(buf)
-	if err != nil {
-		return fmt.Errorf("failed reading header from buffer in mmap reader for '%s': %w", r.path, err)
+	if header.hasFooter {
+		footer, err := readFooter(r.mmapReader, uint64(r.mmapReader.Len()), header.size())
+		if err != nil {
+			return fmt.Errorf("failed reading footer in mmap reader for '%s': %w", r.path, err)
+		}
+		// the reads end at the footer, files without a complete footer are read until their end
+		if footer != nil {
+			r.mmapReader = limitedFile{randomAccessFile: r.mmapReader, size: int(footer.recordsEnd)}
+		}
 	}
 
 	r.header = header
-	r.bufferPool = pool.NewPool(1024, 20)
+	if r.bufferPool == nil {
+		r.bufferPool = pool.NewPool(1024, 20)
+	}
 	r.open = true
 	return nil
 }
 
+// SchemaID returns the schema id of the file header, see SchemaIDReaderI.
+func (r *MMapReader) SchemaID() (uint32, bool) {
+	if r.header == nil {
+		return 0, false
+	}
+	return r.header.schemaID, r.header.hasSchemaID
+}
+
 func (r *MMapReader) Size() uint64 {
 	return uint64(r.mmapReader.Len())
 }
 
+// Preload reads the whole file in chunks of preloadChunkBytes, which faults in all pages of the mapping, see PreloadI.
+func (r *MMapReader) Preload(ctx context.Context) (uint64, error) {
+	buf := make([]byte, preloadChunkBytes)
+	size := int64(r.mmapReader.Len())
+	var offset int64
+	for offset < size {
+		if err := ctx.Err(); err != nil {
+			return uint64(offset), err
+		}
+
+		n, err := r.mmapReader.ReadAt(buf, offset)
+		offset += int64(n)
+		if err != nil && !errors.Is(err, io.EOF) {
+			return uint64(offset), fmt.Errorf("failed preloading at offset %d in mmap reader for '%s': %w", offset, r.path, err)
+		}
+		if n == 0 {
+			break
+		}
+	}
+	return uint64(offset), nil
+}
+
 func (r *MMapReader) SeekNext(offset uint64) (uint64, []byte, error) {
 	if !r.open || r.closed {
 		return 0, nil, fmt.Errorf("reader at '%s' was either not opened yet or is closed already", r.path)
@@ -127,14 +188,19 @@ func (r *MMapReader) SeekNext(offset uint64) (uint64, []byte, error) {
 }
 
 func (r *MMapReader) ReadNextAt(offset uint64) ([]byte, error) {
+	return r.ReadNextAtInto(offset, nil)
+}
+
+// ReadNextAtInto reads the record at the given offset into dst, see IntoReadAtI. Nil records return nil.
+func (r *MMapReader) ReadNextAtInto(offset uint64, dst []byte) ([]byte, error) {
 	if !r.open || r.closed {
 		return nil, fmt.Errorf("reader at '%s' was either not opened yet or is closed already", r.path)
 	}
 
 	if r.header.fileVersion == Version1 {
-		return readNextAtV1(r, offset)
+		return readNextAtV1(r, offset, dst)
 	} else if r.header.fileVersion == Version2 {
-		return readNextAtV2(r, offset)
+		return readNextAtV2(r, offset, dst)
 	} else {
 		headerBufPooled := r.bufferPool.Get(RecordHeaderV3MaxSizeBytes)
 		defer r.bufferPool.Put(headerBufPooled)
@@ -154,16 +220,17 @@ func (r *MMapReader) ReadNextAt(offset uint64) ([]byte, error) {
 		}
 
 		headerByteReader := NewCountingByteReader(bufio.NewReader(bytes.NewReader(headerBufPooled[:numRead])))
-		payloadSizeUncompressed, payloadSizeCompressed, recordNil, err := readRecordHeaderV3(headerByteReader)
+		payloadSizeUncompressed, payloadSizeCompressed, flags, err := readRecordHeaderV3(headerByteReader)
 		if err != nil {
 			return nil, fmt.Errorf("failed reading record header at offset %d in mmap reader for '%s': %w", offset, r.path, err)
 		}
 
-		if recordNil {
+		if flags&recordFlagNil != 0 {
 			return nil, nil
 		}
 
-		expectedBytesRead, pooledRecordBuf := allocateRecordBufferPooled(r.bufferPool, r.header, payloadSizeUncompressed, payloadSizeCompressed)
+		compressed := isCompressed(r.header, flags)
+		expectedBytesRead, pooledRecordBuf := allocateRecordBufferPooled(r.bufferPool, compressed, payloadSizeUncompressed, payloadSizeCompressed)
 		defer r.bufferPool.Put(pooledRecordBuf)
 
 		numRead, err = r.mmapReader.ReadAt(pooledRecordBuf, int64(offset)+int64(headerByteReader.Count()))
@@ -176,7 +243,7 @@ func (r *MMapReader) ReadNextAt(offset uint64) ([]byte, error) {
 		}
 
 		var returnSlice []byte
-		if r.header.compressor != nil {
+		if compressed {
 			pooledDecompressionBuffer := r.bufferPool.Get(int(payloadSizeUncompressed))
 			defer r.bufferPool.Put(pooledDecompressionBuffer)
 
@@ -185,18 +252,79 @@ func (r *MMapReader) ReadNextAt(offset uint64) ([]byte, error) {
 				return nil, fmt.Errorf("failed decompressing record at offset %d in mmap reader for '%s': %w", offset, r.path, err)
 			}
 			// we do a defensive copy here not to leak the pooled slice
-			returnSlice = make([]byte, len(decompressedRecord))
-			copy(returnSlice, decompressedRecord)
+			returnSlice = copyInto(dst, decompressedRecord)
 		} else {
 			// we do a defensive copy here not to leak the pooled slice
-			returnSlice = make([]byte, len(pooledRecordBuf))
-			copy(returnSlice, pooledRecordBuf)
+			returnSlice = copyInto(dst, pooledRecordBuf)
 		}
 		return returnSlice, nil
 	}
 }
 
-func readNextAtV1(r *MMapReader, offset uint64) ([]byte, error) {
+func (r *MMapReader) ReadRecordAt(offset uint64) ([]byte, error) {
+	if !r.open || r.closed {
+		return nil, fmt.Errorf("reader at '%s' was either not opened yet or is closed already", r.path)
+	}
+
+	if offset == r.Size() {
+		return nil, io.EOF
+	}
+
+	if offset < r.header.size() || offset > r.Size() {
+		return nil, fmt.Errorf("ReadAt offset %d is outside of the records in mmap reader for '%s': %w", offset, r.path, NotARecordBoundaryErr)
+	}
+
+	headerBufPooled := r.bufferPool.Get(RecordHeaderV3MaxSizeBytes)
+	defer r.bufferPool.Put(headerBufPooled)
+
+	numRead, err := r.mmapReader.ReadAt(headerBufPooled, int64(offset))
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("ReadAt failed reading at offset %d in mmap reader for '%s': %w", offset, r.path, err)
+	}
+
+	var headerSize uint64
+	var payloadSizeUncompressed, payloadSizeCompressed uint64
+	compressed := r.header.compressor != nil
+	if r.header.fileVersion == Version1 {
+		if numRead < RecordHeaderSizeBytesV1V2 {
+			return nil, fmt.Errorf("ReadAt offset %d has no complete record header in mmap reader for '%s': %w", offset, r.path, NotARecordBoundaryErr)
+		}
+		headerSize = RecordHeaderSizeBytesV1V2
+		payloadSizeUncompressed, payloadSizeCompressed, err = readRecordHeaderV1(headerBufPooled[:RecordHeaderSizeBytesV1V2])
+	} else {
+		headerByteReader := NewCountingByteReader(bufio.NewReader(bytes.NewReader(headerBufPooled[:numRead])))
+		if r.header.fileVersion == Version2 {
+			payloadSizeUncompressed, payloadSizeCompressed, err = readRecordHeaderV2(headerByteReader)
+		} else {
+			var flags byte
+			payloadSizeUncompressed, payloadSizeCompressed, flags, err = readRecordHeaderV3(headerByteReader)
+			if err == nil && flags&recordFlagNil != 0 {
+				return nil, nil
+			}
+			compressed = isCompressed(r.header, flags)
+		}
+		headerSize = headerByteReader.Count()
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("ReadAt failed reading record header at offset %d in mmap reader for '%s': %w",
+			offset, r.path, errors.Join(NotARecordBoundaryErr, err))
+	}
+
+	payloadSize := payloadSizeUncompressed
+	if compressed {
+		payloadSize = payloadSizeCompressed
+	}
+
+	// a random offset can yield a seemingly valid header, the sizes however are unlikely to fit into the file
+	if payloadSize > r.Size()-offset-headerSize {
+		return nil, fmt.Errorf("ReadAt record at offset %d exceeds the file size in mmap reader for '%s': %w", offset, r.path, NotARecordBoundaryErr)
+	}
+
+	return r.ReadNextAt(offset)
+}
+
+func readNextAtV1(r *MMapReader, offset uint64, dst []byte) ([]byte, error) {
 	headerBufPooled := r.bufferPool.Get(RecordHeaderSizeBytesV1V2)
 	defer r.bufferPool.Put(headerBufPooled)
 
@@ -214,7 +342,7 @@ func readNextAtV1(r *MMapReader, offset uint64) ([]byte, error) {
 		return nil, fmt.Errorf("failed reading record header at offset %d in mmap reader for '%s': %w", offset, r.path, err)
 	}
 
-	expectedBytesRead, recordBuffer := allocateRecordBuffer(r.header, payloadSizeUncompressed, payloadSizeCompressed)
+	expectedBytesRead, recordBuffer := allocateRecordBuffer(r.header.compressor != nil, payloadSizeUncompressed, payloadSizeCompressed)
 	numRead, err = r.mmapReader.ReadAt(recordBuffer, int64(offset+RecordHeaderSizeBytesV1V2))
 	if err != nil {
 		return nil, fmt.Errorf("failed reading record at offset %d in mmap reader for '%s': %w", offset, r.path, err)
@@ -230,10 +358,13 @@ func readNextAtV1(r *MMapReader, offset uint64) ([]byte, error) {
 			return nil, fmt.Errorf("failed decompressing record at offset %d in mmap reader for '%s': %w", offset, r.path, err)
 		}
 	}
+	if dst != nil {
+		return copyInto(dst, recordBuffer), nil
+	}
 	return recordBuffer, nil
 }
 
-func readNextAtV2(r *MMapReader, offset uint64) ([]byte, error) {
+func readNextAtV2(r *MMapReader, offset uint64, dst []byte) ([]byte, error) {
 	headerBufPooled := r.bufferPool.Get(RecordHeaderV3MaxSizeBytes)
 	defer r.bufferPool.Put(headerBufPooled)
 
@@ -257,7 +388,7 @@ func readNextAtV2(r *MMapReader, offset uint64) ([]byte, error) {
 		return nil, fmt.Errorf("failed reading record header at offset %d in mmap reader for '%s': %w", offset, r.path, err)
 	}
 
-	expectedBytesRead, pooledRecordBuf := allocateRecordBufferPooled(r.bufferPool, r.header, payloadSizeUncompressed, payloadSizeCompressed)
+	expectedBytesRead, pooledRecordBuf := allocateRecordBufferPooled(r.bufferPool, r.header.compressor != nil, payloadSizeUncompressed, payloadSizeCompressed)
 	defer r.bufferPool.Put(pooledRecordBuf)
 
 	numRead, err = r.mmapReader.ReadAt(pooledRecordBuf, int64(offset)+int64(headerByteReader.Count()))
@@ -279,12 +410,10 @@ func readNextAtV2(r *MMapReader, offset uint64) ([]byte, error) {
 			return nil, fmt.Errorf("failed decompressing record at offset %d in mmap reader for '%s': %w", offset, r.path, err)
 		}
 		// we do a defensive copy here not to leak the pooled slice
-		returnSlice = make([]byte, len(decompressedRecord))
-		copy(returnSlice, decompressedRecord)
+		returnSlice = copyInto(dst, decompressedRecord)
 	} else {
 		// we do a defensive copy here not to leak the pooled slice
-		returnSlice = make([]byte, len(pooledRecordBuf))
-		copy(returnSlice, pooledRecordBuf)
+		returnSlice = copyInto(dst, pooledRecordBuf)
 	}
 	return returnSlice, nil
 }
@@ -303,3 +432,30 @@ func NewMemoryMappedReaderWithPath(path string) (ReadAtI, error) {
 	}
 	return &MMapReader{mmapReader: mmapReaderAt, path: path, seekLen: 4 * 1024}, nil
 }
+
+// NewReadAtReaderWithFile creates a reader with random access to the records of the given already opened file, which is
+// read with pread instead of a memory map. Close doesn't close the file, its lifetime is managed by the caller, so it can
+// be pooled and shared by many readers at the same time. The file must not be modified while it's read. The scratch
+// buffers are taken from the given pool, nil creates a pool per reader.
+func NewReadAtReaderWithFile(file *os.File, bufferPool BufferPool) (ReadAtI, error) {
+	stat, err := file.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("error while getting the size of '%s': %w", file.Name(), err)
+	}
+	return &MMapReader{
+		mmapReader: unownedFile{file: file, size: int(stat.Size())},
+		path:       file.Name(),
+		seekLen:    4 * 1024,
+		bufferPool: bufferPool,
+	}, nil
+}
+
+// NewMemoryMappedReaderWithPool creates a new mmap reader at the given path, which takes its scratch buffers from the
+// given pool. The pool can be shared across readers.
+func NewMemoryMappedReaderWithPool(path string, bufferPool BufferPool) (ReadAtI, error) {
+	mmapReaderAt, err := mmap.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("error while opening mmap at '%s': %w", path, err)
+	}
+	return &MMapReader{mmapReader: mmapReaderAt, path: path, seekLen: 4 * 1024, bufferPool: bufferPool}, nil
+}
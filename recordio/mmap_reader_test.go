@@ -1,15 +1,19 @@
 package recordio
 
 import (
+	"context"
 	"errors"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"io"
 	"math"
 	"math/rand"
+	"os"
 	"testing"
 )
 
+var _ ValidatingReadAtI = (*MMapReader)(nil)
+
 func TestMMapReaderHappyPathSingleRecord(t *testing.T) {
 	reader := newOpenedTestMMapReader(t, "test_files/v3_compat/recordio_UncompressedSingleRecord")
 	defer closeMMapReader(t, reader)
@@ -20,6 +24,25 @@ func TestMMapReaderHappyPathSingleRecord(t *testing.T) {
 	assertAscendingBytes(t, buf, 13)
 }
 
+func TestReadAtReaderWithFile(t *testing.T) {
+	file, err := os.Open("test_files/v3_compat/recordio_UncompressedSingleRecord")
+	require.NoError(t, err)
+	defer func() { require.NoError(t, file.Close()) }()
+
+	for i := 0; i < 2; i++ {
+		r, err := NewReadAtReaderWithFile(file, nil)
+		require.NoError(t, err)
+		reader := r.(*MMapReader)
+		require.NoError(t, reader.Open())
+
+		buf, err := reader.ReadNextAt(FileHeaderSizeBytes)
+		require.NoError(t, err)
+		assertAscendingBytes(t, buf, 13)
+		// closing the reader leaves the file open for the next one
+		closeMMapReader(t, reader)
+	}
+}
+
 func TestMMapReaderSingleRecordMisalignedOffset(t *testing.T) {
 	reader := newOpenedTestMMapReader(t, "test_files/v3_compat/recordio_UncompressedSingleRecord")
 	defer closeMMapReader(t, reader)
@@ -38,12 +61,12 @@ func TestMMapReaderSingleRecordOffsetBiggerThanFile(t *testing.T) {
 
 func TestMMapReaderVersionMismatchV0(t *testing.T) {
 	reader := newTestMMapReader("test_files/v3_compat/recordio_UncompressedSingleRecord_v0", t)
-	expectErrorStringOnOpen(t, reader, "version mismatch, expected a value from 1 to 3 but was 0")
+	expectErrorStringOnOpen(t, reader, "version mismatch, expected a value from 1 to 4 but was 0")
 }
 
 func TestMMapReaderVersionMismatchV256(t *testing.T) {
 	reader := newTestMMapReader("test_files/v3_compat/recordio_UncompressedSingleRecord_v256", t)
-	expectErrorStringOnOpen(t, reader, "version mismatch, expected a value from 1 to 3 but was 256")
+	expectErrorStringOnOpen(t, reader, "version mismatch, expected a value from 1 to 4 but was 256")
 }
 
 func TestMMapReaderCompressionGzipHeader(t *testing.T) {
@@ -165,6 +188,130 @@ func TestMMApReaderReadSequencedWrites(t *testing.T) {
 	}
 }
 
+func TestMMapReaderReadRecordAt(t *testing.T) {
+	for _, compType := range []int{CompressionTypeNone, CompressionTypeSnappy} {
+		writer, err := newCompressedTestWriter(compType)
+		require.NoError(t, err)
+		require.NoError(t, writer.Open())
+		defer removeFileWriterFile(t, writer)
+
+		var offsets []uint64
+		for i := 0; i < 50; i++ {
+			var record []byte
+			if i%10 != 0 {
+				record = ascendingBytes(i + 1)
+			}
+			offset, err := writer.Write(record)
+			require.NoError(t, err)
+			offsets = append(offsets, offset)
+		}
+		require.NoError(t, writer.Close())
+
+		reader := newOpenedTestMMapReader(t, writer.file.Name())
+		isBoundary := map[uint64]bool{}
+		for i, offset := range offsets {
+			isBoundary[offset] = true
+			record, err := reader.ReadRecordAt(offset)
+			require.NoError(t, err)
+			if i%10 == 0 {
+				require.Nil(t, record)
+			} else {
+				assertAscendingBytes(t, record, i+1)
+			}
+		}
+
+		// every other offset must yield an error and never panic
+		for i := uint64(0); i < reader.Size(); i++ {
+			if isBoundary[i] {
+				continue
+			}
+			_, err := reader.ReadRecordAt(i)
+			if compType == CompressionTypeNone {
+				require.ErrorIs(t, err, NotARecordBoundaryErr, "offset %d", i)
+			}
+		}
+
+		_, err = reader.ReadRecordAt(reader.Size())
+		require.ErrorIs(t, err, io.EOF)
+		_, err = reader.ReadRecordAt(reader.Size() + 1)
+		require.ErrorIs(t, err, NotARecordBoundaryErr)
+		closeMMapReader(t, reader)
+	}
+}
+
+func TestMMapReaderReadRecordAtV1(t *testing.T) {
+	reader := newOpenedTestMMapReader(t, "test_files/v1_compat/recordio_UncompressedSingleRecord")
+	defer closeMMapReader(t, reader)
+
+	buf, err := reader.ReadRecordAt(FileHeaderSizeBytes)
+	require.NoError(t, err)
+	assertAscendingBytes(t, buf, 13)
+
+	_, err = reader.ReadRecordAt(FileHeaderSizeBytes + 1)
+	require.ErrorIs(t, err, NotARecordBoundaryErr)
+}
+
+func TestMMapReaderReadNextAtInto(t *testing.T) {
+	for _, compType := range []int{CompressionTypeNone, CompressionTypeSnappy} {
+		writer, err := newCompressedTestWriter(compType)
+		require.NoError(t, err)
+		require.NoError(t, writer.Open())
+		defer removeFileWriterFile(t, writer)
+
+		var offsets []uint64
+		for i := 0; i < 20; i++ {
+			var record []byte
+			if i%10 != 0 {
+				record = ascendingBytes(i + 1)
+			}
+			offset, err := writer.Write(record)
+			require.NoError(t, err)
+			offsets = append(offsets, offset)
+		}
+		require.NoError(t, writer.Close())
+
+		pool := &countingBufferPool{}
+		r, err := NewMemoryMappedReaderWithPool(writer.file.Name(), pool)
+		require.NoError(t, err)
+		reader := r.(*MMapReader)
+		require.NoError(t, reader.Open())
+
+		dst := make([]byte, 0, 10)
+		for i, offset := range offsets {
+			record, err := reader.ReadNextAtInto(offset, dst)
+			require.NoError(t, err)
+			if i%10 == 0 {
+				require.Nil(t, record)
+				continue
+			}
+			assertAscendingBytes(t, record, i+1)
+			// the record aliases dst as long as it fits
+			assert.Equal(t, len(record) <= cap(dst), &record[0] == &dst[:1][0])
+		}
+		assert.Greater(t, pool.gets, 0)
+		assert.Equal(t, pool.gets, pool.puts)
+		closeMMapReader(t, reader)
+	}
+}
+
+func TestMMapReaderReadNextAtIntoV1(t *testing.T) {
+	reader := newOpenedTestMMapReader(t, "test_files/v1_compat/recordio_UncompressedSingleRecord")
+	defer closeMMapReader(t, reader)
+
+	dst := make([]byte, 0, 20)
+	buf, err := reader.ReadNextAtInto(FileHeaderSizeBytes, dst)
+	require.NoError(t, err)
+	assertAscendingBytes(t, buf, 13)
+	assert.Equal(t, &dst[:1][0], &buf[0])
+}
+
+func TestMMapReaderReadRecordAtNotOpened(t *testing.T) {
+	reader := newTestMMapReader("test_files/v3_compat/recordio_UncompressedSingleRecord", t)
+	defer closeMMapReader(t, reader)
+	_, err := reader.ReadRecordAt(FileHeaderSizeBytes)
+	require.Error(t, err)
+}
+
 func TestMMApReaderReadShuffled(t *testing.T) {
 	writer := newOpenedWriter(t)
 	defer removeFileWriterFile(t, writer)
@@ -259,6 +406,35 @@ func TestMMapReaderMagicNumberContents(t *testing.T) {
 	require.Equal(t, io.EOF, err)
 }
 
+type countingBufferPool struct {
+	gets int
+	puts int
+}
+
+func (p *countingBufferPool) Get(size int) []byte {
+	p.gets++
+	return make([]byte, size)
+}
+
+func (p *countingBufferPool) Put([]byte) {
+	p.puts++
+}
+
+func TestMMapReaderPreload(t *testing.T) {
+	reader := newOpenedTestMMapReader(t, "test_files/v3_compat/recordio_UncompressedSingleRecord")
+	defer closeMMapReader(t, reader)
+
+	n, err := reader.Preload(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, reader.Size(), n)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	n, err = reader.Preload(ctx)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, uint64(0), n)
+}
+
 func newOpenedTestMMapReader(t *testing.T, file string) *MMapReader {
 	reader := newTestMMapReader(file, t)
 	require.NoError(t, reader.Open())
@@ -36,12 +36,12 @@ func TestMMapReaderSingleRecordOffsetBiggerThanFileV2(t *testing.T) {
 
 func TestMMapReaderV2VersionMismatchV0(t *testing.T) {
 	reader := newTestMMapReader("test_files/v2_compat/recordio_UncompressedSingleRecord_v0", t)
-	expectErrorStringOnOpen(t, reader, "version mismatch, expected a value from 1 to 3 but was 0")
+	expectErrorStringOnOpen(t, reader, "version mismatch, expected a value from 1 to 4 but was 0")
 }
 
 func TestMMapReaderV2VersionMismatchV256(t *testing.T) {
 	reader := newTestMMapReader("test_files/v2_compat/recordio_UncompressedSingleRecord_v256", t)
-	expectErrorStringOnOpen(t, reader, "version mismatch, expected a value from 1 to 3 but was 256")
+	expectErrorStringOnOpen(t, reader, "version mismatch, expected a value from 1 to 4 but was 256")
 }
 
 func TestMMapReaderCompressionGzipHeaderV2(t *testing.T) {
@@ -33,6 +33,15 @@ func (w *Writer) WriteSync(record proto.Message) (uint64, error) {
 	return w.writer.WriteSync(bytes)
 }
 
+// Flush writes the buffered records to the file, see recordio.FlushI. Writers without a buffer, like the
+// size estimator, have nothing to flush.
+func (w *Writer) Flush() error {
+	if f, ok := w.writer.(recordio.FlushI); ok {
+		return f.Flush()
+	}
+	return nil
+}
+
 func (w *Writer) Close() error {
 	return w.writer.Close()
 }
@@ -44,11 +53,12 @@ func (w *Writer) Size() uint64 {
 // options
 
 type WriterOptions struct {
-	path            string
-	file            *os.File
-	compressionType int
-	bufSizeBytes    int
-	useDirectIO     bool
+	path             string
+	file             *os.File
+	compressionType  int
+	compressionLevel int
+	bufSizeBytes     int
+	useDirectIO      bool
 }
 
 type WriterOption func(*WriterOptions)
@@ -71,6 +81,13 @@ func CompressionType(p int) WriterOption {
 	}
 }
 
+// CompressionLevel sets the level of the compression type, see recordio.CompressionLevel.
+func CompressionLevel(p int) WriterOption {
+	return func(args *WriterOptions) {
+		args.compressionLevel = p
+	}
+}
+
 func WriteBufferSizeBytes(p int) WriterOption {
 	return func(args *WriterOptions) {
 		args.bufSizeBytes = p
@@ -87,11 +104,12 @@ func DirectIO() WriterOption {
 // turned off by default.
 func NewWriter(writerOptions ...WriterOption) (WriterI, error) {
 	opts := &WriterOptions{
-		path:            "",
-		file:            nil,
-		compressionType: recordio.CompressionTypeNone,
-		bufSizeBytes:    1024 * 1024 * 4,
-		useDirectIO:     false,
+		path:             "",
+		file:             nil,
+		compressionType:  recordio.CompressionTypeNone,
+		compressionLevel: recordio.DefaultCompressionLevel,
+		bufSizeBytes:     1024 * 1024 * 4,
+		useDirectIO:      false,
 	}
 
 	for _, writeOption := range writerOptions {
@@ -124,6 +142,7 @@ func NewWriter(writerOptions ...WriterOption) (WriterI, error) {
 	writer, err := recordio.NewFileWriter(
 		recordio.File(opts.file),
 		recordio.CompressionType(opts.compressionType),
+		recordio.CompressionLevel(opts.compressionLevel),
 		recordio.BufferSizeBytes(opts.bufSizeBytes))
 	if err != nil {
 		return nil, err
@@ -131,3 +150,19 @@ func NewWriter(writerOptions ...WriterOption) (WriterI, error) {
 
 	return &Writer{writer: writer}, nil
 }
+
+// NewSizeEstimator creates a writer that only accounts for the bytes a writer with the given compression type would
+// write, see recordio.NewSizeEstimator.
+func NewSizeEstimator(compressionType int) (WriterI, error) {
+	return NewSizeEstimatorWithCompressionLevel(compressionType, recordio.DefaultCompressionLevel)
+}
+
+// NewSizeEstimatorWithCompressionLevel is like NewSizeEstimator, but compresses with the given level.
+func NewSizeEstimatorWithCompressionLevel(compressionType int, compressionLevel int) (WriterI, error) {
+	writer, err := recordio.NewSizeEstimatorWithCompressionLevel(compressionType, compressionLevel)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Writer{writer: writer}, nil
+}
@@ -0,0 +1,111 @@
+package recordio
+
+import (
+	"errors"
+	"io"
+	"sync"
+)
+
+type readAheadChunk struct {
+	buf []byte
+	err error
+}
+
+// readAheadReader reads the underlying reader sequentially in a separate goroutine, so the next chunks are already in
+// memory while the current one is consumed. At most two chunks of half the read-ahead size are in flight.
+type readAheadReader struct {
+	r io.Reader
+
+	chunks chan readAheadChunk
+	free   chan []byte
+	done   chan struct{}
+
+	start sync.Once
+	stop  sync.Once
+	wg    sync.WaitGroup
+
+	// current is the unread remainder of buf, err is returned once current was consumed
+	buf     []byte
+	current []byte
+	err     error
+}
+
+func (r *readAheadReader) Read(p []byte) (int, error) {
+	r.start.Do(func() {
+		r.wg.Add(1)
+		go r.readLoop()
+	})
+
+	for len(r.current) == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+
+		if r.buf != nil {
+			r.free <- r.buf
+			r.buf = nil
+		}
+
+		chunk, ok := <-r.chunks
+		if !ok {
+			return 0, errors.New("read ahead was already closed")
+		}
+		r.buf, r.current, r.err = chunk.buf, chunk.buf, chunk.err
+	}
+
+	n := copy(p, r.current)
+	r.current = r.current[n:]
+	return n, nil
+}
+
+func (r *readAheadReader) readLoop() {
+	defer r.wg.Done()
+	defer close(r.chunks)
+
+	for {
+		var buf []byte
+		select {
+		case buf = <-r.free:
+		case <-r.done:
+			return
+		}
+
+		n, err := io.ReadFull(r.r, buf)
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			err = io.EOF
+		}
+
+		select {
+		case r.chunks <- readAheadChunk{buf: buf[:n], err: err}:
+		case <-r.done:
+			return
+		}
+
+		if err != nil {
+			return
+		}
+	}
+}
+
+// Close stops reading ahead, it must be called before the underlying reader is closed.
+func (r *readAheadReader) Close() error {
+	r.stop.Do(func() {
+		close(r.done)
+		r.wg.Wait()
+	})
+	return nil
+}
+
+func newReadAheadReader(r io.Reader, readAheadBytes int) *readAheadReader {
+	chunkSize := max(1, readAheadBytes/2)
+	free := make(chan []byte, 2)
+	free <- make([]byte, chunkSize)
+	free <- make([]byte, chunkSize)
+
+	return &readAheadReader{
+		r:      r,
+		chunks: make(chan readAheadChunk, 2),
+		free:   free,
+		done:   make(chan struct{}),
+	}
+}
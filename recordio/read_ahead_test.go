@@ -0,0 +1,95 @@
+package recordio
+
+import (
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadAheadReaderFullRead(t *testing.T) {
+	for _, readAheadBytes := range []int{1, 2, 7, 64, 1024} {
+		data := ascendingBytes(1000)
+		r := newReadAheadReader(&onlyReader{data: data}, readAheadBytes)
+		actual, err := io.ReadAll(r)
+		require.NoError(t, err)
+		assert.Equal(t, data, actual)
+		require.NoError(t, r.Close())
+	}
+}
+
+func TestReadAheadReaderPropagatesErrors(t *testing.T) {
+	expectedErr := errors.New("disk on fire")
+	r := newReadAheadReader(io.MultiReader(&onlyReader{data: ascendingBytes(10)}, &failingReader{err: expectedErr}), 8)
+	defer func() { require.NoError(t, r.Close()) }()
+
+	actual, err := io.ReadAll(r)
+	assert.ErrorIs(t, err, expectedErr)
+	assert.Equal(t, ascendingBytes(10), actual)
+}
+
+func TestReadAheadReaderCloseBeforeDone(t *testing.T) {
+	r := newReadAheadReader(&onlyReader{data: ascendingBytes(1000)}, 8)
+	buf := make([]byte, 4)
+	_, err := io.ReadFull(r, buf)
+	require.NoError(t, err)
+	require.NoError(t, r.Close())
+	require.NoError(t, r.Close())
+
+	_, err = io.ReadAll(r)
+	require.Error(t, err)
+}
+
+func TestFileReaderWithReadAhead(t *testing.T) {
+	writer := newOpenedWriter(t)
+	defer removeFileWriterFile(t, writer)
+	for i := 0; i < 1000; i++ {
+		_, err := writer.Write(ascendingBytes(i % 100))
+		require.NoError(t, err)
+	}
+	require.NoError(t, writer.Close())
+
+	for _, readAheadBytes := range []int{16, 4096, 1024 * 1024} {
+		reader, err := NewFileReader(ReaderPath(writer.file.Name()), ReaderBufferSizeBytes(1024), ReaderReadAheadBytes(readAheadBytes))
+		require.NoError(t, err)
+		require.NoError(t, reader.Open())
+
+		for i := 0; i < 1000; i++ {
+			// skipping seeks in the file, the read ahead has to continue after the skipped record
+			if i%10 == 5 {
+				require.NoError(t, reader.SkipNext())
+				continue
+			}
+			buf, err := reader.ReadNext()
+			require.NoError(t, err)
+			assert.Equal(t, ascendingBytes(i%100), buf)
+		}
+		_, err = reader.ReadNext()
+		assert.ErrorIs(t, err, io.EOF)
+		require.NoError(t, reader.Close())
+	}
+}
+
+// onlyReader hides all other interfaces of the underlying bytes
+type onlyReader struct {
+	data []byte
+}
+
+func (r *onlyReader) Read(p []byte) (int, error) {
+	if len(r.data) == 0 {
+		return 0, io.EOF
+	}
+	n := copy(p, r.data)
+	r.data = r.data[n:]
+	return n, nil
+}
+
+type failingReader struct {
+	err error
+}
+
+func (r *failingReader) Read([]byte) (int, error) {
+	return 0, r.err
+}
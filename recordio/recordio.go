@@ -1,17 +1,30 @@
 package recordio
 
 import (
+	"compress/gzip"
+	"context"
 	"encoding/binary"
 	"fmt"
+	"io"
 	"os"
 
 	"github.com/thomasjungblut/go-sstables/recordio/compressor"
 )
 
+// The file versions and how they stay compatible:
+//   - v1 has fixed size record headers, v2 encodes them as varints and v3 adds the flags byte to the record header.
+//   - v4 adds a 4 byte flags field after the compression type of the file header, for the features that older
+//     versions can't read: records stored uncompressed in a compressed file (MinCompressSizeBytes), a schema id after
+//     the header (SchemaID) and a footer index at the end of the file (FooterIndex).
+//
+// Writers write a v3 header unless one of these features is used, so those files can still be read by older versions.
+// Readers read every version up to CurrentVersion and reject newer versions as well as unknown flags, a file is either
+// read as written or not at all. New features that change the format must add a flag, or a new version.
 const Version1 uint32 = 0x01
 const Version2 uint32 = 0x02
 const Version3 uint32 = 0x03
-const CurrentVersion = Version3
+const Version4 uint32 = 0x04
+const CurrentVersion = Version4
 const MagicNumberSeparator uint32 = 0x130691
 const MagicNumberSeparatorLong uint64 = 0x130691
 
@@ -19,6 +32,24 @@ var MagicNumberSeparatorLongBytes = []byte{0x91, 0x8d, 0x4c}
 
 // FileHeaderSizeBytes has a 4 byte version number, 4 byte compression code = 8 bytes
 const FileHeaderSizeBytes = 8
+
+// FileHeaderV4SizeBytes is the size of a v4 header, which is followed by a 4 byte flags field
+const FileHeaderV4SizeBytes = FileHeaderSizeBytes + 4
+
+// FileHeaderWithSchemaIDSizeBytes is the size of headers written with SchemaID, v4 headers followed by a 4 byte schema id
+const FileHeaderWithSchemaIDSizeBytes = FileHeaderV4SizeBytes + 4
+
+// the flags of a v4 file header
+const (
+	// fileHeaderFlagUncompressedRecords is set for compressed files that store small records uncompressed, see
+	// MinCompressSizeBytes
+	fileHeaderFlagUncompressedRecords uint32 = 1 << 0
+	// fileHeaderFlagSchemaID is set when the header is followed by a schema id, see SchemaID
+	fileHeaderFlagSchemaID uint32 = 1 << 1
+	// fileHeaderFlagFooter is set for files that end with a footer index, see FooterIndex
+	fileHeaderFlagFooter uint32 = 1 << 2
+	fileHeaderFlagsKnown        = fileHeaderFlagUncompressedRecords | fileHeaderFlagSchemaID | fileHeaderFlagFooter
+)
 const RecordHeaderSizeBytesV1V2 = 20
 
 // RecordHeaderV3MaxSizeBytes is the max buffer sizes to prevent PutUvarint to panic:
@@ -26,6 +57,14 @@ const RecordHeaderSizeBytesV1V2 = 20
 const RecordHeaderV3MaxSizeBytes = binary.MaxVarintLen64 + binary.MaxVarintLen64 + binary.MaxVarintLen64 + 1
 const RecordHeaderV3MinSizeBytes = 1 + 1 + 1 + 1
 
+// the flags byte of a v3 record header
+const (
+	recordFlagNil byte = 1 << 0
+	// recordFlagUncompressed marks records that are stored uncompressed in a compressed file, see MinCompressSizeBytes
+	recordFlagUncompressed byte = 1 << 1
+	recordFlagsKnown            = recordFlagNil | recordFlagUncompressed
+)
+
 // never reorder, always append
 const (
 	CompressionTypeNone   = iota
@@ -34,6 +73,9 @@ const (
 	CompressionTypeLzw    = iota
 )
 
+// DefaultCompressionLevel selects the default level of the compression type, it's the default of CompressionLevel.
+const DefaultCompressionLevel = gzip.DefaultCompression
+
 // DefaultBufferSize is four mebibyte and can be customized using the option BufferSizeBytes.
 const DefaultBufferSize = 1024 * 1024 * 4
 
@@ -75,6 +117,25 @@ type WriterI interface {
 	Seek(offset uint64) error
 }
 
+// FlushI is implemented by writers that buffer records before they are written to the file.
+type FlushI interface {
+	// Flush writes all buffered records to the file, without forcing a disk sync.
+	Flush() error
+}
+
+// RecordWriterI writes the payload of a single record incrementally, see StreamingWriterI
+type RecordWriterI interface {
+	io.WriteCloser
+	// Offset returns the offset the record is written to
+	Offset() uint64
+}
+
+type StreamingWriterI interface {
+	// WriteStreaming starts a new record at the current offset. Its payload is written incrementally through the returned
+	// RecordWriterI and the record is complete once that is closed, no other writes are allowed until then.
+	WriteStreaming() (RecordWriterI, error)
+}
+
 type ReaderI interface {
 	OpenClosableI
 	// ReadNext reads the next record, EOF error when it reaches the end signalled by (nil, io.EOF). It can be wrapped however, so always check using errors.Is(err, io.EOF).
@@ -83,6 +144,13 @@ type ReaderI interface {
 	SkipNext() error
 }
 
+// SkipI is implemented by readers that can skip several records at once.
+type SkipI interface {
+	// Skip advances past the next n records by only reading their headers and seeking over the payloads, which are
+	// never decompressed. Returns an error wrapping io.EOF when the end was reached before n records were skipped.
+	Skip(n int) error
+}
+
 // ReadAtI implementors must make their implementation thread-safe
 type ReadAtI interface {
 	OpenClosableI
@@ -99,6 +167,57 @@ type ReadAtI interface {
 	SeekNext(offset uint64) (uint64, []byte, error)
 }
 
+// ValidatingReadAtI is implemented by random access readers that can validate the offset of a record.
+type ValidatingReadAtI interface {
+	// ReadRecordAt reads the single record starting at the given offset, as returned by WriterI.Write. In contrast to
+	// ReadNextAt, the record header is validated against the file bounds first and an error wrapping
+	// NotARecordBoundaryErr is returned when the offset doesn't point to the start of a record.
+	// Reading at exactly the end of the file returns io.EOF. Implementation must be thread-safe.
+	ReadRecordAt(offset uint64) ([]byte, error)
+}
+
+// SchemaIDReaderI is implemented by readers that can return the schema id of the file header, see SchemaID.
+type SchemaIDReaderI interface {
+	// SchemaID returns the schema id of the opened file, false when it was written without one.
+	SchemaID() (uint32, bool)
+}
+
+// PreloadI is implemented by random access readers that can read their whole file ahead of the first lookups.
+type PreloadI interface {
+	// Preload reads the whole file once, so that later reads don't wait for the disk. It returns the number of bytes
+	// read, along with the error of ctx when it was done before the end of the file.
+	Preload(ctx context.Context) (uint64, error)
+}
+
+// IntoReaderI is implemented by readers that can read the next record into a caller supplied buffer.
+type IntoReaderI interface {
+	// ReadNextInto reads the next record like ReaderI.ReadNext, but copies it into dst, which is grown when its capacity
+	// isn't sufficient. The returned slice aliases dst whenever it fits.
+	ReadNextInto(dst []byte) ([]byte, error)
+}
+
+// IntoReadAtI is implemented by readers that can read a record into a caller supplied buffer.
+type IntoReadAtI interface {
+	// ReadNextAtInto reads the record at the given offset like ReadAtI.ReadNextAt, but copies it into dst, which is
+	// grown when its capacity isn't sufficient. The returned slice aliases dst whenever it fits.
+	ReadNextAtInto(offset uint64, dst []byte) ([]byte, error)
+}
+
+// BufferPool provides the scratch buffers that readers use for headers, compressed payloads and decompression.
+// Implementations must be thread-safe, *bufferpool.Pool from capnproto.org/go/capnp/v3/exp/bufferpool satisfies it.
+type BufferPool interface {
+	// Get returns a buffer with len(buf) == size.
+	Get(size int) []byte
+	// Put returns the buffer to the pool, it must not be used afterwards.
+	Put(buf []byte)
+}
+
+type StreamingReadAtI interface {
+	// ReadNextAtStreaming returns a reader over the payload of the record at the given offset, so large records don't
+	// need to be buffered in memory. Errors follow the semantics of ReadAtI.ReadNextAt, nil records yield an empty reader.
+	ReadNextAtStreaming(offset uint64) (io.Reader, error)
+}
+
 type ReaderWriterCloserFactory interface {
 	CreateNewReader(filePath string, bufSize int) (*os.File, ByteReaderResetCount, error)
 	CreateNewWriter(filePath string, bufSize int) (*os.File, WriteSeekerCloserFlusher, error)
@@ -108,13 +227,22 @@ type ReaderWriterCloserFactory interface {
 // An error is returned if the desired compressor is not implemented.
 // Only CompressionTypeNone, CompressionTypeSnappy and CompressionTypeGZIP are available currently.
 func NewCompressorForType(compType int) (compressor.CompressionI, error) {
+	return newCompressorWithLevel(compType, DefaultCompressionLevel)
+}
+
+// newCompressorWithLevel returns the compressor for the type with the given compression level, see
+// DefaultCompressionLevel. The level is ignored by types that don't support levels.
+func newCompressorWithLevel(compType int, level int) (compressor.CompressionI, error) {
 	switch compType {
 	case CompressionTypeNone:
 		return nil, nil
 	case CompressionTypeSnappy:
 		return &compressor.SnappyCompressor{}, nil
 	case CompressionTypeGZIP:
-		return &compressor.GzipCompressor{}, nil
+		if level < gzip.HuffmanOnly || level > gzip.BestCompression {
+			return nil, fmt.Errorf("unsupported gzip compression level %d", level)
+		}
+		return &compressor.GzipCompressor{Level: level, LevelSet: true}, nil
 	case CompressionTypeLzw:
 		return &compressor.LzwCompressor{}, nil
 	default:
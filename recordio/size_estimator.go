@@ -0,0 +1,227 @@
+package recordio
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/thomasjungblut/go-sstables/recordio/compressor"
+)
+
+// SizeEstimator is a WriterI that runs every record through the same compression and header encoding as the
+// FileWriter, but discards the output. Size returns the exact number of bytes a FileWriter with the same compression
+// type would have written, without doing any IO.
+type SizeEstimator struct {
+	open   bool
+	closed bool
+
+	currentOffset     uint64
+	compressionType   int
+	compressionLevel  int
+	compressor        compressor.CompressionI
+	recordHeaderCache []byte
+	// minCompressSizeBytes stores smaller records uncompressed, see MinCompressSizeBytes
+	minCompressSizeBytes int
+	// headerSize is the size of the file header, which includes the schema id if set
+	headerSize uint64
+}
+
+func (e *SizeEstimator) Open() error {
+	if e.open {
+		return errors.New("size estimator is already opened")
+	}
+
+	if e.closed {
+		return errors.New("size estimator is already closed")
+	}
+
+	var err error
+	e.compressor, err = newCompressorWithLevel(e.compressionType, e.compressionLevel)
+	if err != nil {
+		return fmt.Errorf("creating compressor with type '%d' in size estimator failed with %w", e.compressionType, err)
+	}
+
+	if e.headerSize == 0 {
+		e.headerSize = FileHeaderSizeBytes
+	}
+	e.currentOffset = e.headerSize
+	e.recordHeaderCache = make([]byte, RecordHeaderV3MaxSizeBytes)
+	e.open = true
+	return nil
+}
+
+// Write accounts for a record of bytes, returns the offset this item would have been written to
+func (e *SizeEstimator) Write(record []byte) (uint64, error) {
+	if !e.open || e.closed {
+		return 0, errors.New("size estimator was either not opened yet or is closed already")
+	}
+
+	uncompressedSize := uint64(len(record))
+	compressedSize := uint64(0)
+	payloadSize := uncompressedSize
+	flags := recordFlags(record, e.compressor, e.minCompressSizeBytes)
+	if e.compressor != nil && flags&recordFlagUncompressed == 0 {
+		compressedRecord, err := e.compressor.Compress(record)
+		if err != nil {
+			return 0, fmt.Errorf("failed to compress record in size estimator failed with %w", err)
+		}
+		compressedSize = uint64(len(compressedRecord))
+		payloadSize = compressedSize
+	}
+
+	header := fillRecordHeaderV3(e.recordHeaderCache, uncompressedSize, compressedSize, flags)
+	prevOffset := e.currentOffset
+	e.currentOffset += uint64(len(header))
+	if record != nil {
+		e.currentOffset += payloadSize
+	}
+
+	return prevOffset, nil
+}
+
+// WriteSync is the same as Write, as there is nothing to sync
+func (e *SizeEstimator) WriteSync(record []byte) (uint64, error) {
+	return e.Write(record)
+}
+
+// WriteStreaming accounts for a record that is written incrementally. Like the FileWriter, compressed records are
+// streamed through the compressor, whose output is only counted.
+func (e *SizeEstimator) WriteStreaming() (RecordWriterI, error) {
+	if !e.open || e.closed {
+		return nil, errors.New("size estimator was either not opened yet or is closed already")
+	}
+
+	rw := &estimatedRecordWriter{estimator: e, offset: e.currentOffset}
+	if e.compressor != nil {
+		streamingCompressor, ok := e.compressor.(compressor.StreamingCompressionI)
+		if !ok {
+			return nil, fmt.Errorf("compression type %d does not support streaming records", e.compressionType)
+		}
+		rw.payload = &payloadWriter{}
+		var err error
+		rw.compressWriter, err = streamingCompressor.NewCompressWriter(rw.payload)
+		if err != nil {
+			return nil, fmt.Errorf("failed to start compressing streaming record in size estimator failed with %w", err)
+		}
+	}
+	return rw, nil
+}
+
+type estimatedRecordWriter struct {
+	estimator *SizeEstimator
+	offset    uint64
+	size      uint64
+	// compressWriter compresses into payload, both are nil for uncompressed records
+	compressWriter io.WriteCloser
+	payload        *payloadWriter
+	closed         bool
+}
+
+func (r *estimatedRecordWriter) Write(p []byte) (int, error) {
+	if r.closed {
+		return 0, errors.New("streaming record was already closed")
+	}
+
+	if r.compressWriter != nil {
+		n, err := r.compressWriter.Write(p)
+		r.size += uint64(n)
+		if err != nil {
+			return n, fmt.Errorf("failed to compress streaming record in size estimator failed with %w", err)
+		}
+		return n, nil
+	}
+	r.size += uint64(len(p))
+	return len(p), nil
+}
+
+func (r *estimatedRecordWriter) Close() error {
+	if r.closed {
+		return errors.New("streaming record was already closed")
+	}
+	r.closed = true
+
+	payloadSize := r.size
+	if r.compressWriter != nil {
+		err := r.compressWriter.Close()
+		if err != nil {
+			return fmt.Errorf("failed to compress streaming record in size estimator failed with %w", err)
+		}
+		payloadSize = uint64(r.payload.size)
+	}
+
+	header := fillStreamingRecordHeaderV3(r.estimator.recordHeaderCache, r.compressWriter != nil)
+	r.estimator.currentOffset += uint64(len(header)) + payloadSize
+	return nil
+}
+
+func (r *estimatedRecordWriter) Offset() uint64 {
+	return r.offset
+}
+
+func (e *SizeEstimator) Seek(offset uint64) error {
+	if offset < e.headerSize {
+		return fmt.Errorf("can't seek into the header range, supplied: %d header: %d", offset, e.headerSize)
+	}
+	if offset > e.Size() {
+		return fmt.Errorf("can't seek past file size, supplied: %d header: %d", offset, e.Size())
+	}
+
+	e.currentOffset = offset
+	return nil
+}
+
+func (e *SizeEstimator) Size() uint64 {
+	return e.currentOffset
+}
+
+func (e *SizeEstimator) Close() error {
+	e.closed = true
+	e.open = false
+	return nil
+}
+
+// NewSizeEstimator creates a new WriterI that only accounts for the bytes that would be written with the given
+// compression type, the types are all prefixed with CompressionType*.
+func NewSizeEstimator(compressionType int) (WriterI, error) {
+	return NewSizeEstimatorWithCompressionLevel(compressionType, DefaultCompressionLevel)
+}
+
+// NewSizeEstimatorWithCompressionLevel is like NewSizeEstimator, but compresses with the given level, see
+// CompressionLevel.
+func NewSizeEstimatorWithCompressionLevel(compressionType int, compressionLevel int) (WriterI, error) {
+	if _, err := newCompressorWithLevel(compressionType, compressionLevel); err != nil {
+		return nil, err
+	}
+
+	return &SizeEstimator{compressionType: compressionType, compressionLevel: compressionLevel}, nil
+}
+
+// NewSizeEstimatorWithOptions is like NewSizeEstimator, but takes the compression related options of a FileWriter:
+// CompressionType, CompressionLevel, MinCompressSizeBytes and SchemaID. All other options are ignored.
+func NewSizeEstimatorWithOptions(writerOptions ...FileWriterOption) (WriterI, error) {
+	opts := &FileWriterOptions{compressionType: CompressionTypeNone, compressionLevel: DefaultCompressionLevel}
+	for _, writeOption := range writerOptions {
+		writeOption(opts)
+	}
+
+	if opts.minCompressSize < 0 {
+		return nil, fmt.Errorf("NewSizeEstimatorWithOptions: unexpected min compress size, was: %d", opts.minCompressSize)
+	}
+
+	if _, err := newCompressorWithLevel(opts.compressionType, opts.compressionLevel); err != nil {
+		return nil, err
+	}
+
+	headerSize := uint64(len(fileHeaderAsByteSlice(uint32(opts.compressionType),
+		fileHeaderFlags(opts.compressionType, opts.minCompressSize, opts.hasSchemaID))))
+	if opts.hasSchemaID {
+		headerSize = FileHeaderWithSchemaIDSizeBytes
+	}
+
+	return &SizeEstimator{
+		compressionType:      opts.compressionType,
+		compressionLevel:     opts.compressionLevel,
+		minCompressSizeBytes: opts.minCompressSize,
+		headerSize:           headerSize,
+	}, nil
+}
@@ -0,0 +1,93 @@
+package recordio
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSizeEstimatorMatchesFileWriter(t *testing.T) {
+	for _, compType := range []int{CompressionTypeNone, CompressionTypeSnappy, CompressionTypeLzw} {
+		writer, err := newCompressedTestWriter(compType)
+		require.NoError(t, err)
+		require.NoError(t, writer.Open())
+		estimator, err := NewSizeEstimator(compType)
+		require.NoError(t, err)
+		require.NoError(t, estimator.Open())
+
+		records := [][]byte{ascendingBytes(13), nil, {}, randomRecordOfSize(1024), ascendingBytes(4096)}
+		for _, record := range records {
+			expectedOffset, err := writer.Write(record)
+			require.NoError(t, err)
+			offset, err := estimator.Write(record)
+			require.NoError(t, err)
+			assert.Equal(t, expectedOffset, offset)
+		}
+
+		require.NoError(t, writer.Close())
+		require.NoError(t, estimator.Close())
+		stat, err := os.Stat(writer.file.Name())
+		require.NoError(t, err)
+		assert.Equal(t, uint64(stat.Size()), estimator.Size())
+		require.NoError(t, os.Remove(writer.file.Name()))
+	}
+}
+
+func TestSizeEstimatorWithOptionsMatchesFileWriter(t *testing.T) {
+	for _, opts := range [][]FileWriterOption{
+		{CompressionType(CompressionTypeSnappy), MinCompressSizeBytes(64)},
+		{CompressionType(CompressionTypeSnappy), MinCompressSizeBytes(64), SchemaID(7)},
+	} {
+		tmpFile, err := os.CreateTemp("", "recordio_MinCompressSizeWriter")
+		require.NoError(t, err)
+		w, err := NewFileWriter(append(opts, File(tmpFile))...)
+		require.NoError(t, err)
+		writer := w.(*FileWriter)
+		require.NoError(t, writer.Open())
+		estimator, err := NewSizeEstimatorWithOptions(opts...)
+		require.NoError(t, err)
+		require.NoError(t, estimator.Open())
+
+		records := [][]byte{ascendingBytes(13), nil, {}, randomRecordOfSize(1024), ascendingBytes(63), ascendingBytes(4096)}
+		for _, record := range records {
+			expectedOffset, err := writer.Write(record)
+			require.NoError(t, err)
+			offset, err := estimator.Write(record)
+			require.NoError(t, err)
+			assert.Equal(t, expectedOffset, offset)
+		}
+
+		require.NoError(t, writer.Close())
+		require.NoError(t, estimator.Close())
+		stat, err := os.Stat(tmpFile.Name())
+		require.NoError(t, err)
+		assert.Equal(t, uint64(stat.Size()), estimator.Size())
+		removeFileWriterFile(t, writer)
+	}
+}
+
+func TestSizeEstimatorLifecycle(t *testing.T) {
+	_, err := NewSizeEstimator(42)
+	assert.ErrorContains(t, err, "unsupported compression type 42")
+
+	estimator, err := NewSizeEstimator(CompressionTypeNone)
+	require.NoError(t, err)
+	_, err = estimator.Write([]byte{1})
+	assert.Error(t, err)
+
+	require.NoError(t, estimator.Open())
+	assert.Error(t, estimator.Open())
+	assert.Equal(t, uint64(FileHeaderSizeBytes), estimator.Size())
+	assert.Error(t, estimator.Seek(0))
+
+	_, err = estimator.Write([]byte{1})
+	require.NoError(t, err)
+	require.NoError(t, estimator.Seek(FileHeaderSizeBytes))
+	assert.Equal(t, uint64(FileHeaderSizeBytes), estimator.Size())
+
+	require.NoError(t, estimator.Close())
+	_, err = estimator.Write([]byte{1})
+	assert.Error(t, err)
+}
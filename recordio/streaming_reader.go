@@ -0,0 +1,98 @@
+package recordio
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/thomasjungblut/go-sstables/recordio/compressor"
+)
+
+// ReadNextAtStreaming returns a reader over the payload of the record at the given offset. Records are read directly
+// from the mapped file, compressed records are decompressed on the fly by the streaming compressor. Only records of
+// files older than v3 are read and decompressed as a whole. The reader must not be used after the MMapReader is closed.
+func (r *MMapReader) ReadNextAtStreaming(offset uint64) (io.Reader, error) {
+	if !r.open || r.closed {
+		return nil, fmt.Errorf("reader at '%s' was either not opened yet or is closed already", r.path)
+	}
+
+	if r.header.fileVersion < Version3 {
+		record, err := r.ReadNextAt(offset)
+		if err != nil {
+			return nil, err
+		}
+		return bytes.NewReader(record), nil
+	}
+
+	headerBufPooled := r.bufferPool.Get(RecordHeaderV3MaxSizeBytes)
+	defer r.bufferPool.Put(headerBufPooled)
+
+	numRead, err := r.mmapReader.ReadAt(headerBufPooled, int64(offset))
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			// see ReadNextAt, EOF is only returned when nothing could be read anymore
+			if numRead == 0 {
+				return nil, io.EOF
+			}
+		} else {
+			return nil, fmt.Errorf("ReadNextAtStreaming failed reading at offset %d in mmap reader for '%s': %w", offset, r.path, err)
+		}
+	}
+
+	headerByteReader := NewCountingByteReader(bufio.NewReader(bytes.NewReader(headerBufPooled[:numRead])))
+	payloadSizeUncompressed, payloadSizeCompressed, flags, err := readRecordHeaderV3(headerByteReader)
+	if err != nil {
+		return nil, fmt.Errorf("failed reading record header at offset %d in mmap reader for '%s': %w", offset, r.path, err)
+	}
+
+	if flags&recordFlagNil != 0 {
+		return bytes.NewReader(nil), nil
+	}
+
+	compressed := isCompressed(r.header, flags)
+	payloadSize := payloadSizeUncompressed
+	if compressed {
+		payloadSize = payloadSizeCompressed
+	}
+
+	payloadOffset := offset + headerByteReader.Count()
+	if payloadSize > r.Size()-payloadOffset {
+		return nil, fmt.Errorf("not enough bytes in the record found in mmap reader '%s', expected %d but were %d",
+			r.path, payloadSize, r.Size()-payloadOffset)
+	}
+
+	payload := io.NewSectionReader(r.mmapReader, int64(payloadOffset), int64(payloadSize))
+	if !compressed {
+		return payload, nil
+	}
+
+	streamingCompressor, ok := r.header.compressor.(compressor.StreamingCompressionI)
+	if !ok {
+		return nil, fmt.Errorf("compression type %d of mmap reader '%s' does not support streaming records", r.header.compressionType, r.path)
+	}
+	decompressed, err := streamingCompressor.NewDecompressReader(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress record at offset %d in mmap reader for '%s': %w", offset, r.path, err)
+	}
+	return &sizedReader{r: decompressed, remaining: payloadSizeUncompressed}, nil
+}
+
+// sizedReader verifies that a decompressed record has the uncompressed size of its header
+type sizedReader struct {
+	r         io.Reader
+	remaining uint64
+}
+
+func (s *sizedReader) Read(p []byte) (int, error) {
+	n, err := s.r.Read(p)
+	if uint64(n) > s.remaining {
+		return 0, fmt.Errorf("decompressed record is larger than its header: %w", io.ErrUnexpectedEOF)
+	}
+	s.remaining -= uint64(n)
+	if errors.Is(err, io.EOF) && s.remaining > 0 {
+		return n, fmt.Errorf("decompressed record is %d bytes shorter than its header: %w", s.remaining, io.ErrUnexpectedEOF)
+	}
+	return n, err
+}
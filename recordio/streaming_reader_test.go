@@ -0,0 +1,102 @@
+package recordio
+
+import (
+	"bytes"
+	"io"
+	"math/rand"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadNextAtStreaming(t *testing.T) {
+	for _, compType := range []int{CompressionTypeNone, CompressionTypeSnappy, CompressionTypeGZIP, CompressionTypeLzw} {
+		writer, err := newCompressedTestWriter(compType)
+		require.NoError(t, err)
+		require.NoError(t, writer.Open())
+
+		first, err := writer.Write(ascendingBytes(5))
+		require.NoError(t, err)
+		nilRecord, err := writer.Write(nil)
+		require.NoError(t, err)
+		rw, err := writer.WriteStreaming()
+		require.NoError(t, err)
+		for i := 0; i < 10; i++ {
+			_, err = rw.Write(ascendingBytes(100))
+			require.NoError(t, err)
+		}
+		require.NoError(t, rw.Close())
+		require.NoError(t, writer.Close())
+
+		reader := newOpenedTestMMapReader(t, writer.file.Name())
+		r, err := reader.ReadNextAtStreaming(first)
+		require.NoError(t, err)
+		record, err := io.ReadAll(r)
+		require.NoError(t, err)
+		assert.Equal(t, ascendingBytes(5), record)
+
+		r, err = reader.ReadNextAtStreaming(nilRecord)
+		require.NoError(t, err)
+		record, err = io.ReadAll(r)
+		require.NoError(t, err)
+		assert.Empty(t, record)
+
+		r, err = reader.ReadNextAtStreaming(rw.Offset())
+		require.NoError(t, err)
+		buf := make([]byte, 100)
+		for i := 0; i < 10; i++ {
+			_, err = io.ReadFull(r, buf)
+			require.NoError(t, err)
+			assert.Equal(t, ascendingBytes(100), buf)
+		}
+		_, err = r.Read(buf)
+		assert.ErrorIs(t, err, io.EOF)
+
+		_, err = reader.ReadNextAtStreaming(reader.Size())
+		assert.ErrorIs(t, err, io.EOF)
+
+		closeMMapReader(t, reader)
+		_, err = reader.ReadNextAtStreaming(first)
+		assert.ErrorContains(t, err, "either not opened yet or is closed already")
+		removeFileWriterFile(t, writer)
+	}
+}
+
+func TestReadNextAtStreamingDecompressesOnTheFly(t *testing.T) {
+	for _, compType := range []int{CompressionTypeSnappy, CompressionTypeGZIP} {
+		writer, err := newCompressedTestWriter(compType)
+		require.NoError(t, err)
+		require.NoError(t, writer.Open())
+		record := make([]byte, 1024*1024)
+		_, err = rand.New(rand.NewSource(42)).Read(record)
+		require.NoError(t, err)
+		offset, err := writer.Write(record)
+		require.NoError(t, err)
+		require.NoError(t, writer.Close())
+
+		// corrupt the end of the payload, which must not be read before the start of the record is consumed
+		stat, err := os.Stat(writer.file.Name())
+		require.NoError(t, err)
+		f, err := os.OpenFile(writer.file.Name(), os.O_RDWR, 0)
+		require.NoError(t, err)
+		_, err = f.WriteAt(make([]byte, 1024), stat.Size()-2048)
+		require.NoError(t, err)
+		require.NoError(t, f.Close())
+
+		reader := newOpenedTestMMapReader(t, writer.file.Name())
+		r, err := reader.ReadNextAtStreaming(offset)
+		require.NoError(t, err)
+		buf := make([]byte, 64*1024)
+		_, err = io.ReadFull(r, buf)
+		require.NoError(t, err)
+		assert.Equal(t, record[:len(buf)], buf)
+		// recordio has no checksum of its own, the corruption shows in the data unless the compression detects it
+		rest, err := io.ReadAll(r)
+		assert.True(t, err != nil || !bytes.Equal(record[len(buf):], rest))
+
+		closeMMapReader(t, reader)
+		removeFileWriterFile(t, writer)
+	}
+}
@@ -0,0 +1,217 @@
+package recordio
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/thomasjungblut/go-sstables/recordio/compressor"
+)
+
+// fillStreamingRecordHeaderV3 creates a header for a record whose size is not known yet. The sizes are always encoded
+// with binary.MaxVarintLen64 bytes, so they can be overwritten in place once the record is complete. The compressed size
+// is only padded for compressed records, it's always zero otherwise.
+func fillStreamingRecordHeaderV3(bytes []byte, compressed bool) []byte {
+	off := binary.PutUvarint(bytes, MagicNumberSeparatorLong)
+	bytes[off] = 0
+	off += 1
+	off += putPaddedUvarint(bytes[off:], 0)
+	if compressed {
+		off += putPaddedUvarint(bytes[off:], 0)
+	} else {
+		off += binary.PutUvarint(bytes[off:], 0)
+	}
+
+	return bytes[:off]
+}
+
+// putPaddedUvarint encodes the value like binary.PutUvarint, but always uses binary.MaxVarintLen64 bytes by setting the
+// continuation bit on the leading bytes. binary.Uvarint and binary.ReadUvarint decode it to the same value.
+func putPaddedUvarint(buf []byte, x uint64) int {
+	for i := 0; i < binary.MaxVarintLen64-1; i++ {
+		buf[i] = byte(x) | 0x80
+		x >>= 7
+	}
+	buf[binary.MaxVarintLen64-1] = byte(x)
+	return binary.MaxVarintLen64
+}
+
+// WriteStreaming starts a new record at the current offset. The record is streamed directly to the file, compressed
+// records through the streaming compressor, the sizes in the header are written once the record is closed. Streaming
+// records can't be written with DirectIO and return DirectIOStreamingWriteErr.
+func (w *FileWriter) WriteStreaming() (RecordWriterI, error) {
+	if !w.open || w.closed {
+		return nil, errors.New("writer was either not opened yet or is closed already")
+	}
+
+	if w.activeRecord != nil {
+		return nil, fmt.Errorf("a streaming record is already being written in file at '%s'", w.file.Name())
+	}
+
+	if w.alignedBlockWrites {
+		return nil, DirectIOStreamingWriteErr
+	}
+
+	var streamingCompressor compressor.StreamingCompressionI
+	if w.compressor != nil {
+		var ok bool
+		streamingCompressor, ok = w.compressor.(compressor.StreamingCompressionI)
+		if !ok {
+			return nil, fmt.Errorf("compression type %d of file at '%s' does not support streaming records", w.compressionType, w.file.Name())
+		}
+	}
+
+	header := fillStreamingRecordHeaderV3(w.recordHeaderCache, streamingCompressor != nil)
+	written, err := w.bufWriter.Write(header)
+	if err != nil {
+		return nil, fmt.Errorf("failed to write streaming record header in file at '%s' failed with %w", w.file.Name(), err)
+	}
+
+	rw := &fileRecordWriter{writer: w, offset: w.currentOffset}
+	w.currentOffset += uint64(written)
+	w.activeRecord = rw
+	if streamingCompressor != nil {
+		rw.payload = &payloadWriter{w: w.bufWriter, start: int64(w.currentOffset)}
+		rw.compressWriter, err = streamingCompressor.NewCompressWriter(rw.payload)
+		if err != nil {
+			w.activeRecord = nil
+			return nil, fmt.Errorf("failed to start compressing streaming record in file at '%s' failed with %w", w.file.Name(), err)
+		}
+		w.currentOffset = rw.payloadEnd()
+	}
+	return rw, nil
+}
+
+type fileRecordWriter struct {
+	writer *FileWriter
+	offset uint64
+	// size is the uncompressed size of the record
+	size uint64
+	// compressWriter compresses into payload, both are nil for uncompressed records
+	compressWriter io.WriteCloser
+	payload        *payloadWriter
+	closed         bool
+}
+
+// payloadEnd returns the offset after the compressed payload written so far
+func (r *fileRecordWriter) payloadEnd() uint64 {
+	return uint64(r.payload.start + r.payload.size)
+}
+
+func (r *fileRecordWriter) Write(p []byte) (int, error) {
+	if r.closed || r.writer.activeRecord != r {
+		return 0, errors.New("streaming record was already closed")
+	}
+
+	if r.compressWriter != nil {
+		n, err := r.compressWriter.Write(p)
+		r.size += uint64(n)
+		r.writer.currentOffset = r.payloadEnd()
+		if err != nil {
+			return n, fmt.Errorf("failed to compress streaming record in file at '%s' failed with %w", r.writer.file.Name(), err)
+		}
+		return n, nil
+	}
+
+	n, err := r.writer.bufWriter.Write(p)
+	r.size += uint64(n)
+	r.writer.currentOffset += uint64(n)
+	if err != nil {
+		return n, fmt.Errorf("failed to write streaming record in file at '%s' failed with %w", r.writer.file.Name(), err)
+	}
+
+	return n, nil
+}
+
+// Close completes the record, it seeks back to update the sizes in the header.
+func (r *fileRecordWriter) Close() error {
+	if r.closed || r.writer.activeRecord != r {
+		return errors.New("streaming record was already closed")
+	}
+	r.closed = true
+	w := r.writer
+
+	if r.compressWriter != nil {
+		err := r.compressWriter.Close()
+		w.currentOffset = r.payloadEnd()
+		if err != nil {
+			// the incomplete record stays active, so it's discarded when the writer is closed
+			return fmt.Errorf("failed to compress streaming record in file at '%s' failed with %w", w.file.Name(), err)
+		}
+	}
+	w.activeRecord = nil
+
+	sizeOffset := r.offset + uint64(binary.PutUvarint(w.recordHeaderCache, MagicNumberSeparatorLong)) + 1
+	_, err := w.bufWriter.Seek(int64(sizeOffset), io.SeekStart)
+	if err != nil {
+		return fmt.Errorf("failed to seek to streaming record header in file at '%s' failed with %w", w.file.Name(), err)
+	}
+
+	n := putPaddedUvarint(w.recordHeaderCache, r.size)
+	if r.payload != nil {
+		n += putPaddedUvarint(w.recordHeaderCache[n:], uint64(r.payload.size))
+	}
+	_, err = w.bufWriter.Write(w.recordHeaderCache[:n])
+	if err != nil {
+		return fmt.Errorf("failed to write streaming record size in file at '%s' failed with %w", w.file.Name(), err)
+	}
+
+	_, err = w.bufWriter.Seek(int64(w.currentOffset), io.SeekStart)
+	if err != nil {
+		return fmt.Errorf("failed to seek past streaming record in file at '%s' failed with %w", w.file.Name(), err)
+	}
+
+	w.largestOffset = max(w.largestOffset, w.currentOffset)
+	w.trackFooterOffset(r.offset)
+	return nil
+}
+
+func (r *fileRecordWriter) Offset() uint64 {
+	return r.offset
+}
+
+// payloadWriter is the io.WriteSeeker a streaming compressor writes the payload of a record to. Its offsets are
+// relative to the start of the payload, a nil writer only counts the bytes, see SizeEstimator.
+type payloadWriter struct {
+	w      io.WriteSeeker
+	start  int64
+	offset int64
+	size   int64
+}
+
+func (p *payloadWriter) Write(b []byte) (int, error) {
+	n := len(b)
+	var err error
+	if p.w != nil {
+		n, err = p.w.Write(b)
+	}
+	p.offset += int64(n)
+	p.size = max(p.size, p.offset)
+	return n, err
+}
+
+func (p *payloadWriter) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += p.offset
+	case io.SeekEnd:
+		offset += p.size
+	default:
+		return 0, fmt.Errorf("invalid whence %d", whence)
+	}
+	if offset < 0 || offset > p.size {
+		return 0, fmt.Errorf("can't seek to %d outside of the record payload of %d bytes", offset, p.size)
+	}
+
+	// seeking flushes the buffered writer, so it's avoided when the position doesn't change
+	if p.w != nil && offset != p.offset {
+		_, err := p.w.Seek(p.start+offset, io.SeekStart)
+		if err != nil {
+			return 0, err
+		}
+	}
+	p.offset = offset
+	return offset, nil
+}
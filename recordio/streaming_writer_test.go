@@ -0,0 +1,151 @@
+package recordio
+
+import (
+	"encoding/binary"
+	"math/rand"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPaddedUvarintRoundTrip(t *testing.T) {
+	buf := make([]byte, binary.MaxVarintLen64)
+	for _, x := range []uint64{0, 1, 127, 128, 1 << 32, 1<<63 - 1, 1 << 63} {
+		assert.Equal(t, binary.MaxVarintLen64, putPaddedUvarint(buf, x))
+		v, n := binary.Uvarint(buf)
+		assert.Equal(t, x, v)
+		assert.Equal(t, binary.MaxVarintLen64, n)
+	}
+}
+
+func TestWriteStreamingRoundTrip(t *testing.T) {
+	for _, compType := range []int{CompressionTypeNone, CompressionTypeSnappy, CompressionTypeGZIP, CompressionTypeLzw} {
+		writer, err := newCompressedTestWriter(compType)
+		require.NoError(t, err)
+		require.NoError(t, writer.Open())
+		estimator, err := NewSizeEstimator(compType)
+		require.NoError(t, err)
+		require.NoError(t, estimator.Open())
+
+		var offsets []uint64
+		for _, w := range []StreamingWriterI{writer, estimator.(*SizeEstimator)} {
+			first, err := w.(WriterI).Write(ascendingBytes(5))
+			require.NoError(t, err)
+			offsets = append(offsets, first)
+
+			rw, err := w.WriteStreaming()
+			require.NoError(t, err)
+			for i := 0; i < 10; i++ {
+				n, err := rw.Write(ascendingBytes(100))
+				require.NoError(t, err)
+				assert.Equal(t, 100, n)
+			}
+			require.NoError(t, rw.Close())
+			assert.Error(t, rw.Close())
+			offsets = append(offsets, rw.Offset())
+
+			empty, err := w.WriteStreaming()
+			require.NoError(t, err)
+			require.NoError(t, empty.Close())
+			offsets = append(offsets, empty.Offset())
+
+			last, err := w.(WriterI).Write(ascendingBytes(7))
+			require.NoError(t, err)
+			offsets = append(offsets, last)
+		}
+		assert.Equal(t, offsets[:4], offsets[4:])
+		require.NoError(t, writer.Close())
+		assert.Equal(t, writer.Size(), estimator.Size())
+
+		reader := newReaderOnTopOfWriter(t, writer)
+		readNextExpectAscendingBytesOfLen(t, reader, 5)
+		streamed, err := reader.ReadNext()
+		require.NoError(t, err)
+		require.Equal(t, 1000, len(streamed))
+		for i := 0; i < 10; i++ {
+			assert.Equal(t, ascendingBytes(100), streamed[i*100:(i+1)*100])
+		}
+		empty, err := reader.ReadNext()
+		require.NoError(t, err)
+		assert.NotNil(t, empty)
+		assert.Empty(t, empty)
+		readNextExpectAscendingBytesOfLen(t, reader, 7)
+		readNextExpectEOF(t, reader)
+		closeFileReader(t, reader)
+
+		mmapReader := newOpenedTestMMapReader(t, writer.file.Name())
+		streamed, err = mmapReader.ReadNextAt(offsets[1])
+		require.NoError(t, err)
+		assert.Equal(t, 1000, len(streamed))
+		closeMMapReader(t, mmapReader)
+		removeFileWriterFile(t, writer)
+	}
+}
+
+func TestWriteStreamingCompressedIsNotBuffered(t *testing.T) {
+	writer, err := newCompressedTestWriter(CompressionTypeSnappy)
+	require.NoError(t, err)
+	require.NoError(t, writer.Open())
+	defer removeFileWriterFile(t, writer)
+
+	rw, err := writer.WriteStreaming()
+	require.NoError(t, err)
+	chunk := make([]byte, 1024*1024)
+	for i := 0; i < 8; i++ {
+		_, err = rand.New(rand.NewSource(int64(i))).Read(chunk)
+		require.NoError(t, err)
+		_, err = rw.Write(chunk)
+		require.NoError(t, err)
+
+		// random bytes don't compress, so everything but the last block and the write buffer is on disk already
+		stat, err := os.Stat(writer.file.Name())
+		require.NoError(t, err)
+		assert.Greater(t, stat.Size(), int64((i+1)*len(chunk)-2*65536))
+	}
+	require.NoError(t, rw.Close())
+	require.NoError(t, writer.Close())
+
+	reader := newReaderOnTopOfWriter(t, writer)
+	defer closeFileReader(t, reader)
+	record, err := reader.ReadNext()
+	require.NoError(t, err)
+	assert.Equal(t, 8*len(chunk), len(record))
+	assert.Equal(t, chunk, record[7*len(chunk):])
+}
+
+func TestWriteStreamingExclusive(t *testing.T) {
+	writer := newOpenedWriter(t)
+	defer removeFileWriterFile(t, writer)
+
+	_, err := writer.Write(ascendingBytes(5))
+	require.NoError(t, err)
+	sizeBefore := writer.Size()
+
+	rw, err := writer.WriteStreaming()
+	require.NoError(t, err)
+	_, err = rw.Write(ascendingBytes(50))
+	require.NoError(t, err)
+
+	_, err = writer.WriteStreaming()
+	assert.ErrorContains(t, err, "a streaming record is already being written")
+	_, err = writer.Write(ascendingBytes(5))
+	assert.ErrorContains(t, err, "can't write while a streaming record is being written")
+	assert.ErrorContains(t, writer.Seek(FileHeaderSizeBytes), "can't seek while a streaming record is being written")
+
+	// closing the writer discards the incomplete record
+	require.NoError(t, writer.Close())
+	_, err = rw.Write(ascendingBytes(5))
+	assert.Error(t, err)
+	assert.Error(t, rw.Close())
+
+	stat, err := os.Stat(writer.file.Name())
+	require.NoError(t, err)
+	assert.Equal(t, int64(sizeBefore), stat.Size())
+
+	reader := newReaderOnTopOfWriter(t, writer)
+	defer closeFileReader(t, reader)
+	readNextExpectAscendingBytesOfLen(t, reader, 5)
+	readNextExpectEOF(t, reader)
+}
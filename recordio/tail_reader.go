@@ -0,0 +1,90 @@
+package recordio
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// seekToTail positions the reader at the start of the last n records. The record headers are skipped from the last
+// offsets of the footer index on, without reading any payloads, earlier offsets are only used when there are fewer
+// than n records after them. Files without a footer index are skipped through from the start.
+func (r *FileReader) seekToTail(n int) error {
+	checkpoints := []uint64{r.header.size()}
+	// every offset in the index is followed by about interval records
+	back := 1
+	if r.footer != nil {
+		checkpoints = append(checkpoints, r.footer.offsets...)
+		back = n/int(r.footer.interval) + 1
+	}
+
+	for {
+		i := max(0, len(checkpoints)-back)
+		offsets, err := r.lastRecordOffsets(checkpoints[i], n)
+		if err != nil {
+			return err
+		}
+
+		// the spacing of the offsets is irregular after the writer seeked back, so the number of records is verified
+		if len(offsets) == n || i == 0 {
+			if len(offsets) == 0 {
+				return r.seekTo(checkpoints[i])
+			}
+			return r.seekTo(offsets[0])
+		}
+		back *= 2
+	}
+}
+
+// lastRecordOffsets returns the offsets of up to the last n records that start at or after the given record offset
+func (r *FileReader) lastRecordOffsets(from uint64, n int) ([]uint64, error) {
+	err := r.seekTo(from)
+	if err != nil {
+		return nil, err
+	}
+
+	var offsets []uint64
+	for {
+		offset := r.currentOffset
+		err := r.SkipNext()
+		if errors.Is(err, io.EOF) {
+			return offsets, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		offsets = append(offsets, offset)
+		if len(offsets) > n {
+			offsets = offsets[1:]
+		}
+	}
+}
+
+// seekTo continues reading at the record that starts at the given offset
+func (r *FileReader) seekTo(offset uint64) error {
+	r.stopReadAhead()
+	_, err := r.file.Seek(int64(offset), io.SeekStart)
+	if err != nil {
+		return fmt.Errorf("error while seeking to offset %d in '%s': %w", offset, r.file.Name(), err)
+	}
+
+	r.resetReader()
+	r.currentOffset = offset
+	return nil
+}
+
+// ReaderTail positions the reader at the last n records on Open, so ReadNext only returns those. It jumps close to the
+// end through the index of files written with FooterIndex, other files are skipped through from the start by only
+// reading the record headers. Files with fewer than n records are read from their first record.
+func ReaderTail(n int) FileReaderOption {
+	return func(args *FileReaderOptions) {
+		args.tail = n
+	}
+}
+
+// NewTailReader creates a new recordio file reader that returns the last n records of the file at the given path once
+// it's opened, see ReaderTail.
+func NewTailReader(path string, n int) (ReaderI, error) {
+	return NewFileReader(ReaderPath(path), ReaderTail(n))
+}
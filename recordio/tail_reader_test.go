@@ -0,0 +1,221 @@
+package recordio
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// tailTestRecord returns a distinct record for every i, every 13th record is nil
+func tailTestRecord(i int) []byte {
+	if i%13 == 12 {
+		return nil
+	}
+	return []byte(fmt.Sprintf("record-%d", i))
+}
+
+// writeTailTestFile writes n records and returns the offset the records end at
+func writeTailTestFile(t *testing.T, path string, n int, opts ...FileWriterOption) uint64 {
+	w, err := NewFileWriter(append([]FileWriterOption{Path(path)}, opts...)...)
+	require.NoError(t, err)
+	require.NoError(t, w.Open())
+	for i := 0; i < n; i++ {
+		_, err := w.Write(tailTestRecord(i))
+		require.NoError(t, err)
+	}
+	recordsEnd := w.Size()
+	require.NoError(t, w.Close())
+	return recordsEnd
+}
+
+// assertTail checks that the reader returns exactly the records from first to last (exclusive)
+func assertTail(t *testing.T, reader ReaderI, first int, last int) {
+	for i := first; i < last; i++ {
+		record, err := reader.ReadNext()
+		require.NoError(t, err, "record %d", i)
+		assert.Equal(t, tailTestRecord(i), record, "record %d", i)
+	}
+	_, err := reader.ReadNext()
+	assert.ErrorIs(t, err, io.EOF)
+}
+
+func readTail(t *testing.T, path string, n int) ReaderI {
+	reader, err := NewTailReader(path, n)
+	require.NoError(t, err)
+	require.NoError(t, reader.Open())
+	return reader
+}
+
+func TestFooterIndexTailReader(t *testing.T) {
+	for _, compType := range []int{CompressionTypeNone, CompressionTypeSnappy} {
+		for _, interval := range []int{1, 10, 1000} {
+			path := filepath.Join(t.TempDir(), "tail.rio")
+			recordsEnd := writeTailTestFile(t, path, 100, CompressionType(compType), FooterIndex(interval))
+
+			stat, err := os.Stat(path)
+			require.NoError(t, err)
+			numOffsets := (100 + interval - 1) / interval
+			assert.Equal(t, int64(recordsEnd)+int64(numOffsets*FooterEntrySizeBytes+FooterTrailerSizeBytes), stat.Size())
+
+			for _, n := range []int{1, 5, 10, 11, 99, 100, 150} {
+				reader := readTail(t, path, n)
+				assertTail(t, reader, max(0, 100-n), 100)
+				require.NoError(t, reader.Close())
+			}
+
+			// the other readers stop at the footer as well
+			reader, err := NewFileReaderWithPath(path)
+			require.NoError(t, err)
+			require.NoError(t, reader.Open())
+			assertTail(t, reader, 0, 100)
+			require.NoError(t, reader.(SkipI).Skip(0))
+			assert.ErrorIs(t, reader.SkipNext(), io.EOF)
+			require.NoError(t, reader.Close())
+
+			mmapReader, err := NewMemoryMappedReaderWithPath(path)
+			require.NoError(t, err)
+			require.NoError(t, mmapReader.Open())
+			assert.Equal(t, recordsEnd, mmapReader.Size())
+			_, err = mmapReader.(ValidatingReadAtI).ReadRecordAt(recordsEnd)
+			assert.ErrorIs(t, err, io.EOF)
+			_, err = mmapReader.ReadNextAt(recordsEnd)
+			assert.ErrorIs(t, err, io.EOF)
+			require.NoError(t, mmapReader.Close())
+		}
+	}
+}
+
+func TestTailReaderWithoutFooterIndex(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tail.rio")
+	writeTailTestFile(t, path, 50)
+
+	for _, n := range []int{1, 7, 50, 51} {
+		reader := readTail(t, path, n)
+		assertTail(t, reader, max(0, 50-n), 50)
+		require.NoError(t, reader.Close())
+	}
+
+	empty := filepath.Join(t.TempDir(), "empty.rio")
+	writeTailTestFile(t, empty, 0, FooterIndex(10))
+	reader := readTail(t, empty, 5)
+	assertTail(t, reader, 0, 0)
+	require.NoError(t, reader.Close())
+
+	_, err := NewTailReader(path, -1)
+	assert.Error(t, err)
+}
+
+func TestFooterIndexMissingAfterCrash(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tail.rio")
+	recordsEnd := writeTailTestFile(t, path, 40, FooterIndex(8))
+	// simulate a writer that was never closed
+	require.NoError(t, os.Truncate(path, int64(recordsEnd)))
+
+	reader := readTail(t, path, 3)
+	assertTail(t, reader, 37, 40)
+	require.NoError(t, reader.Close())
+
+	// appending rebuilds the index
+	w, err := NewFileWriter(Path(path), FooterIndex(8), Append())
+	require.NoError(t, err)
+	require.NoError(t, w.Open())
+	assert.Equal(t, recordsEnd, w.Size())
+	for i := 40; i < 45; i++ {
+		_, err := w.Write(tailTestRecord(i))
+		require.NoError(t, err)
+	}
+	require.NoError(t, w.Close())
+	footer := w.(*FileWriter).footerOffsets
+	assert.Equal(t, 6, len(footer))
+
+	reader = readTail(t, path, 12)
+	assertTail(t, reader, 33, 45)
+	require.NoError(t, reader.Close())
+
+	w, err = NewFileWriter(Path(path), Append())
+	require.NoError(t, err)
+	assert.ErrorContains(t, w.Open(), "footer index mismatch")
+}
+
+func TestFooterIndexCorrupt(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tail.rio")
+	recordsEnd := writeTailTestFile(t, path, 20, FooterIndex(5))
+
+	f, err := os.OpenFile(path, os.O_RDWR, 0)
+	require.NoError(t, err)
+	_, err = f.WriteAt([]byte{0xFF}, int64(recordsEnd)+1)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	reader, err := NewTailReader(path, 1)
+	require.NoError(t, err)
+	assert.ErrorIs(t, reader.Open(), FooterCorruptErr)
+
+	mmapReader, err := NewMemoryMappedReaderWithPath(path)
+	require.NoError(t, err)
+	assert.ErrorIs(t, mmapReader.Open(), FooterCorruptErr)
+}
+
+func TestFooterIndexAfterSeek(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tail.rio")
+	w, err := NewFileWriter(Path(path), FooterIndex(3))
+	require.NoError(t, err)
+	require.NoError(t, w.Open())
+	var offsets []uint64
+	for i := 0; i < 20; i++ {
+		offset, err := w.Write(tailTestRecord(i))
+		require.NoError(t, err)
+		offsets = append(offsets, offset)
+	}
+
+	// overwrite the records from 10 on with fewer records than before
+	require.NoError(t, w.Seek(offsets[10]))
+	for i := 10; i < 14; i++ {
+		_, err := w.Write(tailTestRecord(i))
+		require.NoError(t, err)
+	}
+	require.NoError(t, w.Close())
+
+	for _, n := range []int{1, 4, 5, 14, 20} {
+		reader := readTail(t, path, n)
+		assertTail(t, reader, max(0, 14-n), 14)
+		require.NoError(t, reader.Close())
+	}
+}
+
+func TestFooterIndexStreamingWrites(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tail.rio")
+	w, err := NewFileWriter(Path(path), FooterIndex(2))
+	require.NoError(t, err)
+	require.NoError(t, w.Open())
+	for i := 0; i < 6; i++ {
+		rw, err := w.(StreamingWriterI).WriteStreaming()
+		require.NoError(t, err)
+		_, err = rw.Write([]byte(fmt.Sprintf("record-%d", i)))
+		require.NoError(t, err)
+		require.NoError(t, rw.Close())
+	}
+	// an incomplete streaming record is discarded on Close
+	_, err = w.(StreamingWriterI).WriteStreaming()
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	assert.Equal(t, 3, len(w.(*FileWriter).footerOffsets))
+
+	reader := readTail(t, path, 3)
+	assertTail(t, reader, 3, 6)
+	require.NoError(t, reader.Close())
+}
+
+func TestFooterIndexInvalidOptions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tail.rio")
+	_, err := NewFileWriter(Path(path), FooterIndex(-1))
+	assert.ErrorContains(t, err, "unexpected footer index interval")
+
+	_, err = NewFileWriter(Path(path), FooterIndex(10), DirectIO())
+	assert.ErrorContains(t, err, "FooterIndex is not supported with DirectIO")
+}
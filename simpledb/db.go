@@ -211,7 +211,7 @@ func (db *DB) GetBytes(keyBytes []byte) ([]byte, error) {
 	var sstableNotFound bool
 	ssTableVal, err := db.sstableManager.currentSSTable().Get(keyBytes)
 	if err != nil {
-		if errors.Is(err, sstables.NotFound) {
+		if errors.Is(err, sstables.ErrKeyNotFound) {
 			sstableNotFound = true
 		} else {
 			return nil, err
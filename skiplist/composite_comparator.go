@@ -0,0 +1,77 @@
+package skiplist
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+)
+
+// FieldSpec describes a field of a composite key as a byte range that is compared lexicographically.
+type FieldSpec struct {
+	// Offset is the position of the first byte of the field in the key.
+	Offset int
+	// Length is the fixed width of the field in bytes, zero extends the field to the end of the key.
+	Length int
+	// Descending reverses the order of the field.
+	Descending bool
+}
+
+// CompositeComparator compares keys field by field in the order of its FieldSpecs, for example to sort keys made of a
+// prefix and a timestamp by the timestamp within each prefix. Keys whose fields are all equal are compared as a whole
+// with bytes.Compare, so the order stays total and different keys are never considered equal.
+// Keys that are too short for a field are compared by the bytes they have, use Validate to reject such keys up front,
+// for example with sstables.WithWriteValidator.
+type CompositeComparator struct {
+	fields    []FieldSpec
+	minKeyLen int
+}
+
+// NewCompositeComparator creates a comparator for the given fields, it returns an error when no fields are given or
+// when an offset or a length is negative.
+func NewCompositeComparator(fields []FieldSpec) (CompositeComparator, error) {
+	if len(fields) == 0 {
+		return CompositeComparator{}, errors.New("composite comparator needs at least one field")
+	}
+
+	minKeyLen := 0
+	for i, f := range fields {
+		if f.Offset < 0 || f.Length < 0 {
+			return CompositeComparator{}, fmt.Errorf("field %d of composite comparator has a negative offset or length: %d/%d", i, f.Offset, f.Length)
+		}
+		minKeyLen = max(minKeyLen, f.Offset+f.Length)
+	}
+
+	return CompositeComparator{fields: append([]FieldSpec{}, fields...), minKeyLen: minKeyLen}, nil
+}
+
+func (c CompositeComparator) Compare(a []byte, b []byte) int {
+	for _, f := range c.fields {
+		cmp := bytes.Compare(field(a, f), field(b, f))
+		if cmp != 0 {
+			if f.Descending {
+				return -cmp
+			}
+			return cmp
+		}
+	}
+	return bytes.Compare(a, b)
+}
+
+// Validate returns an error when the key is too short to contain all fixed-width fields.
+func (c CompositeComparator) Validate(key []byte) error {
+	if len(key) < c.minKeyLen {
+		return fmt.Errorf("key of length %d is too short for the composite comparator fields, which need %d bytes", len(key), c.minKeyLen)
+	}
+	return nil
+}
+
+// field returns the bytes of the field in the key, truncated when the key is too short
+func field(key []byte, f FieldSpec) []byte {
+	if f.Offset >= len(key) {
+		return nil
+	}
+	if f.Length == 0 || f.Offset+f.Length > len(key) {
+		return key[f.Offset:]
+	}
+	return key[f.Offset : f.Offset+f.Length]
+}
@@ -0,0 +1,94 @@
+package skiplist
+
+import (
+	"encoding/binary"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// compositeKey is a two byte prefix followed by a big endian timestamp
+func compositeKey(prefix string, ts uint32) []byte {
+	return binary.BigEndian.AppendUint32([]byte(prefix), ts)
+}
+
+func TestCompositeComparatorSortsByTimestampWithinPrefix(t *testing.T) {
+	cmp, err := NewCompositeComparator([]FieldSpec{
+		{Offset: 0, Length: 2},
+		{Offset: 2, Length: 4, Descending: true},
+	})
+	require.NoError(t, err)
+
+	list := NewSkipListMap[[]byte, int](cmp)
+	list.Insert(compositeKey("bb", 1), 0)
+	list.Insert(compositeKey("aa", 1), 0)
+	list.Insert(compositeKey("aa", 3), 0)
+	list.Insert(compositeKey("bb", 2), 0)
+	list.Insert(compositeKey("aa", 2), 0)
+
+	expected := [][]byte{
+		compositeKey("aa", 3),
+		compositeKey("aa", 2),
+		compositeKey("aa", 1),
+		compositeKey("bb", 2),
+		compositeKey("bb", 1),
+	}
+	it, err := list.Iterator()
+	require.NoError(t, err)
+	for _, e := range expected {
+		k, _, err := it.Next()
+		require.NoError(t, err)
+		assert.Equal(t, e, k)
+	}
+	_, _, err = it.Next()
+	assert.True(t, errors.Is(err, Done))
+}
+
+func TestCompositeComparatorFieldOrder(t *testing.T) {
+	// the timestamp is compared before the prefix
+	cmp, err := NewCompositeComparator([]FieldSpec{
+		{Offset: 2, Length: 4},
+		{Offset: 0, Length: 2},
+	})
+	require.NoError(t, err)
+
+	assert.Less(t, cmp.Compare(compositeKey("bb", 1), compositeKey("aa", 2)), 0)
+	assert.Less(t, cmp.Compare(compositeKey("aa", 1), compositeKey("bb", 1)), 0)
+	assert.Equal(t, 0, cmp.Compare(compositeKey("aa", 1), compositeKey("aa", 1)))
+}
+
+func TestCompositeComparatorTailField(t *testing.T) {
+	cmp, err := NewCompositeComparator([]FieldSpec{{Offset: 1, Descending: true}})
+	require.NoError(t, err)
+
+	assert.Greater(t, cmp.Compare([]byte{0, 1}, []byte{9, 2}), 0)
+	assert.Greater(t, cmp.Compare([]byte{0, 1}, []byte{0, 1, 0}), 0)
+	// equal fields fall back to the whole key
+	assert.Less(t, cmp.Compare([]byte{0, 1}, []byte{9, 1}), 0)
+	assert.NoError(t, cmp.Validate([]byte{1}))
+}
+
+func TestCompositeComparatorShortKeys(t *testing.T) {
+	cmp, err := NewCompositeComparator([]FieldSpec{{Offset: 0, Length: 2}, {Offset: 2, Length: 4}})
+	require.NoError(t, err)
+
+	assert.NoError(t, cmp.Validate(compositeKey("aa", 1)))
+	assert.Error(t, cmp.Validate([]byte("aa")))
+	assert.Error(t, cmp.Validate(nil))
+
+	// short keys don't panic and sort before longer ones with the same bytes
+	assert.Less(t, cmp.Compare([]byte("a"), compositeKey("aa", 1)), 0)
+	assert.Less(t, cmp.Compare(nil, []byte("a")), 0)
+	assert.Equal(t, 0, cmp.Compare([]byte("aa"), []byte("aa")))
+}
+
+func TestCompositeComparatorInvalidFields(t *testing.T) {
+	_, err := NewCompositeComparator(nil)
+	assert.Error(t, err)
+	_, err = NewCompositeComparator([]FieldSpec{{Offset: -1, Length: 2}})
+	assert.Error(t, err)
+	_, err = NewCompositeComparator([]FieldSpec{{Offset: 0, Length: -2}})
+	assert.Error(t, err)
+}
@@ -44,6 +44,11 @@ func (BytesComparator) Compare(a []byte, b []byte) int {
 	return bytes.Compare(a, b)
 }
 
+// Name identifies the comparator, sstables record it to verify that a table is read with the comparator it was written with.
+func (BytesComparator) Name() string {
+	return "bytes"
+}
+
 type IteratorI[K any, V any] interface {
 	// Next returns the next key, value in sequence
 	// returns Done as the error when the iterator is exhausted
@@ -79,6 +84,7 @@ func (it *Iterator[K, V]) Next() (_ K, _ V, done error) {
 }
 
 type MapI[K any, V any] interface {
+	// Size returns the number of elements in constant time.
 	Size() int
 
 	// Insert key/value into the list.
@@ -105,6 +111,18 @@ type MapI[K any, V any] interface {
 	IteratorBetween(keyLower K, keyHigher K) (IteratorI[K, V], error)
 }
 
+// LenI is implemented by maps that return their number of elements like the len builtin, like Map.
+type LenI interface {
+	// Len is the same as Size, for symmetry with the len builtin.
+	Len() int
+}
+
+// DeleteI is implemented by maps that can remove entries, like Map.
+type DeleteI[K any, V any] interface {
+	// Delete removes the entry that compares equal to key and returns its value, removed is false when there was none.
+	Delete(key K) (value V, removed bool)
+}
+
 type NodeI[K any, V any] interface {
 	Next(height int) *Node[K, V]
 	SetNext(height int, node *Node[K, V])
@@ -135,9 +153,15 @@ func newDefaultSkipListNode[K any, V any](maxHeight int) *Node[K, V] {
 	return &Node[K, V]{next: nextNodes}
 }
 
+// Map is not safe for concurrent use, readers need to be synchronized with writers by the caller, for example with a
+// sync.RWMutex. Iterators don't need to be synchronized with mutations from the same goroutine: a deleted node keeps
+// pointing to its successors, so an iterator that already points at it still returns it and then continues with the
+// entries that are in the list at that time. Entries that are deleted ahead of an iterator are skipped.
 type Map[K any, V any] struct {
 	maxHeight int
 	size      int
+	// rnd assigns the node heights, nil uses the randomly seeded global source of math/rand
+	rnd *rand.Rand
 
 	comp Comparator[K]
 	head *Node[K, V]
@@ -152,7 +176,7 @@ func (list *Map[K, V]) Insert(key K, value V) {
 		panic("duplicate key insertions are not allowed")
 	}
 
-	randomHeight := randomHeight(list.maxHeight)
+	randomHeight := randomHeight(list.rnd, list.maxHeight)
 	// do a re-balancing if we have reached new heights
 	if randomHeight > list.maxHeight {
 		for i := list.maxHeight; i < randomHeight; i++ {
@@ -170,10 +194,30 @@ func (list *Map[K, V]) Insert(key K, value V) {
 	list.size++
 }
 
+func (list *Map[K, V]) Delete(key K) (_ V, removed bool) {
+	prevTable := make([]*Node[K, V], list.maxHeight)
+	x := findGreaterOrEqual(list, key, prevTable)
+	if x == nil || list.comp.Compare(key, x.key) != 0 {
+		return
+	}
+
+	// the node is linked on every level up to its height, each right after the last smaller node of that level
+	for i := 0; i < len(x.next); i++ {
+		prevTable[i].SetNext(i, x.Next(i))
+	}
+
+	list.size--
+	return x.value, true
+}
+
 func (list *Map[K, V]) Size() int {
 	return list.size
 }
 
+func (list *Map[K, V]) Len() int {
+	return list.size
+}
+
 func (list *Map[K, V]) Contains(key K) bool {
 	_, err := list.Get(key)
 	if err == nil {
@@ -204,16 +248,92 @@ func (list *Map[K, V]) IteratorStartingAt(key K) (IteratorI[K, V], error) {
 }
 
 func (list *Map[K, V]) IteratorBetween(keyLower K, keyHigher K) (IteratorI[K, V], error) {
-	node := findGreaterOrEqual(list, keyLower, nil)
 	if list.comp.Compare(keyLower, keyHigher) > 0 {
 		return nil, errors.New("keyHigher is lower than keyLower")
 	}
+	node := findGreaterOrEqual(list, keyLower, nil)
 	return &Iterator[K, V]{node: node, comp: list.comp, keyHigher: &keyHigher}, nil
 }
 
-func NewSkipListMap[K any, V any](comp Comparator[K]) MapI[K, V] {
+type MapOptions struct {
+	rnd *rand.Rand
+}
+
+type MapOption func(*MapOptions)
+
+// WithRand assigns the node heights from the given source, which makes the structure of the map reproducible for the
+// same sequence of inserts. The source is used without locking, so it must not be shared with other maps or goroutines.
+func WithRand(rnd *rand.Rand) MapOption {
+	return func(opts *MapOptions) {
+		opts.rnd = rnd
+	}
+}
+
+// WithSeed is WithRand with a new source for the given seed.
+func WithSeed(seed int64) MapOption {
+	return func(opts *MapOptions) {
+		opts.rnd = rand.New(rand.NewSource(seed))
+	}
+}
+
+func NewSkipListMap[K any, V any](comp Comparator[K], mapOptions ...MapOption) MapI[K, V] {
+	opts := &MapOptions{}
+	for _, opt := range mapOptions {
+		opt(opts)
+	}
+
 	const maxHeight = 12
-	return &Map[K, V]{head: newDefaultSkipListNode[K, V](maxHeight), comp: comp, maxHeight: maxHeight}
+	return &Map[K, V]{head: newDefaultSkipListNode[K, V](maxHeight), comp: comp, maxHeight: maxHeight, rnd: opts.rnd}
+}
+
+// NewFromSorted builds a skip list from an iterator that yields strictly ascending keys. In contrast to inserting the
+// keys one by one, no insertion point needs to be searched: the nodes are appended to the end of every level, which
+// builds the list bottom-up in O(N). Every 4th node of a level is promoted to the level above.
+// An error is returned when the keys are not strictly ascending or when the iterator returns any error other than Done.
+func NewFromSorted[K any, V any](comp Comparator[K], it IteratorI[K, V]) (MapI[K, V], error) {
+	list := NewSkipListMap[K, V](comp).(*Map[K, V])
+	// tails contains the last node of every level, new nodes are appended after them
+	tails := make([]*Node[K, V], list.maxHeight)
+	for i := range tails {
+		tails[i] = list.head
+	}
+
+	var last *Node[K, V]
+	for {
+		key, value, err := it.Next()
+		if errors.Is(err, Done) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if last != nil && comp.Compare(last.key, key) >= 0 {
+			return nil, errors.New("keys are not in strictly ascending order")
+		}
+
+		list.size++
+		height := sortedHeight(list.size, list.maxHeight)
+		x := newSkipListNode(key, value, height)
+		for i := 0; i < height; i++ {
+			tails[i].SetNext(i, x)
+			tails[i] = x
+		}
+		last = x
+	}
+
+	return list, nil
+}
+
+// sortedHeight returns the height of the nth node (starting at one) when building a list from sorted input
+func sortedHeight(n int, maxHeight int) int {
+	const branchFactor = 4
+	height := 1
+	for height < maxHeight && n%branchFactor == 0 {
+		n /= branchFactor
+		height++
+	}
+	return height
 }
 
 func findGreaterOrEqual[K any, V any](list *Map[K, V], key K, prevTable []*Node[K, V]) *Node[K, V] {
@@ -240,10 +360,15 @@ func findGreaterOrEqual[K any, V any](list *Map[K, V], key K, prevTable []*Node[
 	}
 }
 
-func randomHeight(maxHeight int) int {
+func randomHeight(rnd *rand.Rand, maxHeight int) int {
 	const branchFactor = 4
+	intn := rand.Intn
+	if rnd != nil {
+		intn = rnd.Intn
+	}
+
 	height := 1
-	for height < maxHeight && ((rand.Int() % branchFactor) == 0) {
+	for height < maxHeight && intn(branchFactor) == 0 {
 		height++
 	}
 
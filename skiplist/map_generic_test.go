@@ -4,6 +4,7 @@ import (
 	"errors"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"math/rand"
 	"reflect"
 	"slices"
 	"sort"
@@ -78,6 +79,130 @@ func TestSkipListDoubleEqualInsert(t *testing.T) {
 	})
 }
 
+func TestSkipListLen(t *testing.T) {
+	list := NewSkipListMap[int, int](OrderedComparator[int]{})
+	lenList, ok := list.(LenI)
+	require.True(t, ok)
+	assert.Equal(t, 0, lenList.Len())
+	for i := 0; i < 100; i++ {
+		list.Insert(i, i)
+		assert.Equal(t, i+1, lenList.Len())
+		assert.Equal(t, list.Size(), lenList.Len())
+	}
+}
+
+func TestSkipListDelete(t *testing.T) {
+	list := NewSkipListMap[int, int](OrderedComparator[int]{})
+	batchInsertAndAssertContains(t, []int{79, 14, 91, 27, 62, 41, 58, 2, 20, 87, 34}, list)
+	deleter, ok := list.(DeleteI[int, int])
+	require.True(t, ok)
+
+	v, removed := deleter.Delete(41)
+	assert.True(t, removed)
+	assert.Equal(t, 42, v)
+	_, err := list.Get(41)
+	assert.Equal(t, NotFound, err)
+	assert.False(t, list.Contains(41))
+	assert.Equal(t, 10, list.Size())
+
+	_, removed = deleter.Delete(41)
+	assert.False(t, removed)
+	_, removed = deleter.Delete(100)
+	assert.False(t, removed)
+	assert.Equal(t, 10, list.Size())
+
+	// first and last element
+	_, removed = deleter.Delete(2)
+	assert.True(t, removed)
+	_, removed = deleter.Delete(91)
+	assert.True(t, removed)
+
+	it, err := list.Iterator()
+	require.NoError(t, err)
+	assertIteratorOutputs(t, []int{14, 20, 27, 34, 58, 62, 79, 87}, it)
+	it, err = list.IteratorBetween(20, 62)
+	require.NoError(t, err)
+	assertIteratorOutputs(t, []int{20, 27, 34, 58, 62}, it)
+
+	// the key can be inserted again after it was deleted
+	list.Insert(41, 42)
+	it, err = list.Iterator()
+	require.NoError(t, err)
+	assertIteratorOutputs(t, []int{14, 20, 27, 34, 41, 58, 62, 79, 87}, it)
+}
+
+func TestSkipListDeleteAll(t *testing.T) {
+	list := NewSkipListMap[int, int](OrderedComparator[int]{}).(*Map[int, int])
+	for i := 0; i < 1000; i++ {
+		list.Insert(i, i)
+	}
+	for i := 0; i < 1000; i += 2 {
+		_, removed := list.Delete(i)
+		require.True(t, removed)
+	}
+	assert.Equal(t, 500, list.Size())
+	for i := 0; i < 1000; i++ {
+		assert.Equal(t, i%2 == 1, list.Contains(i), "key %d", i)
+	}
+	for i := 1; i < 1000; i += 2 {
+		_, removed := list.Delete(i)
+		require.True(t, removed)
+	}
+	assert.Equal(t, 0, list.Size())
+
+	// no level must point to a deleted node anymore
+	head := list.head
+	for i := range head.next {
+		assert.Nil(t, head.Next(i))
+	}
+}
+
+func TestSkipListDeleteWhileIterating(t *testing.T) {
+	list := NewSkipListMap[int, int](OrderedComparator[int]{}).(*Map[int, int])
+	batchInsertAndAssertContains(t, []int{1, 2, 3, 4, 5}, list)
+
+	it, err := list.Iterator()
+	require.NoError(t, err)
+	k, _, err := it.Next()
+	require.NoError(t, err)
+	assert.Equal(t, 1, k)
+
+	// the iterator already points at 2, which is still returned before continuing with its successor
+	_, removed := list.Delete(2)
+	assert.True(t, removed)
+	// nodes that are deleted ahead of the iterator are skipped
+	_, removed = list.Delete(4)
+	assert.True(t, removed)
+
+	var keys []int
+	for {
+		k, _, err := it.Next()
+		if errors.Is(err, Done) {
+			break
+		}
+		require.NoError(t, err)
+		keys = append(keys, k)
+	}
+	assert.Equal(t, []int{2, 3, 5}, keys)
+}
+
+func TestSkipListWithSeed(t *testing.T) {
+	insert := func(list MapI[int, int]) []int {
+		for i := 0; i < 1000; i++ {
+			list.Insert((i*7919)%1000, i)
+		}
+		return nodeHeights(list.(*Map[int, int]))
+	}
+
+	heights := insert(NewSkipListMap[int, int](OrderedComparator[int]{}, WithSeed(42)))
+	assert.Equal(t, heights, insert(NewSkipListMap[int, int](OrderedComparator[int]{}, WithSeed(42))))
+	assert.Equal(t, heights, insert(NewSkipListMap[int, int](OrderedComparator[int]{}, WithRand(rand.New(rand.NewSource(42))))))
+	assert.NotEqual(t, heights, insert(NewSkipListMap[int, int](OrderedComparator[int]{}, WithSeed(43))))
+
+	list := NewSkipListMap[int, int](OrderedComparator[int]{}, WithSeed(42))
+	batchInsertAndAssertContains(t, []int{79, 14, 91, 27, 62, 41, 58, 2, 20, 87, 34}, list)
+}
+
 func TestSkipListEmptyIterator(t *testing.T) {
 	list := NewSkipListMap[int, int](OrderedComparator[int]{})
 
@@ -170,6 +295,34 @@ func TestSkipListBetweenIterator(t *testing.T) {
 	assert.Equal(t, Done, err)
 }
 
+func TestSkipListBetweenIteratorEmptyRanges(t *testing.T) {
+	list := NewSkipListMap[int, int](OrderedComparator[int]{})
+	empty, err := list.IteratorBetween(1, 5)
+	require.NoError(t, err)
+	_, _, err = empty.Next()
+	assert.Equal(t, Done, err)
+
+	batchInsertAndAssertContains(t, []int{2, 4, 10, 12}, list)
+	// ranges before the first key, within a hole and after the last key
+	for _, bounds := range [][2]int{{-5, 1}, {5, 9}, {13, 20}} {
+		it, err := list.IteratorBetween(bounds[0], bounds[1])
+		require.NoError(t, err)
+		assertIteratorOutputs(t, []int{}, it)
+	}
+
+	// an exhausted iterator keeps returning Done
+	it, err := list.IteratorBetween(3, 4)
+	require.NoError(t, err)
+	assertIteratorOutputs(t, []int{4}, it)
+	for i := 0; i < 3; i++ {
+		_, _, err = it.Next()
+		assert.Equal(t, Done, err)
+	}
+
+	_, err = list.IteratorBetween(5, 4)
+	assert.EqualError(t, err, "keyHigher is lower than keyLower")
+}
+
 func TestSkipListBetweenIteratorScanOverHoles(t *testing.T) {
 	list := NewSkipListMap[[]byte, []byte](BytesComparator{})
 	wholeSequence := [][]byte{{0}, {1}, {2}, {4}, {8}, {9}, {10}}
@@ -282,6 +435,98 @@ func TestSkipListRangeScanQuick(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestSkipListNewFromSorted(t *testing.T) {
+	for _, n := range []int{0, 1, 3, 4, 17, 1000} {
+		source := NewSkipListMap[int, int](OrderedComparator[int]{})
+		var expected []int
+		for i := 0; i < n; i++ {
+			source.Insert(i*2, i*2+1)
+			expected = append(expected, i*2)
+		}
+		sourceIt, err := source.Iterator()
+		require.NoError(t, err)
+
+		list, err := NewFromSorted[int, int](OrderedComparator[int]{}, sourceIt)
+		require.NoError(t, err)
+		assert.Equal(t, n, list.Size())
+		assertLevelsSorted(t, list.(*Map[int, int]))
+
+		for i := -1; i < n*2+1; i++ {
+			v, err := list.Get(i)
+			if i >= 0 && i < n*2 && i%2 == 0 {
+				require.NoError(t, err)
+				assert.Equal(t, i+1, v)
+			} else {
+				assert.Equal(t, NotFound, err)
+			}
+		}
+
+		it, err := list.Iterator()
+		require.NoError(t, err)
+		assertIteratorOutputs(t, expected, it)
+
+		if n > 3 {
+			it, err = list.IteratorBetween(1, 6)
+			require.NoError(t, err)
+			assertIteratorOutputs(t, []int{2, 4, 6}, it)
+		}
+
+		// the bulk loaded list can still be inserted into
+		list.Insert(-1, 0)
+		list.Insert(n*2+1, n*2+2)
+		assert.Equal(t, n+2, list.Size())
+		assertLevelsSorted(t, list.(*Map[int, int]))
+	}
+}
+
+func TestSkipListNewFromSortedErrors(t *testing.T) {
+	unordered := NewSkipListMap[int, int](OrderedComparator[int]{})
+	unordered.Insert(1, 2)
+	unordered.Insert(2, 3)
+	it, err := unordered.Iterator()
+	require.NoError(t, err)
+	// reversing the comparator makes the ascending source unordered
+	_, err = NewFromSorted[int, int](reverseComparator{}, it)
+	assert.ErrorContains(t, err, "keys are not in strictly ascending order")
+
+	expectedErr := errors.New("broken iterator")
+	_, err = NewFromSorted[int, int](OrderedComparator[int]{}, failingIterator{expectedErr})
+	assert.ErrorIs(t, err, expectedErr)
+}
+
+type reverseComparator struct{}
+
+func (reverseComparator) Compare(a int, b int) int {
+	return b - a
+}
+
+type failingIterator struct {
+	err error
+}
+
+func (it failingIterator) Next() (int, int, error) {
+	return 0, 0, it.err
+}
+
+// assertLevelsSorted checks that every level is sorted and only contains nodes that are part of the level below
+func assertLevelsSorted(t *testing.T, list *Map[int, int]) {
+	for level := 0; level < list.maxHeight; level++ {
+		count := 0
+		for x := list.head.Next(level); x != nil; x = x.Next(level) {
+			count++
+			if next := x.Next(level); next != nil {
+				require.Less(t, list.comp.Compare(x.key, next.key), 0)
+			}
+			if level > 0 {
+				require.True(t, list.Contains(x.key))
+			}
+		}
+		if level == 0 {
+			require.Equal(t, list.Size(), count)
+		}
+	}
+}
+
 func singleElementSkipList(t *testing.T) MapI[int, int] {
 	list := NewSkipListMap[int, int](OrderedComparator[int]{})
 	list.Insert(13, 91)
@@ -291,6 +536,15 @@ func singleElementSkipList(t *testing.T) MapI[int, int] {
 	return list
 }
 
+// nodeHeights returns the height of every node in key order
+func nodeHeights(list *Map[int, int]) []int {
+	var heights []int
+	for n := list.head.Next(0); n != nil; n = n.Next(0) {
+		heights = append(heights, len(n.next))
+	}
+	return heights
+}
+
 func assertIteratorOutputs(t *testing.T, expectedSeq []int, it IteratorI[int, int]) {
 	currentIndex := 0
 	for {
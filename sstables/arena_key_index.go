@@ -0,0 +1,157 @@
+package sstables
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+
+	rProto "github.com/thomasjungblut/go-sstables/recordio/proto"
+	"github.com/thomasjungblut/go-sstables/skiplist"
+	"github.com/thomasjungblut/go-sstables/sstables/proto"
+)
+
+// ArenaKeyIndex is keeping the entire index in memory like the SliceKeyIndex, but stores all keys in a single
+// contiguous arena. Compared to a slice per key, this avoids an allocation per key and the values don't contain any
+// pointers, which drastically reduces the time the garbage collector spends scanning very large indices.
+type ArenaKeyIndex struct {
+	NoOpOpenClose
+	arena []byte
+	// keyOffsets contains the start of every key in the arena, followed by the end of the last key
+	keyOffsets []uint64
+	values     []IndexVal
+}
+
+func (s *ArenaKeyIndex) key(i int) []byte {
+	start, end := s.keyOffsets[i], s.keyOffsets[i+1]
+	// the capacity is limited, so appending to a returned key can't overwrite the next one
+	return s.arena[start:end:end]
+}
+
+// search returns the index of the first key that is greater or equal to the given key, and whether it's equal
+func (s *ArenaKeyIndex) search(key []byte) (int, bool) {
+	n := len(s.values)
+	idx := sort.Search(n, func(i int) bool {
+		return bytes.Compare(s.key(i), key) >= 0
+	})
+	return idx, idx < n && bytes.Equal(s.key(idx), key)
+}
+
+func (s *ArenaKeyIndex) EntryAt(n uint64) ([]byte, IndexVal, error) {
+	if n >= uint64(len(s.values)) {
+		return nil, IndexVal{}, ErrOrdinalOutOfRange
+	}
+	return s.key(int(n)), s.values[n], nil
+}
+
+func (s *ArenaKeyIndex) Get(key []byte) (IndexVal, error) {
+	idx, found := s.search(key)
+	if found {
+		return s.values[idx], nil
+	}
+
+	return IndexVal{}, skiplist.NotFound
+}
+
+func (s *ArenaKeyIndex) Contains(key []byte) (bool, error) {
+	_, found := s.search(key)
+	return found, nil
+}
+
+func (s *ArenaKeyIndex) Iterator() (skiplist.IteratorI[[]byte, IndexVal], error) {
+	return &ArenaKeyIndexIterator{index: s, endIndexExcl: len(s.values)}, nil
+}
+
+func (s *ArenaKeyIndex) IteratorStartingAt(key []byte) (skiplist.IteratorI[[]byte, IndexVal], error) {
+	idx, _ := s.search(key)
+	return &ArenaKeyIndexIterator{index: s, currentIndex: idx, endIndexExcl: len(s.values)}, nil
+}
+
+func (s *ArenaKeyIndex) IteratorBetween(keyLower []byte, keyHigher []byte) (skiplist.IteratorI[[]byte, IndexVal], error) {
+	if bytes.Compare(keyLower, keyHigher) > 0 {
+		return nil, errors.New("keyHigher is lower than keyLower")
+	}
+
+	startIdx, _ := s.search(keyLower)
+	fx, _ := s.search(keyHigher)
+	// the range is the same as with the SliceKeyIndex
+	endIdx := min(fx+1, len(s.values))
+	return &ArenaKeyIndexIterator{index: s, currentIndex: startIdx, endIndexExcl: endIdx}, nil
+}
+
+type ArenaKeyIndexIterator struct {
+	index        *ArenaKeyIndex
+	endIndexExcl int
+	currentIndex int
+}
+
+func (s *ArenaKeyIndexIterator) Next() ([]byte, IndexVal, error) {
+	if s.currentIndex >= s.endIndexExcl {
+		return nil, IndexVal{}, skiplist.Done
+	}
+	i := s.currentIndex
+	s.currentIndex += 1
+	return s.index.key(i), s.index.values[i], nil
+}
+
+type ArenaKeyIndexLoader struct {
+	ReadBufferSize int
+}
+
+func (s *ArenaKeyIndexLoader) Load(indexPath string, metadata *proto.MetaData) (_ SortedKeyIndex, err error) {
+	reader, err := rProto.NewReader(
+		rProto.ReaderPath(indexPath),
+		rProto.ReadBufferSizeBytes(s.ReadBufferSize),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error while creating index reader of sstable in '%s': %w", indexPath, err)
+	}
+
+	err = reader.Open()
+	if err != nil {
+		return nil, fmt.Errorf("error while opening index reader of sstable in '%s': %w", indexPath, err)
+	}
+
+	defer func() {
+		err = errors.Join(err, reader.Close())
+	}()
+
+	numRecords, arenaCapacity := uint64(0), uint64(0)
+	if metadata != nil {
+		numRecords = metadata.NumRecords
+		// the keys are a part of the index file, which makes its size a good upper bound
+		arenaCapacity = metadata.IndexBytes
+	}
+
+	index := &ArenaKeyIndex{
+		arena:      make([]byte, 0, arenaCapacity),
+		keyOffsets: make([]uint64, 1, numRecords+1),
+		values:     make([]IndexVal, 0, numRecords),
+	}
+
+	record := &proto.IndexEntry{}
+	var key []byte
+	for {
+		_, err := reader.ReadNext(record)
+		// io.EOF signals that no records are left to be read
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("error while reading index records of sstable in '%s': %w", indexPath, err)
+		}
+
+		key, err = fullIndexKey(key, record)
+		if err != nil {
+			return nil, fmt.Errorf("error while reading index records of sstable in '%s': %w", indexPath, err)
+		}
+
+		index.arena = append(index.arena, key...)
+		index.keyOffsets = append(index.keyOffsets, uint64(len(index.arena)))
+		index.values = append(index.values, IndexVal{Offset: record.ValueOffset, Checksum: record.Checksum, Tombstoned: record.Tombstoned, NullValue: record.NullValue, SequenceNumber: record.SequenceNumber})
+	}
+
+	return index, nil
+}
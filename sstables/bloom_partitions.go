@@ -0,0 +1,122 @@
+package sstables
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/steakknife/bloomfilter"
+	rProto "github.com/thomasjungblut/go-sstables/recordio/proto"
+	"github.com/thomasjungblut/go-sstables/skiplist"
+	"github.com/thomasjungblut/go-sstables/sstables/proto"
+)
+
+// bloomPartition is the bloom filter over a range of consecutive keys, starting at firstKey
+type bloomPartition struct {
+	firstKey []byte
+	filter   *bloomfilter.Filter
+}
+
+// newBloomPartitionFilter creates the filter of a new partition, tests replace it to seed the hashes deterministically
+var newBloomPartitionFilter = bloomfilter.NewOptimal
+
+// addToBloomPartition adds the key to the last partition, a new partition is started every BloomPartitionEveryNthKey keys
+func (writer *SSTableStreamWriter) addToBloomPartition(key []byte) error {
+	if len(writer.bloomPartitions) == 0 ||
+		writer.bloomFilter.N() >= uint64(writer.opts.bloomPartitionEveryNthKey) {
+		bf, err := newBloomPartitionFilter(uint64(writer.opts.bloomPartitionEveryNthKey), writer.opts.bloomFpProbability)
+		if err != nil {
+			return fmt.Errorf("error while creating bloom partition in '%s': %w", writer.opts.basePath, err)
+		}
+		writer.bloomPartitions = append(writer.bloomPartitions, bloomPartition{firstKey: append([]byte{}, key...), filter: bf})
+		writer.bloomFilter = bf
+	}
+
+	fnvHash := fnv.New64()
+	_, _ = fnvHash.Write(key)
+	writer.bloomFilter.Add(fnvHash)
+	return nil
+}
+
+// writeBloomPartitions writes all partitions in key order into a single file at the given path
+func writeBloomPartitions(path string, partitions []bloomPartition) (err error) {
+	writer, err := rProto.NewWriter(rProto.Path(path))
+	if err != nil {
+		return err
+	}
+	err = writer.Open()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = errors.Join(err, writer.Close())
+	}()
+
+	for _, p := range partitions {
+		filter, err := p.filter.MarshalBinary()
+		if err != nil {
+			return err
+		}
+		_, err = writer.Write(&proto.BloomPartition{FirstKey: p.firstKey, Filter: filter})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func readBloomPartitionsIfExists(path string) (partitions []bloomPartition, err error) {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	reader, err := rProto.NewProtoReaderWithPath(path)
+	if err != nil {
+		return nil, fmt.Errorf("error while creating bloom partition reader in '%s': %w", path, err)
+	}
+
+	err = reader.Open()
+	if err != nil {
+		return nil, fmt.Errorf("error while opening bloom partitions in '%s': %w", path, err)
+	}
+
+	defer func() {
+		err = errors.Join(err, reader.Close())
+	}()
+
+	for {
+		entry := &proto.BloomPartition{}
+		_, err := reader.ReadNext(entry)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, fmt.Errorf("error while reading bloom partitions in '%s': %w", path, err)
+		}
+
+		filter := &bloomfilter.Filter{}
+		err = filter.UnmarshalBinary(entry.Filter)
+		if err != nil {
+			return nil, fmt.Errorf("error while parsing bloom partition in '%s': %w", path, err)
+		}
+		partitions = append(partitions, bloomPartition{firstKey: entry.FirstKey, filter: filter})
+	}
+
+	return partitions, nil
+}
+
+// findBloomPartition returns the filter of the last partition that starts at or before the key, nil when the key is
+// lower than the first key of the table
+func findBloomPartition(partitions []bloomPartition, key []byte, cmp skiplist.Comparator[[]byte]) *bloomfilter.Filter {
+	i := sort.Search(len(partitions), func(i int) bool {
+		return cmp.Compare(partitions[i].firstKey, key) > 0
+	})
+	if i == 0 {
+		return nil
+	}
+	return partitions[i-1].filter
+}
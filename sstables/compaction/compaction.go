@@ -0,0 +1,110 @@
+// Package compaction contains planners that decide which sstables should be compacted together.
+// The planners only operate on the metadata of each table, the mechanical merge is left to sstables.SSTableMerger.
+package compaction
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/thomasjungblut/go-sstables/skiplist"
+	"github.com/thomasjungblut/go-sstables/sstables"
+	"github.com/thomasjungblut/go-sstables/sstables/proto"
+)
+
+// Table describes a single sstable by its base path and its metadata, as returned by SSTableReaderI.MetaData().
+type Table struct {
+	Path     string
+	MetaData *proto.MetaData
+}
+
+// Job is a set of tables that should be merged into a single new table.
+// The tables are kept in the order they were supplied to the planner, which is important to resolve conflicts
+// with reduce functions like sstables.ScanReduceLatestWins.
+type Job struct {
+	Tables []Table
+}
+
+// Paths returns the base paths of all tables in this job.
+func (j Job) Paths() []string {
+	var paths []string
+	for _, t := range j.Tables {
+		paths = append(paths, t.Path)
+	}
+	return paths
+}
+
+// TotalBytes returns the sum of all TotalBytes of the tables in this job.
+func (j Job) TotalBytes() uint64 {
+	sum := uint64(0)
+	for _, t := range j.Tables {
+		sum += t.MetaData.TotalBytes
+	}
+	return sum
+}
+
+// NumRecords returns the sum of all records in this job, which is the upper bound of records in the merged table.
+func (j Job) NumRecords() uint64 {
+	sum := uint64(0)
+	for _, t := range j.Tables {
+		sum += t.MetaData.NumRecords
+	}
+	return sum
+}
+
+// Plan is the result of a planner, no table is ever part of more than one job.
+type Plan struct {
+	Jobs []Job
+}
+
+// openReader opens the tables of a job, tests replace it to inject failures
+var openReader = func(path string, cmp skiplist.Comparator[[]byte]) (sstables.SSTableReaderI, error) {
+	return sstables.NewSSTableReader(sstables.ReadBasePath(path), sstables.ReadWithKeyComparator(cmp))
+}
+
+// Execute runs the given job by scanning all of its tables and merging them into the already opened writer using
+// sstables.SSTableMerger. When reduce is nil, a plain Merge is executed, otherwise MergeCompact with the given function.
+// The caller needs to close the writer. Errors while closing the tables are returned as well.
+func Execute(job Job, writer sstables.SSTableStreamWriterI, cmp skiplist.Comparator[[]byte], reduce sstables.ReduceFunc) (err error) {
+	var iterators []sstables.SSTableMergeIteratorContext
+	for i, table := range job.Tables {
+		// err must not be shadowed here, the deferred Close errors are joined into the named return
+		var reader sstables.SSTableReaderI
+		reader, err = openReader(table.Path, cmp)
+		if err != nil {
+			return fmt.Errorf("compaction error while opening table at '%s': %w", table.Path, err)
+		}
+
+		defer func(r sstables.SSTableReaderI) {
+			err = errors.Join(err, r.Close())
+		}(reader)
+
+		var scanner sstables.SSTableIteratorI
+		scanner, err = reader.Scan()
+		if err != nil {
+			return fmt.Errorf("compaction error while scanning table at '%s': %w", table.Path, err)
+		}
+
+		iterators = append(iterators, sstables.NewMergeIteratorContext(i, scanner))
+	}
+
+	merger := sstables.NewSSTableMerger(cmp)
+	if reduce == nil {
+		return merger.Merge(iterators, writer)
+	}
+
+	return merger.MergeCompact(iterators, writer, reduce)
+}
+
+func validateTables(tables []Table) error {
+	seen := make(map[string]struct{}, len(tables))
+	for _, t := range tables {
+		if t.MetaData == nil {
+			return fmt.Errorf("table at '%s' has no metadata", t.Path)
+		}
+		if _, ok := seen[t.Path]; ok {
+			return fmt.Errorf("table at '%s' was supplied more than once", t.Path)
+		}
+		seen[t.Path] = struct{}{}
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package compaction
+
+import (
+	"sort"
+
+	"github.com/thomasjungblut/go-sstables/skiplist"
+	"github.com/thomasjungblut/go-sstables/sstables/proto"
+)
+
+// BoundaryRule defines whether two key ranges that only touch at their boundaries are considered overlapping.
+type BoundaryRule int
+
+const (
+	// BoundaryInclusive considers ranges overlapping when a MaxKey equals the MinKey of another range.
+	BoundaryInclusive BoundaryRule = iota
+	// BoundaryExclusive considers ranges only overlapping when they share more than their boundary keys.
+	BoundaryExclusive
+)
+
+// OverlappingTables returns the tables of a level that overlap the key range [MinKey, MaxKey] of the candidate.
+// The level must be sorted by MinKey and must not contain overlapping tables, which is the invariant of any level > 0
+// in a leveled compaction strategy. The returned tables are a contiguous and ordered subset of the level.
+// Tables without any records (nil MinKey and MaxKey) never overlap.
+func OverlappingTables(candidate *proto.MetaData, level []Table, cmp skiplist.Comparator[[]byte], rule BoundaryRule) []Table {
+	if candidate == nil || candidate.NumRecords == 0 || candidate.MinKey == nil || candidate.MaxKey == nil {
+		return nil
+	}
+
+	// before returns true when the table ends before the candidate starts
+	before := func(t Table) bool {
+		c := cmp.Compare(t.MetaData.MaxKey, candidate.MinKey)
+		if rule == BoundaryInclusive {
+			return c < 0
+		}
+		return c <= 0
+	}
+
+	// after returns true when the table starts after the candidate ends
+	after := func(t Table) bool {
+		c := cmp.Compare(t.MetaData.MinKey, candidate.MaxKey)
+		if rule == BoundaryInclusive {
+			return c > 0
+		}
+		return c >= 0
+	}
+
+	var nonEmpty []Table
+	for _, t := range level {
+		if t.MetaData != nil && t.MetaData.NumRecords > 0 {
+			nonEmpty = append(nonEmpty, t)
+		}
+	}
+
+	start := sort.Search(len(nonEmpty), func(i int) bool {
+		return !before(nonEmpty[i])
+	})
+
+	var result []Table
+	for i := start; i < len(nonEmpty); i++ {
+		if after(nonEmpty[i]) {
+			break
+		}
+		result = append(result, nonEmpty[i])
+	}
+
+	return result
+}
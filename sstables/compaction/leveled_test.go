@@ -0,0 +1,76 @@
+package compaction
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thomasjungblut/go-sstables/skiplist"
+	"github.com/thomasjungblut/go-sstables/sstables/proto"
+)
+
+func TestOverlappingTablesEmptyLevel(t *testing.T) {
+	assert.Empty(t, OverlappingTables(rangeMeta(1, 5), nil, skiplist.BytesComparator{}, BoundaryInclusive))
+}
+
+func TestOverlappingTablesEmptyCandidate(t *testing.T) {
+	level := levelWithRanges(1, 10, 11, 20)
+	assert.Empty(t, OverlappingTables(&proto.MetaData{}, level, skiplist.BytesComparator{}, BoundaryInclusive))
+	assert.Empty(t, OverlappingTables(nil, level, skiplist.BytesComparator{}, BoundaryInclusive))
+}
+
+func TestOverlappingTablesInside(t *testing.T) {
+	level := levelWithRanges(1, 10, 11, 20, 21, 30)
+	res := OverlappingTables(rangeMeta(12, 15), level, skiplist.BytesComparator{}, BoundaryInclusive)
+	assert.Equal(t, []string{"l1"}, Job{Tables: res}.Paths())
+}
+
+func TestOverlappingTablesSpanning(t *testing.T) {
+	level := levelWithRanges(1, 10, 11, 20, 21, 30, 31, 40)
+	res := OverlappingTables(rangeMeta(5, 25), level, skiplist.BytesComparator{}, BoundaryInclusive)
+	assert.Equal(t, []string{"l0", "l1", "l2"}, Job{Tables: res}.Paths())
+
+	res = OverlappingTables(rangeMeta(0, 100), level, skiplist.BytesComparator{}, BoundaryInclusive)
+	assert.Equal(t, []string{"l0", "l1", "l2", "l3"}, Job{Tables: res}.Paths())
+}
+
+func TestOverlappingTablesOutOfRange(t *testing.T) {
+	level := levelWithRanges(10, 20, 30, 40)
+	assert.Empty(t, OverlappingTables(rangeMeta(1, 5), level, skiplist.BytesComparator{}, BoundaryInclusive))
+	assert.Empty(t, OverlappingTables(rangeMeta(50, 55), level, skiplist.BytesComparator{}, BoundaryInclusive))
+	assert.Empty(t, OverlappingTables(rangeMeta(21, 29), level, skiplist.BytesComparator{}, BoundaryInclusive))
+}
+
+func TestOverlappingTablesBoundaryTouch(t *testing.T) {
+	level := levelWithRanges(1, 10, 20, 30, 40, 50)
+
+	res := OverlappingTables(rangeMeta(10, 20), level, skiplist.BytesComparator{}, BoundaryInclusive)
+	assert.Equal(t, []string{"l0", "l1"}, Job{Tables: res}.Paths())
+	res = OverlappingTables(rangeMeta(10, 20), level, skiplist.BytesComparator{}, BoundaryExclusive)
+	assert.Empty(t, res)
+
+	res = OverlappingTables(rangeMeta(10, 40), level, skiplist.BytesComparator{}, BoundaryInclusive)
+	assert.Equal(t, []string{"l0", "l1", "l2"}, Job{Tables: res}.Paths())
+	res = OverlappingTables(rangeMeta(10, 40), level, skiplist.BytesComparator{}, BoundaryExclusive)
+	assert.Equal(t, []string{"l1"}, Job{Tables: res}.Paths())
+}
+
+func TestOverlappingTablesSkipsEmptyTables(t *testing.T) {
+	level := levelWithRanges(1, 10, 11, 20)
+	level = append([]Table{{Path: "empty", MetaData: &proto.MetaData{}}}, level...)
+	res := OverlappingTables(rangeMeta(1, 20), level, skiplist.BytesComparator{}, BoundaryInclusive)
+	assert.Equal(t, []string{"l0", "l1"}, Job{Tables: res}.Paths())
+}
+
+func rangeMeta(min int, max int) *proto.MetaData {
+	return &proto.MetaData{MinKey: intToByteSlice(min), MaxKey: intToByteSlice(max), NumRecords: uint64(max - min + 1)}
+}
+
+// levelWithRanges takes pairs of min/max keys and returns a level of tables
+func levelWithRanges(ranges ...int) []Table {
+	var level []Table
+	for i := 0; i < len(ranges); i += 2 {
+		level = append(level, Table{Path: fmt.Sprintf("l%d", i/2), MetaData: rangeMeta(ranges[i], ranges[i+1])})
+	}
+	return level
+}
@@ -0,0 +1,137 @@
+package compaction
+
+import (
+	"errors"
+	"sort"
+)
+
+// PlanSizeTiered groups tables of similar size into buckets and returns a job for every bucket that reaches the
+// minimum threshold of tables. Tables are considered similar when their TotalBytes are within
+// [bucketAvg * bucketLow, bucketAvg * bucketHigh] of the running average of the bucket they are sorted into.
+// Tables smaller than the configured minimum table size are always grouped together in one bucket.
+// Every table is part of at most one job.
+func PlanSizeTiered(tables []Table, opts ...SizeTieredOption) (*Plan, error) {
+	options := &SizeTieredOptions{
+		bucketLow:         0.5,
+		bucketHigh:        1.5,
+		minThreshold:      4,
+		maxThreshold:      32,
+		minTableSizeBytes: 50 * 1024 * 1024,
+	}
+
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	if options.bucketLow <= 0 || options.bucketLow > 1 {
+		return nil, errors.New("bucketLow must be in range (0, 1]")
+	}
+
+	if options.bucketHigh < 1 {
+		return nil, errors.New("bucketHigh must be at least 1")
+	}
+
+	if options.minThreshold < 2 {
+		return nil, errors.New("minThreshold must be at least 2")
+	}
+
+	if options.maxThreshold < options.minThreshold {
+		return nil, errors.New("maxThreshold must be larger or equal to minThreshold")
+	}
+
+	if err := validateTables(tables); err != nil {
+		return nil, err
+	}
+
+	// we sort a permutation by size, but keep the input order within a job later on
+	order := make([]int, len(tables))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return tables[order[i]].MetaData.TotalBytes < tables[order[j]].MetaData.TotalBytes
+	})
+
+	var buckets [][]int
+	var bucketAvg []float64
+	for _, idx := range order {
+		size := float64(tables[idx].MetaData.TotalBytes)
+		placed := false
+		for b := range buckets {
+			avg := bucketAvg[b]
+			small := size < float64(options.minTableSizeBytes) && avg < float64(options.minTableSizeBytes)
+			if small || (size >= avg*options.bucketLow && size <= avg*options.bucketHigh) {
+				n := float64(len(buckets[b]))
+				buckets[b] = append(buckets[b], idx)
+				bucketAvg[b] = (avg*n + size) / (n + 1)
+				placed = true
+				break
+			}
+		}
+
+		if !placed {
+			buckets = append(buckets, []int{idx})
+			bucketAvg = append(bucketAvg, size)
+		}
+	}
+
+	plan := &Plan{}
+	for _, bucket := range buckets {
+		if len(bucket) < options.minThreshold {
+			continue
+		}
+
+		// the bucket is sorted by size, we compact the smallest tables first when we exceed the max threshold
+		bucket = bucket[:min(len(bucket), options.maxThreshold)]
+		sort.Ints(bucket)
+
+		job := Job{}
+		for _, idx := range bucket {
+			job.Tables = append(job.Tables, tables[idx])
+		}
+		plan.Jobs = append(plan.Jobs, job)
+	}
+
+	return plan, nil
+}
+
+// options
+
+type SizeTieredOptions struct {
+	bucketLow         float64
+	bucketHigh        float64
+	minThreshold      int
+	maxThreshold      int
+	minTableSizeBytes uint64
+}
+
+type SizeTieredOption func(*SizeTieredOptions)
+
+// BucketRatio sets the lower and upper bound of how far a table's size can be off the bucket average, defaults to 0.5 and 1.5.
+func BucketRatio(low float64, high float64) SizeTieredOption {
+	return func(args *SizeTieredOptions) {
+		args.bucketLow = low
+		args.bucketHigh = high
+	}
+}
+
+// MinThreshold sets the minimum number of tables in a bucket to plan a job, defaults to 4.
+func MinThreshold(n int) SizeTieredOption {
+	return func(args *SizeTieredOptions) {
+		args.minThreshold = n
+	}
+}
+
+// MaxThreshold sets the maximum number of tables that are compacted in a single job, defaults to 32.
+func MaxThreshold(n int) SizeTieredOption {
+	return func(args *SizeTieredOptions) {
+		args.maxThreshold = n
+	}
+}
+
+// MinTableSizeBytes sets the size under which all tables are put into the same bucket, defaults to 50 MiB.
+func MinTableSizeBytes(n uint64) SizeTieredOption {
+	return func(args *SizeTieredOptions) {
+		args.minTableSizeBytes = n
+	}
+}
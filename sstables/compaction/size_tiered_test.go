@@ -0,0 +1,187 @@
+package compaction
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thomasjungblut/go-sstables/skiplist"
+	"github.com/thomasjungblut/go-sstables/sstables"
+	"github.com/thomasjungblut/go-sstables/sstables/proto"
+)
+
+func TestSizeTieredPlanEmpty(t *testing.T) {
+	plan, err := PlanSizeTiered(nil)
+	require.NoError(t, err)
+	assert.Empty(t, plan.Jobs)
+}
+
+func TestSizeTieredPlanBelowThreshold(t *testing.T) {
+	plan, err := PlanSizeTiered(tablesWithSizes(100, 100, 100), MinTableSizeBytes(0))
+	require.NoError(t, err)
+	assert.Empty(t, plan.Jobs)
+}
+
+func TestSizeTieredPlanGroupsSimilarSizes(t *testing.T) {
+	tables := tablesWithSizes(100, 10000, 110, 9000, 90, 11000, 105, 10500)
+	plan, err := PlanSizeTiered(tables, MinTableSizeBytes(0))
+	require.NoError(t, err)
+	require.Equal(t, 2, len(plan.Jobs))
+
+	// jobs keep the input order of the tables
+	assert.Equal(t, []string{"t0", "t2", "t4", "t6"}, plan.Jobs[0].Paths())
+	assert.Equal(t, []string{"t1", "t3", "t5", "t7"}, plan.Jobs[1].Paths())
+	assert.Equal(t, uint64(405), plan.Jobs[0].TotalBytes())
+	assert.Equal(t, uint64(40500), plan.Jobs[1].TotalBytes())
+	assertNoTableInMultipleJobs(t, plan)
+}
+
+func TestSizeTieredPlanSmallTablesAlwaysBucketed(t *testing.T) {
+	tables := tablesWithSizes(1, 1000, 50, 5000)
+	plan, err := PlanSizeTiered(tables, MinTableSizeBytes(10000))
+	require.NoError(t, err)
+	require.Equal(t, 1, len(plan.Jobs))
+	assert.Equal(t, []string{"t0", "t1", "t2", "t3"}, plan.Jobs[0].Paths())
+}
+
+func TestSizeTieredPlanMaxThreshold(t *testing.T) {
+	tables := tablesWithSizes(100, 100, 100, 100, 100, 100)
+	plan, err := PlanSizeTiered(tables, MinTableSizeBytes(0), MinThreshold(2), MaxThreshold(3))
+	require.NoError(t, err)
+	require.Equal(t, 1, len(plan.Jobs))
+	assert.Equal(t, 3, len(plan.Jobs[0].Tables))
+	assertNoTableInMultipleJobs(t, plan)
+}
+
+func TestSizeTieredPlanValidation(t *testing.T) {
+	_, err := PlanSizeTiered(tablesWithSizes(1), MinThreshold(1))
+	assert.Error(t, err)
+	_, err = PlanSizeTiered(tablesWithSizes(1), MinThreshold(4), MaxThreshold(3))
+	assert.Error(t, err)
+	_, err = PlanSizeTiered(tablesWithSizes(1), BucketRatio(0, 1.5))
+	assert.Error(t, err)
+	_, err = PlanSizeTiered(tablesWithSizes(1), BucketRatio(0.5, 0.9))
+	assert.Error(t, err)
+	_, err = PlanSizeTiered([]Table{{Path: "a"}})
+	assert.Error(t, err)
+
+	tables := tablesWithSizes(1, 2)
+	tables[1].Path = tables[0].Path
+	_, err = PlanSizeTiered(tables)
+	assert.ErrorContains(t, err, "was supplied more than once")
+}
+
+func TestExecuteJobEndToEnd(t *testing.T) {
+	var tables []Table
+	for i := 0; i < 4; i++ {
+		tables = append(tables, writeTable(t, i*10, (i+1)*10))
+	}
+
+	plan, err := PlanSizeTiered(tables)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(plan.Jobs))
+
+	outPath, err := os.MkdirTemp("", "compaction_out")
+	require.NoError(t, err)
+	defer func() { require.NoError(t, os.RemoveAll(outPath)) }()
+
+	writer, err := sstables.NewSSTableStreamWriter(
+		sstables.WriteBasePath(outPath),
+		sstables.WithKeyComparator(skiplist.BytesComparator{}))
+	require.NoError(t, err)
+	require.NoError(t, writer.Open())
+	require.NoError(t, Execute(plan.Jobs[0], writer, skiplist.BytesComparator{}, nil))
+	require.NoError(t, writer.Close())
+
+	reader, err := sstables.NewSSTableReader(sstables.ReadBasePath(outPath))
+	require.NoError(t, err)
+	defer func() { require.NoError(t, reader.Close()) }()
+	assert.Equal(t, uint64(40), reader.MetaData().NumRecords)
+	assert.Equal(t, plan.Jobs[0].NumRecords(), reader.MetaData().NumRecords)
+}
+
+// closeFailingReader fails on Close after closing the wrapped reader
+type closeFailingReader struct {
+	sstables.SSTableReaderI
+}
+
+var errCloseFailed = errors.New("close failed")
+
+func (r closeFailingReader) Close() error {
+	return errors.Join(r.SSTableReaderI.Close(), errCloseFailed)
+}
+
+func TestExecuteReturnsCloseErrors(t *testing.T) {
+	defaultOpenReader := openReader
+	defer func() { openReader = defaultOpenReader }()
+	openReader = func(path string, cmp skiplist.Comparator[[]byte]) (sstables.SSTableReaderI, error) {
+		reader, err := defaultOpenReader(path, cmp)
+		if err != nil {
+			return nil, err
+		}
+		return closeFailingReader{reader}, nil
+	}
+
+	job := Job{Tables: []Table{writeTable(t, 0, 10), writeTable(t, 10, 20)}}
+	writer, err := sstables.NewSSTableStreamWriter(
+		sstables.WriteBasePath(t.TempDir()),
+		sstables.WithKeyComparator(skiplist.BytesComparator{}))
+	require.NoError(t, err)
+	require.NoError(t, writer.Open())
+	defer func() { require.NoError(t, writer.Close()) }()
+
+	err = Execute(job, writer, skiplist.BytesComparator{}, nil)
+	assert.ErrorIs(t, err, errCloseFailed)
+}
+
+func assertNoTableInMultipleJobs(t *testing.T, plan *Plan) {
+	seen := map[string]bool{}
+	for _, job := range plan.Jobs {
+		for _, p := range job.Paths() {
+			require.False(t, seen[p], "table %s is in more than one job", p)
+			seen[p] = true
+		}
+	}
+}
+
+func tablesWithSizes(sizes ...uint64) []Table {
+	var tables []Table
+	for i, s := range sizes {
+		tables = append(tables, Table{
+			Path:     fmt.Sprintf("t%d", i),
+			MetaData: &proto.MetaData{TotalBytes: s, NumRecords: s},
+		})
+	}
+	return tables
+}
+
+func writeTable(t *testing.T, start int, end int) Table {
+	path, err := os.MkdirTemp("", "compaction_in")
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, os.RemoveAll(path)) })
+
+	writer, err := sstables.NewSSTableStreamWriter(
+		sstables.WriteBasePath(path),
+		sstables.WithKeyComparator(skiplist.BytesComparator{}))
+	require.NoError(t, err)
+	require.NoError(t, writer.Open())
+	for i := start; i < end; i++ {
+		require.NoError(t, writer.WriteNext(intToByteSlice(i), intToByteSlice(i+1)))
+	}
+	require.NoError(t, writer.Close())
+
+	reader, err := sstables.NewSSTableReader(sstables.ReadBasePath(path))
+	require.NoError(t, err)
+	defer func() { require.NoError(t, reader.Close()) }()
+	return Table{Path: path, MetaData: reader.MetaData()}
+}
+
+func intToByteSlice(e int) []byte {
+	key := make([]byte, 4)
+	binary.BigEndian.PutUint32(key, uint32(e))
+	return key
+}
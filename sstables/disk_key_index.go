@@ -9,6 +9,7 @@ import (
 	"github.com/thomasjungblut/go-sstables/skiplist"
 	"github.com/thomasjungblut/go-sstables/sstables/proto"
 	"io"
+	"sort"
 )
 
 // DiskKeyIndex is doing lookups on disk to find the value for a given key using binary search
@@ -18,6 +19,10 @@ type DiskKeyIndex struct {
 	offsetCacheMaxSize int
 	// offsetCache caches the result of a given offset for the binary search
 	offsetCache map[uint64]*proto.IndexEntry
+	// summary contains every nth key with the offset of its index record, can be empty
+	summary []*proto.SummaryEntry
+	// prefixCompressed is true when the index was written with IndexKeyPrefixCompression, only restarts contain full keys
+	prefixCompressed bool
 }
 
 func (s *DiskKeyIndex) Close() error {
@@ -43,21 +48,23 @@ func (s *DiskKeyIndex) Get(key []byte) (IndexVal, error) {
 	}
 
 	return IndexVal{
-		Offset:   v.ValueOffset,
-		Checksum: v.Checksum,
+		Offset:         v.ValueOffset,
+		Checksum:       v.Checksum,
+		NullValue:      v.NullValue,
+		SequenceNumber: v.SequenceNumber,
 	}, nil
 }
 
 func (s *DiskKeyIndex) Iterator() (skiplist.IteratorI[[]byte, IndexVal], error) {
-	return s.newIterator(recordio.FileHeaderSizeBytes, s.reader.Size()), nil
+	return s.newIterator(recordio.FileHeaderSizeBytes, s.reader.Size(), nil), nil
 }
 
 func (s *DiskKeyIndex) IteratorStartingAt(key []byte) (skiplist.IteratorI[[]byte, IndexVal], error) {
-	offset, _, _, err := s.binarySearch(key)
+	offset, prevKey, err := s.iteratorStart(key)
 	if err != nil {
 		return nil, err
 	}
-	return s.newIterator(offset, s.reader.Size()), nil
+	return s.newIterator(offset, s.reader.Size(), prevKey), nil
 }
 
 func (s *DiskKeyIndex) IteratorBetween(keyLower []byte, keyHigher []byte) (skiplist.IteratorI[[]byte, IndexVal], error) {
@@ -65,7 +72,7 @@ func (s *DiskKeyIndex) IteratorBetween(keyLower []byte, keyHigher []byte) (skipl
 		return nil, errors.New("keyHigher is lower than keyLower")
 	}
 
-	startOffset, _, _, err := s.binarySearch(keyLower)
+	startOffset, prevKey, err := s.iteratorStart(keyLower)
 	if err != nil {
 		return nil, err
 	}
@@ -75,15 +82,32 @@ func (s *DiskKeyIndex) IteratorBetween(keyLower []byte, keyHigher []byte) (skipl
 		return nil, err
 	}
 
-	return s.newIterator(startOffset, endOffset), nil
+	return s.newIterator(startOffset, endOffset, prevKey), nil
+}
+
+// iteratorStart returns the offset of the first entry that is greater or equal to the key, along with the full key of
+// the entry before it to reconstruct prefix compressed keys.
+func (s *DiskKeyIndex) iteratorStart(key []byte) (uint64, []byte, error) {
+	if s.prefixCompressed {
+		offset, _, _, prevKey, err := s.prefixSearch(key)
+		return offset, prevKey, err
+	}
+
+	offset, _, _, err := s.binarySearch(key)
+	return offset, nil, err
 }
 
 // adjusted version of sort.BinarySearchFunc, returning a file offset instead of an index
 func (s *DiskKeyIndex) binarySearch(target []byte) (uint64, *proto.IndexEntry, bool, error) {
+	if s.prefixCompressed {
+		offset, entry, exists, _, err := s.prefixSearch(target)
+		return offset, entry, exists, err
+	}
+
 	n := s.reader.Size()
 	// Define cmp(x[-1], target) < 0 and cmp(x[n], target) >= 0 .
 	// Invariant: cmp(x[i - 1], target) < 0, cmp(x[j], target) >= 0.
-	i, j := uint64(0), n
+	i, j := s.summaryRegion(target, n)
 	for i < j {
 		h := (i + j) >> 1 // avoid overflow when computing h
 		// i ≤ h < j
@@ -111,6 +135,110 @@ func (s *DiskKeyIndex) binarySearch(target []byte) (uint64, *proto.IndexEntry, b
 	return i, at, i < n && bytes.Compare(at.Key, target) == 0, nil
 }
 
+// summaryRegion returns the range of index offsets that needs to be searched for the target, which is the whole index
+// without a summary. Otherwise, it's the region from the last summary key lower or equal to the target until the next.
+func (s *DiskKeyIndex) summaryRegion(target []byte, n uint64) (uint64, uint64) {
+	// p is the first summary entry with a key greater than the target
+	p := sort.Search(len(s.summary), func(i int) bool {
+		return bytes.Compare(s.summary[i].Key, target) > 0
+	})
+
+	i, j := uint64(0), n
+	if p > 0 {
+		i = s.summary[p-1].IndexOffset
+	}
+	if p < len(s.summary) {
+		j = s.summary[p].IndexOffset
+	}
+	return i, j
+}
+
+// prefixSearch is the binarySearch for prefix compressed indices. It binary searches for the last restart with a key
+// lower or equal to the target and from there reconstructs the keys sequentially until the target is reached.
+// The returned entry contains the full key, in addition the full key of the previous entry is returned.
+func (s *DiskKeyIndex) prefixSearch(target []byte) (uint64, *proto.IndexEntry, bool, []byte, error) {
+	n := s.reader.Size()
+	start, j := s.summaryRegion(target, n)
+	// Invariant: restart(i - 1) <= target, restart(j) > target, where restart(h) is the first restart at or after h.
+	// Once i == j, the restart at i - 1 starts exactly at i - 1, as it differs from the restart at i.
+	i := start
+	for i < j {
+		h := (i + j) >> 1
+		at, err := s.findRestartAt(h)
+		if err != nil && !errors.Is(err, io.EOF) {
+			return 0, nil, false, nil, err
+		}
+		// no restart after h behaves like a key greater than the target
+		if err == nil && bytes.Compare(at.Key, target) <= 0 {
+			i = h + 1
+		} else {
+			j = h
+		}
+	}
+
+	off := start
+	if i > start {
+		off = i - 1
+	}
+
+	var prevKey []byte
+	for {
+		record := &proto.IndexEntry{}
+		recordOffset, _, err := s.reader.SeekNext(record, off)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return n, nil, false, prevKey, nil
+			}
+			return 0, nil, false, nil, err
+		}
+		off = recordOffset + 1
+
+		// we can only start reconstructing keys from a restart
+		if prevKey == nil && record.SharedPrefixLength != 0 {
+			continue
+		}
+
+		key, err := fullIndexKey(prevKey, record)
+		if err != nil {
+			return 0, nil, false, nil, err
+		}
+		record.Key = key
+		record.SharedPrefixLength = 0
+
+		cmp := bytes.Compare(key, target)
+		if cmp >= 0 {
+			return recordOffset, record, cmp == 0, prevKey, nil
+		}
+		prevKey = key
+	}
+}
+
+// findRestartAt returns the first restart entry at or after the given offset
+func (s *DiskKeyIndex) findRestartAt(off uint64) (*proto.IndexEntry, error) {
+	if val, ok := s.offsetCache[off]; ok {
+		return val, nil
+	}
+
+	record := &proto.IndexEntry{}
+	seekOffset := off
+	for {
+		recordOffset, _, err := s.reader.SeekNext(record, seekOffset)
+		if err != nil {
+			return record, err
+		}
+		if record.SharedPrefixLength == 0 {
+			break
+		}
+		seekOffset = recordOffset + 1
+	}
+
+	if len(s.offsetCache) < s.offsetCacheMaxSize {
+		s.offsetCache[off] = record
+	}
+
+	return record, nil
+}
+
 func (s *DiskKeyIndex) findAt(off uint64) (*proto.IndexEntry, error) {
 	if val, ok := s.offsetCache[off]; ok {
 		return val, nil
@@ -125,12 +253,13 @@ func (s *DiskKeyIndex) findAt(off uint64) (*proto.IndexEntry, error) {
 	return record, err
 }
 
-func (s *DiskKeyIndex) newIterator(offset, endOffset uint64) *DiskKeyIndexIterator {
+func (s *DiskKeyIndex) newIterator(offset, endOffset uint64, prevKey []byte) *DiskKeyIndexIterator {
 	return &DiskKeyIndexIterator{
 		reader:        s.reader,
 		entry:         &proto.IndexEntry{},
 		currentOffset: offset,
 		endOffset:     endOffset,
+		prevKey:       prevKey,
 	}
 }
 
@@ -139,6 +268,8 @@ type DiskKeyIndexIterator struct {
 	entry         *proto.IndexEntry
 	currentOffset uint64
 	endOffset     uint64
+	// prevKey is the full key of the previous entry to reconstruct prefix compressed keys
+	prevKey []byte
 }
 
 func (s *DiskKeyIndexIterator) Next() ([]byte, IndexVal, error) {
@@ -155,16 +286,24 @@ func (s *DiskKeyIndexIterator) Next() ([]byte, IndexVal, error) {
 	}
 
 	s.currentOffset = offset + 1
-	return s.entry.Key, IndexVal{
-		Offset:   s.entry.ValueOffset,
-		Checksum: s.entry.Checksum,
+	key, err := fullIndexKey(s.prevKey, s.entry)
+	if err != nil {
+		return nil, IndexVal{}, err
+	}
+	s.prevKey = key
+
+	return key, IndexVal{
+		Offset:         s.entry.ValueOffset,
+		Checksum:       s.entry.Checksum,
+		NullValue:      s.entry.NullValue,
+		SequenceNumber: s.entry.SequenceNumber,
 	}, nil
 }
 
 type DiskIndexLoader struct {
 }
 
-func (l *DiskIndexLoader) Load(indexPath string, _ *proto.MetaData) (_ SortedKeyIndex, err error) {
+func (l *DiskIndexLoader) Load(indexPath string, metadata *proto.MetaData) (_ SortedKeyIndex, err error) {
 	reader, err := rProto.NewMMapProtoReaderWithPath(indexPath)
 	if err != nil {
 		return nil, fmt.Errorf("error while creating index reader of sstable in '%s': %w", indexPath, err)
@@ -174,6 +313,7 @@ func (l *DiskIndexLoader) Load(indexPath string, _ *proto.MetaData) (_ SortedKey
 		reader:             reader,
 		offsetCacheMaxSize: 128,
 		offsetCache:        make(map[uint64]*proto.IndexEntry),
+		prefixCompressed:   metadata != nil && metadata.IndexRestartInterval > 0,
 	}
 	return idx, nil
 }
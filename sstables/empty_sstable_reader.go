@@ -13,7 +13,7 @@ func (EmptySStableReader) Contains(_ []byte) (bool, error) {
 }
 
 func (EmptySStableReader) Get(_ []byte) ([]byte, error) {
-	return nil, NotFound
+	return nil, ErrKeyNotFound
 }
 
 func (EmptySStableReader) Scan() (SSTableIteratorI, error) {
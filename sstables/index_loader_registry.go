@@ -0,0 +1,88 @@
+package sstables
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/thomasjungblut/go-sstables/skiplist"
+	"github.com/thomasjungblut/go-sstables/sstables/proto"
+)
+
+// IndexLoaderOptions are the options of a reader that are passed to an IndexLoaderFactory.
+type IndexLoaderOptions struct {
+	BasePath       string
+	ReadBufferSize int
+	KeyComparator  skiplist.Comparator[[]byte]
+}
+
+// IndexLoaderFactory creates the IndexLoader for a table from its metadata and the options of the reader, see
+// RegisterIndexLoader.
+type IndexLoaderFactory func(metaData *proto.MetaData, opts IndexLoaderOptions) (IndexLoader, error)
+
+var indexLoadersMu sync.RWMutex
+
+// indexLoaderFactories contains the built-in loaders, RegisterIndexLoader adds more
+var indexLoaderFactories = map[string]IndexLoaderFactory{
+	"skiplist": func(_ *proto.MetaData, opts IndexLoaderOptions) (IndexLoader, error) {
+		return &SkipListIndexLoader{KeyComparator: opts.KeyComparator, ReadBufferSize: opts.ReadBufferSize}, nil
+	},
+	"slice": func(_ *proto.MetaData, opts IndexLoaderOptions) (IndexLoader, error) {
+		return &SliceKeyIndexLoader{ReadBufferSize: opts.ReadBufferSize}, nil
+	},
+	"arena": func(_ *proto.MetaData, opts IndexLoaderOptions) (IndexLoader, error) {
+		return &ArenaKeyIndexLoader{ReadBufferSize: opts.ReadBufferSize}, nil
+	},
+	"disk": func(_ *proto.MetaData, _ IndexLoaderOptions) (IndexLoader, error) {
+		return &DiskIndexLoader{}, nil
+	},
+}
+
+// RegisterIndexLoader makes a loader available under the given name for ReadIndexLoaderName, for example to select
+// the loader from a config file. The built-in loaders are registered as "skiplist", "slice", "arena" and "disk".
+// Registering a name twice or a nil factory returns an error.
+func RegisterIndexLoader(name string, factory IndexLoaderFactory) error {
+	if name == "" {
+		return errors.New("index loader name must not be empty")
+	}
+	if factory == nil {
+		return fmt.Errorf("index loader factory for '%s' must not be nil", name)
+	}
+
+	indexLoadersMu.Lock()
+	defer indexLoadersMu.Unlock()
+	if _, ok := indexLoaderFactories[name]; ok {
+		return fmt.Errorf("index loader '%s' is already registered", name)
+	}
+	indexLoaderFactories[name] = factory
+	return nil
+}
+
+// IndexLoaderNames returns the sorted names of all registered loaders.
+func IndexLoaderNames() []string {
+	indexLoadersMu.RLock()
+	defer indexLoadersMu.RUnlock()
+	names := make([]string, 0, len(indexLoaderFactories))
+	for name := range indexLoaderFactories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// newNamedIndexLoader creates the loader that was registered under the given name
+func newNamedIndexLoader(name string, metaData *proto.MetaData, opts IndexLoaderOptions) (IndexLoader, error) {
+	indexLoadersMu.RLock()
+	factory, ok := indexLoaderFactories[name]
+	indexLoadersMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown index loader '%s', registered are %v", name, IndexLoaderNames())
+	}
+
+	loader, err := factory(metaData, opts)
+	if err != nil {
+		return nil, fmt.Errorf("error while creating index loader '%s': %w", name, err)
+	}
+	return loader, nil
+}
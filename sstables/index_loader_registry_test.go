@@ -0,0 +1,66 @@
+package sstables
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thomasjungblut/go-sstables/sstables/proto"
+)
+
+func TestReadIndexLoaderName(t *testing.T) {
+	writer, err := newTestSSTableStreamWriter()
+	require.NoError(t, err)
+	defer cleanWriterDir(t, writer)
+	expected := streamedWriteAscendingIntegers(t, writer, 100)
+	basePath := writer.opts.basePath
+
+	assert.Subset(t, IndexLoaderNames(), []string{"arena", "disk", "skiplist", "slice"})
+	for _, name := range []string{"arena", "disk", "skiplist", "slice"} {
+		reader, err := NewSSTableReader(ReadBasePath(basePath), ReadIndexLoaderName(name))
+		require.NoError(t, err, name)
+		it, err := reader.ScanStartingAt(intToByteSlice(0))
+		require.NoError(t, err)
+		assertIteratorMatchesSlice(t, it, expected)
+		closeReader(t, reader)
+	}
+
+	var factoryMetaData *proto.MetaData
+	var factoryOpts IndexLoaderOptions
+	require.NoError(t, RegisterIndexLoader("test-registry", func(md *proto.MetaData, opts IndexLoaderOptions) (IndexLoader, error) {
+		factoryMetaData, factoryOpts = md, opts
+		return &SliceKeyIndexLoader{ReadBufferSize: opts.ReadBufferSize}, nil
+	}))
+	assert.Error(t, RegisterIndexLoader("test-registry", func(*proto.MetaData, IndexLoaderOptions) (IndexLoader, error) {
+		return nil, nil
+	}))
+
+	r, err := NewSSTableReader(ReadBasePath(basePath), ReadIndexLoaderName("test-registry"), ReadBufferSizeBytes(1024))
+	require.NoError(t, err)
+	defer closeReader(t, r)
+	_, ok := r.(*SSTableReader).index.(*SliceKeyIndex)
+	assert.True(t, ok)
+	assert.Equal(t, uint64(100), factoryMetaData.NumRecords)
+	assert.Equal(t, basePath, factoryOpts.BasePath)
+	assert.Equal(t, 1024, factoryOpts.ReadBufferSize)
+	assert.NotNil(t, factoryOpts.KeyComparator)
+}
+
+func TestReadIndexLoaderNameErrors(t *testing.T) {
+	writer, err := newTestSSTableStreamWriter()
+	require.NoError(t, err)
+	defer cleanWriterDir(t, writer)
+	streamedWriteAscendingIntegers(t, writer, 10)
+	basePath := writer.opts.basePath
+
+	_, err = NewSSTableReader(ReadBasePath(basePath), ReadIndexLoaderName("does-not-exist"))
+	assert.ErrorContains(t, err, "unknown index loader 'does-not-exist'")
+
+	_, err = NewSSTableReader(ReadBasePath(basePath), ReadIndexLoaderName("disk"), ReadIndexLoader(&DiskIndexLoader{}))
+	assert.ErrorContains(t, err, "can't be combined")
+
+	assert.Error(t, RegisterIndexLoader("", func(*proto.MetaData, IndexLoaderOptions) (IndexLoader, error) {
+		return nil, nil
+	}))
+	assert.Error(t, RegisterIndexLoader("test-nil", nil))
+}
@@ -0,0 +1,67 @@
+package sstables
+
+import (
+	"fmt"
+	"unsafe"
+
+	"github.com/thomasjungblut/go-sstables/sstables/proto"
+)
+
+// IndexMemoryEstimator is implemented by index loaders that can estimate the memory of the loaded index upfront.
+type IndexMemoryEstimator interface {
+	// EstimateMemoryBytes returns the estimated memory in bytes of the index of a table with the given metadata.
+	EstimateMemoryBytes(metadata *proto.MetaData) uint64
+}
+
+// IndexMemoryLimitError is returned by NewSSTableReader when the estimated memory of the index exceeds the limit set
+// with ReadMaxIndexMemoryBytes.
+type IndexMemoryLimitError struct {
+	EstimatedBytes uint64
+	LimitBytes     uint64
+}
+
+func (e IndexMemoryLimitError) Error() string {
+	return fmt.Sprintf("estimated index memory of %d bytes exceeds the limit of %d bytes", e.EstimatedBytes, e.LimitBytes)
+}
+
+// EstimateIndexMemoryBytes returns the estimated memory in bytes that the given loader needs to load the index of a
+// table with the given metadata, false when the loader doesn't implement IndexMemoryEstimator.
+// The keys are estimated by the size of the index file, which is an upper bound for uncompressed indices.
+func EstimateIndexMemoryBytes(loader IndexLoader, metadata *proto.MetaData) (uint64, bool) {
+	estimator, ok := loader.(IndexMemoryEstimator)
+	if !ok {
+		return 0, false
+	}
+	return estimator.EstimateMemoryBytes(metadata), true
+}
+
+const (
+	sliceHeaderBytes = uint64(unsafe.Sizeof([]byte{}))
+	indexValBytes    = uint64(unsafe.Sizeof(IndexVal{}))
+	// a skip list node contains the key, the value and the slice of its next pointers, which has 4/3 pointers on average
+	skipListNodeBytes = 2*sliceHeaderBytes + indexValBytes + 16
+)
+
+func (l *SkipListIndexLoader) EstimateMemoryBytes(metadata *proto.MetaData) uint64 {
+	return metadata.NumRecords*skipListNodeBytes + metadata.IndexBytes
+}
+
+func (s *SliceKeyIndexLoader) EstimateMemoryBytes(metadata *proto.MetaData) uint64 {
+	return metadata.NumRecords*uint64(unsafe.Sizeof(sliceKey{})) + metadata.IndexBytes
+}
+
+func (s *ArenaKeyIndexLoader) EstimateMemoryBytes(metadata *proto.MetaData) uint64 {
+	return metadata.NumRecords*(8+indexValBytes) + metadata.IndexBytes
+}
+
+func (s *MapKeyIndexLoader[T]) EstimateMemoryBytes(metadata *proto.MetaData) uint64 {
+	var key T
+	// the map is kept in addition to the slice, its buckets are about half full
+	mapBytes := 2 * metadata.NumRecords * (uint64(unsafe.Sizeof(key)) + indexValBytes)
+	return metadata.NumRecords*uint64(unsafe.Sizeof(sliceKey{})) + metadata.IndexBytes + mapBytes
+}
+
+// EstimateMemoryBytes is always zero, the index stays on disk. Only the small optional summary is kept in memory.
+func (l *DiskIndexLoader) EstimateMemoryBytes(_ *proto.MetaData) uint64 {
+	return 0
+}
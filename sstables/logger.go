@@ -0,0 +1,15 @@
+package sstables
+
+// Logger receives structured log messages at key points of the lifetime of a table, see WithLogger, ReadWithLogger
+// and MergeWithLogger. The args are alternating keys and values like in log/slog, a *slog.Logger satisfies it.
+type Logger interface {
+	Info(msg string, args ...any)
+	Warn(msg string, args ...any)
+}
+
+// noopLogger is the default Logger, which discards all messages
+type noopLogger struct{}
+
+func (noopLogger) Info(string, ...any) {}
+
+func (noopLogger) Warn(string, ...any) {}
@@ -0,0 +1,164 @@
+package sstables
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thomasjungblut/go-sstables/skiplist"
+)
+
+var _ Logger = (*slog.Logger)(nil)
+
+type logEntry struct {
+	level string
+	msg   string
+	args  map[string]any
+}
+
+// recordingLogger keeps all messages in memory
+type recordingLogger struct {
+	mu      sync.Mutex
+	entries []logEntry
+}
+
+func (l *recordingLogger) Info(msg string, args ...any) {
+	l.log("info", msg, args)
+}
+
+func (l *recordingLogger) Warn(msg string, args ...any) {
+	l.log("warn", msg, args)
+}
+
+func (l *recordingLogger) log(level string, msg string, args []any) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	m := map[string]any{}
+	for i := 0; i+1 < len(args); i += 2 {
+		m[args[i].(string)] = args[i+1]
+	}
+	l.entries = append(l.entries, logEntry{level: level, msg: msg, args: m})
+}
+
+func (l *recordingLogger) messages() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var msgs []string
+	for _, e := range l.entries {
+		msgs = append(msgs, e.level+" "+e.msg)
+	}
+	return msgs
+}
+
+func (l *recordingLogger) last() logEntry {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.entries[len(l.entries)-1]
+}
+
+func TestWriteAndReadWithLogger(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "sstables_Logger")
+	require.NoError(t, err)
+	logger := &recordingLogger{}
+	writer, err := NewSSTableStreamWriter(WriteBasePath(tmpDir), WithKeyComparator(skiplist.BytesComparator{}), WithLogger(logger))
+	require.NoError(t, err)
+	defer cleanWriterDir(t, writer)
+	streamedWriteAscendingIntegers(t, writer, 100)
+
+	assert.Equal(t, []string{"info sstable written"}, logger.messages())
+	written := logger.last()
+	assert.Equal(t, writer.opts.basePath, written.args["path"])
+	assert.Equal(t, uint64(100), written.args["records"])
+	assert.Equal(t, writer.metaData.DataBytes, written.args["dataBytes"])
+	assert.Equal(t, writer.metaData.IndexBytes, written.args["indexBytes"])
+
+	reader, err := NewSSTableReader(ReadBasePath(writer.opts.basePath), ReadWithLogger(logger))
+	require.NoError(t, err)
+	defer closeReader(t, reader)
+	assert.Equal(t, []string{"info sstable written", "info sstable opened"}, logger.messages())
+	opened := logger.last()
+	assert.Equal(t, writer.opts.basePath, opened.args["path"])
+	assert.Equal(t, uint64(100), opened.args["records"])
+	assert.Equal(t, writer.metaData.IndexBytes, opened.args["indexBytes"])
+	assert.Contains(t, opened.args, "loadTime")
+}
+
+func TestReadWithLoggerChecksumMismatch(t *testing.T) {
+	logger := &recordingLogger{}
+	_, err := NewSSTableReader(
+		ReadBasePath("test_files/SimpleWriteHappyPathSSTableWithCRCHashesMismatch"),
+		ReadWithLogger(logger))
+	require.ErrorIs(t, err, ChecksumError{})
+	assert.Equal(t, []string{"warn sstable checksum mismatch"}, logger.messages())
+	assert.Equal(t, uint64(41), logger.last().args["offset"])
+
+	logger = &recordingLogger{}
+	r, err := NewSSTableReader(
+		ReadBasePath("test_files/SimpleWriteHappyPathSSTableWithCRCHashesMismatch"),
+		SkipHashCheckOnLoad(),
+		ReadWithLogger(logger))
+	require.NoError(t, err)
+	defer closeReader(t, r)
+	value, err := r.(*SSTableReader).GetStreaming(intToByteSlice(4))
+	require.NoError(t, err)
+	_, err = io.ReadAll(value)
+	require.ErrorIs(t, err, ChecksumError{})
+	assert.Equal(t, []string{"info sstable opened", "warn sstable checksum mismatch"}, logger.messages())
+}
+
+func TestReadWithLoggerPartialFiles(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "sstables_LoggerPartialFiles")
+	require.NoError(t, err)
+	defer func() { require.NoError(t, os.RemoveAll(tmpDir)) }()
+
+	writer, err := NewSSTableStreamWriter(WriteBasePath(tmpDir), WithKeyComparator(skiplist.BytesComparator{}), SummaryEveryNthKey(4))
+	require.NoError(t, err)
+	streamedWriteAscendingIntegers(t, writer, 100)
+	require.NoError(t, os.Truncate(filepath.Join(tmpDir, SummaryFileName), 10))
+
+	logger := &recordingLogger{}
+	reader, err := NewSSTableReader(ReadBasePath(tmpDir), ReadIndexLoader(&DiskIndexLoader{}), ReadToleratePartialFiles(), ReadWithLogger(logger))
+	require.NoError(t, err)
+	defer closeReader(t, reader)
+	assert.Equal(t, []string{"warn sstable summary unreadable, searching the whole index", "info sstable opened"}, logger.messages())
+}
+
+func TestMergeWithLogger(t *testing.T) {
+	writer, err := newTestSSTableStreamWriter()
+	require.NoError(t, err)
+	defer cleanWriterDir(t, writer)
+	streamedWriteAscendingIntegers(t, writer, 100)
+	reader, iterator := getFullScanIterator(t, writer.opts.basePath)
+	defer closeReader(t, reader)
+
+	outWriter, err := newTestSSTableStreamWriter()
+	require.NoError(t, err)
+	require.NoError(t, outWriter.Open())
+	defer cleanWriterDir(t, outWriter)
+
+	logger := &recordingLogger{}
+	merger := NewSSTableMerger(skiplist.BytesComparator{}, MergeWithLogger(logger))
+	require.NoError(t, merger.Merge([]SSTableMergeIteratorContext{NewMergeIteratorContext(0, iterator)}, outWriter))
+	require.NoError(t, outWriter.Close())
+	assert.Equal(t, []string{"info compaction started", "info compaction finished"}, logger.messages())
+	assert.Equal(t, 1, logger.last().args["tables"])
+	assert.Equal(t, uint64(100), logger.last().args["records"])
+
+	failing := []SSTableMergeIteratorContext{NewMergeIteratorContext(0, &failingIterator{err: errors.New("broken"), failAfter: 10})}
+	logger = &recordingLogger{}
+	merger = NewSSTableMerger(skiplist.BytesComparator{}, MergeWithLogger(logger))
+	failWriter, err := newTestSSTableStreamWriter()
+	require.NoError(t, err)
+	require.NoError(t, failWriter.Open())
+	defer cleanWriterDir(t, failWriter)
+	require.Error(t, merger.MergeCompact(failing, failWriter, ScanReduceLatestWins))
+	require.NoError(t, failWriter.Close())
+	assert.Equal(t, []string{"info compaction started", "warn compaction failed"}, logger.messages())
+	assert.Less(t, logger.last().args["records"], uint64(10))
+}
@@ -96,6 +96,7 @@ func (s *MapKeyIndexLoader[T]) Load(indexPath string, metadata *proto.MetaData)
 	sx := make([]sliceKey, 0, capacity)
 
 	record := &proto.IndexEntry{}
+	var key []byte
 	var i = 0
 	for {
 		_, err := reader.ReadNext(record)
@@ -108,9 +109,14 @@ func (s *MapKeyIndexLoader[T]) Load(indexPath string, metadata *proto.MetaData)
 			return nil, fmt.Errorf("error while reading index records of sstable in '%s': %w", indexPath, err)
 		}
 
-		kBytes := s.Mapper.MapBytes(record.Key)
-		smap[kBytes] = IndexVal{Offset: record.ValueOffset, Checksum: record.Checksum, Tombstoned: record.Tombstoned}
-		sx = append(sx, sliceKey{smap[kBytes], record.Key})
+		key, err = fullIndexKey(key, record)
+		if err != nil {
+			return nil, fmt.Errorf("error while reading index records of sstable in '%s': %w", indexPath, err)
+		}
+
+		kBytes := s.Mapper.MapBytes(key)
+		smap[kBytes] = IndexVal{Offset: record.ValueOffset, Checksum: record.Checksum, Tombstoned: record.Tombstoned, NullValue: record.NullValue, SequenceNumber: record.SequenceNumber}
+		sx = append(sx, sliceKey{smap[kBytes], key})
 
 		i++
 	}
@@ -0,0 +1,82 @@
+package sstables
+
+import (
+	"container/list"
+	"sync"
+	"sync/atomic"
+)
+
+// negativeCache is a fixed size LRU of keys that are known to be absent from a table. Tables are immutable, so the
+// entries never need to be invalidated.
+type negativeCache struct {
+	mu       sync.Mutex
+	capacity int
+	lru      *list.List
+	keys     map[string]*list.Element
+
+	hits   atomic.Uint64
+	misses atomic.Uint64
+}
+
+func newNegativeCache(capacity int) *negativeCache {
+	return &negativeCache{
+		capacity: capacity,
+		lru:      list.New(),
+		keys:     make(map[string]*list.Element, capacity),
+	}
+}
+
+// contains returns true when the key was added before and wasn't evicted since, which also counts as a hit
+func (c *negativeCache) contains(key []byte) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.keys[string(key)]
+	if !ok {
+		c.misses.Add(1)
+		return false
+	}
+
+	c.lru.MoveToFront(e)
+	c.hits.Add(1)
+	return true
+}
+
+// add records the key as absent, evicting the least recently used key when the cache is full
+func (c *negativeCache) add(key []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, ok := c.keys[string(key)]; ok {
+		c.lru.MoveToFront(e)
+		return
+	}
+
+	if c.lru.Len() >= c.capacity {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.keys, oldest.Value.(string))
+	}
+
+	k := string(key)
+	c.keys[k] = c.lru.PushFront(k)
+}
+
+// ReaderStats contains the counters of a SSTableReader, see SSTableReader.Stats.
+type ReaderStats struct {
+	// NegativeCacheHits is the number of lookups that were answered by the negative cache, see ReadWithNegativeCache
+	NegativeCacheHits uint64
+	// NegativeCacheMisses is the number of lookups that passed the bloom filter and had to search the index
+	NegativeCacheMisses uint64
+}
+
+// Stats returns the counters of this reader, clones share the counters with the reader they were cloned from.
+func (reader *SSTableReader) Stats() ReaderStats {
+	if reader.negativeCache == nil {
+		return ReaderStats{}
+	}
+	return ReaderStats{
+		NegativeCacheHits:   reader.negativeCache.hits.Load(),
+		NegativeCacheMisses: reader.negativeCache.misses.Load(),
+	}
+}
@@ -0,0 +1,96 @@
+package sstables
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNegativeCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	c := newNegativeCache(2)
+	c.add([]byte{1})
+	c.add([]byte{2})
+	assert.True(t, c.contains([]byte{1}))
+	c.add([]byte{3})
+
+	assert.True(t, c.contains([]byte{1}))
+	assert.False(t, c.contains([]byte{2}))
+	assert.True(t, c.contains([]byte{3}))
+	assert.Equal(t, 2, c.lru.Len())
+	assert.Equal(t, uint64(3), c.hits.Load())
+	assert.Equal(t, uint64(1), c.misses.Load())
+}
+
+func TestReaderNegativeCache(t *testing.T) {
+	writer, err := newTestSSTableStreamWriter()
+	require.NoError(t, err)
+	defer cleanWriterDir(t, writer)
+	streamedWriteAscendingIntegers(t, writer, 100)
+	// without the bloom filter every lookup of an absent key goes to the index
+	require.NoError(t, os.Remove(filepath.Join(writer.opts.basePath, BloomFileName)))
+
+	r, err := NewSSTableReader(ReadBasePath(writer.opts.basePath), ReadWithNegativeCache(10))
+	require.NoError(t, err)
+	defer closeReader(t, r)
+	reader := r.(*SSTableReader)
+
+	absent := intToByteSlice(1000)
+	for i := 0; i < 3; i++ {
+		_, err = reader.Get(absent)
+		assert.ErrorIs(t, err, ErrKeyNotFound)
+	}
+	contains, err := reader.Contains(absent)
+	require.NoError(t, err)
+	assert.False(t, contains)
+	assert.Equal(t, ReaderStats{NegativeCacheHits: 3, NegativeCacheMisses: 1}, reader.Stats())
+
+	// existing keys are never cached
+	k, v := getKeyValueAsBytes(42)
+	for i := 0; i < 2; i++ {
+		actual, err := reader.Get(k)
+		require.NoError(t, err)
+		assert.Equal(t, v, actual)
+	}
+	contains, err = reader.Contains(k)
+	require.NoError(t, err)
+	assert.True(t, contains)
+	assert.Equal(t, ReaderStats{NegativeCacheHits: 3, NegativeCacheMisses: 4}, reader.Stats())
+
+	// clones share the cache
+	clone, err := reader.Clone()
+	require.NoError(t, err)
+	defer closeReader(t, clone)
+	_, err = clone.Get(absent)
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+	assert.Equal(t, uint64(4), reader.Stats().NegativeCacheHits)
+}
+
+func TestReaderNegativeCacheWithVersions(t *testing.T) {
+	tmpDir := writeVersionedTable(t)
+	defer func() { require.NoError(t, os.RemoveAll(tmpDir)) }()
+
+	// key 5 only exists as of seq 30
+	r, err := NewSSTableReader(ReadBasePath(tmpDir), ReadAsOfSeq(20), ReadWithNegativeCache(10))
+	require.NoError(t, err)
+	defer closeReader(t, r)
+	for i := 0; i < 2; i++ {
+		_, err = r.Get(intToByteSlice(5))
+		assert.ErrorIs(t, err, ErrKeyNotFound)
+	}
+	assert.Equal(t, uint64(1), r.(*SSTableReader).Stats().NegativeCacheHits)
+}
+
+func TestReaderNegativeCacheDisabledByDefault(t *testing.T) {
+	reader, err := NewSSTableReader(ReadBasePath("test_files/SimpleWriteHappyPathSSTable"))
+	require.NoError(t, err)
+	defer closeReader(t, reader)
+	_, err = reader.Get([]byte{42})
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+	assert.Equal(t, ReaderStats{}, reader.(*SSTableReader).Stats())
+
+	_, err = NewSSTableReader(ReadBasePath("test_files/SimpleWriteHappyPathSSTable"), ReadWithNegativeCache(-1))
+	assert.Error(t, err)
+}
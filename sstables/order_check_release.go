@@ -0,0 +1,6 @@
+//go:build !sstables_debug
+
+package sstables
+
+// debugOrderCheck keeps the key order check of the writer enabled despite UnsafeSkipOrderCheck
+const debugOrderCheck = false
@@ -0,0 +1,230 @@
+package sstables
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"github.com/thomasjungblut/go-sstables/skiplist"
+	"github.com/thomasjungblut/go-sstables/sstables/proto"
+	"golang.org/x/exp/slices"
+)
+
+// ErrOverlappingPartitions is returned by NewPartitionedReader when the key ranges of two tables in the manifest overlap.
+var ErrOverlappingPartitions = errors.New("key ranges of the partitions overlap")
+
+type partition struct {
+	minKey []byte
+	maxKey []byte
+	reader SSTableReaderI
+}
+
+// PartitionedReader reads the tables of a manifest, as written by the SplittingSSTableWriter, like a single table.
+// The key ranges of the tables don't overlap, so Get and Contains are routed to the single table whose range covers the
+// key with a binary search, and the scans concatenate the scans of the tables in key order.
+type PartitionedReader struct {
+	basePath   string
+	partitions []partition
+	comp       skiplist.Comparator[[]byte]
+}
+
+// find returns the partition whose key range covers the key, nil if there is none
+func (p *PartitionedReader) find(key []byte) *partition {
+	i := p.firstPartitionNotBefore(key)
+	if i == len(p.partitions) || p.comp.Compare(p.partitions[i].minKey, key) > 0 {
+		return nil
+	}
+	return &p.partitions[i]
+}
+
+// firstPartitionNotBefore returns the index of the first partition whose max key is not lower than the key
+func (p *PartitionedReader) firstPartitionNotBefore(key []byte) int {
+	return sort.Search(len(p.partitions), func(i int) bool {
+		return p.comp.Compare(p.partitions[i].maxKey, key) >= 0
+	})
+}
+
+func (p *PartitionedReader) Contains(key []byte) (bool, error) {
+	part := p.find(key)
+	if part == nil {
+		return false, nil
+	}
+	return part.reader.Contains(key)
+}
+
+func (p *PartitionedReader) Get(key []byte) ([]byte, error) {
+	part := p.find(key)
+	if part == nil {
+		return nil, ErrKeyNotFound
+	}
+	return part.reader.Get(key)
+}
+
+func (p *PartitionedReader) Scan() (SSTableIteratorI, error) {
+	scans := make([]func() (SSTableIteratorI, error), len(p.partitions))
+	for i, part := range p.partitions {
+		scans[i] = part.reader.Scan
+	}
+	return &concatIterator{scans: scans}, nil
+}
+
+func (p *PartitionedReader) ScanStartingAt(key []byte) (SSTableIteratorI, error) {
+	var scans []func() (SSTableIteratorI, error)
+	for i := p.firstPartitionNotBefore(key); i < len(p.partitions); i++ {
+		reader := p.partitions[i].reader
+		if len(scans) == 0 {
+			scans = append(scans, func() (SSTableIteratorI, error) {
+				return reader.ScanStartingAt(key)
+			})
+		} else {
+			scans = append(scans, reader.Scan)
+		}
+	}
+	return &concatIterator{scans: scans}, nil
+}
+
+func (p *PartitionedReader) ScanRange(keyLower []byte, keyHigher []byte) (SSTableIteratorI, error) {
+	if p.comp.Compare(keyLower, keyHigher) > 0 {
+		return nil, fmt.Errorf("error in partitioned sstable '%s' in ScanRange: keyHigher is lower than keyLower", p.basePath)
+	}
+
+	var scans []func() (SSTableIteratorI, error)
+	for i := p.firstPartitionNotBefore(keyLower); i < len(p.partitions) && p.comp.Compare(p.partitions[i].minKey, keyHigher) <= 0; i++ {
+		reader := p.partitions[i].reader
+		scans = append(scans, func() (SSTableIteratorI, error) {
+			return reader.ScanRange(keyLower, keyHigher)
+		})
+	}
+	return &concatIterator{scans: scans}, nil
+}
+
+func (p *PartitionedReader) Close() (err error) {
+	for _, part := range p.partitions {
+		err = errors.Join(err, part.reader.Close())
+	}
+	return
+}
+
+// MetaData returns the sum over all tables, with the key range from the first to the last table.
+func (p *PartitionedReader) MetaData() *proto.MetaData {
+	sum := &proto.MetaData{}
+	for _, part := range p.partitions {
+		m := part.reader.MetaData()
+		sum.NumRecords += m.NumRecords
+		sum.DataBytes += m.DataBytes
+		sum.IndexBytes += m.IndexBytes
+		sum.TotalBytes += m.TotalBytes
+		sum.Version = m.Version
+	}
+
+	if len(p.partitions) > 0 {
+		sum.MinKey = p.partitions[0].minKey
+		sum.MaxKey = p.partitions[len(p.partitions)-1].maxKey
+	}
+	return sum
+}
+
+// BasePath returns the directory of the manifest.
+func (p *PartitionedReader) BasePath() string {
+	return p.basePath
+}
+
+// concatIterator returns the records of the iterators one after another, every iterator is only created once the
+// previous one is exhausted.
+type concatIterator struct {
+	scans   []func() (SSTableIteratorI, error)
+	current SSTableIteratorI
+}
+
+func (c *concatIterator) Next() ([]byte, []byte, error) {
+	for {
+		if c.current == nil {
+			if len(c.scans) == 0 {
+				return nil, nil, Done
+			}
+			it, err := c.scans[0]()
+			if err != nil {
+				return nil, nil, err
+			}
+			c.current = it
+			c.scans = c.scans[1:]
+		}
+
+		k, v, err := c.current.Next()
+		if errors.Is(err, Done) {
+			c.current = nil
+			continue
+		}
+		return k, v, err
+	}
+}
+
+func (c *concatIterator) SequenceNumber() uint64 {
+	if it, ok := c.current.(SequencedIteratorI); ok {
+		return it.SequenceNumber()
+	}
+	return 0
+}
+
+// NewPartitionedReader opens the tables of the manifest that a SplittingSSTableWriter wrote into basePath,
+// see NewPartitionedReaderWithManifest.
+func NewPartitionedReader(basePath string, readerOptions ...ReadOption) (*PartitionedReader, error) {
+	manifest, err := ReadManifest(basePath)
+	if err != nil {
+		return nil, err
+	}
+	return NewPartitionedReaderWithManifest(basePath, manifest, readerOptions...)
+}
+
+// NewPartitionedReaderWithManifest opens all tables of the manifest with the given reader options, relative paths are
+// resolved against basePath. Tables without records are skipped. The key ranges are verified with the comparator of
+// ReadWithKeyComparator: a table whose min key is greater than its max key returns an error, overlapping key ranges
+// return ErrOverlappingPartitions.
+func NewPartitionedReaderWithManifest(basePath string, manifest *proto.Manifest, readerOptions ...ReadOption) (*PartitionedReader, error) {
+	opts := &SSTableReaderOptions{}
+	for _, readOption := range readerOptions {
+		readOption(opts)
+	}
+	comp := opts.keyComparator
+	if comp == nil {
+		comp = skiplist.BytesComparator{}
+	}
+
+	var entries []*proto.ManifestEntry
+	for _, entry := range manifest.Tables {
+		if entry.NumRecords == 0 {
+			continue
+		}
+		if comp.Compare(entry.MinKey, entry.MaxKey) > 0 {
+			return nil, fmt.Errorf("error in manifest of '%s': min key of table '%s' is greater than its max key", basePath, entry.Path)
+		}
+		entries = append(entries, entry)
+	}
+
+	slices.SortFunc(entries, func(a, b *proto.ManifestEntry) int {
+		return comp.Compare(a.MinKey, b.MinKey)
+	})
+	for i := 1; i < len(entries); i++ {
+		if comp.Compare(entries[i-1].MaxKey, entries[i].MinKey) >= 0 {
+			return nil, fmt.Errorf("error in manifest of '%s', tables '%s' and '%s': %w",
+				basePath, entries[i-1].Path, entries[i].Path, ErrOverlappingPartitions)
+		}
+	}
+
+	reader := &PartitionedReader{basePath: basePath, comp: comp}
+	for _, entry := range entries {
+		path := entry.Path
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(basePath, path)
+		}
+
+		r, err := NewSSTableReader(append(slices.Clone(readerOptions), ReadBasePath(path))...)
+		if err != nil {
+			return nil, errors.Join(fmt.Errorf("error while opening partition '%s': %w", path, err), reader.Close())
+		}
+		reader.partitions = append(reader.partitions, partition{minKey: entry.MinKey, maxKey: entry.MaxKey, reader: r})
+	}
+
+	return reader, nil
+}
@@ -0,0 +1,112 @@
+package sstables
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thomasjungblut/go-sstables/sstables/proto"
+)
+
+// writePartitionedTables writes the integers from 0 to n into split tables and returns their base path
+func writePartitionedTables(t *testing.T, n int) string {
+	writer := newTestSplittingSSTableWriter(t, WriteMaxBytesPerTable(1024))
+	require.NoError(t, writer.Open())
+	for i := 0; i < n; i++ {
+		k, v := getKeyValueAsBytes(i)
+		require.NoError(t, writer.WriteNext(k, v))
+	}
+	require.NoError(t, writer.Close())
+	require.Greater(t, len(writer.Manifest().Tables), 2)
+	return writer.opts.basePath
+}
+
+func TestPartitionedReader(t *testing.T) {
+	basePath := writePartitionedTables(t, 500)
+	var r SSTableReaderI
+	r, err := NewPartitionedReader(basePath)
+	require.NoError(t, err)
+	defer closeReader(t, r)
+
+	for i := 0; i < 500; i++ {
+		k, v := getKeyValueAsBytes(i)
+		actual, err := r.Get(k)
+		require.NoError(t, err)
+		assert.Equal(t, v, actual)
+		ok, err := r.Contains(k)
+		require.NoError(t, err)
+		assert.True(t, ok)
+	}
+	for _, i := range []int{-1, 500, 1000} {
+		_, err := r.Get(intToByteSlice(i))
+		assert.Equal(t, ErrKeyNotFound, err)
+		ok, err := r.Contains(intToByteSlice(i))
+		require.NoError(t, err)
+		assert.False(t, ok)
+	}
+
+	var expected []int
+	for i := 0; i < 500; i++ {
+		expected = append(expected, i)
+	}
+	it, err := r.Scan()
+	require.NoError(t, err)
+	assertIteratorMatchesSlice(t, it, expected)
+
+	it, err = r.ScanStartingAt(intToByteSlice(123))
+	require.NoError(t, err)
+	assertIteratorMatchesSlice(t, it, expected[123:])
+
+	it, err = r.ScanRange(intToByteSlice(42), intToByteSlice(321))
+	require.NoError(t, err)
+	assertIteratorMatchesSlice(t, it, expected[42:322])
+	_, err = r.ScanRange(intToByteSlice(321), intToByteSlice(42))
+	assert.Error(t, err)
+
+	md := r.MetaData()
+	assert.Equal(t, uint64(500), md.NumRecords)
+	assert.Equal(t, intToByteSlice(0), md.MinKey)
+	assert.Equal(t, intToByteSlice(499), md.MaxKey)
+	assert.Equal(t, basePath, r.BasePath())
+}
+
+func TestPartitionedReaderValidatesManifest(t *testing.T) {
+	basePath := writePartitionedTables(t, 500)
+	manifest, err := ReadManifest(basePath)
+	require.NoError(t, err)
+
+	// the order of the manifest doesn't matter
+	tables := manifest.Tables
+	reversed := &proto.Manifest{}
+	for i := len(tables) - 1; i >= 0; i-- {
+		reversed.Tables = append(reversed.Tables, tables[i])
+	}
+	r, err := NewPartitionedReaderWithManifest(basePath, reversed)
+	require.NoError(t, err)
+	v, err := r.Get(intToByteSlice(250))
+	require.NoError(t, err)
+	assert.Equal(t, intToByteSlice(251), v)
+	closeReader(t, r)
+
+	overlapping := &proto.Manifest{Tables: []*proto.ManifestEntry{tables[0], {
+		Path:       tables[1].Path,
+		MinKey:     tables[0].MaxKey,
+		MaxKey:     tables[1].MaxKey,
+		NumRecords: tables[1].NumRecords,
+	}}}
+	_, err = NewPartitionedReaderWithManifest(basePath, overlapping)
+	assert.ErrorIs(t, err, ErrOverlappingPartitions)
+
+	inverted := &proto.Manifest{Tables: []*proto.ManifestEntry{{
+		Path:       tables[0].Path,
+		MinKey:     tables[0].MaxKey,
+		MaxKey:     tables[0].MinKey,
+		NumRecords: tables[0].NumRecords,
+	}}}
+	_, err = NewPartitionedReaderWithManifest(basePath, inverted)
+	assert.ErrorContains(t, err, "greater than its max key")
+
+	missing := &proto.Manifest{Tables: []*proto.ManifestEntry{tables[0], {Path: "missing", MinKey: []byte{1, 0, 0, 0}, MaxKey: []byte{2, 0, 0, 0}, NumRecords: 1}}}
+	_, err = NewPartitionedReaderWithManifest(basePath, missing)
+	assert.ErrorContains(t, err, "error while opening partition")
+}
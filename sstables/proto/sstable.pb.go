@@ -25,10 +25,13 @@ type IndexEntry struct {
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Key         []byte `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
-	ValueOffset uint64 `protobuf:"varint,2,opt,name=valueOffset,proto3" json:"valueOffset,omitempty"`
-	Checksum    uint64 `protobuf:"varint,3,opt,name=checksum,proto3" json:"checksum,omitempty"` // a golang crc-64 checksum of the respective dataEntry
-	Tombstoned  bool   `protobuf:"varint,4,opt,name=tombstoned,proto3" json:"tombstoned,omitempty"`
+	Key                []byte `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	ValueOffset        uint64 `protobuf:"varint,2,opt,name=valueOffset,proto3" json:"valueOffset,omitempty"`
+	Checksum           uint64 `protobuf:"varint,3,opt,name=checksum,proto3" json:"checksum,omitempty"` // a golang crc-64 checksum of the respective dataEntry
+	Tombstoned         bool   `protobuf:"varint,4,opt,name=tombstoned,proto3" json:"tombstoned,omitempty"`
+	NullValue          bool   `protobuf:"varint,5,opt,name=nullValue,proto3" json:"nullValue,omitempty"`                   // true when the value was written as nil, as opposed to an empty value
+	SharedPrefixLength uint32 `protobuf:"varint,6,opt,name=sharedPrefixLength,proto3" json:"sharedPrefixLength,omitempty"` // the length of the prefix shared with the previous key, the key only contains the remaining suffix
+	SequenceNumber     uint64 `protobuf:"varint,7,opt,name=sequenceNumber,proto3" json:"sequenceNumber,omitempty"`         // the version of the record as given to WriteNextWithSeq, zero otherwise
 }
 
 func (x *IndexEntry) Reset() {
@@ -91,6 +94,139 @@ func (x *IndexEntry) GetTombstoned() bool {
 	return false
 }
 
+func (x *IndexEntry) GetNullValue() bool {
+	if x != nil {
+		return x.NullValue
+	}
+	return false
+}
+
+func (x *IndexEntry) GetSharedPrefixLength() uint32 {
+	if x != nil {
+		return x.SharedPrefixLength
+	}
+	return 0
+}
+
+func (x *IndexEntry) GetSequenceNumber() uint64 {
+	if x != nil {
+		return x.SequenceNumber
+	}
+	return 0
+}
+
+// a bloom filter over the keys from firstKey up to the first key of the next partition
+type BloomPartition struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	FirstKey []byte `protobuf:"bytes,1,opt,name=firstKey,proto3" json:"firstKey,omitempty"`
+	Filter   []byte `protobuf:"bytes,2,opt,name=filter,proto3" json:"filter,omitempty"` // the binary marshalled bloom filter
+}
+
+func (x *BloomPartition) Reset() {
+	*x = BloomPartition{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_sstables_proto_sstable_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *BloomPartition) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BloomPartition) ProtoMessage() {}
+
+func (x *BloomPartition) ProtoReflect() protoreflect.Message {
+	mi := &file_sstables_proto_sstable_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BloomPartition.ProtoReflect.Descriptor instead.
+func (*BloomPartition) Descriptor() ([]byte, []int) {
+	return file_sstables_proto_sstable_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *BloomPartition) GetFirstKey() []byte {
+	if x != nil {
+		return x.FirstKey
+	}
+	return nil
+}
+
+func (x *BloomPartition) GetFilter() []byte {
+	if x != nil {
+		return x.Filter
+	}
+	return nil
+}
+
+// every nth index entry, pointing to the offset of its record in the index file
+type SummaryEntry struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Key         []byte `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	IndexOffset uint64 `protobuf:"varint,2,opt,name=indexOffset,proto3" json:"indexOffset,omitempty"`
+}
+
+func (x *SummaryEntry) Reset() {
+	*x = SummaryEntry{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_sstables_proto_sstable_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SummaryEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SummaryEntry) ProtoMessage() {}
+
+func (x *SummaryEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_sstables_proto_sstable_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SummaryEntry.ProtoReflect.Descriptor instead.
+func (*SummaryEntry) Descriptor() ([]byte, []int) {
+	return file_sstables_proto_sstable_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *SummaryEntry) GetKey() []byte {
+	if x != nil {
+		return x.Key
+	}
+	return nil
+}
+
+func (x *SummaryEntry) GetIndexOffset() uint64 {
+	if x != nil {
+		return x.IndexOffset
+	}
+	return 0
+}
+
 // deprecated, it's unnecessary overhead to marshal the bytes once more
 type DataEntry struct {
 	state         protoimpl.MessageState
@@ -103,7 +239,7 @@ type DataEntry struct {
 func (x *DataEntry) Reset() {
 	*x = DataEntry{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_sstables_proto_sstable_proto_msgTypes[1]
+		mi := &file_sstables_proto_sstable_proto_msgTypes[3]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -116,7 +252,7 @@ func (x *DataEntry) String() string {
 func (*DataEntry) ProtoMessage() {}
 
 func (x *DataEntry) ProtoReflect() protoreflect.Message {
-	mi := &file_sstables_proto_sstable_proto_msgTypes[1]
+	mi := &file_sstables_proto_sstable_proto_msgTypes[3]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -129,7 +265,7 @@ func (x *DataEntry) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use DataEntry.ProtoReflect.Descriptor instead.
 func (*DataEntry) Descriptor() ([]byte, []int) {
-	return file_sstables_proto_sstable_proto_rawDescGZIP(), []int{1}
+	return file_sstables_proto_sstable_proto_rawDescGZIP(), []int{3}
 }
 
 func (x *DataEntry) GetValue() []byte {
@@ -144,21 +280,34 @@ type MetaData struct {
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	NumRecords     uint64 `protobuf:"varint,1,opt,name=numRecords,proto3" json:"numRecords,omitempty"`
-	MinKey         []byte `protobuf:"bytes,2,opt,name=minKey,proto3" json:"minKey,omitempty"`
-	MaxKey         []byte `protobuf:"bytes,3,opt,name=maxKey,proto3" json:"maxKey,omitempty"`
-	DataBytes      uint64 `protobuf:"varint,4,opt,name=dataBytes,proto3" json:"dataBytes,omitempty"`
-	IndexBytes     uint64 `protobuf:"varint,5,opt,name=indexBytes,proto3" json:"indexBytes,omitempty"`
-	TotalBytes     uint64 `protobuf:"varint,6,opt,name=totalBytes,proto3" json:"totalBytes,omitempty"`
-	Version        uint32 `protobuf:"varint,7,opt,name=version,proto3" json:"version,omitempty"` // currently version 1, the default is version 0 with protos as values
-	SkippedRecords uint64 `protobuf:"varint,8,opt,name=skippedRecords,proto3" json:"skippedRecords,omitempty"`
-	NullValues     uint64 `protobuf:"varint,9,opt,name=nullValues,proto3" json:"nullValues,omitempty"` // in simpleDB that corresponds to the number of tombstones
+	NumRecords             uint64   `protobuf:"varint,1,opt,name=numRecords,proto3" json:"numRecords,omitempty"`
+	MinKey                 []byte   `protobuf:"bytes,2,opt,name=minKey,proto3" json:"minKey,omitempty"`
+	MaxKey                 []byte   `protobuf:"bytes,3,opt,name=maxKey,proto3" json:"maxKey,omitempty"`
+	DataBytes              uint64   `protobuf:"varint,4,opt,name=dataBytes,proto3" json:"dataBytes,omitempty"`
+	IndexBytes             uint64   `protobuf:"varint,5,opt,name=indexBytes,proto3" json:"indexBytes,omitempty"`
+	TotalBytes             uint64   `protobuf:"varint,6,opt,name=totalBytes,proto3" json:"totalBytes,omitempty"`
+	Version                uint32   `protobuf:"varint,7,opt,name=version,proto3" json:"version,omitempty"` // currently version 2, the default is version 0 with protos as values
+	SkippedRecords         uint64   `protobuf:"varint,8,opt,name=skippedRecords,proto3" json:"skippedRecords,omitempty"`
+	NullValues             uint64   `protobuf:"varint,9,opt,name=nullValues,proto3" json:"nullValues,omitempty"`                          // in simpleDB that corresponds to the number of tombstones
+	IndexChecksum          uint64   `protobuf:"varint,10,opt,name=indexChecksum,proto3" json:"indexChecksum,omitempty"`                   // a golang crc-64 checksum over all index entries, zero for tables written without it
+	CreatedAtUnixMillis    int64    `protobuf:"varint,11,opt,name=createdAtUnixMillis,proto3" json:"createdAtUnixMillis,omitempty"`       // the time the table was written, as milliseconds since the unix epoch
+	UserTag                []byte   `protobuf:"bytes,12,opt,name=userTag,proto3" json:"userTag,omitempty"`                                // an arbitrary label supplied by the writer
+	ContentHash            uint64   `protobuf:"varint,13,opt,name=contentHash,proto3" json:"contentHash,omitempty"`                       // a golang crc-64 over all keys and value checksums in write order, independent of compression
+	IndexRestartInterval   uint32   `protobuf:"varint,14,opt,name=indexRestartInterval,proto3" json:"indexRestartInterval,omitempty"`     // non-zero when index keys are prefix compressed, every nth key is stored in full
+	Versioned              bool     `protobuf:"varint,15,opt,name=versioned,proto3" json:"versioned,omitempty"`                           // true when keys can have multiple versions, ordered by ascending sequence numbers
+	ComparatorName         string   `protobuf:"bytes,16,opt,name=comparatorName,proto3" json:"comparatorName,omitempty"`                  // the identity of the key comparator the table is sorted by, empty when it is unknown
+	ValueSizeHistogram     []uint64 `protobuf:"varint,17,rep,packed,name=valueSizeHistogram,proto3" json:"valueSizeHistogram,omitempty"`  // the number of values per power of two size bucket, trailing empty buckets are omitted
+	ScanOnly               bool     `protobuf:"varint,18,opt,name=scanOnly,proto3" json:"scanOnly,omitempty"`                             // true when the index entries are stored after their values in the data file and the index file is empty
+	BloomKeyTransformed    bool     `protobuf:"varint,19,opt,name=bloomKeyTransformed,proto3" json:"bloomKeyTransformed,omitempty"`       // true when the bloom filter contains transformed keys, see BloomKeyTransform
+	BloomPartitions        uint32   `protobuf:"varint,20,opt,name=bloomPartitions,proto3" json:"bloomPartitions,omitempty"`               // the number of bloom filter partitions in the bloom partition file, zero for a single bloom filter
+	ValueChecksumsDisabled bool     `protobuf:"varint,21,opt,name=valueChecksumsDisabled,proto3" json:"valueChecksumsDisabled,omitempty"` // true when the values were written without checksums, all checksums in the index are zero
+	ValueChecksumName      string   `protobuf:"bytes,22,opt,name=valueChecksumName,proto3" json:"valueChecksumName,omitempty"`            // the name of the function the value checksums were computed with, empty for a golang crc-64 with the ISO polynomial
 }
 
 func (x *MetaData) Reset() {
 	*x = MetaData{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_sstables_proto_sstable_proto_msgTypes[2]
+		mi := &file_sstables_proto_sstable_proto_msgTypes[4]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -171,7 +320,7 @@ func (x *MetaData) String() string {
 func (*MetaData) ProtoMessage() {}
 
 func (x *MetaData) ProtoReflect() protoreflect.Message {
-	mi := &file_sstables_proto_sstable_proto_msgTypes[2]
+	mi := &file_sstables_proto_sstable_proto_msgTypes[4]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -184,7 +333,7 @@ func (x *MetaData) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use MetaData.ProtoReflect.Descriptor instead.
 func (*MetaData) Descriptor() ([]byte, []int) {
-	return file_sstables_proto_sstable_proto_rawDescGZIP(), []int{2}
+	return file_sstables_proto_sstable_proto_rawDescGZIP(), []int{4}
 }
 
 func (x *MetaData) GetNumRecords() uint64 {
@@ -250,44 +399,325 @@ func (x *MetaData) GetNullValues() uint64 {
 	return 0
 }
 
+func (x *MetaData) GetIndexChecksum() uint64 {
+	if x != nil {
+		return x.IndexChecksum
+	}
+	return 0
+}
+
+func (x *MetaData) GetCreatedAtUnixMillis() int64 {
+	if x != nil {
+		return x.CreatedAtUnixMillis
+	}
+	return 0
+}
+
+func (x *MetaData) GetUserTag() []byte {
+	if x != nil {
+		return x.UserTag
+	}
+	return nil
+}
+
+func (x *MetaData) GetContentHash() uint64 {
+	if x != nil {
+		return x.ContentHash
+	}
+	return 0
+}
+
+func (x *MetaData) GetIndexRestartInterval() uint32 {
+	if x != nil {
+		return x.IndexRestartInterval
+	}
+	return 0
+}
+
+func (x *MetaData) GetVersioned() bool {
+	if x != nil {
+		return x.Versioned
+	}
+	return false
+}
+
+func (x *MetaData) GetComparatorName() string {
+	if x != nil {
+		return x.ComparatorName
+	}
+	return ""
+}
+
+func (x *MetaData) GetValueSizeHistogram() []uint64 {
+	if x != nil {
+		return x.ValueSizeHistogram
+	}
+	return nil
+}
+
+func (x *MetaData) GetScanOnly() bool {
+	if x != nil {
+		return x.ScanOnly
+	}
+	return false
+}
+
+func (x *MetaData) GetBloomKeyTransformed() bool {
+	if x != nil {
+		return x.BloomKeyTransformed
+	}
+	return false
+}
+
+func (x *MetaData) GetBloomPartitions() uint32 {
+	if x != nil {
+		return x.BloomPartitions
+	}
+	return 0
+}
+
+func (x *MetaData) GetValueChecksumsDisabled() bool {
+	if x != nil {
+		return x.ValueChecksumsDisabled
+	}
+	return false
+}
+
+func (x *MetaData) GetValueChecksumName() string {
+	if x != nil {
+		return x.ValueChecksumName
+	}
+	return ""
+}
+
+// a table of a SplittingSSTableWriter, the path is relative to the directory of the manifest
+type ManifestEntry struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Path       string `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	MinKey     []byte `protobuf:"bytes,2,opt,name=minKey,proto3" json:"minKey,omitempty"`
+	MaxKey     []byte `protobuf:"bytes,3,opt,name=maxKey,proto3" json:"maxKey,omitempty"`
+	NumRecords uint64 `protobuf:"varint,4,opt,name=numRecords,proto3" json:"numRecords,omitempty"`
+	DataBytes  uint64 `protobuf:"varint,5,opt,name=dataBytes,proto3" json:"dataBytes,omitempty"`
+}
+
+func (x *ManifestEntry) Reset() {
+	*x = ManifestEntry{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_sstables_proto_sstable_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ManifestEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ManifestEntry) ProtoMessage() {}
+
+func (x *ManifestEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_sstables_proto_sstable_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ManifestEntry.ProtoReflect.Descriptor instead.
+func (*ManifestEntry) Descriptor() ([]byte, []int) {
+	return file_sstables_proto_sstable_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ManifestEntry) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *ManifestEntry) GetMinKey() []byte {
+	if x != nil {
+		return x.MinKey
+	}
+	return nil
+}
+
+func (x *ManifestEntry) GetMaxKey() []byte {
+	if x != nil {
+		return x.MaxKey
+	}
+	return nil
+}
+
+func (x *ManifestEntry) GetNumRecords() uint64 {
+	if x != nil {
+		return x.NumRecords
+	}
+	return 0
+}
+
+func (x *ManifestEntry) GetDataBytes() uint64 {
+	if x != nil {
+		return x.DataBytes
+	}
+	return 0
+}
+
+// the tables of a SplittingSSTableWriter, ordered by their key ranges
+type Manifest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Tables []*ManifestEntry `protobuf:"bytes,1,rep,name=tables,proto3" json:"tables,omitempty"`
+}
+
+func (x *Manifest) Reset() {
+	*x = Manifest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_sstables_proto_sstable_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Manifest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Manifest) ProtoMessage() {}
+
+func (x *Manifest) ProtoReflect() protoreflect.Message {
+	mi := &file_sstables_proto_sstable_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Manifest.ProtoReflect.Descriptor instead.
+func (*Manifest) Descriptor() ([]byte, []int) {
+	return file_sstables_proto_sstable_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *Manifest) GetTables() []*ManifestEntry {
+	if x != nil {
+		return x.Tables
+	}
+	return nil
+}
+
 var File_sstables_proto_sstable_proto protoreflect.FileDescriptor
 
 var file_sstables_proto_sstable_proto_rawDesc = []byte{
 	0x0a, 0x1c, 0x73, 0x73, 0x74, 0x61, 0x62, 0x6c, 0x65, 0x73, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f,
 	0x2f, 0x73, 0x73, 0x74, 0x61, 0x62, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x05,
-	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x7c, 0x0a, 0x0a, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x45, 0x6e,
-	0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c,
-	0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x20, 0x0a, 0x0b, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x4f, 0x66,
-	0x66, 0x73, 0x65, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0b, 0x76, 0x61, 0x6c, 0x75,
-	0x65, 0x4f, 0x66, 0x66, 0x73, 0x65, 0x74, 0x12, 0x1a, 0x0a, 0x08, 0x63, 0x68, 0x65, 0x63, 0x6b,
-	0x73, 0x75, 0x6d, 0x18, 0x03, 0x20, 0x01, 0x28, 0x04, 0x52, 0x08, 0x63, 0x68, 0x65, 0x63, 0x6b,
-	0x73, 0x75, 0x6d, 0x12, 0x1e, 0x0a, 0x0a, 0x74, 0x6f, 0x6d, 0x62, 0x73, 0x74, 0x6f, 0x6e, 0x65,
-	0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0a, 0x74, 0x6f, 0x6d, 0x62, 0x73, 0x74, 0x6f,
-	0x6e, 0x65, 0x64, 0x22, 0x21, 0x0a, 0x09, 0x44, 0x61, 0x74, 0x61, 0x45, 0x6e, 0x74, 0x72, 0x79,
-	0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52,
-	0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x22, 0x9a, 0x02, 0x0a, 0x08, 0x4d, 0x65, 0x74, 0x61, 0x44,
-	0x61, 0x74, 0x61, 0x12, 0x1e, 0x0a, 0x0a, 0x6e, 0x75, 0x6d, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64,
-	0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0a, 0x6e, 0x75, 0x6d, 0x52, 0x65, 0x63, 0x6f,
-	0x72, 0x64, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x6d, 0x69, 0x6e, 0x4b, 0x65, 0x79, 0x18, 0x02, 0x20,
-	0x01, 0x28, 0x0c, 0x52, 0x06, 0x6d, 0x69, 0x6e, 0x4b, 0x65, 0x79, 0x12, 0x16, 0x0a, 0x06, 0x6d,
-	0x61, 0x78, 0x4b, 0x65, 0x79, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x06, 0x6d, 0x61, 0x78,
-	0x4b, 0x65, 0x79, 0x12, 0x1c, 0x0a, 0x09, 0x64, 0x61, 0x74, 0x61, 0x42, 0x79, 0x74, 0x65, 0x73,
-	0x18, 0x04, 0x20, 0x01, 0x28, 0x04, 0x52, 0x09, 0x64, 0x61, 0x74, 0x61, 0x42, 0x79, 0x74, 0x65,
-	0x73, 0x12, 0x1e, 0x0a, 0x0a, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x42, 0x79, 0x74, 0x65, 0x73, 0x18,
-	0x05, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0a, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x42, 0x79, 0x74, 0x65,
-	0x73, 0x12, 0x1e, 0x0a, 0x0a, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x42, 0x79, 0x74, 0x65, 0x73, 0x18,
-	0x06, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0a, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x42, 0x79, 0x74, 0x65,
-	0x73, 0x12, 0x18, 0x0a, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x07, 0x20, 0x01,
-	0x28, 0x0d, 0x52, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x26, 0x0a, 0x0e, 0x73,
-	0x6b, 0x69, 0x70, 0x70, 0x65, 0x64, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x73, 0x18, 0x08, 0x20,
-	0x01, 0x28, 0x04, 0x52, 0x0e, 0x73, 0x6b, 0x69, 0x70, 0x70, 0x65, 0x64, 0x52, 0x65, 0x63, 0x6f,
-	0x72, 0x64, 0x73, 0x12, 0x1e, 0x0a, 0x0a, 0x6e, 0x75, 0x6c, 0x6c, 0x56, 0x61, 0x6c, 0x75, 0x65,
-	0x73, 0x18, 0x09, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0a, 0x6e, 0x75, 0x6c, 0x6c, 0x56, 0x61, 0x6c,
-	0x75, 0x65, 0x73, 0x42, 0x36, 0x5a, 0x34, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f,
-	0x6d, 0x2f, 0x74, 0x68, 0x6f, 0x6d, 0x61, 0x73, 0x6a, 0x75, 0x6e, 0x67, 0x62, 0x6c, 0x75, 0x74,
-	0x2f, 0x67, 0x6f, 0x2d, 0x73, 0x73, 0x74, 0x61, 0x62, 0x6c, 0x65, 0x73, 0x2f, 0x73, 0x73, 0x74,
-	0x61, 0x62, 0x6c, 0x65, 0x73, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x06, 0x70, 0x72, 0x6f,
-	0x74, 0x6f, 0x33,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0xf2, 0x01, 0x0a, 0x0a, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x45,
+	0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x0c, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x20, 0x0a, 0x0b, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x4f,
+	0x66, 0x66, 0x73, 0x65, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0b, 0x76, 0x61, 0x6c,
+	0x75, 0x65, 0x4f, 0x66, 0x66, 0x73, 0x65, 0x74, 0x12, 0x1a, 0x0a, 0x08, 0x63, 0x68, 0x65, 0x63,
+	0x6b, 0x73, 0x75, 0x6d, 0x18, 0x03, 0x20, 0x01, 0x28, 0x04, 0x52, 0x08, 0x63, 0x68, 0x65, 0x63,
+	0x6b, 0x73, 0x75, 0x6d, 0x12, 0x1e, 0x0a, 0x0a, 0x74, 0x6f, 0x6d, 0x62, 0x73, 0x74, 0x6f, 0x6e,
+	0x65, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0a, 0x74, 0x6f, 0x6d, 0x62, 0x73, 0x74,
+	0x6f, 0x6e, 0x65, 0x64, 0x12, 0x1c, 0x0a, 0x09, 0x6e, 0x75, 0x6c, 0x6c, 0x56, 0x61, 0x6c, 0x75,
+	0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x08, 0x52, 0x09, 0x6e, 0x75, 0x6c, 0x6c, 0x56, 0x61, 0x6c,
+	0x75, 0x65, 0x12, 0x2e, 0x0a, 0x12, 0x73, 0x68, 0x61, 0x72, 0x65, 0x64, 0x50, 0x72, 0x65, 0x66,
+	0x69, 0x78, 0x4c, 0x65, 0x6e, 0x67, 0x74, 0x68, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x12,
+	0x73, 0x68, 0x61, 0x72, 0x65, 0x64, 0x50, 0x72, 0x65, 0x66, 0x69, 0x78, 0x4c, 0x65, 0x6e, 0x67,
+	0x74, 0x68, 0x12, 0x26, 0x0a, 0x0e, 0x73, 0x65, 0x71, 0x75, 0x65, 0x6e, 0x63, 0x65, 0x4e, 0x75,
+	0x6d, 0x62, 0x65, 0x72, 0x18, 0x07, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0e, 0x73, 0x65, 0x71, 0x75,
+	0x65, 0x6e, 0x63, 0x65, 0x4e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x22, 0x44, 0x0a, 0x0e, 0x42, 0x6c,
+	0x6f, 0x6f, 0x6d, 0x50, 0x61, 0x72, 0x74, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x1a, 0x0a, 0x08,
+	0x66, 0x69, 0x72, 0x73, 0x74, 0x4b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x08,
+	0x66, 0x69, 0x72, 0x73, 0x74, 0x4b, 0x65, 0x79, 0x12, 0x16, 0x0a, 0x06, 0x66, 0x69, 0x6c, 0x74,
+	0x65, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x06, 0x66, 0x69, 0x6c, 0x74, 0x65, 0x72,
+	0x22, 0x42, 0x0a, 0x0c, 0x53, 0x75, 0x6d, 0x6d, 0x61, 0x72, 0x79, 0x45, 0x6e, 0x74, 0x72, 0x79,
+	0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x03, 0x6b,
+	0x65, 0x79, 0x12, 0x20, 0x0a, 0x0b, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x4f, 0x66, 0x66, 0x73, 0x65,
+	0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0b, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x4f, 0x66,
+	0x66, 0x73, 0x65, 0x74, 0x22, 0x21, 0x0a, 0x09, 0x44, 0x61, 0x74, 0x61, 0x45, 0x6e, 0x74, 0x72,
+	0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c,
+	0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x22, 0xb6, 0x06, 0x0a, 0x08, 0x4d, 0x65, 0x74, 0x61,
+	0x44, 0x61, 0x74, 0x61, 0x12, 0x1e, 0x0a, 0x0a, 0x6e, 0x75, 0x6d, 0x52, 0x65, 0x63, 0x6f, 0x72,
+	0x64, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0a, 0x6e, 0x75, 0x6d, 0x52, 0x65, 0x63,
+	0x6f, 0x72, 0x64, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x6d, 0x69, 0x6e, 0x4b, 0x65, 0x79, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x0c, 0x52, 0x06, 0x6d, 0x69, 0x6e, 0x4b, 0x65, 0x79, 0x12, 0x16, 0x0a, 0x06,
+	0x6d, 0x61, 0x78, 0x4b, 0x65, 0x79, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x06, 0x6d, 0x61,
+	0x78, 0x4b, 0x65, 0x79, 0x12, 0x1c, 0x0a, 0x09, 0x64, 0x61, 0x74, 0x61, 0x42, 0x79, 0x74, 0x65,
+	0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x04, 0x52, 0x09, 0x64, 0x61, 0x74, 0x61, 0x42, 0x79, 0x74,
+	0x65, 0x73, 0x12, 0x1e, 0x0a, 0x0a, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x42, 0x79, 0x74, 0x65, 0x73,
+	0x18, 0x05, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0a, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x42, 0x79, 0x74,
+	0x65, 0x73, 0x12, 0x1e, 0x0a, 0x0a, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x42, 0x79, 0x74, 0x65, 0x73,
+	0x18, 0x06, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0a, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x42, 0x79, 0x74,
+	0x65, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x07, 0x20,
+	0x01, 0x28, 0x0d, 0x52, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x26, 0x0a, 0x0e,
+	0x73, 0x6b, 0x69, 0x70, 0x70, 0x65, 0x64, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x73, 0x18, 0x08,
+	0x20, 0x01, 0x28, 0x04, 0x52, 0x0e, 0x73, 0x6b, 0x69, 0x70, 0x70, 0x65, 0x64, 0x52, 0x65, 0x63,
+	0x6f, 0x72, 0x64, 0x73, 0x12, 0x1e, 0x0a, 0x0a, 0x6e, 0x75, 0x6c, 0x6c, 0x56, 0x61, 0x6c, 0x75,
+	0x65, 0x73, 0x18, 0x09, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0a, 0x6e, 0x75, 0x6c, 0x6c, 0x56, 0x61,
+	0x6c, 0x75, 0x65, 0x73, 0x12, 0x24, 0x0a, 0x0d, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x43, 0x68, 0x65,
+	0x63, 0x6b, 0x73, 0x75, 0x6d, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0d, 0x69, 0x6e, 0x64,
+	0x65, 0x78, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x73, 0x75, 0x6d, 0x12, 0x30, 0x0a, 0x13, 0x63, 0x72,
+	0x65, 0x61, 0x74, 0x65, 0x64, 0x41, 0x74, 0x55, 0x6e, 0x69, 0x78, 0x4d, 0x69, 0x6c, 0x6c, 0x69,
+	0x73, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x03, 0x52, 0x13, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64,
+	0x41, 0x74, 0x55, 0x6e, 0x69, 0x78, 0x4d, 0x69, 0x6c, 0x6c, 0x69, 0x73, 0x12, 0x18, 0x0a, 0x07,
+	0x75, 0x73, 0x65, 0x72, 0x54, 0x61, 0x67, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x07, 0x75,
+	0x73, 0x65, 0x72, 0x54, 0x61, 0x67, 0x12, 0x20, 0x0a, 0x0b, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x6e,
+	0x74, 0x48, 0x61, 0x73, 0x68, 0x18, 0x0d, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0b, 0x63, 0x6f, 0x6e,
+	0x74, 0x65, 0x6e, 0x74, 0x48, 0x61, 0x73, 0x68, 0x12, 0x32, 0x0a, 0x14, 0x69, 0x6e, 0x64, 0x65,
+	0x78, 0x52, 0x65, 0x73, 0x74, 0x61, 0x72, 0x74, 0x49, 0x6e, 0x74, 0x65, 0x72, 0x76, 0x61, 0x6c,
+	0x18, 0x0e, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x14, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x52, 0x65, 0x73,
+	0x74, 0x61, 0x72, 0x74, 0x49, 0x6e, 0x74, 0x65, 0x72, 0x76, 0x61, 0x6c, 0x12, 0x1c, 0x0a, 0x09,
+	0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x65, 0x64, 0x18, 0x0f, 0x20, 0x01, 0x28, 0x08, 0x52,
+	0x09, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x65, 0x64, 0x12, 0x26, 0x0a, 0x0e, 0x63, 0x6f,
+	0x6d, 0x70, 0x61, 0x72, 0x61, 0x74, 0x6f, 0x72, 0x4e, 0x61, 0x6d, 0x65, 0x18, 0x10, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x0e, 0x63, 0x6f, 0x6d, 0x70, 0x61, 0x72, 0x61, 0x74, 0x6f, 0x72, 0x4e, 0x61,
+	0x6d, 0x65, 0x12, 0x2e, 0x0a, 0x12, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x53, 0x69, 0x7a, 0x65, 0x48,
+	0x69, 0x73, 0x74, 0x6f, 0x67, 0x72, 0x61, 0x6d, 0x18, 0x11, 0x20, 0x03, 0x28, 0x04, 0x52, 0x12,
+	0x76, 0x61, 0x6c, 0x75, 0x65, 0x53, 0x69, 0x7a, 0x65, 0x48, 0x69, 0x73, 0x74, 0x6f, 0x67, 0x72,
+	0x61, 0x6d, 0x12, 0x1a, 0x0a, 0x08, 0x73, 0x63, 0x61, 0x6e, 0x4f, 0x6e, 0x6c, 0x79, 0x18, 0x12,
+	0x20, 0x01, 0x28, 0x08, 0x52, 0x08, 0x73, 0x63, 0x61, 0x6e, 0x4f, 0x6e, 0x6c, 0x79, 0x12, 0x30,
+	0x0a, 0x13, 0x62, 0x6c, 0x6f, 0x6f, 0x6d, 0x4b, 0x65, 0x79, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x66,
+	0x6f, 0x72, 0x6d, 0x65, 0x64, 0x18, 0x13, 0x20, 0x01, 0x28, 0x08, 0x52, 0x13, 0x62, 0x6c, 0x6f,
+	0x6f, 0x6d, 0x4b, 0x65, 0x79, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x6f, 0x72, 0x6d, 0x65, 0x64,
+	0x12, 0x28, 0x0a, 0x0f, 0x62, 0x6c, 0x6f, 0x6f, 0x6d, 0x50, 0x61, 0x72, 0x74, 0x69, 0x74, 0x69,
+	0x6f, 0x6e, 0x73, 0x18, 0x14, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0f, 0x62, 0x6c, 0x6f, 0x6f, 0x6d,
+	0x50, 0x61, 0x72, 0x74, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x36, 0x0a, 0x16, 0x76, 0x61,
+	0x6c, 0x75, 0x65, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x73, 0x75, 0x6d, 0x73, 0x44, 0x69, 0x73, 0x61,
+	0x62, 0x6c, 0x65, 0x64, 0x18, 0x15, 0x20, 0x01, 0x28, 0x08, 0x52, 0x16, 0x76, 0x61, 0x6c, 0x75,
+	0x65, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x73, 0x75, 0x6d, 0x73, 0x44, 0x69, 0x73, 0x61, 0x62, 0x6c,
+	0x65, 0x64, 0x12, 0x2c, 0x0a, 0x11, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x43, 0x68, 0x65, 0x63, 0x6b,
+	0x73, 0x75, 0x6d, 0x4e, 0x61, 0x6d, 0x65, 0x18, 0x16, 0x20, 0x01, 0x28, 0x09, 0x52, 0x11, 0x76,
+	0x61, 0x6c, 0x75, 0x65, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x73, 0x75, 0x6d, 0x4e, 0x61, 0x6d, 0x65,
+	0x22, 0x91, 0x01, 0x0a, 0x0d, 0x4d, 0x61, 0x6e, 0x69, 0x66, 0x65, 0x73, 0x74, 0x45, 0x6e, 0x74,
+	0x72, 0x79, 0x12, 0x12, 0x0a, 0x04, 0x70, 0x61, 0x74, 0x68, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x04, 0x70, 0x61, 0x74, 0x68, 0x12, 0x16, 0x0a, 0x06, 0x6d, 0x69, 0x6e, 0x4b, 0x65, 0x79,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x06, 0x6d, 0x69, 0x6e, 0x4b, 0x65, 0x79, 0x12, 0x16,
+	0x0a, 0x06, 0x6d, 0x61, 0x78, 0x4b, 0x65, 0x79, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x06,
+	0x6d, 0x61, 0x78, 0x4b, 0x65, 0x79, 0x12, 0x1e, 0x0a, 0x0a, 0x6e, 0x75, 0x6d, 0x52, 0x65, 0x63,
+	0x6f, 0x72, 0x64, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0a, 0x6e, 0x75, 0x6d, 0x52,
+	0x65, 0x63, 0x6f, 0x72, 0x64, 0x73, 0x12, 0x1c, 0x0a, 0x09, 0x64, 0x61, 0x74, 0x61, 0x42, 0x79,
+	0x74, 0x65, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x04, 0x52, 0x09, 0x64, 0x61, 0x74, 0x61, 0x42,
+	0x79, 0x74, 0x65, 0x73, 0x22, 0x38, 0x0a, 0x08, 0x4d, 0x61, 0x6e, 0x69, 0x66, 0x65, 0x73, 0x74,
+	0x12, 0x2c, 0x0a, 0x06, 0x74, 0x61, 0x62, 0x6c, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b,
+	0x32, 0x14, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x4d, 0x61, 0x6e, 0x69, 0x66, 0x65, 0x73,
+	0x74, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x06, 0x74, 0x61, 0x62, 0x6c, 0x65, 0x73, 0x42, 0x36,
+	0x5a, 0x34, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x74, 0x68, 0x6f,
+	0x6d, 0x61, 0x73, 0x6a, 0x75, 0x6e, 0x67, 0x62, 0x6c, 0x75, 0x74, 0x2f, 0x67, 0x6f, 0x2d, 0x73,
+	0x73, 0x74, 0x61, 0x62, 0x6c, 0x65, 0x73, 0x2f, 0x73, 0x73, 0x74, 0x61, 0x62, 0x6c, 0x65, 0x73,
+	0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -302,18 +732,23 @@ func file_sstables_proto_sstable_proto_rawDescGZIP() []byte {
 	return file_sstables_proto_sstable_proto_rawDescData
 }
 
-var file_sstables_proto_sstable_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_sstables_proto_sstable_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
 var file_sstables_proto_sstable_proto_goTypes = []interface{}{
-	(*IndexEntry)(nil), // 0: proto.IndexEntry
-	(*DataEntry)(nil),  // 1: proto.DataEntry
-	(*MetaData)(nil),   // 2: proto.MetaData
+	(*IndexEntry)(nil),     // 0: proto.IndexEntry
+	(*BloomPartition)(nil), // 1: proto.BloomPartition
+	(*SummaryEntry)(nil),   // 2: proto.SummaryEntry
+	(*DataEntry)(nil),      // 3: proto.DataEntry
+	(*MetaData)(nil),       // 4: proto.MetaData
+	(*ManifestEntry)(nil),  // 5: proto.ManifestEntry
+	(*Manifest)(nil),       // 6: proto.Manifest
 }
 var file_sstables_proto_sstable_proto_depIdxs = []int32{
-	0, // [0:0] is the sub-list for method output_type
-	0, // [0:0] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+	5, // 0: proto.Manifest.tables:type_name -> proto.ManifestEntry
+	1, // [1:1] is the sub-list for method output_type
+	1, // [1:1] is the sub-list for method input_type
+	1, // [1:1] is the sub-list for extension type_name
+	1, // [1:1] is the sub-list for extension extendee
+	0, // [0:1] is the sub-list for field type_name
 }
 
 func init() { file_sstables_proto_sstable_proto_init() }
@@ -335,7 +770,7 @@ func file_sstables_proto_sstable_proto_init() {
 			}
 		}
 		file_sstables_proto_sstable_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*DataEntry); i {
+			switch v := v.(*BloomPartition); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -347,6 +782,30 @@ func file_sstables_proto_sstable_proto_init() {
 			}
 		}
 		file_sstables_proto_sstable_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SummaryEntry); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_sstables_proto_sstable_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DataEntry); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_sstables_proto_sstable_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*MetaData); i {
 			case 0:
 				return &v.state
@@ -358,6 +817,30 @@ func file_sstables_proto_sstable_proto_init() {
 				return nil
 			}
 		}
+		file_sstables_proto_sstable_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ManifestEntry); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_sstables_proto_sstable_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Manifest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
@@ -365,7 +848,7 @@ func file_sstables_proto_sstable_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_sstables_proto_sstable_proto_rawDesc,
 			NumEnums:      0,
-			NumMessages:   3,
+			NumMessages:   7,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
@@ -0,0 +1,87 @@
+package sstables
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/thomasjungblut/go-sstables/recordio"
+)
+
+// RateLimiter throttles IO to a number of bytes per second, see WithRateLimiter and ReadWithRateLimiter. The WaitN function of
+// golang.org/x/time/rate.Limiter satisfies it, as long as its burst is at least as large as the largest record.
+type RateLimiter interface {
+	// WaitN blocks until n bytes may be processed, it returns the error of the context when it is done before that.
+	WaitN(ctx context.Context, n int) error
+}
+
+// TokenBucketRateLimiter is a RateLimiter that refills bytesPerSecond tokens every second, up to a burst of one second
+// worth of tokens. Requests larger than the available tokens go into debt that the following requests wait for, so
+// records larger than the burst never block forever. It is safe for concurrent use, for example to share a budget
+// between several writers.
+type TokenBucketRateLimiter struct {
+	mu             sync.Mutex
+	bytesPerSecond float64
+	tokens         float64
+	last           time.Time
+}
+
+func (l *TokenBucketRateLimiter) WaitN(ctx context.Context, n int) error {
+	l.mu.Lock()
+	now := time.Now()
+	l.tokens = min(l.bytesPerSecond, l.tokens+now.Sub(l.last).Seconds()*l.bytesPerSecond)
+	l.last = now
+	l.tokens -= float64(n)
+	if l.tokens >= 0 {
+		l.mu.Unlock()
+		return nil
+	}
+	wait := time.Duration(-l.tokens / l.bytesPerSecond * float64(time.Second))
+	l.mu.Unlock()
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		// the bytes were not processed, so they can be used by others
+		l.mu.Lock()
+		l.tokens += float64(n)
+		l.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+// NewTokenBucketRateLimiter creates a TokenBucketRateLimiter that allows bytesPerSecond, starting with a full bucket.
+func NewTokenBucketRateLimiter(bytesPerSecond int) *TokenBucketRateLimiter {
+	return &TokenBucketRateLimiter{
+		bytesPerSecond: float64(bytesPerSecond),
+		tokens:         float64(bytesPerSecond),
+		last:           time.Now(),
+	}
+}
+
+// scanThrottle waits on the rate limiter for the bytes a sequential scan consumed from the data file
+type scanThrottle struct {
+	limiter    RateLimiter
+	ctx        context.Context
+	dataReader *recordio.FileReader
+	lastOffset uint64
+}
+
+// wait blocks until the limiter allows the bytes that were read since the last call
+func (s *scanThrottle) wait() error {
+	offset := s.dataReader.Offset()
+	n := int(offset - s.lastOffset)
+	s.lastOffset = offset
+	if err := s.limiter.WaitN(s.ctx, n); err != nil {
+		return fmt.Errorf("error while scanning, rate limiter error: %w", err)
+	}
+	return nil
+}
+
+func newScanThrottle(ctx context.Context, limiter RateLimiter, dataReader *recordio.FileReader) *scanThrottle {
+	return &scanThrottle{limiter: limiter, ctx: ctx, dataReader: dataReader, lastOffset: dataReader.Offset()}
+}
@@ -0,0 +1,56 @@
+package sstables
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingRateLimiter never blocks, but records the bytes it was asked for
+type countingRateLimiter struct {
+	bytes atomic.Int64
+	calls atomic.Int64
+}
+
+func (l *countingRateLimiter) WaitN(ctx context.Context, n int) error {
+	l.bytes.Add(int64(n))
+	l.calls.Add(1)
+	return ctx.Err()
+}
+
+func TestTokenBucketRateLimiter(t *testing.T) {
+	limiter := NewTokenBucketRateLimiter(10_000)
+	ctx := context.Background()
+
+	// the bucket starts full
+	start := time.Now()
+	require.NoError(t, limiter.WaitN(ctx, 10_000))
+	assert.Less(t, time.Since(start), 50*time.Millisecond)
+
+	start = time.Now()
+	require.NoError(t, limiter.WaitN(ctx, 1_000))
+	assert.GreaterOrEqual(t, time.Since(start), 80*time.Millisecond)
+
+	// requests larger than the burst go into debt instead of blocking forever
+	start = time.Now()
+	require.NoError(t, limiter.WaitN(ctx, 12_000))
+	assert.GreaterOrEqual(t, time.Since(start), time.Second)
+}
+
+func TestTokenBucketRateLimiterContextDone(t *testing.T) {
+	limiter := NewTokenBucketRateLimiter(100)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	require.NoError(t, limiter.WaitN(ctx, 100))
+	start := time.Now()
+	assert.ErrorIs(t, limiter.WaitN(ctx, 1000), context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), time.Second)
+
+	// the cancelled request doesn't consume any tokens
+	assert.GreaterOrEqual(t, limiter.tokens, float64(0))
+}
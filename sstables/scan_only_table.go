@@ -0,0 +1,161 @@
+package sstables
+
+import (
+	"errors"
+	"fmt"
+	"hash"
+	"hash/crc64"
+	"io"
+	"path/filepath"
+
+	"github.com/thomasjungblut/go-sstables/recordio"
+	"github.com/thomasjungblut/go-sstables/skiplist"
+	sProto "github.com/thomasjungblut/go-sstables/sstables/proto"
+	"google.golang.org/protobuf/proto"
+)
+
+// scanOnlyIndexLoader is used for tables written with ScanOnly, their index file doesn't contain any entries.
+type scanOnlyIndexLoader struct {
+}
+
+func (l scanOnlyIndexLoader) Load(_ string, _ *sProto.MetaData) (SortedKeyIndex, error) {
+	return scanOnlyIndex{}, nil
+}
+
+// scanOnlyIndex rejects every lookup with ErrScanOnlyTable.
+type scanOnlyIndex struct {
+}
+
+func (scanOnlyIndex) Open() error {
+	return nil
+}
+
+func (scanOnlyIndex) Close() error {
+	return nil
+}
+
+func (scanOnlyIndex) Contains(_ []byte) (bool, error) {
+	return false, ErrScanOnlyTable
+}
+
+func (scanOnlyIndex) Get(_ []byte) (IndexVal, error) {
+	return IndexVal{}, ErrScanOnlyTable
+}
+
+func (scanOnlyIndex) Iterator() (skiplist.IteratorI[[]byte, IndexVal], error) {
+	return nil, ErrScanOnlyTable
+}
+
+func (scanOnlyIndex) IteratorStartingAt(_ []byte) (skiplist.IteratorI[[]byte, IndexVal], error) {
+	return nil, ErrScanOnlyTable
+}
+
+func (scanOnlyIndex) IteratorBetween(_ []byte, _ []byte) (skiplist.IteratorI[[]byte, IndexVal], error) {
+	return nil, ErrScanOnlyTable
+}
+
+// scanOnlyIterator reads the data file of a table written with ScanOnly sequentially, every value is followed by its
+// index entry.
+type scanOnlyIterator struct {
+	dataReader recordio.ReaderI
+
+	skipHashCheck   bool
+	verifyChecksums bool
+	newChecksum     func() hash.Hash64
+	reuseBuffers    bool
+	valueBuf        []byte
+	// iVal is the index entry of the record last returned by Next
+	iVal IndexVal
+	// throttle is nil without ReadWithRateLimiter
+	throttle *scanThrottle
+}
+
+func (it *scanOnlyIterator) Next() ([]byte, []byte, error) {
+	var value []byte
+	var err error
+	if intoReader, ok := it.dataReader.(recordio.IntoReaderI); ok && it.reuseBuffers {
+		value, err = intoReader.ReadNextInto(it.valueBuf)
+		if value != nil {
+			it.valueBuf = value
+		}
+	} else {
+		value, err = it.dataReader.ReadNext()
+	}
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, nil, Done
+		}
+		return nil, nil, err
+	}
+
+	entryBytes, err := it.dataReader.ReadNext()
+	if err != nil {
+		// a value is always followed by its entry, even at the end of the file
+		if errors.Is(err, io.EOF) {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, nil, fmt.Errorf("error while reading index entry of scan only table: %w", err)
+	}
+
+	if it.throttle != nil {
+		if err := it.throttle.wait(); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	entry := &sProto.IndexEntry{}
+	if err := proto.Unmarshal(entryBytes, entry); err != nil {
+		return nil, nil, fmt.Errorf("error while parsing index entry of scan only table: %w", err)
+	}
+
+	it.iVal = IndexVal{Checksum: entry.Checksum, NullValue: entry.NullValue, SequenceNumber: entry.SequenceNumber}
+	if entry.NullValue {
+		value = nil
+	}
+
+	if it.skipHashCheck && !it.verifyChecksums {
+		return entry.Key, value, nil
+	}
+
+	return checkScannedValue(entry.Key, value, it.iVal, it.verifyChecksums, it.newChecksum)
+}
+
+func (it *scanOnlyIterator) SequenceNumber() uint64 {
+	return it.iVal.SequenceNumber
+}
+
+// validateScanOnlyDataFile reads the whole data file of a scan only table to verify all values and the index checksum
+func (reader *SSTableReader) validateScanOnlyDataFile() (err error) {
+	dataReader, err := recordio.NewFileReaderWithPath(filepath.Join(reader.opts.basePath, reader.opts.dataFileName))
+	if err != nil {
+		return fmt.Errorf("validateDataFile error creating data reader of sstable '%s': %w", reader.opts.basePath, err)
+	}
+	err = dataReader.Open()
+	if err != nil {
+		return fmt.Errorf("validateDataFile error opening data reader of sstable '%s': %w", reader.opts.basePath, err)
+	}
+	defer func() {
+		err = errors.Join(err, dataReader.Close())
+	}()
+
+	it := &scanOnlyIterator{dataReader: dataReader, verifyChecksums: !reader.metaData.ValueChecksumsDisabled, newChecksum: reader.opts.newChecksum}
+	indexChecksum := crc64.New(crc64.MakeTable(crc64.ISO))
+	for {
+		k, _, err := it.Next()
+		if err != nil {
+			if errors.Is(err, Done) {
+				break
+			}
+			return fmt.Errorf("validateDataFile error scanning sstable '%s' at key [%v]: %w", reader.opts.basePath, k, err)
+		}
+		updateIndexChecksum(indexChecksum, k, it.iVal)
+	}
+
+	if reader.metaData.IndexChecksum != indexChecksum.Sum64() {
+		reader.opts.logger.Warn("sstable index checksum mismatch", "path", reader.opts.basePath)
+		return fmt.Errorf("validateDataFile error index checksum mismatch in sstable '%s': %w",
+			reader.opts.basePath, ChecksumError{indexChecksum.Sum64(), reader.metaData.IndexChecksum})
+	}
+
+	return nil
+}
@@ -0,0 +1,168 @@
+package sstables
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thomasjungblut/go-sstables/recordio"
+	"github.com/thomasjungblut/go-sstables/skiplist"
+)
+
+func newTestScanOnlyWriter(t *testing.T, opts ...WriterOption) *SSTableStreamWriter {
+	tmpDir, err := os.MkdirTemp("", "sstables_ScanOnly")
+	require.NoError(t, err)
+	writer, err := NewSSTableStreamWriter(append([]WriterOption{
+		WriteBasePath(tmpDir),
+		WithKeyComparator(skiplist.BytesComparator{}),
+		ScanOnly(),
+	}, opts...)...)
+	require.NoError(t, err)
+	return writer
+}
+
+func TestScanOnlyTable(t *testing.T) {
+	writer := newTestScanOnlyWriter(t)
+	defer cleanWriterDir(t, writer)
+	expected := streamedWriteAscendingIntegers(t, writer, 1000)
+
+	_, err := os.Stat(filepath.Join(writer.opts.basePath, BloomFileName))
+	assert.True(t, os.IsNotExist(err))
+
+	for _, opts := range [][]ReadOption{nil, {ReadReuseScanBuffers()}, {EnableHashCheckOnReads()}} {
+		reader, err := NewSSTableReader(append(opts, ReadBasePath(writer.opts.basePath))...)
+		require.NoError(t, err)
+
+		metaData := reader.MetaData()
+		assert.True(t, metaData.ScanOnly)
+		assert.Equal(t, uint64(1000), metaData.NumRecords)
+		minKey, _ := getKeyValueAsBytes(0)
+		maxKey, _ := getKeyValueAsBytes(999)
+		assert.Equal(t, minKey, metaData.MinKey)
+		assert.Equal(t, maxKey, metaData.MaxKey)
+		assert.Equal(t, uint64(recordio.FileHeaderSizeBytes), metaData.IndexBytes)
+
+		it, err := reader.Scan()
+		require.NoError(t, err)
+		assertIteratorMatchesSlice(t, it, expected)
+		closeReader(t, reader)
+	}
+}
+
+func TestScanOnlyTableRejectsLookups(t *testing.T) {
+	writer := newTestScanOnlyWriter(t)
+	defer cleanWriterDir(t, writer)
+	streamedWriteAscendingIntegers(t, writer, 10)
+
+	// the configured index loader is ignored for scan only tables
+	reader, err := NewSSTableReader(ReadBasePath(writer.opts.basePath), ReadIndexLoader(&SliceKeyIndexLoader{ReadBufferSize: 4096}))
+	require.NoError(t, err)
+	defer closeReader(t, reader)
+
+	k, _ := getKeyValueAsBytes(1)
+	_, err = reader.Get(k)
+	assert.ErrorIs(t, err, ErrScanOnlyTable)
+	_, err = reader.Contains(k)
+	assert.ErrorIs(t, err, ErrScanOnlyTable)
+	_, err = reader.ScanStartingAt(k)
+	assert.ErrorIs(t, err, ErrScanOnlyTable)
+	_, err = reader.ScanRange(k, k)
+	assert.ErrorIs(t, err, ErrScanOnlyTable)
+	_, err = reader.(*SSTableReader).IndexIterator()
+	assert.ErrorIs(t, err, ErrScanOnlyTable)
+}
+
+func TestScanOnlyTableIsSmaller(t *testing.T) {
+	writer := newTestScanOnlyWriter(t)
+	defer cleanWriterDir(t, writer)
+	streamedWriteAscendingIntegers(t, writer, 1000)
+	full, err := newTestSSTableStreamWriter()
+	require.NoError(t, err)
+	defer cleanWriterDir(t, full)
+	streamedWriteAscendingIntegers(t, full, 1000)
+
+	assert.Less(t, writer.metaData.IndexBytes*100, full.metaData.IndexBytes)
+	// the keys move from the index into the data file, but without their offsets
+	assert.Less(t, writer.metaData.TotalBytes, full.metaData.TotalBytes)
+}
+
+func TestScanOnlyTableWithNilValuesAndVersions(t *testing.T) {
+	writer := newTestScanOnlyWriter(t, WithVersioning())
+	defer cleanWriterDir(t, writer)
+	require.NoError(t, writer.Open())
+	require.NoError(t, writer.WriteNextWithSeq([]byte{1}, []byte{1}, 1))
+	require.NoError(t, writer.WriteNextWithSeq([]byte{1}, nil, 2))
+	require.NoError(t, writer.WriteNextWithSeq([]byte{2}, []byte{}, 1))
+	require.NoError(t, writer.WriteNextWithSeq([]byte{3}, []byte{3}, 1))
+	require.NoError(t, writer.WriteNextWithSeq([]byte{3}, []byte{4}, 3))
+	value, err := writer.WriteNextStreaming([]byte{4})
+	require.NoError(t, err)
+	_, err = value.Write([]byte{5, 6})
+	require.NoError(t, err)
+	require.NoError(t, value.Close())
+	require.NoError(t, writer.Close())
+
+	for _, seq := range []uint64{1, 3} {
+		reader, err := NewSSTableReader(ReadBasePath(writer.opts.basePath), ReadAsOfSeq(seq))
+		require.NoError(t, err)
+		it, err := reader.Scan()
+		require.NoError(t, err)
+
+		var keys, values [][]byte
+		for {
+			k, v, err := it.Next()
+			if errors.Is(err, Done) {
+				break
+			}
+			require.NoError(t, err)
+			keys = append(keys, k)
+			values = append(values, v)
+		}
+		closeReader(t, reader)
+
+		assert.Equal(t, [][]byte{{1}, {2}, {3}, {4}}, keys)
+		if seq == 1 {
+			assert.Equal(t, [][]byte{{1}, {}, {3}, {5, 6}}, values)
+		} else {
+			assert.Equal(t, [][]byte{nil, {}, {4}, {5, 6}}, values)
+		}
+	}
+}
+
+func TestScanOnlyTableDetectsCorruption(t *testing.T) {
+	writer := newTestScanOnlyWriter(t, DataCompressionType(recordio.CompressionTypeNone))
+	defer cleanWriterDir(t, writer)
+	streamedWriteAscendingIntegers(t, writer, 10)
+
+	dataPath := filepath.Join(writer.opts.basePath, DataFileName)
+	content, err := os.ReadFile(dataPath)
+	require.NoError(t, err)
+	// the last byte belongs to the index entry of the last record
+	content[len(content)-1] ^= 0xFF
+	require.NoError(t, os.WriteFile(dataPath, content, 0666))
+
+	_, err = NewSSTableReader(ReadBasePath(writer.opts.basePath))
+	assert.Error(t, err)
+
+	reader, err := NewSSTableReader(ReadBasePath(writer.opts.basePath), SkipHashCheckOnLoad())
+	require.NoError(t, err)
+	defer closeReader(t, reader)
+	it, err := reader.Scan()
+	require.NoError(t, err)
+	for i := 0; i < 9; i++ {
+		_, _, err := it.Next()
+		require.NoError(t, err)
+	}
+	_, _, err = it.Next()
+	assert.Error(t, err)
+}
+
+func TestScanOnlyInvalidOptions(t *testing.T) {
+	_, err := NewSSTableStreamWriter(WriteBasePath("a"), WithKeyComparator(skiplist.BytesComparator{}), ScanOnly(), SummaryEveryNthKey(10))
+	assert.Error(t, err)
+	_, err = NewSSTableStreamWriter(WriteBasePath("a"), WithKeyComparator(skiplist.BytesComparator{}), ScanOnly(), IndexKeyPrefixCompression(16))
+	assert.Error(t, err)
+}
@@ -41,25 +41,42 @@ func (l *SkipListIndexLoader) Load(indexPath string, _ *proto.MetaData) (_ Sorte
 		err = errors.Join(err, reader.Close())
 	}()
 
-	indexMap := skiplist.NewSkipListMap[[]byte, IndexVal](l.KeyComparator)
-	record := &proto.IndexEntry{}
+	// the index is sorted already, which allows to bulk load the skip list
+	indexMap, err := skiplist.NewFromSorted[[]byte, IndexVal](l.KeyComparator, &indexReaderIterator{reader: reader})
+	if err != nil {
+		return nil, fmt.Errorf("error while reading index records of sstable in '%s': %w", indexPath, err)
+	}
+
+	return &SkipListIndex{indexMap, NoOpOpenClose{}}, nil
+}
+
+// indexReaderIterator returns the index entries with their full keys in the order they are read from the index file
+type indexReaderIterator struct {
+	reader rProto.ReaderI
+	key    []byte
+}
 
-	for {
-		_, err := reader.ReadNext(record)
-		// io.EOF signals that no records are left to be read
-		if errors.Is(err, io.EOF) {
-			break
-		}
+func (it *indexReaderIterator) Next() ([]byte, IndexVal, error) {
+	record := &proto.IndexEntry{}
+	_, err := it.reader.ReadNext(record)
+	// io.EOF signals that no records are left to be read
+	if errors.Is(err, io.EOF) {
+		return nil, IndexVal{}, skiplist.Done
+	}
 
-		if err != nil {
-			return nil, fmt.Errorf("error while reading index records of sstable in '%s': %w", indexPath, err)
-		}
+	if err != nil {
+		return nil, IndexVal{}, err
+	}
 
-		indexMap.Insert(record.Key, IndexVal{
-			Offset:   record.ValueOffset,
-			Checksum: record.Checksum,
-		})
+	it.key, err = fullIndexKey(it.key, record)
+	if err != nil {
+		return nil, IndexVal{}, err
 	}
 
-	return &SkipListIndex{indexMap, NoOpOpenClose{}}, nil
+	return it.key, IndexVal{
+		Offset:         record.ValueOffset,
+		Checksum:       record.Checksum,
+		NullValue:      record.NullValue,
+		SequenceNumber: record.SequenceNumber,
+	}, nil
 }
@@ -29,6 +29,13 @@ func (s *SliceKeyIndex) search(key []byte) (int, bool) {
 	})
 }
 
+func (s *SliceKeyIndex) EntryAt(n uint64) ([]byte, IndexVal, error) {
+	if n >= uint64(len(s.index)) {
+		return nil, IndexVal{}, ErrOrdinalOutOfRange
+	}
+	return s.index[n].key, s.index[n].IndexVal, nil
+}
+
 func (s *SliceKeyIndex) Get(key []byte) (IndexVal, error) {
 	idx, found := s.search(key)
 	if found {
@@ -109,6 +116,7 @@ func (s *SliceKeyIndexLoader) Load(indexPath string, metadata *proto.MetaData) (
 	sx := make([]sliceKey, 0, capacity)
 
 	record := &proto.IndexEntry{}
+	var key []byte
 	for {
 		_, err := reader.ReadNext(record)
 		// io.EOF signals that no records are left to be read
@@ -120,7 +128,12 @@ func (s *SliceKeyIndexLoader) Load(indexPath string, metadata *proto.MetaData) (
 			return nil, fmt.Errorf("error while reading index records of sstable in '%s': %w", indexPath, err)
 		}
 
-		sx = append(sx, sliceKey{IndexVal{Offset: record.ValueOffset, Checksum: record.Checksum, Tombstoned: record.Tombstoned}, record.Key})
+		key, err = fullIndexKey(key, record)
+		if err != nil {
+			return nil, fmt.Errorf("error while reading index records of sstable in '%s': %w", indexPath, err)
+		}
+
+		sx = append(sx, sliceKey{IndexVal{Offset: record.ValueOffset, Checksum: record.Checksum, Tombstoned: record.Tombstoned, NullValue: record.NullValue, SequenceNumber: record.SequenceNumber}, key})
 	}
 
 	return &SliceKeyIndex{NoOpOpenClose{}, sx}, nil
@@ -2,21 +2,135 @@ package sstables
 
 import (
 	"errors"
+	"fmt"
 	"github.com/thomasjungblut/go-sstables/skiplist"
 	"github.com/thomasjungblut/go-sstables/sstables/proto"
+	"hash"
+	"hash/crc64"
+	"math/bits"
 )
 
 var IndexFileName = "index.rio"
 var DataFileName = "data.rio"
 var BloomFileName = "bloom.bf.gz"
 var MetaFileName = "meta.pb.bin"
+var SummaryFileName = "summary.rio"
+var BloomPartitionsFileName = "bloom_partitions.rio"
 
-var Version = uint32(1)
+// CommittedFileName is the marker file that Close writes after all other files of the table were synced to disk
+var CommittedFileName = "COMMITTED"
+
+// Version is the on-disk format of the tables written by this package, readers reject tables of a newer version.
+//   - 0: the values are protobuf messages and there is no metadata file
+//   - 1: the values are plain bytes, the metadata file describes the table
+//   - 2: tables are only complete when they contain the CommittedFileName marker
+//
+// The format options of the writer, IndexKeyPrefixCompression, ScanOnly, WithChecksumFunc, MinCompressSizeBytes and
+// WithSchemaID, were all added with version 2. They are recorded in the metadata or in the recordio header of the data
+// file, where version 2 readers detect them. Readers of version 1 predate the version check and the options, they only
+// reject the data files with a recordio v4 header and misread the others. New format options must either be detected
+// by the readers of the current version, or raise it.
+var Version = uint32(2)
+
+// validateFileNames ensures that the files of a table can't overwrite each other.
+func validateFileNames(names ...string) error {
+	seen := make(map[string]struct{}, len(names))
+	for _, name := range names {
+		if name == "" {
+			return errors.New("file names must not be empty")
+		}
+		if _, ok := seen[name]; ok {
+			return fmt.Errorf("file name '%s' is used more than once", name)
+		}
+		seen[name] = struct{}{}
+	}
+	return nil
+}
+
+// ValueSizeHistogramBuckets is the maximum number of buckets of MetaData.ValueSizeHistogram. Bucket 0 counts the empty
+// and nil values, bucket i counts the values with a size in [2^(i-1), 2^i) and the last bucket also all larger values.
+const ValueSizeHistogramBuckets = 32
+
+// valueSizeBucket returns the bucket of MetaData.ValueSizeHistogram that counts values of the given size
+func valueSizeBucket(size int) int {
+	return min(bits.Len(uint(size)), ValueSizeHistogramBuckets-1)
+}
+
+// ValueSizeBucketLowerBound returns the smallest value size that is counted in the given bucket of
+// MetaData.ValueSizeHistogram, see ValueSizeHistogramBuckets.
+func ValueSizeBucketLowerBound(bucket int) uint64 {
+	if bucket <= 0 {
+		return 0
+	}
+	return 1 << (bucket - 1)
+}
 
 // Done indicates an iterator has returned all items.
 // https://github.com/GoogleCloudPlatform/google-cloud-go/wiki/Iterator-Guidelines
 var Done = errors.New("no more items in iterator")
-var NotFound = errors.New("key was not found")
+
+// ErrKeyNotFound is returned by Get when the key does not exist in the table. Errors while reading, for example IO
+// errors or a ChecksumError, are always wrapped with context and never match ErrKeyNotFound or Done.
+var ErrKeyNotFound = errors.New("key was not found")
+
+// ErrOrdinalOutOfRange is returned by GetByOrdinal when the ordinal is not lower than the number of records.
+var ErrOrdinalOutOfRange = errors.New("ordinal is out of range")
+
+// ErrEmptyTable is returned by FirstKey and LastKey when the table has no records.
+var ErrEmptyTable = errors.New("table has no records")
+
+// ErrTableNotCommitted is returned by NewSSTableReader when a table lacks the CommittedFileName marker, which means it
+// is still being written or its writer failed. Use ReadUncommitted to open it anyway.
+var ErrTableNotCommitted = errors.New("table is not committed")
+
+// ErrComparatorMismatch is returned by NewSSTableReader when the comparator of the reader has another name than the one
+// recorded by the writer of the table. Use ReadIgnoreComparatorCheck to open it anyway.
+var ErrComparatorMismatch = errors.New("comparator does not match the comparator the table was written with")
+
+// ErrChecksumFuncMismatch is returned by NewSSTableReader when the values of the table were checksummed with a function
+// of WithChecksumFunc that wasn't supplied with ReadWithChecksumFunc under the same name.
+var ErrChecksumFuncMismatch = errors.New("checksum function does not match the function the table was written with")
+
+// ErrKeyRangeMismatch is returned by NewSSTableReader with ReadValidateKeyRange when the keys of the index don't match
+// the MinKey and MaxKey of the metadata.
+var ErrKeyRangeMismatch = errors.New("key range of the index does not match the metadata")
+
+// ErrBloomFilterMismatch is returned by SSTableReader.VerifyBloomFilter when the bloom filter rules out a key of the
+// index, for example because it was copied from another table.
+var ErrBloomFilterMismatch = errors.New("bloom filter does not contain a key of the index")
+
+// ErrMetaDataCorrupt is returned by NewSSTableReader when the metadata file is truncated or its checksum doesn't match,
+// for example after a partial copy of the table.
+var ErrMetaDataCorrupt = errors.New("metadata corrupt")
+
+// ErrScanOnlyTable is returned by all lookups on tables written with ScanOnly, which can only be read with Scan.
+var ErrScanOnlyTable = errors.New("table was written with ScanOnly and only supports Scan")
+
+// NotFound is the same sentinel as ErrKeyNotFound and kept for backward compatibility.
+var NotFound = ErrKeyNotFound
+
+// NamedComparator is implemented by comparators that can identify themselves, for example skiplist.BytesComparator
+// is named "bytes". Writers record the name in the metadata and readers verify it against their own comparator.
+type NamedComparator interface {
+	Name() string
+}
+
+// comparatorName returns the explicitly configured name, or the name of a NamedComparator. It's empty when the
+// comparator is unknown.
+func comparatorName(cmp skiplist.Comparator[[]byte], name string) string {
+	if name != "" {
+		return name
+	}
+	if named, ok := cmp.(NamedComparator); ok {
+		return named.Name()
+	}
+	return ""
+}
+
+// newCRC64ISO returns the default hash for value checksums, a CRC-64 with the ISO polynomial
+func newCRC64ISO() hash.Hash64 {
+	return crc64.New(crc64.MakeTable(crc64.ISO))
+}
 
 type SSTableIteratorI interface {
 	// Next returns the next key, value in sequence.
@@ -24,10 +138,17 @@ type SSTableIteratorI interface {
 	Next() ([]byte, []byte, error)
 }
 
+// SequencedIteratorI is implemented by the iterators of the SSTableReader to expose the versions of the records.
+type SequencedIteratorI interface {
+	SSTableIteratorI
+	// SequenceNumber returns the sequence number of the record last returned by Next, zero if it was written without.
+	SequenceNumber() uint64
+}
+
 type SSTableReaderI interface {
 	// Contains returns true when the given key exists, false otherwise
 	Contains(key []byte) (bool, error)
-	// Get returns the value associated with the given key, NotFound as the error otherwise
+	// Get returns the value associated with the given key, ErrKeyNotFound as the error otherwise
 	Get(key []byte) ([]byte, error)
 	// Scan returns an iterator over the whole sorted sequence. Scan uses a more optimized version that iterates the
 	// data file sequentially, whereas the other Scan* functions use the index and random access using mmap.
@@ -0,0 +1,84 @@
+package sstables
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/thomasjungblut/go-sstables/skiplist"
+	"golang.org/x/exp/slices"
+)
+
+// BatchResult is the result of a single key of GetBatch.
+type BatchResult struct {
+	Value []byte
+	// Found is false when the key is not in the table, Value is nil then. Found keys can have nil values too.
+	Found bool
+}
+
+// batchLookup is a distinct key of GetBatch that was found in the index, positions are its indices in the input
+type batchLookup struct {
+	iVal      IndexVal
+	positions []int
+}
+
+// GetBatch returns the values of all given keys in a single call, the results are aligned to the order of keys.
+// Keys that are not in the table return a result with Found set to false, any other error fails the whole batch.
+// Keys the bloom filter rules out are skipped right away, the others are searched in the index in ascending order and
+// their values are read in ascending offset order, so the reads move forward through the data file instead of jumping
+// around. This pays off over a loop of Get for large batches on tables that don't fit into the page cache.
+// Keys that occur more than once in keys are only read once and share the same value slice.
+func (reader *SSTableReader) GetBatch(keys [][]byte) ([]BatchResult, error) {
+	results := make([]BatchResult, len(keys))
+
+	order := make([]int, 0, len(keys))
+	for i, key := range keys {
+		if reader.bloomMightContain(key) {
+			order = append(order, i)
+		}
+	}
+	slices.SortStableFunc(order, func(a, b int) int {
+		return reader.opts.keyComparator.Compare(keys[a], keys[b])
+	})
+
+	var lookups []batchLookup
+	for start := 0; start < len(order); {
+		end := start + 1
+		for end < len(order) && reader.opts.keyComparator.Compare(keys[order[start]], keys[order[end]]) == 0 {
+			end++
+		}
+
+		iVal, err := reader.getIndexVal(keys[order[start]])
+		if err != nil {
+			if !errors.Is(err, skiplist.NotFound) {
+				return nil, fmt.Errorf("error in sstable '%s' on getting key from index: %w", reader.opts.basePath, err)
+			}
+		} else {
+			lookups = append(lookups, batchLookup{iVal: iVal, positions: order[start:end]})
+		}
+		start = end
+	}
+
+	slices.SortFunc(lookups, func(a, b batchLookup) int {
+		if a.iVal.Offset < b.iVal.Offset {
+			return -1
+		} else if a.iVal.Offset > b.iVal.Offset {
+			return 1
+		}
+		return 0
+	})
+
+	for _, lookup := range lookups {
+		v, err := reader.getValueAtOffset(lookup.iVal, reader.opts.skipHashCheckOnRead)
+		if err != nil {
+			if reader.treatAsMissing(keys[lookup.positions[0]], err) {
+				continue
+			}
+			return nil, err
+		}
+		for _, pos := range lookup.positions {
+			results[pos] = BatchResult{Value: v, Found: true}
+		}
+	}
+
+	return results, nil
+}
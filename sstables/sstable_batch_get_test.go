@@ -0,0 +1,58 @@
+package sstables
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetBatch(t *testing.T) {
+	writer, err := newTestSSTableStreamWriter()
+	require.NoError(t, err)
+	defer cleanWriterDir(t, writer)
+	streamedWriteAscendingIntegers(t, writer, 1000)
+
+	reader, err := NewSSTableReader(ReadBasePath(writer.opts.basePath))
+	require.NoError(t, err)
+	defer closeReader(t, reader)
+
+	var keys [][]byte
+	for _, i := range []int{512, 3, 1000, 999, 0, 3, -1, 42, 2000} {
+		keys = append(keys, intToByteSlice(i))
+	}
+
+	results, err := reader.(*SSTableReader).GetBatch(keys)
+	require.NoError(t, err)
+	require.Len(t, results, len(keys))
+	for i, key := range keys {
+		v, err := reader.Get(key)
+		if err != nil {
+			assert.Equal(t, ErrKeyNotFound, err)
+			assert.Equal(t, BatchResult{}, results[i], "key %v", key)
+			continue
+		}
+		assert.True(t, results[i].Found, "key %v", key)
+		assert.Equal(t, v, results[i].Value, "key %v", key)
+	}
+
+	results, err = reader.(*SSTableReader).GetBatch(nil)
+	require.NoError(t, err)
+	assert.Empty(t, results)
+}
+
+func TestGetBatchChecksumMismatch(t *testing.T) {
+	reader, err := NewSSTableReader(
+		ReadBasePath("test_files/SimpleWriteHappyPathSSTableWithCRCHashesMismatch"),
+		SkipHashCheckOnLoad(),
+		EnableHashCheckOnReads())
+	require.NoError(t, err)
+	defer closeReader(t, reader)
+
+	var keys [][]byte
+	for i := 1; i <= 7; i++ {
+		keys = append(keys, intToByteSlice(i))
+	}
+	_, err = reader.(*SSTableReader).GetBatch(keys)
+	assert.ErrorIs(t, err, ChecksumError{})
+}
@@ -0,0 +1,104 @@
+package sstables
+
+import (
+	"fmt"
+
+	"github.com/thomasjungblut/go-sstables/recordio"
+)
+
+// KV is a single record of a batch written with BatchWriter.WriteBatch.
+type KV struct {
+	Key   []byte
+	Value []byte
+}
+
+// BatchWriter writes batches of already sorted records into a SSTableStreamWriter, for example when replaying a WAL.
+// The order and the WriteValidator are checked for the whole batch before anything is written, so an invalid batch
+// leaves the table unchanged. The records are then written in a single loop without any further per-record checks
+// and flushed once at the end.
+// Errors while writing, for example IO errors, can leave a part of the batch written.
+type BatchWriter struct {
+	writer *SSTableStreamWriter
+}
+
+// WriteBatch writes all records of the batch, which must be strictly ascending and start after the last key written.
+// The buffers of the data and index files are flushed once after the whole batch was written, without syncing them.
+// A batch can't contain multiple versions of a key, versioned tables need WriteNextWithSeq for that.
+func (b *BatchWriter) WriteBatch(batch []KV) error {
+	if len(batch) == 0 {
+		return nil
+	}
+
+	writer := b.writer
+	if err := writer.checkKeyOrder(batch[0].Key, 0); err != nil {
+		return err
+	}
+
+	// the first key may only be equal to the last key of the writer with versioning, where it would need a seq
+	if writer.lastKey != nil && writer.opts.keyComparator.Compare(writer.lastKey, batch[0].Key) == 0 {
+		return fmt.Errorf("sstables.WriteBatch '%s': the same key cannot be written more than once", writer.opts.basePath)
+	}
+
+	for i := 1; i < len(batch); i++ {
+		if writer.opts.keyComparator.Compare(batch[i-1].Key, batch[i].Key) >= 0 {
+			return fmt.Errorf("sstables.WriteBatch '%s': batch is not strictly ascending at index %d", writer.opts.basePath, i)
+		}
+	}
+
+	if writer.opts.writeValidator != nil {
+		for i, kv := range batch {
+			if err := writer.opts.writeValidator(kv.Key, kv.Value); err != nil {
+				return fmt.Errorf("sstables.WriteBatch '%s': validation failed at index %d: %w", writer.opts.basePath, i, err)
+			}
+		}
+	}
+
+	crc := writer.opts.newChecksum()
+	for _, kv := range batch {
+		writer.trackKey(kv.Key)
+		writer.lastSeq = 0
+
+		crc.Reset()
+		var checksum uint64
+		if !writer.opts.disableValueChecksums {
+			_, _ = crc.Write(kv.Value)
+			checksum = crc.Sum64()
+		}
+
+		preWriteOffset := writer.dataWriter.Size()
+		recordOffset, err := writer.dataWriter.Write(kv.Value)
+		if err != nil {
+			return fmt.Errorf("error writeBatch data writer error in '%s': %w", writer.opts.basePath, err)
+		}
+
+		err = writer.appendIndexEntry(kv.Key, recordOffset, preWriteOffset, checksum, len(kv.Value), kv.Value == nil, 0)
+		if err != nil {
+			return err
+		}
+	}
+
+	return b.flush()
+}
+
+// flush writes the buffers of the data and index writers to their files once for the whole batch. Data files written
+// with direct IO can only be written in full blocks, so their buffer is only written when it is full.
+func (b *BatchWriter) flush() error {
+	writer := b.writer
+	if f, ok := writer.dataWriter.(recordio.FlushI); ok && !writer.useDirectIO() {
+		if err := f.Flush(); err != nil {
+			return fmt.Errorf("error writeBatch flushing data writer in '%s': %w", writer.opts.basePath, err)
+		}
+	}
+	if f, ok := writer.indexWriter.(recordio.FlushI); ok {
+		if err := f.Flush(); err != nil {
+			return fmt.Errorf("error writeBatch flushing index writer in '%s': %w", writer.opts.basePath, err)
+		}
+	}
+	return nil
+}
+
+// NewBatchWriter creates a BatchWriter on top of the given opened writer, which can still be used directly in between
+// batches. Closing the stream writer completes the table.
+func NewBatchWriter(writer *SSTableStreamWriter) *BatchWriter {
+	return &BatchWriter{writer: writer}
+}
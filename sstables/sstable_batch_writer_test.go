@@ -0,0 +1,101 @@
+package sstables
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thomasjungblut/go-sstables/recordio"
+	"github.com/thomasjungblut/go-sstables/skiplist"
+)
+
+func batchOfAscendingIntegers(start int, end int) []KV {
+	var batch []KV
+	for i := start; i < end; i++ {
+		k, v := getKeyValueAsBytes(i)
+		batch = append(batch, KV{Key: k, Value: v})
+	}
+	return batch
+}
+
+func TestBatchWriterHappyPath(t *testing.T) {
+	writer, err := newTestSSTableStreamWriter()
+	require.NoError(t, err)
+	defer cleanWriterDir(t, writer)
+	require.NoError(t, writer.Open())
+
+	batchWriter := NewBatchWriter(writer)
+	require.NoError(t, batchWriter.WriteBatch(batchOfAscendingIntegers(0, 50)))
+	require.NoError(t, batchWriter.WriteBatch(nil))
+	k, v := getKeyValueAsBytes(50)
+	require.NoError(t, writer.WriteNext(k, v))
+	require.NoError(t, batchWriter.WriteBatch(batchOfAscendingIntegers(51, 100)))
+	require.NoError(t, writer.Close())
+
+	var expected []int
+	for i := 0; i < 100; i++ {
+		expected = append(expected, i)
+	}
+	assertRandomAndSequentialRead(t, writer.opts.basePath, expected)
+}
+
+func TestBatchWriterFlushes(t *testing.T) {
+	writer, err := newTestSSTableStreamWriter()
+	require.NoError(t, err)
+	defer cleanWriterDir(t, writer)
+	require.NoError(t, writer.Open())
+
+	require.NoError(t, NewBatchWriter(writer).WriteBatch(batchOfAscendingIntegers(0, 50)))
+	// the batch is smaller than the write buffers, so only the flush puts it into the files
+	dataStat, err := os.Stat(filepath.Join(writer.opts.basePath, DataFileName))
+	require.NoError(t, err)
+	assert.Equal(t, writer.dataWriter.Size(), uint64(dataStat.Size()))
+	indexStat, err := os.Stat(filepath.Join(writer.opts.basePath, IndexFileName))
+	require.NoError(t, err)
+	assert.Equal(t, writer.indexWriter.Size(), uint64(indexStat.Size()))
+	assert.Greater(t, indexStat.Size(), int64(recordio.FileHeaderSizeBytes))
+
+	require.NoError(t, writer.Close())
+}
+
+func TestBatchWriterRejectsInvalidBatches(t *testing.T) {
+	tmpDir := t.TempDir()
+	writer, err := NewSSTableStreamWriter(WriteBasePath(tmpDir), WithKeyComparator(skiplist.BytesComparator{}),
+		WithWriteValidator(func(key []byte, value []byte) error {
+			if len(value) == 0 {
+				return errors.New("empty value")
+			}
+			return nil
+		}))
+	require.NoError(t, err)
+	require.NoError(t, writer.Open())
+
+	batchWriter := NewBatchWriter(writer)
+	require.NoError(t, batchWriter.WriteBatch(batchOfAscendingIntegers(10, 20)))
+
+	// the batch starts before or at the last key
+	require.ErrorContains(t, batchWriter.WriteBatch(batchOfAscendingIntegers(19, 25)), "more than once")
+	require.ErrorContains(t, batchWriter.WriteBatch(batchOfAscendingIntegers(5, 25)), "non-ascending")
+
+	unsorted := batchOfAscendingIntegers(20, 25)
+	unsorted[3], unsorted[4] = unsorted[4], unsorted[3]
+	require.ErrorContains(t, batchWriter.WriteBatch(unsorted), "not strictly ascending at index 4")
+
+	invalid := batchOfAscendingIntegers(20, 25)
+	invalid[2].Value = nil
+	require.ErrorContains(t, batchWriter.WriteBatch(invalid), "validation failed at index 2")
+
+	// rejected batches don't write anything
+	assert.Equal(t, uint64(10), writer.metaData.NumRecords)
+	require.NoError(t, batchWriter.WriteBatch(batchOfAscendingIntegers(20, 25)))
+	require.NoError(t, writer.Close())
+
+	var expected []int
+	for i := 10; i < 25; i++ {
+		expected = append(expected, i)
+	}
+	assertRandomAndSequentialRead(t, tmpDir, expected)
+}
@@ -0,0 +1,340 @@
+package sstables
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/thomasjungblut/go-sstables/skiplist"
+	"github.com/thomasjungblut/go-sstables/sstables/proto"
+	"golang.org/x/exp/slices"
+)
+
+// DeltaDirPrefix is the prefix of the numbered directories below the base path of a table that contain its deltas
+var DeltaDirPrefix = "delta_"
+
+// DeltaWriter writes a delta, a small table with updates to an existing base table that can't be changed anymore.
+// Every delta is a valid standalone table in its own numbered directory below the base path of the table: delta_000,
+// delta_001 and so on. Later deltas win over earlier ones and the base, nil values are tombstones that delete the key.
+// The number of the delta is assigned on Open, so only a single DeltaWriter per table may be open at a time. The delta
+// is written into a hidden staging directory and only renamed to its numbered directory on a successful Close, so a
+// crash or a failed write never leaves a partial delta behind that readers would pick up.
+type DeltaWriter struct {
+	basePath      string
+	writerOptions []WriterOption
+
+	path   string
+	writer *SSTableStreamWriter
+}
+
+func (d *DeltaWriter) Open() error {
+	if _, err := ReadMetaData(ReadBasePath(d.basePath)); err != nil {
+		return fmt.Errorf("deltas can only be written for an existing table: %w", err)
+	}
+
+	deltas, err := listDeltas(d.basePath)
+	if err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf("%s%03d", DeltaDirPrefix, len(deltas))
+	path := filepath.Join(d.basePath, name)
+	// the staging directory must not look like a delta, a leftover of an earlier crash is removed by the writer
+	stagingPath := filepath.Join(d.basePath, "."+name+".tmp")
+	writer, err := NewSSTableStreamWriter(append(slices.Clone(d.writerOptions), WriteBasePath(path), WriteStagingPath(stagingPath))...)
+	if err != nil {
+		return fmt.Errorf("error while creating writer for delta '%s': %w", path, err)
+	}
+
+	err = writer.Open()
+	if err != nil {
+		return errors.Join(err, os.RemoveAll(stagingPath))
+	}
+
+	d.path = path
+	d.writer = writer
+	return nil
+}
+
+// WriteNext writes the next record of the delta, a nil value deletes the key from the base and all earlier deltas.
+func (d *DeltaWriter) WriteNext(key []byte, value []byte) error {
+	if d.writer == nil {
+		return fmt.Errorf("sstables.WriteNext '%s': delta writer is not open", d.basePath)
+	}
+	return d.writer.WriteNext(key, value)
+}
+
+func (d *DeltaWriter) Close() error {
+	if d.writer == nil {
+		return fmt.Errorf("sstables.Close '%s': delta writer is not open", d.basePath)
+	}
+	return d.writer.Close()
+}
+
+// Path returns the directory of the delta once the writer is open.
+func (d *DeltaWriter) Path() string {
+	return d.path
+}
+
+// NewDeltaWriter creates a writer for the next delta of the table in the given base path, see DeltaWriter.
+// The writer options apply to the delta, a WriteBasePath or WriteStagingPath among them is replaced by the directory of
+// the delta and its staging directory.
+func NewDeltaWriter(basePath string, writerOptions ...WriterOption) (*DeltaWriter, error) {
+	// the options are validated upfront, the directory of the delta is only known on Open
+	_, err := NewSSTableStreamWriter(append(slices.Clone(writerOptions), WriteBasePath(basePath))...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &DeltaWriter{basePath: basePath, writerOptions: writerOptions}, nil
+}
+
+// listDeltas returns the directories of all deltas below the base path in the order they were written. The deltas
+// need to be numbered without gaps, a missing delta would silently bring back the values it overwrote.
+func listDeltas(basePath string) ([]string, error) {
+	entries, err := os.ReadDir(basePath)
+	if err != nil {
+		return nil, fmt.Errorf("error while listing deltas in '%s': %w", basePath, err)
+	}
+
+	var numbers []int
+	for _, entry := range entries {
+		if !entry.IsDir() || !strings.HasPrefix(entry.Name(), DeltaDirPrefix) {
+			continue
+		}
+		n, err := strconv.Atoi(strings.TrimPrefix(entry.Name(), DeltaDirPrefix))
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("unexpected delta directory '%s' in '%s'", entry.Name(), basePath)
+		}
+		numbers = append(numbers, n)
+	}
+	sort.Ints(numbers)
+
+	paths := make([]string, len(numbers))
+	for i, n := range numbers {
+		if n != i {
+			return nil, fmt.Errorf("deltas in '%s' are not contiguous, delta %d is missing", basePath, i)
+		}
+		paths[i] = filepath.Join(basePath, fmt.Sprintf("%s%03d", DeltaDirPrefix, n))
+	}
+	return paths, nil
+}
+
+// DeltaReader reads a base table together with all of its deltas, as written by the DeltaWriter, like a single table.
+// The newest version of every key wins and keys whose newest value is nil are deleted, so Get returns ErrKeyNotFound
+// and the scans skip them. Empty values are kept. Point lookups check the tables from the newest delta to the base and
+// skip deltas whose key range doesn't cover the key, the scans merge all tables.
+type DeltaReader struct {
+	basePath string
+	// tables contains the base first, followed by its deltas from the oldest to the newest
+	tables []*SSTableReader
+	comp   skiplist.Comparator[[]byte]
+}
+
+// newest returns the newest table that contains the key along with its index entry, nil if none does
+func (d *DeltaReader) newest(key []byte) (*SSTableReader, IndexVal, error) {
+	for i := len(d.tables) - 1; i >= 0; i-- {
+		table := d.tables[i]
+		md := table.metaData
+		if md.MinKey != nil && d.comp.Compare(key, md.MinKey) < 0 ||
+			md.MaxKey != nil && d.comp.Compare(key, md.MaxKey) > 0 {
+			continue
+		}
+		if !table.bloomMightContain(key) {
+			continue
+		}
+
+		iVal, err := table.getIndexVal(key)
+		if err != nil {
+			if errors.Is(err, skiplist.NotFound) {
+				continue
+			}
+			return nil, IndexVal{}, fmt.Errorf("error in sstable '%s' on getting key from index: %w", table.opts.basePath, err)
+		}
+		return table, iVal, nil
+	}
+	return nil, IndexVal{}, nil
+}
+
+// Contains returns true when the newest version of the key isn't a tombstone. Like SSTableReader.Contains, it never
+// reads the data files.
+func (d *DeltaReader) Contains(key []byte) (bool, error) {
+	table, iVal, err := d.newest(key)
+	if err != nil {
+		return false, err
+	}
+	return table != nil && !iVal.NullValue, nil
+}
+
+func (d *DeltaReader) Get(key []byte) ([]byte, error) {
+	table, iVal, err := d.newest(key)
+	if err != nil {
+		return nil, err
+	}
+	if table == nil || iVal.NullValue {
+		return nil, ErrKeyNotFound
+	}
+	return table.getValueAtOffset(iVal, table.opts.skipHashCheckOnRead)
+}
+
+func (d *DeltaReader) Scan() (SSTableIteratorI, error) {
+	return d.merge(func(reader *SSTableReader) (SSTableIteratorI, error) {
+		return reader.Scan()
+	})
+}
+
+func (d *DeltaReader) ScanStartingAt(key []byte) (SSTableIteratorI, error) {
+	return d.merge(func(reader *SSTableReader) (SSTableIteratorI, error) {
+		return reader.ScanStartingAt(key)
+	})
+}
+
+func (d *DeltaReader) ScanRange(keyLower []byte, keyHigher []byte) (SSTableIteratorI, error) {
+	if d.comp.Compare(keyLower, keyHigher) > 0 {
+		return nil, errors.New("keyHigher is lower than keyLower")
+	}
+	return d.merge(func(reader *SSTableReader) (SSTableIteratorI, error) {
+		return reader.ScanRange(keyLower, keyHigher)
+	})
+}
+
+// merge combines the scans of all tables, the context is the position of the table so that later deltas win
+func (d *DeltaReader) merge(scan func(reader *SSTableReader) (SSTableIteratorI, error)) (SSTableIteratorI, error) {
+	var iterators []SSTableMergeIteratorContext
+	for i, table := range d.tables {
+		it, err := scan(table)
+		if err != nil {
+			return nil, err
+		}
+		iterators = append(iterators, NewMergeIteratorContext(i, it))
+	}
+
+	// the merge iterator omits keys whose reduced value is nil, which are the tombstones
+	return NewSSTableMerger(d.comp).MergeCompactIterator(iterators, ScanReduceLatestWins)
+}
+
+// Flatten writes the merged content of the base and all of its deltas into a new table, which contains every key with
+// its newest value and no tombstones. The writer options need to contain a WriteBasePath other than the one of this
+// reader, the comparator of the base is used unless another one is supplied. Replacing the base with the new table is
+// up to the caller, for example by renaming the directories once this reader is closed.
+func (d *DeltaReader) Flatten(writerOptions ...WriterOption) (err error) {
+	writer, err := NewSSTableStreamWriter(append([]WriterOption{WithKeyComparator(d.comp)}, writerOptions...)...)
+	if err != nil {
+		return err
+	}
+
+	out, err := filepath.Abs(writer.opts.basePath)
+	if err != nil {
+		return err
+	}
+	in, err := filepath.Abs(d.basePath)
+	if err != nil {
+		return err
+	}
+	if out == in {
+		return fmt.Errorf("can't flatten '%s' into itself", d.basePath)
+	}
+
+	it, err := d.Scan()
+	if err != nil {
+		return err
+	}
+
+	err = writer.Open()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = errors.Join(err, writer.Close())
+	}()
+
+	for {
+		key, value, err := it.Next()
+		if errors.Is(err, Done) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("error while flattening '%s': %w", d.basePath, err)
+		}
+
+		err = writer.WriteNext(key, value)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (d *DeltaReader) Close() (err error) {
+	for _, table := range d.tables {
+		err = errors.Join(err, table.Close())
+	}
+	return
+}
+
+// MetaData returns the metadata of the base with the sums over all deltas. Keys that were overwritten or deleted by a
+// delta are counted in every table that contains them, so NumRecords is an upper bound.
+func (d *DeltaReader) MetaData() *proto.MetaData {
+	base := d.tables[0].metaData
+	sum := &proto.MetaData{
+		MinKey:  base.MinKey,
+		MaxKey:  base.MaxKey,
+		Version: base.Version,
+	}
+
+	for _, table := range d.tables {
+		m := table.metaData
+		sum.NumRecords += m.NumRecords
+		sum.DataBytes += m.DataBytes
+		sum.IndexBytes += m.IndexBytes
+		sum.TotalBytes += m.TotalBytes
+		if m.MinKey != nil && (sum.MinKey == nil || d.comp.Compare(m.MinKey, sum.MinKey) < 0) {
+			sum.MinKey = m.MinKey
+		}
+		if m.MaxKey != nil && (sum.MaxKey == nil || d.comp.Compare(m.MaxKey, sum.MaxKey) > 0) {
+			sum.MaxKey = m.MaxKey
+		}
+	}
+	return sum
+}
+
+func (d *DeltaReader) BasePath() string {
+	return d.basePath
+}
+
+// NewDeltaReader opens the table in the given base path along with all of its deltas, see DeltaReader. The reader
+// options apply to every table. Point lookups rely on the key ranges of the deltas, so the deltas are always opened
+// with ReadValidateKeyRange.
+func NewDeltaReader(basePath string, readerOptions ...ReadOption) (_ *DeltaReader, err error) {
+	deltas, err := listDeltas(basePath)
+	if err != nil {
+		return nil, err
+	}
+
+	reader := &DeltaReader{basePath: basePath}
+	defer func() {
+		if err != nil {
+			err = errors.Join(err, reader.Close())
+		}
+	}()
+
+	base, err := NewSSTableReader(append(slices.Clone(readerOptions), ReadBasePath(basePath))...)
+	if err != nil {
+		return nil, err
+	}
+	reader.tables = append(reader.tables, base.(*SSTableReader))
+	reader.comp = base.(*SSTableReader).opts.keyComparator
+
+	for _, path := range deltas {
+		delta, err := NewSSTableReader(append(slices.Clone(readerOptions), ReadBasePath(path), ReadValidateKeyRange())...)
+		if err != nil {
+			return nil, fmt.Errorf("error while opening delta '%s': %w", path, err)
+		}
+		reader.tables = append(reader.tables, delta.(*SSTableReader))
+	}
+
+	return reader, nil
+}
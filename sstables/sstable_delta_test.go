@@ -0,0 +1,227 @@
+package sstables
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thomasjungblut/go-sstables/skiplist"
+)
+
+type deltaRecord struct {
+	key   int
+	value []byte
+}
+
+// writeDelta writes the records as the next delta of the table in basePath, nil values are tombstones
+func writeDelta(t *testing.T, basePath string, records ...deltaRecord) string {
+	writer, err := NewDeltaWriter(basePath, WithKeyComparator(skiplist.BytesComparator{}))
+	require.NoError(t, err)
+	require.NoError(t, writer.Open())
+	for _, r := range records {
+		require.NoError(t, writer.WriteNext(intToByteSlice(r.key), r.value))
+	}
+	require.NoError(t, writer.Close())
+	return writer.Path()
+}
+
+// assertDeltaContent checks the point lookups and a full scan of the reader against the expected values by key
+func assertDeltaContent(t *testing.T, reader SSTableReaderI, expected map[int][]byte) {
+	for i := -10; i < 200; i++ {
+		expectedValue, ok := expected[i]
+		actual, err := reader.Get(intToByteSlice(i))
+		contains, cErr := reader.Contains(intToByteSlice(i))
+		require.NoError(t, cErr)
+		assert.Equal(t, ok, contains, "key %d", i)
+		if !ok {
+			assert.ErrorIs(t, err, ErrKeyNotFound, "key %d", i)
+			continue
+		}
+		require.NoError(t, err)
+		assert.Equal(t, expectedValue, actual, "key %d", i)
+	}
+
+	var expectedKeys []int
+	for i := range expected {
+		expectedKeys = append(expectedKeys, i)
+	}
+	sort.Ints(expectedKeys)
+
+	it, err := reader.Scan()
+	require.NoError(t, err)
+	for _, i := range expectedKeys {
+		k, v, err := it.Next()
+		require.NoError(t, err)
+		assert.Equal(t, intToByteSlice(i), k)
+		assert.Equal(t, expected[i], v, "key %d", i)
+	}
+	_, _, err = it.Next()
+	assert.Equal(t, Done, err)
+}
+
+func TestDeltaReader(t *testing.T) {
+	writer, err := newTestSSTableStreamWriter()
+	require.NoError(t, err)
+	defer cleanWriterDir(t, writer)
+	basePath := writer.opts.basePath
+
+	expected := map[int][]byte{}
+	for _, i := range streamedWriteAscendingIntegers(t, writer, 100) {
+		_, v := getKeyValueAsBytes(i)
+		expected[i] = v
+	}
+
+	path := writeDelta(t, basePath,
+		deltaRecord{10, []byte("first")},
+		deltaRecord{20, nil},
+		deltaRecord{30, []byte{}},
+		deltaRecord{150, []byte("new")},
+	)
+	assert.Equal(t, filepath.Join(basePath, "delta_000"), path)
+	path = writeDelta(t, basePath,
+		deltaRecord{10, []byte("second")},
+		deltaRecord{20, []byte("back")},
+		deltaRecord{150, nil},
+		deltaRecord{160, []byte("newer")},
+	)
+	assert.Equal(t, filepath.Join(basePath, "delta_001"), path)
+
+	expected[10] = []byte("second")
+	expected[20] = []byte("back")
+	expected[30] = []byte{}
+	expected[160] = []byte("newer")
+
+	reader, err := NewDeltaReader(basePath)
+	require.NoError(t, err)
+	defer closeReader(t, reader)
+	assertDeltaContent(t, reader, expected)
+	assert.Equal(t, uint64(108), reader.MetaData().NumRecords)
+	assert.Equal(t, intToByteSlice(0), reader.MetaData().MinKey)
+	assert.Equal(t, intToByteSlice(160), reader.MetaData().MaxKey)
+
+	it, err := reader.ScanRange(intToByteSlice(5), intToByteSlice(25))
+	require.NoError(t, err)
+	var keys [][]byte
+	for {
+		k, _, err := it.Next()
+		if err == Done {
+			break
+		}
+		require.NoError(t, err)
+		keys = append(keys, k)
+	}
+	assert.Equal(t, 21, len(keys))
+
+	flattened := t.TempDir()
+	require.NoError(t, reader.Flatten(WriteBasePath(flattened)))
+	flatReader, err := NewSSTableReader(ReadBasePath(flattened))
+	require.NoError(t, err)
+	defer closeReader(t, flatReader)
+	assertDeltaContent(t, flatReader, expected)
+	assert.Equal(t, uint64(len(expected)), flatReader.MetaData().NumRecords)
+
+	assert.Error(t, reader.Flatten(WriteBasePath(basePath)))
+}
+
+func TestDeltaReaderWithoutDeltas(t *testing.T) {
+	writer, err := newTestSSTableStreamWriter()
+	require.NoError(t, err)
+	defer cleanWriterDir(t, writer)
+	expected := map[int][]byte{}
+	for _, i := range streamedWriteAscendingIntegers(t, writer, 50) {
+		_, v := getKeyValueAsBytes(i)
+		expected[i] = v
+	}
+
+	reader, err := NewDeltaReader(writer.opts.basePath)
+	require.NoError(t, err)
+	defer closeReader(t, reader)
+	assertDeltaContent(t, reader, expected)
+}
+
+func TestDeltaReaderMissingDelta(t *testing.T) {
+	writer, err := newTestSSTableStreamWriter()
+	require.NoError(t, err)
+	defer cleanWriterDir(t, writer)
+	basePath := writer.opts.basePath
+	streamedWriteAscendingIntegers(t, writer, 50)
+
+	writeDelta(t, basePath, deltaRecord{1, []byte("a")})
+	writeDelta(t, basePath, deltaRecord{2, []byte("b")})
+	require.NoError(t, os.RemoveAll(filepath.Join(basePath, "delta_000")))
+
+	_, err = NewDeltaReader(basePath)
+	assert.ErrorContains(t, err, "delta 0 is missing")
+
+	deltaWriter, err := NewDeltaWriter(basePath, WithKeyComparator(skiplist.BytesComparator{}))
+	require.NoError(t, err)
+	assert.ErrorContains(t, deltaWriter.Open(), "delta 0 is missing")
+}
+
+func TestDeltaWriterWithoutBase(t *testing.T) {
+	_, err := NewDeltaWriter(t.TempDir())
+	assert.ErrorContains(t, err, "no key comparator supplied")
+
+	writer, err := NewDeltaWriter(t.TempDir(), WithKeyComparator(skiplist.BytesComparator{}))
+	require.NoError(t, err)
+	assert.ErrorIs(t, writer.Open(), os.ErrNotExist)
+	assert.Error(t, writer.WriteNext([]byte{1}, []byte{1}))
+}
+
+func TestDeltaWriterUncommittedDelta(t *testing.T) {
+	writer, err := newTestSSTableStreamWriter()
+	require.NoError(t, err)
+	defer cleanWriterDir(t, writer)
+	basePath := writer.opts.basePath
+	expected := map[int][]byte{}
+	for _, i := range streamedWriteAscendingIntegers(t, writer, 50) {
+		_, v := getKeyValueAsBytes(i)
+		expected[i] = v
+	}
+
+	// a writer that is never closed, like after a crash, must not leave a delta behind
+	abandoned, err := NewDeltaWriter(basePath, WithKeyComparator(skiplist.BytesComparator{}))
+	require.NoError(t, err)
+	require.NoError(t, abandoned.Open())
+	require.NoError(t, abandoned.WriteNext(intToByteSlice(1), []byte("lost")))
+	assert.NoDirExists(t, abandoned.Path())
+
+	reader, err := NewDeltaReader(basePath)
+	require.NoError(t, err)
+	assertDeltaContent(t, reader, expected)
+	require.NoError(t, reader.Close())
+
+	path := writeDelta(t, basePath, deltaRecord{1, []byte("a")})
+	assert.Equal(t, filepath.Join(basePath, "delta_000"), path)
+	expected[1] = []byte("a")
+
+	reader, err = NewDeltaReader(basePath)
+	require.NoError(t, err)
+	defer closeReader(t, reader)
+	assertDeltaContent(t, reader, expected)
+}
+
+func TestDeltaWriterFailedOpenCleansUp(t *testing.T) {
+	writer, err := newTestSSTableStreamWriter()
+	require.NoError(t, err)
+	defer cleanWriterDir(t, writer)
+	basePath := writer.opts.basePath
+	streamedWriteAscendingIntegers(t, writer, 10)
+	entries, err := os.ReadDir(basePath)
+	require.NoError(t, err)
+
+	// the data file can't be created in a directory that doesn't exist
+	deltaWriter, err := NewDeltaWriter(basePath, WithKeyComparator(skiplist.BytesComparator{}), WithDataFileName("missing/data.rio"))
+	require.NoError(t, err)
+	assert.Error(t, deltaWriter.Open())
+
+	after, err := os.ReadDir(basePath)
+	require.NoError(t, err)
+	assert.Equal(t, entries, after)
+	reader, err := NewDeltaReader(basePath)
+	require.NoError(t, err)
+	closeReader(t, reader)
+}
@@ -0,0 +1,134 @@
+package sstables
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/thomasjungblut/go-sstables/skiplist"
+)
+
+// DiffSide describes how a key differs between the two tables of a Diff.
+type DiffSide int
+
+const (
+	// DiffOnlyInA marks keys that only exist in the first table.
+	DiffOnlyInA DiffSide = iota
+	// DiffOnlyInB marks keys that only exist in the second table.
+	DiffOnlyInB
+	// DiffValueMismatch marks keys that exist in both tables with different values.
+	DiffValueMismatch
+)
+
+func (s DiffSide) String() string {
+	switch s {
+	case DiffOnlyInA:
+		return "OnlyInA"
+	case DiffOnlyInB:
+		return "OnlyInB"
+	case DiffValueMismatch:
+		return "ValueMismatch"
+	}
+	return fmt.Sprintf("DiffSide(%d)", int(s))
+}
+
+// DiffEntry is a single key that differs between two tables. The value of the side that doesn't contain the key is nil.
+type DiffEntry struct {
+	Key    []byte
+	Side   DiffSide
+	ValueA []byte
+	ValueB []byte
+}
+
+// DiffIterator streams the differences of two tables in key order, see Diff.
+type DiffIterator struct {
+	cmp                skiplist.Comparator[[]byte]
+	itA, itB           SSTableIteratorI
+	keyA, valA         []byte
+	keyB, valB         []byte
+	doneA, doneB       bool
+	advanceA, advanceB bool
+}
+
+// Diff co-iterates both tables with a full Scan and returns an iterator over all keys that only exist on one side or
+// whose values differ, for example to verify that a merge produced the expected table. Both tables need to be sorted
+// by the given comparator. Nil values (tombstones) only equal other nil values, so a tombstone on one side and a put on
+// the other, even of an empty value, is a DiffValueMismatch. Nothing is materialized besides the current record of
+// each table. When a reader was opened with ReadReuseScanBuffers, the values are only valid until the next call to Next.
+func Diff(readerA SSTableReaderI, readerB SSTableReaderI, cmp skiplist.Comparator[[]byte]) (*DiffIterator, error) {
+	itA, err := readerA.Scan()
+	if err != nil {
+		return nil, fmt.Errorf("diff error while scanning the first table: %w", err)
+	}
+	itB, err := readerB.Scan()
+	if err != nil {
+		return nil, fmt.Errorf("diff error while scanning the second table: %w", err)
+	}
+	return &DiffIterator{cmp: cmp, itA: itA, itB: itB, advanceA: true, advanceB: true}, nil
+}
+
+// Next returns the next differing key, Done as the error when both tables were compared completely.
+func (d *DiffIterator) Next() (DiffEntry, error) {
+	for {
+		if err := d.advance(); err != nil {
+			return DiffEntry{}, err
+		}
+
+		if d.doneA && d.doneB {
+			return DiffEntry{}, Done
+		}
+
+		var c int
+		if d.doneA {
+			c = 1
+		} else if d.doneB {
+			c = -1
+		} else {
+			c = d.cmp.Compare(d.keyA, d.keyB)
+		}
+
+		if c < 0 {
+			d.advanceA = true
+			return DiffEntry{Key: d.keyA, Side: DiffOnlyInA, ValueA: d.valA}, nil
+		}
+		if c > 0 {
+			d.advanceB = true
+			return DiffEntry{Key: d.keyB, Side: DiffOnlyInB, ValueB: d.valB}, nil
+		}
+
+		d.advanceA, d.advanceB = true, true
+		if !valuesEqual(d.valA, d.valB) {
+			return DiffEntry{Key: d.keyA, Side: DiffValueMismatch, ValueA: d.valA, ValueB: d.valB}, nil
+		}
+	}
+}
+
+// advance moves the iterators whose record was consumed by the previous call to Next
+func (d *DiffIterator) advance() error {
+	if d.advanceA && !d.doneA {
+		k, v, err := d.itA.Next()
+		if err != nil && !errors.Is(err, Done) {
+			return fmt.Errorf("diff error while reading the first table: %w", err)
+		}
+		d.keyA, d.valA, d.doneA = k, v, err != nil
+	}
+	d.advanceA = false
+
+	if d.advanceB && !d.doneB {
+		k, v, err := d.itB.Next()
+		if err != nil && !errors.Is(err, Done) {
+			return fmt.Errorf("diff error while reading the second table: %w", err)
+		}
+		d.keyB, d.valB, d.doneB = k, v, err != nil
+	}
+	d.advanceB = false
+	return nil
+}
+
+// valuesEqual distinguishes nil values (tombstones) from empty values
+func valuesEqual(a []byte, b []byte) bool {
+	if (a == nil) != (b == nil) {
+		return false
+	}
+	return bytes.Equal(a, b)
+}
@@ -0,0 +1,108 @@
+package sstables
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thomasjungblut/go-sstables/skiplist"
+)
+
+func writeDiffTable(t *testing.T, kvs []KV) string {
+	tmpDir, err := os.MkdirTemp("", "sstables_Diff")
+	require.NoError(t, err)
+	writer, err := NewSSTableStreamWriter(WriteBasePath(tmpDir), WithKeyComparator(skiplist.BytesComparator{}))
+	require.NoError(t, err)
+	require.NoError(t, writer.Open())
+	for _, kv := range kvs {
+		require.NoError(t, writer.WriteNext(kv.Key, kv.Value))
+	}
+	require.NoError(t, writer.Close())
+	return tmpDir
+}
+
+func collectDiff(t *testing.T, pathA string, pathB string) []DiffEntry {
+	readerA, err := NewSSTableReader(ReadBasePath(pathA))
+	require.NoError(t, err)
+	defer closeReader(t, readerA)
+	readerB, err := NewSSTableReader(ReadBasePath(pathB))
+	require.NoError(t, err)
+	defer closeReader(t, readerB)
+
+	it, err := Diff(readerA, readerB, skiplist.BytesComparator{})
+	require.NoError(t, err)
+	var entries []DiffEntry
+	for {
+		e, err := it.Next()
+		if errors.Is(err, Done) {
+			break
+		}
+		require.NoError(t, err)
+		entries = append(entries, e)
+	}
+	_, err = it.Next()
+	assert.ErrorIs(t, err, Done)
+	return entries
+}
+
+func TestDiff(t *testing.T) {
+	pathA := writeDiffTable(t, []KV{
+		{Key: []byte("a"), Value: []byte("1")},
+		{Key: []byte("b"), Value: []byte("2")},
+		{Key: []byte("c"), Value: nil},
+		{Key: []byte("d"), Value: nil},
+		{Key: []byte("e"), Value: []byte("5")},
+		{Key: []byte("g"), Value: nil},
+	})
+	defer func() { require.NoError(t, os.RemoveAll(pathA)) }()
+	pathB := writeDiffTable(t, []KV{
+		{Key: []byte("b"), Value: []byte("2")},
+		{Key: []byte("c"), Value: []byte{}},
+		{Key: []byte("d"), Value: nil},
+		{Key: []byte("e"), Value: []byte("6")},
+		{Key: []byte("f"), Value: []byte("7")},
+		{Key: []byte("h"), Value: []byte("8")},
+	})
+	defer func() { require.NoError(t, os.RemoveAll(pathB)) }()
+
+	assert.Equal(t, []DiffEntry{
+		{Key: []byte("a"), Side: DiffOnlyInA, ValueA: []byte("1")},
+		{Key: []byte("c"), Side: DiffValueMismatch, ValueB: []byte{}},
+		{Key: []byte("e"), Side: DiffValueMismatch, ValueA: []byte("5"), ValueB: []byte("6")},
+		{Key: []byte("f"), Side: DiffOnlyInB, ValueB: []byte("7")},
+		{Key: []byte("g"), Side: DiffOnlyInA},
+		{Key: []byte("h"), Side: DiffOnlyInB, ValueB: []byte("8")},
+	}, collectDiff(t, pathA, pathB))
+
+	// the sides swap when the tables do
+	entries := collectDiff(t, pathB, pathA)
+	require.Len(t, entries, 6)
+	assert.Equal(t, DiffOnlyInB, entries[0].Side)
+	assert.Equal(t, []byte("1"), entries[0].ValueB)
+}
+
+func TestDiffIdenticalAndEmptyTables(t *testing.T) {
+	writer, err := newTestSSTableStreamWriter()
+	require.NoError(t, err)
+	defer cleanWriterDir(t, writer)
+	streamedWriteAscendingIntegers(t, writer, 100)
+
+	assert.Empty(t, collectDiff(t, writer.opts.basePath, writer.opts.basePath))
+
+	empty := writeDiffTable(t, nil)
+	defer func() { require.NoError(t, os.RemoveAll(empty)) }()
+	entries := collectDiff(t, empty, writer.opts.basePath)
+	require.Len(t, entries, 100)
+	for i, e := range entries {
+		k, v := getKeyValueAsBytes(i)
+		assert.Equal(t, DiffEntry{Key: k, Side: DiffOnlyInB, ValueB: v}, e)
+	}
+}
+
+func TestDiffSideString(t *testing.T) {
+	assert.Equal(t, "OnlyInA", DiffOnlyInA.String())
+	assert.Equal(t, "ValueMismatch", DiffValueMismatch.String())
+	assert.Equal(t, "DiffSide(42)", DiffSide(42).String())
+}
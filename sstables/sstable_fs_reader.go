@@ -0,0 +1,142 @@
+package sstables
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+)
+
+// NewSSTableReaderFromFS opens the table in the directory dir of fsys, for example a table embedded with go:embed.
+// The data file is memory mapped and the index loaders read from paths, so all files of the directory are copied into
+// a temporary directory first, which is removed again when the reader is closed. BasePath returns the temporary
+// directory, a ReadBasePath in readerOptions is ignored.
+func NewSSTableReaderFromFS(fsys fs.FS, dir string, readerOptions ...ReadOption) (SSTableReaderI, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, fmt.Errorf("error while listing sstable in '%s': %w", dir, err)
+	}
+
+	tmpDir, err := os.MkdirTemp("", "sstables_fs")
+	if err != nil {
+		return nil, fmt.Errorf("error while creating temporary directory for sstable in '%s': %w", dir, err)
+	}
+
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() {
+			continue
+		}
+
+		err = copyFromFS(fsys, path.Join(dir, entry.Name()), filepath.Join(tmpDir, entry.Name()))
+		if err != nil {
+			return nil, errors.Join(fmt.Errorf("error while copying sstable in '%s': %w", dir, err), os.RemoveAll(tmpDir))
+		}
+	}
+
+	return openTemporaryTable(tmpDir, readerOptions)
+}
+
+// NewSSTableReaderFromTarGz opens the table in a gzip compressed tar archive of its files, as created by
+// "tar -czf table.tar.gz table". The files are extracted by their base name into a temporary directory, which is
+// removed again when the reader is closed, see NewSSTableReaderFromFS. Directories in the archive are ignored, an
+// archive that contains two files with the same base name returns an error.
+func NewSSTableReaderFromTarGz(archive io.Reader, readerOptions ...ReadOption) (SSTableReaderI, error) {
+	gz, err := gzip.NewReader(archive)
+	if err != nil {
+		return nil, fmt.Errorf("error while opening gzip archive of sstable: %w", err)
+	}
+
+	tmpDir, err := os.MkdirTemp("", "sstables_tar")
+	if err != nil {
+		return nil, fmt.Errorf("error while creating temporary directory for sstable archive: %w", err)
+	}
+
+	err = extractTar(tar.NewReader(gz), tmpDir)
+	if err != nil {
+		return nil, errors.Join(fmt.Errorf("error while extracting sstable archive: %w", err), os.RemoveAll(tmpDir))
+	}
+
+	return openTemporaryTable(tmpDir, readerOptions)
+}
+
+// openTemporaryTable opens the table in tmpDir, which is removed when the reader is closed or can't be opened
+func openTemporaryTable(tmpDir string, readerOptions []ReadOption) (SSTableReaderI, error) {
+	reader, err := NewSSTableReader(append(slices.Clone(readerOptions), ReadBasePath(tmpDir))...)
+	if err != nil {
+		return nil, errors.Join(err, os.RemoveAll(tmpDir))
+	}
+
+	reader.(*SSTableReader).tempDir = tmpDir
+	return reader, nil
+}
+
+func copyFromFS(fsys fs.FS, src string, dst string) (err error) {
+	in, err := fsys.Open(src)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = errors.Join(err, in.Close())
+	}()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = errors.Join(err, out.Close())
+	}()
+
+	_, err = io.Copy(out, in)
+	return err
+}
+
+func extractTar(tr *tar.Reader, dir string) error {
+	seen := map[string]struct{}{}
+	for {
+		hdr, err := tr.Next()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		// only the base name is used, which can't escape the directory
+		name := path.Base(filepath.ToSlash(hdr.Name))
+		if name == "." || name == ".." || name == "/" {
+			return fmt.Errorf("unexpected file name '%s'", hdr.Name)
+		}
+		if _, ok := seen[name]; ok {
+			return fmt.Errorf("duplicate file name '%s'", name)
+		}
+		seen[name] = struct{}{}
+
+		if err := extractTarEntry(tr, filepath.Join(dir, name)); err != nil {
+			return fmt.Errorf("error while extracting '%s': %w", hdr.Name, err)
+		}
+	}
+}
+
+func extractTarEntry(tr *tar.Reader, dst string) (err error) {
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = errors.Join(err, out.Close())
+	}()
+
+	_, err = io.Copy(out, tr)
+	return err
+}
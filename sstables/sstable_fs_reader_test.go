@@ -0,0 +1,96 @@
+package sstables
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReaderFromFS(t *testing.T) {
+	reader, err := NewSSTableReaderFromFS(os.DirFS("test_files"), "SimpleWriteHappyPathSSTableWithBloom")
+	require.NoError(t, err)
+	tmpDir := reader.BasePath()
+	assert.NotEqual(t, "test_files/SimpleWriteHappyPathSSTableWithBloom", tmpDir)
+
+	assert.Equal(t, 7, int(reader.MetaData().NumRecords))
+	assertContentMatchesSkipList(t, reader, TEST_ONLY_NewSkipListMapWithElements([]int{1, 2, 3, 4, 5, 6, 7}))
+	closeReader(t, reader)
+
+	_, err = os.Stat(tmpDir)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestReaderFromFSErrors(t *testing.T) {
+	_, err := NewSSTableReaderFromFS(os.DirFS("test_files"), "missing")
+	require.Error(t, err)
+
+	// the table can't be opened without its data file
+	fsys := fstest.MapFS{}
+	for _, name := range []string{IndexFileName, MetaFileName} {
+		content, err := os.ReadFile(filepath.Join("test_files/SimpleWriteHappyPathSSTableWithBloom", name))
+		require.NoError(t, err)
+		fsys["table/"+name] = &fstest.MapFile{Data: content}
+	}
+	_, err = NewSSTableReaderFromFS(fsys, "table")
+	require.Error(t, err)
+}
+
+func TestReaderFromTarGz(t *testing.T) {
+	archive := tarGzTable(t, "test_files/SimpleWriteHappyPathSSTableWithBloom", "table/")
+	reader, err := NewSSTableReaderFromTarGz(bytes.NewReader(archive))
+	require.NoError(t, err)
+	tmpDir := reader.BasePath()
+
+	assertContentMatchesSkipList(t, reader, TEST_ONLY_NewSkipListMapWithElements([]int{1, 2, 3, 4, 5, 6, 7}))
+	closeReader(t, reader)
+
+	_, err = os.Stat(tmpDir)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestReaderFromTarGzDuplicateNames(t *testing.T) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for _, name := range []string{"a/" + DataFileName, "b/" + DataFileName} {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: 1, Typeflag: tar.TypeReg}))
+		_, err := tw.Write([]byte{1})
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, gz.Close())
+
+	_, err := NewSSTableReaderFromTarGz(&buf)
+	assert.ErrorContains(t, err, "duplicate file name")
+
+	_, err = NewSSTableReaderFromTarGz(bytes.NewReader([]byte("not gzip")))
+	require.Error(t, err)
+}
+
+// tarGzTable archives all files of the table in dir with the given prefix, including an entry for the directory
+func tarGzTable(t *testing.T, dir string, prefix string) []byte {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	require.NoError(t, tw.WriteHeader(&tar.Header{Name: prefix, Mode: 0755, Typeflag: tar.TypeDir}))
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	for _, entry := range entries {
+		content, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		require.NoError(t, err)
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: prefix + entry.Name(), Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg}))
+		_, err = tw.Write(content)
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, gz.Close())
+	return buf.Bytes()
+}
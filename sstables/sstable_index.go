@@ -1,15 +1,98 @@
 package sstables
 
 import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+
 	"github.com/thomasjungblut/go-sstables/recordio"
 	"github.com/thomasjungblut/go-sstables/skiplist"
 	"github.com/thomasjungblut/go-sstables/sstables/proto"
+	"golang.org/x/exp/slices"
 )
 
 type IndexVal struct {
 	Offset     uint64
 	Checksum   uint64
 	Tombstoned bool
+	// NullValue is true when the value was written as nil, as opposed to an empty value
+	NullValue bool
+	// SequenceNumber is the version of the record given to WriteNextWithSeq, zero otherwise
+	SequenceNumber uint64
+}
+
+// IndexEntryView is a read-only view of a single index entry, as returned by SSTableReader.IndexIterator.
+// The key is a copy, so it can't change the loaded index.
+type IndexEntryView struct {
+	Key []byte
+	IndexVal
+}
+
+// IndexEntryIterator iterates the entries of the index in key order, without reading any values.
+type IndexEntryIterator struct {
+	it skiplist.IteratorI[[]byte, IndexVal]
+}
+
+// Next returns the next entry of the index, Done as the error when the iterator is exhausted
+func (i *IndexEntryIterator) Next() (IndexEntryView, error) {
+	key, iVal, err := i.it.Next()
+	if err != nil {
+		if errors.Is(err, skiplist.Done) {
+			return IndexEntryView{}, Done
+		}
+		return IndexEntryView{}, err
+	}
+	return IndexEntryView{Key: slices.Clone(key), IndexVal: iVal}, nil
+}
+
+// updateIndexChecksum adds the given index entry to the running checksum over the whole index.
+func updateIndexChecksum(crc hash.Hash64, key []byte, val IndexVal) {
+	buf := make([]byte, binary.MaxVarintLen64+16+1)
+	n := binary.PutUvarint(buf, uint64(len(key)))
+	_, _ = crc.Write(buf[:n])
+	_, _ = crc.Write(key)
+	binary.BigEndian.PutUint64(buf, val.Offset)
+	binary.BigEndian.PutUint64(buf[8:], val.Checksum)
+	buf[16] = 0
+	if val.NullValue {
+		buf[16] = 1
+	}
+	_, _ = crc.Write(buf[:17])
+	// sequence numbers are only hashed when present, which keeps the checksums of older tables stable
+	if val.SequenceNumber != 0 {
+		binary.BigEndian.PutUint64(buf, val.SequenceNumber)
+		_, _ = crc.Write(buf[:8])
+	}
+}
+
+// fullIndexKey reconstructs the key of a prefix compressed index entry from the full key of the previous entry.
+// Entries without a shared prefix, for example all entries of uncompressed indices, already contain the full key.
+func fullIndexKey(prevKey []byte, entry *proto.IndexEntry) ([]byte, error) {
+	shared := int(entry.SharedPrefixLength)
+	if shared == 0 {
+		return entry.Key, nil
+	}
+
+	if shared > len(prevKey) {
+		return nil, fmt.Errorf("shared prefix length %d exceeds the previous key length %d", shared, len(prevKey))
+	}
+
+	key := make([]byte, shared+len(entry.Key))
+	copy(key, prevKey[:shared])
+	copy(key[shared:], entry.Key)
+	return key, nil
+}
+
+// sharedPrefixLength returns the length of the common prefix of both keys
+func sharedPrefixLength(a []byte, b []byte) int {
+	n := min(len(a), len(b))
+	for i := 0; i < n; i++ {
+		if a[i] != b[i] {
+			return i
+		}
+	}
+	return n
 }
 
 type NoOpOpenClose struct {
@@ -42,6 +125,12 @@ type SortedKeyIndex interface {
 	IteratorBetween(keyLower []byte, keyHigher []byte) (skiplist.IteratorI[[]byte, IndexVal], error)
 }
 
+// OrdinalIndex is implemented by indices that can return the entry at a position in key order in constant time.
+type OrdinalIndex interface {
+	// EntryAt returns the key and IndexVal at the given 0-based position, ErrOrdinalOutOfRange if there is none.
+	EntryAt(n uint64) ([]byte, IndexVal, error)
+}
+
 type IndexLoader interface {
 	// Load is creating a SortedKeyIndex from the given path.
 	Load(path string, metadata *proto.MetaData) (SortedKeyIndex, error)
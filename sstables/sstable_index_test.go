@@ -2,10 +2,12 @@ package sstables
 
 import (
 	"encoding/binary"
+	"fmt"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/thomasjungblut/go-sstables/skiplist"
 	"github.com/thomasjungblut/go-sstables/sstables/proto"
+	"os"
 	"reflect"
 	"testing"
 )
@@ -20,6 +22,9 @@ var indexLoaders = []func() IndexLoader{
 	func() IndexLoader {
 		return &SliceKeyIndexLoader{ReadBufferSize: 4096}
 	},
+	func() IndexLoader {
+		return &ArenaKeyIndexLoader{ReadBufferSize: 4096}
+	},
 	func() IndexLoader {
 		return &DiskIndexLoader{}
 	},
@@ -239,3 +244,88 @@ func assertIndexIteratorMatchesSlice(t *testing.T, it skiplist.IteratorI[[]byte,
 	require.Nil(t, k)
 	require.Equal(t, IndexVal{}, v)
 }
+
+func TestIndexPrefixCompressedKeys(t *testing.T) {
+	for _, summaryEveryNthKey := range []int{0, 7} {
+		tmpDir := writePrefixCompressedEvenKeys(t, summaryEveryNthKey)
+		for _, loaderFunc := range indexLoaders {
+			loader := loaderFunc()
+			t.Run(reflect.TypeOf(loader).String(), func(t *testing.T) {
+				r, err := NewSSTableReader(ReadBasePath(tmpDir), ReadIndexLoader(loader))
+				require.NoError(t, err)
+				defer closeReader(t, r)
+
+				var expected []int
+				for i := -1; i < 1002; i++ {
+					v, err := r.Get(intToByteSlice(i))
+					if i >= 0 && i < 1000 && i%2 == 0 {
+						require.NoError(t, err, "key %d", i)
+						assert.Equal(t, intToByteSlice(i+1), v)
+						expected = append(expected, i)
+					} else {
+						assert.ErrorIs(t, err, ErrKeyNotFound, "key %d", i)
+					}
+				}
+				assertContentMatchesSlice(t, r, expected)
+
+				it, err := r.ScanStartingAt(intToByteSlice(255))
+				require.NoError(t, err)
+				assertIteratorMatchesSlice(t, it, expected[128:])
+
+				it, err = r.ScanRange(intToByteSlice(254), intToByteSlice(300))
+				require.NoError(t, err)
+				assertIteratorMatchesSlice(t, it, expected[127:151])
+			})
+		}
+		require.NoError(t, os.RemoveAll(tmpDir))
+	}
+}
+
+func TestIndexPrefixCompressionShrinksPathKeys(t *testing.T) {
+	indexBytes := make([]uint64, 2)
+	for i, restartInterval := range []int{0, 16} {
+		tmpDir, err := os.MkdirTemp("", "sstables_PrefixCompression")
+		require.NoError(t, err)
+		defer func() { require.NoError(t, os.RemoveAll(tmpDir)) }()
+
+		writer, err := NewSSTableStreamWriter(
+			WriteBasePath(tmpDir),
+			WithKeyComparator(skiplist.BytesComparator{}),
+			IndexKeyPrefixCompression(restartInterval))
+		require.NoError(t, err)
+		require.NoError(t, writer.Open())
+		for j := 0; j < 1000; j++ {
+			require.NoError(t, writer.WriteNext([]byte(fmt.Sprintf("/some/very/long/hierarchical/path/%05d", j)), []byte{1}))
+		}
+		require.NoError(t, writer.Close())
+
+		r, err := NewSSTableReader(ReadBasePath(tmpDir))
+		require.NoError(t, err)
+		v, err := r.Get([]byte("/some/very/long/hierarchical/path/00042"))
+		require.NoError(t, err)
+		assert.Equal(t, []byte{1}, v)
+		indexBytes[i] = r.MetaData().IndexBytes
+		closeReader(t, r)
+	}
+
+	assert.Less(t, indexBytes[1], indexBytes[0]/2)
+}
+
+func writePrefixCompressedEvenKeys(t *testing.T, summaryEveryNthKey int) string {
+	tmpDir, err := os.MkdirTemp("", "sstables_PrefixCompression")
+	require.NoError(t, err)
+
+	writer, err := NewSSTableStreamWriter(
+		WriteBasePath(tmpDir),
+		WithKeyComparator(skiplist.BytesComparator{}),
+		IndexKeyPrefixCompression(5),
+		SummaryEveryNthKey(summaryEveryNthKey))
+	require.NoError(t, err)
+	require.NoError(t, writer.Open())
+	for i := 0; i < 1000; i += 2 {
+		k, v := getKeyValueAsBytes(i)
+		require.NoError(t, writer.WriteNext(k, v))
+	}
+	require.NoError(t, writer.Close())
+	return tmpDir
+}
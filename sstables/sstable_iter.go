@@ -0,0 +1,62 @@
+package sstables
+
+import (
+	"errors"
+	"iter"
+)
+
+// All returns the records of Scan for a range-over-func loop: for k, v := range reader.All() {}.
+// The loop ends early at the first error of the scan, use AllE to get it.
+func (reader *SSTableReader) All() iter.Seq2[[]byte, []byte] {
+	return reader.AllE(nil)
+}
+
+// AllE is like All, but stores the first error of the scan in err once the loop ended, nil otherwise. err can be nil to
+// ignore the error.
+func (reader *SSTableReader) AllE(err *error) iter.Seq2[[]byte, []byte] {
+	return func(yield func([]byte, []byte) bool) {
+		setErr(err, scanAll(reader, yield))
+	}
+}
+
+// Keys returns the keys of Scan for a range-over-func loop, the values are still read and verified like in Scan. Use
+// IndexIterator to only read the index. The loop ends early at the first error of the scan, use KeysE to get it.
+func (reader *SSTableReader) Keys() iter.Seq[[]byte] {
+	return reader.KeysE(nil)
+}
+
+// KeysE is like Keys, but stores the first error of the scan in err once the loop ended, nil otherwise.
+func (reader *SSTableReader) KeysE(err *error) iter.Seq[[]byte] {
+	return func(yield func([]byte) bool) {
+		setErr(err, scanAll(reader, func(key []byte, _ []byte) bool {
+			return yield(key)
+		}))
+	}
+}
+
+// scanAll passes all records of Scan to yield until it returns false
+func scanAll(reader *SSTableReader, yield func([]byte, []byte) bool) error {
+	it, err := reader.Scan()
+	if err != nil {
+		return err
+	}
+
+	for {
+		key, value, err := it.Next()
+		if errors.Is(err, Done) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if !yield(key, value) {
+			return nil
+		}
+	}
+}
+
+func setErr(dst *error, err error) {
+	if dst != nil {
+		*dst = err
+	}
+}
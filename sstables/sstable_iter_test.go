@@ -0,0 +1,72 @@
+package sstables
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReaderAllAndKeys(t *testing.T) {
+	writer, err := newTestSSTableStreamWriter()
+	require.NoError(t, err)
+	defer cleanWriterDir(t, writer)
+	streamedWriteAscendingIntegers(t, writer, 100)
+
+	r, err := NewSSTableReader(ReadBasePath(writer.opts.basePath))
+	require.NoError(t, err)
+	defer closeReader(t, r)
+	reader := r.(*SSTableReader)
+
+	expected := scanRecords(t, reader)
+	var records []scannedRecord
+	for k, v := range reader.All() {
+		records = append(records, scannedRecord{k, v})
+	}
+	assert.Equal(t, expected, records)
+
+	var keys [][]byte
+	var iterErr error
+	for k := range reader.KeysE(&iterErr) {
+		keys = append(keys, k)
+	}
+	require.NoError(t, iterErr)
+	require.Equal(t, len(expected), len(keys))
+	for i, k := range keys {
+		assert.Equal(t, expected[i].key, k)
+	}
+
+	// breaking out of the loop is not an error
+	count := 0
+	for range reader.AllE(&iterErr) {
+		count++
+		if count == 10 {
+			break
+		}
+	}
+	require.NoError(t, iterErr)
+	assert.Equal(t, 10, count)
+}
+
+func TestReaderAllStopsOnError(t *testing.T) {
+	reader, err := NewSSTableReader(
+		ReadBasePath("test_files/SimpleWriteHappyPathSSTableWithCRCHashesMismatch"),
+		SkipHashCheckOnLoad(),
+		VerifyChecksumsOnScan())
+	require.NoError(t, err)
+	defer closeReader(t, reader)
+
+	var keys [][]byte
+	var iterErr error
+	for k := range reader.(*SSTableReader).KeysE(&iterErr) {
+		keys = append(keys, k)
+	}
+	assert.ErrorIs(t, iterErr, ChecksumError{})
+	assert.Equal(t, [][]byte{intToByteSlice(1), intToByteSlice(2), intToByteSlice(3)}, keys)
+
+	keys = nil
+	for k := range reader.(*SSTableReader).Keys() {
+		keys = append(keys, k)
+	}
+	assert.Equal(t, 3, len(keys))
+}
@@ -6,11 +6,16 @@ import (
 	rProto "github.com/thomasjungblut/go-sstables/recordio/proto"
 	"github.com/thomasjungblut/go-sstables/skiplist"
 	"github.com/thomasjungblut/go-sstables/sstables/proto"
+	"hash"
 )
 
 type SSTableIterator struct {
 	reader      *SSTableReader
 	keyIterator skiplist.IteratorI[[]byte, IndexVal]
+	seq         uint64
+	// reuseBuffers reads every value into valueBuf, see ReadReuseScanBuffers
+	reuseBuffers bool
+	valueBuf     []byte
 }
 
 func (it *SSTableIterator) Next() ([]byte, []byte, error) {
@@ -22,15 +27,36 @@ func (it *SSTableIterator) Next() ([]byte, []byte, error) {
 			return nil, nil, err
 		}
 	}
+	it.seq = iv.SequenceNumber
 
-	valBytes, err := it.reader.getValueAtOffset(iv, it.reader.opts.skipHashCheckOnRead)
+	verify := it.reader.opts.verifyChecksumsOnScan
+	var valBytes []byte
+	if it.reuseBuffers {
+		valBytes, err = it.reader.getValueAtOffsetInto(iv, it.reader.opts.skipHashCheckOnRead && !verify, it.valueBuf)
+		if valBytes != nil {
+			it.valueBuf = valBytes
+		}
+	} else {
+		valBytes, err = it.reader.getValueAtOffset(iv, it.reader.opts.skipHashCheckOnRead && !verify)
+	}
 	if err != nil {
+		var checksumErr ChecksumError
+		if errors.As(err, &checksumErr) {
+			if verify {
+				return key, valBytes, ScanChecksumError{Key: key, Offset: iv.Offset, Err: checksumErr}
+			}
+			return key, valBytes, err
+		}
 		return nil, nil, err
 	}
 
 	return key, valBytes, nil
 }
 
+func (it *SSTableIterator) SequenceNumber() uint64 {
+	return it.seq
+}
+
 // V0SSTableFullScanIterator deprecated, since this is for the v0 protobuf based sstables.
 // this is an optimized iterator that does a sequential read over the index+data files instead of a
 // sequential read on the index with a random access lookup on the data file via mmap
@@ -58,6 +84,11 @@ func (it *V0SSTableFullScanIterator) Next() ([]byte, []byte, error) {
 	return key, value.Value, nil
 }
 
+// SequenceNumber is always zero, v0 tables have no sequence numbers
+func (it *V0SSTableFullScanIterator) SequenceNumber() uint64 {
+	return 0
+}
+
 func newV0SStableFullScanIterator(keyIterator skiplist.IteratorI[[]byte, IndexVal], dataReader rProto.ReaderI) (SSTableIteratorI, error) {
 	return &V0SSTableFullScanIterator{
 		keyIterator: keyIterator,
@@ -72,6 +103,15 @@ type SSTableFullScanIterator struct {
 	dataReader  recordio.ReaderI
 
 	skipHashCheck bool
+	// verifyChecksums forces the check and reports mismatches as ScanChecksumError
+	verifyChecksums bool
+	newChecksum     func() hash.Hash64
+	seq             uint64
+	// reuseBuffers reads every value into valueBuf, see ReadReuseScanBuffers
+	reuseBuffers bool
+	valueBuf     []byte
+	// throttle is nil without ReadWithRateLimiter
+	throttle *scanThrottle
 }
 
 func (it *SSTableFullScanIterator) Next() ([]byte, []byte, error) {
@@ -84,16 +124,41 @@ func (it *SSTableFullScanIterator) Next() ([]byte, []byte, error) {
 		}
 	}
 
-	next, err := it.dataReader.ReadNext()
+	var next []byte
+	if intoReader, ok := it.dataReader.(recordio.IntoReaderI); ok && it.reuseBuffers {
+		next, err = intoReader.ReadNextInto(it.valueBuf)
+		if next != nil {
+			it.valueBuf = next
+		}
+	} else {
+		next, err = it.dataReader.ReadNext()
+	}
 	if err != nil {
 		return nil, nil, err
 	}
+	it.seq = iVal.SequenceNumber
+
+	if it.throttle != nil {
+		if err := it.throttle.wait(); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if iVal.NullValue {
+		next = nil
+	}
 
-	if it.skipHashCheck {
+	if it.skipHashCheck && !it.verifyChecksums {
 		return key, next, nil
 	}
 
-	checksum, err := checksumValue(next)
+	return checkScannedValue(key, next, iVal, it.verifyChecksums, it.newChecksum)
+}
+
+// checkScannedValue compares the checksum of a value that was read by a full scan with its index entry. With
+// verifyChecksums, mismatches are returned as ScanChecksumError.
+func checkScannedValue(key []byte, value []byte, iVal IndexVal, verifyChecksums bool, newChecksum func() hash.Hash64) ([]byte, []byte, error) {
+	checksum, err := checksumValue(newChecksum, value)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -101,22 +166,170 @@ func (it *SSTableFullScanIterator) Next() ([]byte, []byte, error) {
 	if checksum != iVal.Checksum {
 		// this mismatch could come from default values, reading older formats
 		if iVal.Checksum == 0 {
-			return key, next, nil
+			return key, value, nil
 		}
 
-		return key, next, ChecksumError{checksum, iVal.Checksum}
+		if verifyChecksums {
+			return key, value, ScanChecksumError{Key: key, Offset: iVal.Offset, Err: ChecksumError{checksum, iVal.Checksum}}
+		}
+		return key, value, ChecksumError{checksum, iVal.Checksum}
 	}
 
-	return key, next, err
+	return key, value, nil
+}
+
+func (it *SSTableFullScanIterator) SequenceNumber() uint64 {
+	return it.seq
 }
 
 func newSStableFullScanIterator(
 	keyIterator skiplist.IteratorI[[]byte, IndexVal],
 	dataReader recordio.ReaderI,
-	skipHashCheck bool) (SSTableIteratorI, error) {
+	skipHashCheck bool,
+	verifyChecksums bool,
+	newChecksum func() hash.Hash64,
+	reuseBuffers bool,
+	throttle *scanThrottle) (SSTableIteratorI, error) {
 	return &SSTableFullScanIterator{
-		keyIterator:   keyIterator,
-		dataReader:    dataReader,
-		skipHashCheck: skipHashCheck,
+		keyIterator:     keyIterator,
+		dataReader:      dataReader,
+		skipHashCheck:   skipHashCheck,
+		verifyChecksums: verifyChecksums,
+		newChecksum:     newChecksum,
+		reuseBuffers:    reuseBuffers,
+		throttle:        throttle,
 	}, nil
 }
+
+// skipCorruptIterator skips the records of the wrapped iterator that fail their checksum, see ChecksumMismatchPolicy
+type skipCorruptIterator struct {
+	it     SSTableIteratorI
+	reader *SSTableReader
+}
+
+func (it *skipCorruptIterator) Next() ([]byte, []byte, error) {
+	for {
+		key, value, err := it.it.Next()
+		if err != nil && it.reader.treatAsMissing(key, err) {
+			continue
+		}
+		return key, value, err
+	}
+}
+
+func (it *skipCorruptIterator) SequenceNumber() uint64 {
+	if sequenced, ok := it.it.(SequencedIteratorI); ok {
+		return sequenced.SequenceNumber()
+	}
+	return 0
+}
+
+// versionFilterIterator only returns the newest visible version of every key, which requires the versions of a key to
+// be returned next to each other by ascending sequence numbers.
+type versionFilterIterator struct {
+	it        SequencedIteratorI
+	cmp       skiplist.Comparator[[]byte]
+	isVisible func(seq uint64) bool
+
+	// the record that was read ahead, it's the first version of the next key
+	pendingKey   []byte
+	pendingValue []byte
+	pendingSeq   uint64
+	hasPending   bool
+	exhausted    bool
+	seq          uint64
+}
+
+func (it *versionFilterIterator) Next() ([]byte, []byte, error) {
+	for {
+		if !it.hasPending {
+			if it.exhausted {
+				return nil, nil, Done
+			}
+			if err := it.readAhead(); err != nil {
+				return nil, nil, err
+			}
+			if !it.hasPending {
+				return nil, nil, Done
+			}
+		}
+
+		key := it.pendingKey
+		var value []byte
+		var seq uint64
+		found := false
+		for it.hasPending && it.cmp.Compare(it.pendingKey, key) == 0 {
+			if it.isVisible(it.pendingSeq) {
+				value, seq, found = it.pendingValue, it.pendingSeq, true
+			}
+			if err := it.readAhead(); err != nil {
+				return nil, nil, err
+			}
+		}
+
+		if found {
+			it.seq = seq
+			return key, value, nil
+		}
+	}
+}
+
+// readAhead reads the next record into the pending fields, hasPending is false once the underlying iterator is done
+func (it *versionFilterIterator) readAhead() error {
+	k, v, err := it.it.Next()
+	if err != nil {
+		it.hasPending = false
+		if errors.Is(err, Done) {
+			it.exhausted = true
+			return nil
+		}
+		return err
+	}
+
+	it.pendingKey, it.pendingValue, it.pendingSeq, it.hasPending = k, v, it.it.SequenceNumber(), true
+	return nil
+}
+
+func (it *versionFilterIterator) SequenceNumber() uint64 {
+	return it.seq
+}
+
+func newVersionFilterIterator(it SequencedIteratorI, cmp skiplist.Comparator[[]byte], isVisible func(seq uint64) bool) SSTableIteratorI {
+	return &versionFilterIterator{it: it, cmp: cmp, isVisible: isVisible}
+}
+
+// boundedKeyIterator ends the iteration of the index after the last version of keyHigher (inclusive)
+type boundedKeyIterator struct {
+	it        skiplist.IteratorI[[]byte, IndexVal]
+	keyHigher []byte
+	cmp       skiplist.Comparator[[]byte]
+}
+
+func (it *boundedKeyIterator) Next() ([]byte, IndexVal, error) {
+	key, iVal, err := it.it.Next()
+	if err != nil {
+		return nil, IndexVal{}, err
+	}
+	if it.cmp.Compare(key, it.keyHigher) > 0 {
+		return nil, IndexVal{}, skiplist.Done
+	}
+	return key, iVal, nil
+}
+
+// filterKeyIterator skips all entries of the index whose key doesn't match the predicate
+type filterKeyIterator struct {
+	it   skiplist.IteratorI[[]byte, IndexVal]
+	pred func(key []byte) bool
+}
+
+func (it *filterKeyIterator) Next() ([]byte, IndexVal, error) {
+	for {
+		key, iVal, err := it.it.Next()
+		if err != nil {
+			return nil, IndexVal{}, err
+		}
+		if it.pred(key) {
+			return key, iVal, nil
+		}
+	}
+}
@@ -3,6 +3,9 @@ package sstables
 import (
 	"errors"
 	"fmt"
+	"sync"
+	"time"
+
 	"github.com/thomasjungblut/go-sstables/pq"
 	"github.com/thomasjungblut/go-sstables/skiplist"
 )
@@ -19,7 +22,7 @@ func (s SSTableMergeIteratorContext) Next() ([]byte, []byte, error) {
 	if errors.Is(err, Done) {
 		return nil, nil, pq.Done
 	}
-	return k, v, nil
+	return k, v, err
 }
 
 func (s SSTableMergeIteratorContext) Context() int {
@@ -35,14 +38,22 @@ func NewMergeIteratorContext(context int, iterator SSTableIteratorI) SSTableMerg
 
 type SSTableMerger struct {
 	comp skiplist.Comparator[[]byte]
+	// bufferDepth is the number of records prefetched per iterator, zero merges serially
+	bufferDepth int
+	logger      Logger
+	// gcHorizon drops tombstones below it in MergeLatestBySeq, but only when includesOldestTable is set as well
+	gcHorizon           uint64
+	includesOldestTable bool
 }
 
 // Merge accepts a slice of sstable iterators to merge into an already opened writer. The caller needs to close the writer.
 func (m SSTableMerger) Merge(iterators []SSTableMergeIteratorContext, writer SSTableStreamWriterI) (err error) {
-	var iteratorWithContext []pq.IteratorWithContext[[]byte, []byte, int]
-	for _, iterator := range iterators {
-		iteratorWithContext = append(iteratorWithContext, iterator)
-	}
+	records := uint64(0)
+	defer m.logCompaction(len(iterators))(&records, &err)
+
+	iteratorWithContext, stop := m.heapIterators(iterators)
+	defer stop()
+
 	pqq, err := pq.NewPriorityQueue[[]byte, []byte, int](m.comp, iteratorWithContext)
 	if err != nil {
 		return fmt.Errorf("merge error while initializing the heap: %w", err)
@@ -62,6 +73,7 @@ func (m SSTableMerger) Merge(iterators []SSTableMergeIteratorContext, writer SST
 		if err != nil {
 			return fmt.Errorf("merge error while writing next record: %w", err)
 		}
+		records++
 	}
 
 	return nil
@@ -144,7 +156,14 @@ func (m SSTableMerger) MergeCompactIterator(iterators []SSTableMergeIteratorCont
 
 // MergeCompact accepts a slice of sstable iterators to merge into an already opened writer. The caller needs to close the writer.
 func (m SSTableMerger) MergeCompact(iterators []SSTableMergeIteratorContext, writer SSTableStreamWriterI, reduce ReduceFunc) (err error) {
-	iterator, err := m.MergeCompactIterator(iterators, reduce)
+	records := uint64(0)
+	defer m.logCompaction(len(iterators))(&records, &err)
+
+	// the prefetching goroutines can only be stopped here, the returned MergeCompactIterator always merges serially
+	prefetched, stop := m.prefetch(iterators)
+	defer stop()
+
+	iterator, err := m.MergeCompactIterator(prefetched, reduce)
 	if err != nil {
 		return fmt.Errorf("merge compact error while initializing the iterator: %w", err)
 	}
@@ -159,11 +178,306 @@ func (m SSTableMerger) MergeCompact(iterators []SSTableMergeIteratorContext, wri
 			}
 		}
 		err = writer.WriteNext(k, v)
+		if err != nil {
+			return fmt.Errorf("merge compact error while writing next record: %w", err)
+		}
+		records++
 	}
 
 	return nil
 }
 
-func NewSSTableMerger(comp skiplist.Comparator[[]byte]) SSTableMerger {
-	return SSTableMerger{comp}
+// MergeLatestBySeq merges the iterators into an already opened writer and only keeps the version of every key with the
+// highest sequence number, see SequencedIteratorI. On equal sequence numbers, the iterator with the lowest context wins.
+// Tombstones, values written as nil, are kept when they are the newest version, so they shadow older versions in other
+// tables, unless they are below the MergeGCHorizon of a merge with MergeIncludesOldestTable. The sequence numbers are
+// preserved when the writer is a *SSTableStreamWriter. The caller needs to close the writer.
+func (m SSTableMerger) MergeLatestBySeq(iterators []SSTableMergeIteratorContext, writer SSTableStreamWriterI) (err error) {
+	records := uint64(0)
+	defer m.logCompaction(len(iterators))(&records, &err)
+
+	prefetched, stop := m.prefetch(iterators)
+	defer stop()
+
+	var iteratorWithContext []pq.IteratorWithContext[[]byte, sequencedValue, int]
+	for _, iterator := range prefetched {
+		iteratorWithContext = append(iteratorWithContext, sequencedMergeIterator{iterator})
+	}
+	pqq, err := pq.NewPriorityQueue[[]byte, sequencedValue, int](m.comp, iteratorWithContext)
+	if err != nil {
+		return fmt.Errorf("merge latest error while initializing the heap: %w", err)
+	}
+
+	seqWriter, preserveSeq := writer.(*SSTableStreamWriter)
+	var latestKey []byte
+	var latest sequencedValue
+	latestCtx := 0
+	flush := func() error {
+		if latestKey == nil {
+			return nil
+		}
+		if latest.value == nil && m.canDropTombstone(latest.seq) {
+			// the older versions it shadows were already dropped in favor of the tombstone
+			return nil
+		}
+		var err error
+		if preserveSeq {
+			err = seqWriter.WriteNextWithSeq(latestKey, latest.value, latest.seq)
+		} else {
+			err = writer.WriteNext(latestKey, latest.value)
+		}
+		if err != nil {
+			return fmt.Errorf("merge latest error while writing next record: %w", err)
+		}
+		records++
+		return nil
+	}
+
+	for {
+		k, v, c, err := pqq.Next()
+		if err != nil {
+			if errors.Is(err, pq.Done) {
+				break
+			}
+			return fmt.Errorf("merge latest error during heap next: %w", err)
+		}
+
+		if latestKey != nil && m.comp.Compare(k, latestKey) == 0 {
+			if v.seq > latest.seq || (v.seq == latest.seq && c < latestCtx) {
+				latest, latestCtx = v, c
+			}
+			continue
+		}
+
+		if err := flush(); err != nil {
+			return err
+		}
+		latestKey, latest, latestCtx = k, v, c
+	}
+
+	return flush()
+}
+
+// canDropTombstone returns true when no snapshot can see a tombstone with the given sequence number anymore and no
+// older version of its key exists outside the merged tables
+func (m SSTableMerger) canDropTombstone(seq uint64) bool {
+	return m.includesOldestTable && seq < m.gcHorizon
+}
+
+type sequencedValue struct {
+	value []byte
+	seq   uint64
+}
+
+// sequencedMergeIterator returns the values along with their sequence number for the heap
+type sequencedMergeIterator struct {
+	iterator SSTableMergeIteratorContext
+}
+
+func (s sequencedMergeIterator) Next() ([]byte, sequencedValue, error) {
+	k, v, err := s.iterator.Next()
+	if err != nil {
+		return nil, sequencedValue{}, err
+	}
+
+	var seq uint64
+	if sequenced, ok := s.iterator.iterator.(SequencedIteratorI); ok {
+		seq = sequenced.SequenceNumber()
+	}
+	return k, sequencedValue{value: v, seq: seq}, nil
+}
+
+func (s sequencedMergeIterator) Context() int {
+	return s.iterator.Context()
+}
+
+// heapIterators converts the iterators for the priority queue, the returned function stops any prefetching
+func (m SSTableMerger) heapIterators(iterators []SSTableMergeIteratorContext) ([]pq.IteratorWithContext[[]byte, []byte, int], func()) {
+	prefetched, stop := m.prefetch(iterators)
+	var iteratorWithContext []pq.IteratorWithContext[[]byte, []byte, int]
+	for _, iterator := range prefetched {
+		iteratorWithContext = append(iteratorWithContext, iterator)
+	}
+	return iteratorWithContext, stop
+}
+
+// prefetch wraps every iterator into a prefetchingIterator when a buffer depth is configured
+func (m SSTableMerger) prefetch(iterators []SSTableMergeIteratorContext) ([]SSTableMergeIteratorContext, func()) {
+	if m.bufferDepth <= 0 {
+		return iterators, func() {}
+	}
+
+	var prefetched []SSTableMergeIteratorContext
+	var stops []func()
+	for _, iterator := range iterators {
+		p := newPrefetchingIterator(iterator.iterator, m.bufferDepth)
+		prefetched = append(prefetched, NewMergeIteratorContext(iterator.ctx, p))
+		stops = append(stops, p.stop)
+	}
+
+	return prefetched, func() {
+		for _, stop := range stops {
+			stop()
+		}
+	}
+}
+
+type prefetchedRecord struct {
+	key   []byte
+	value []byte
+	seq   uint64
+	err   error
+}
+
+// prefetchingIterator reads, and thus decompresses, the records of the wrapped iterator in its own goroutine ahead of
+// the consumer. The records are buffered in a channel with the configured depth, which keeps their order intact.
+type prefetchingIterator struct {
+	records chan prefetchedRecord
+	done    chan struct{}
+	// finished is closed when the goroutine returned and no longer reads from the wrapped iterator
+	finished chan struct{}
+	stopOnce sync.Once
+	// err is the sticky error of the wrapped iterator, only accessed by the consumer
+	err error
+	// seq is the sequence number of the record last returned, only accessed by the consumer
+	seq uint64
+}
+
+func newPrefetchingIterator(iterator SSTableIteratorI, bufferDepth int) *prefetchingIterator {
+	p := &prefetchingIterator{
+		records:  make(chan prefetchedRecord, bufferDepth),
+		done:     make(chan struct{}),
+		finished: make(chan struct{}),
+	}
+
+	go func() {
+		defer close(p.finished)
+		defer close(p.records)
+		for {
+			select {
+			case <-p.done:
+				return
+			default:
+			}
+
+			k, v, err := iterator.Next()
+			if errors.Is(err, Done) {
+				return
+			}
+
+			var seq uint64
+			if sequenced, ok := iterator.(SequencedIteratorI); ok {
+				seq = sequenced.SequenceNumber()
+			}
+
+			select {
+			case p.records <- prefetchedRecord{key: k, value: v, seq: seq, err: err}:
+			case <-p.done:
+				return
+			}
+
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	return p
+}
+
+func (p *prefetchingIterator) Next() ([]byte, []byte, error) {
+	if p.err != nil {
+		return nil, nil, p.err
+	}
+
+	record, ok := <-p.records
+	if !ok {
+		return nil, nil, Done
+	}
+
+	if record.err != nil {
+		p.err = record.err
+		return nil, nil, record.err
+	}
+
+	p.seq = record.seq
+	return record.key, record.value, nil
+}
+
+func (p *prefetchingIterator) SequenceNumber() uint64 {
+	return p.seq
+}
+
+// stop ends the prefetching goroutine and waits until it returned, so the wrapped iterator and its reader can be
+// closed afterwards. A record that is currently read from the wrapped iterator is read to the end first.
+func (p *prefetchingIterator) stop() {
+	p.stopOnce.Do(func() {
+		close(p.done)
+	})
+	<-p.finished
+}
+
+// logCompaction logs the start of a merge of the given number of tables, the returned function logs its end with the
+// number of records written.
+func (m SSTableMerger) logCompaction(tables int) func(records *uint64, err *error) {
+	start := time.Now()
+	m.logger.Info("compaction started", "tables", tables)
+	return func(records *uint64, err *error) {
+		if *err != nil {
+			m.logger.Warn("compaction failed", "tables", tables, "records", *records, "error", *err)
+			return
+		}
+		m.logger.Info("compaction finished", "tables", tables, "records", *records, "duration", time.Since(start))
+	}
+}
+
+type MergeOption func(*SSTableMerger)
+
+// MergeBufferDepth prefetches up to the given number of records of every iterator in its own goroutine, so reading
+// and decompressing the tables is done in parallel to the merge. The output is the same as a serial merge, which is
+// the default. The iterators must return keys and values that stay valid after subsequent calls to Next.
+// Only Merge and MergeCompact prefetch, MergeCompactIterator is always serial.
+func MergeBufferDepth(depth int) MergeOption {
+	return func(m *SSTableMerger) {
+		m.bufferDepth = depth
+	}
+}
+
+// MergeWithLogger logs when Merge, MergeCompact and MergeLatestBySeq start and finish, with the number of tables and
+// the number of records written. By default, nothing is logged.
+func MergeWithLogger(logger Logger) MergeOption {
+	return func(m *SSTableMerger) {
+		m.logger = logger
+	}
+}
+
+// MergeGCHorizon lets MergeLatestBySeq physically remove tombstones with a sequence number below the horizon, together
+// with the versions they shadow. The horizon is the sequence number of the oldest snapshot that is still read, so no
+// reader can see these tombstones anymore. Dropping a tombstone is only correct when no table outside the merge still
+// has an older version of its key, which would otherwise become visible again. Hence, tombstones are only dropped when
+// the caller also confirms with MergeIncludesOldestTable that the merge includes the oldest table of the key range.
+// Records written without a sequence number have the sequence number zero.
+func MergeGCHorizon(seq uint64) MergeOption {
+	return func(m *SSTableMerger) {
+		m.gcHorizon = seq
+	}
+}
+
+// MergeIncludesOldestTable confirms that the merged tables include the oldest table for their key range, for example in
+// a full compaction or a compaction into the last level, which is required to drop tombstones with MergeGCHorizon.
+func MergeIncludesOldestTable() MergeOption {
+	return func(m *SSTableMerger) {
+		m.includesOldestTable = true
+	}
+}
+
+func NewSSTableMerger(comp skiplist.Comparator[[]byte], opts ...MergeOption) SSTableMerger {
+	m := SSTableMerger{comp: comp}
+	for _, opt := range opts {
+		opt(&m)
+	}
+	if m.logger == nil {
+		m.logger = noopLogger{}
+	}
+	return m
 }
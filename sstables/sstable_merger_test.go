@@ -1,11 +1,15 @@
 package sstables
 
 import (
+	"encoding/binary"
+	"errors"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/thomasjungblut/go-sstables/skiplist"
 	"sort"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 // we only do some e2e tests with randoms here, the main logic is tested in the priority queue and its unit tests
@@ -30,7 +34,112 @@ func TestSSTableMergeFiveFilesEndToEnd(t *testing.T) {
 	writeFilesMergeAndCheck(t, 5, 200)
 }
 
-func writeFilesMergeAndCheck(t *testing.T, numFiles int, numElementsPerFile int) {
+func TestSSTableMergeWithBufferDepthEndToEnd(t *testing.T) {
+	for _, depth := range []int{1, 16} {
+		writeFilesMergeAndCheck(t, 5, 200, MergeBufferDepth(depth))
+		writeMergeCompactAndCheck(t, 5, 200, MergeBufferDepth(depth))
+	}
+}
+
+func TestSSTableMergeWithBufferDepthPropagatesErrors(t *testing.T) {
+	writer, err := newTestSSTableStreamWriter()
+	require.Nil(t, err)
+	defer cleanWriterDir(t, writer)
+	streamedWriteAscendingIntegers(t, writer, 100)
+	reader, iterator := getFullScanIterator(t, writer.opts.basePath)
+	defer closeReader(t, reader)
+
+	expectedErr := errors.New("broken iterator")
+	iterators := []SSTableMergeIteratorContext{
+		NewMergeIteratorContext(0, iterator),
+		NewMergeIteratorContext(1, &failingIterator{err: expectedErr, failAfter: 10}),
+	}
+
+	outWriter, err := newTestSSTableStreamWriter()
+	require.Nil(t, err)
+	require.NoError(t, outWriter.Open())
+	defer cleanWriterDir(t, outWriter)
+
+	merger := NewSSTableMerger(skiplist.BytesComparator{}, MergeBufferDepth(4))
+	err = merger.Merge(iterators, outWriter)
+	assert.ErrorIs(t, err, expectedErr)
+	require.NoError(t, outWriter.Close())
+}
+
+func TestSSTableMergeWithBufferDepthStopsPrefetchingOnWriteErrors(t *testing.T) {
+	var iterators []SSTableMergeIteratorContext
+	var tracked []*trackingIterator
+	for i := 0; i < 4; i++ {
+		it := &trackingIterator{start: i * 1000000}
+		tracked = append(tracked, it)
+		iterators = append(iterators, NewMergeIteratorContext(i, it))
+	}
+
+	expectedErr := errors.New("broken writer")
+	written := 0
+	outWriter, err := NewSSTableStreamWriter(WriteBasePath(t.TempDir()), WithKeyComparator(skiplist.BytesComparator{}),
+		WithWriteValidator(func(key []byte, value []byte) error {
+			written++
+			if written > 50 {
+				return expectedErr
+			}
+			return nil
+		}))
+	require.NoError(t, err)
+	require.NoError(t, outWriter.Open())
+
+	merger := NewSSTableMerger(skiplist.BytesComparator{}, MergeBufferDepth(4))
+	err = merger.Merge(iterators, outWriter)
+	assert.ErrorIs(t, err, expectedErr)
+
+	// no goroutine may read from the iterators anymore once Merge returned, the readers are closed after it
+	var calls []int64
+	for _, it := range tracked {
+		assert.Equal(t, int32(0), it.active.Load())
+		calls = append(calls, it.calls.Load())
+	}
+	time.Sleep(20 * time.Millisecond)
+	for i, it := range tracked {
+		assert.Equal(t, calls[i], it.calls.Load())
+	}
+	require.NoError(t, outWriter.Close())
+}
+
+// trackingIterator returns ascending keys forever and tracks the calls of Next, which take a moment each
+type trackingIterator struct {
+	start  int
+	i      int
+	active atomic.Int32
+	calls  atomic.Int64
+}
+
+func (it *trackingIterator) Next() ([]byte, []byte, error) {
+	it.active.Add(1)
+	defer it.active.Add(-1)
+	it.calls.Add(1)
+	time.Sleep(time.Millisecond)
+	it.i++
+	k, v := getKeyValueAsBytes(it.start + it.i)
+	return k, v, nil
+}
+
+// failingIterator returns ascending keys and fails after the given number of keys
+type failingIterator struct {
+	err       error
+	failAfter int
+	i         int
+}
+
+func (f *failingIterator) Next() ([]byte, []byte, error) {
+	if f.i >= f.failAfter {
+		return nil, nil, f.err
+	}
+	f.i++
+	k, v := getKeyValueAsBytes(1000 + f.i)
+	return k, v, nil
+}
+
+func writeFilesMergeAndCheck(t *testing.T, numFiles int, numElementsPerFile int, opts ...MergeOption) {
 	var expectedNumbers []int
 	var iterators []SSTableMergeIteratorContext
 	for i := 0; i < numFiles; i++ {
@@ -49,7 +158,7 @@ func writeFilesMergeAndCheck(t *testing.T, numFiles int, numElementsPerFile int)
 	require.NoError(t, outWriter.Open())
 	defer cleanWriterDir(t, outWriter)
 
-	merger := NewSSTableMerger(skiplist.BytesComparator{})
+	merger := NewSSTableMerger(skiplist.BytesComparator{}, opts...)
 	err = merger.Merge(iterators, outWriter)
 	require.Nil(t, err)
 	require.NoError(t, outWriter.Close())
@@ -77,7 +186,7 @@ func TestSSTableMergeAndCompactFiveFilesEndToEnd(t *testing.T) {
 	writeMergeCompactAndCheck(t, 5, 200)
 }
 
-func writeMergeCompactAndCheck(t *testing.T, numFiles int, numElementsPerFile int) {
+func writeMergeCompactAndCheck(t *testing.T, numFiles int, numElementsPerFile int, opts ...MergeOption) {
 	var writersToClean []*SSTableStreamWriter
 	defer cleanWriterDirs(t, &writersToClean)
 	var expectedNumbers []int
@@ -101,7 +210,7 @@ func writeMergeCompactAndCheck(t *testing.T, numFiles int, numElementsPerFile in
 	require.NoError(t, outWriter.Open())
 	writersToClean = append(writersToClean, outWriter)
 
-	merger := NewSSTableMerger(skiplist.BytesComparator{})
+	merger := NewSSTableMerger(skiplist.BytesComparator{}, opts...)
 	err = merger.MergeCompact(iterators, outWriter,
 		func(key []byte, values [][]byte, context []int) ([]byte, []byte) {
 			// there should be as many values as we have files
@@ -193,3 +302,112 @@ func TestMergeAndCompactEmptyResult(t *testing.T) {
 	require.Nil(t, outWriter.Close())
 	assertRandomAndSequentialRead(t, outWriter.opts.basePath, []int{})
 }
+
+func TestMergeLatestBySeq(t *testing.T) {
+	type record struct {
+		key   int
+		value []byte
+		seq   uint64
+	}
+	tables := [][]record{
+		{{1, []byte("a"), 1}, {5, []byte("a"), 1}, {7, []byte("a"), 1}, {8, []byte("a"), 5}},
+		{{5, []byte("b"), 2}, {7, []byte("b"), 2}, {8, []byte("b"), 1}, {9, []byte("b"), 0}},
+		// the tombstone for 7 shadows both older puts, 9 is a tie that goes to the lower context
+		{{7, nil, 3}, {9, []byte("c"), 0}},
+	}
+	expected := []record{{1, []byte("a"), 1}, {5, []byte("b"), 2}, {7, nil, 3}, {8, []byte("a"), 5}, {9, []byte("b"), 0}}
+
+	for _, opts := range [][]MergeOption{nil, {MergeBufferDepth(2)}} {
+		var iterators []SSTableMergeIteratorContext
+		for i, table := range tables {
+			writer, err := newTestSSTableStreamWriter()
+			require.NoError(t, err)
+			defer cleanWriterDir(t, writer)
+			require.NoError(t, writer.Open())
+			for _, r := range table {
+				require.NoError(t, writer.WriteNextWithSeq(intToByteSlice(r.key), r.value, r.seq))
+			}
+			require.NoError(t, writer.Close())
+
+			reader, iterator := getFullScanIterator(t, writer.opts.basePath)
+			defer closeReader(t, reader)
+			iterators = append(iterators, NewMergeIteratorContext(i, iterator))
+		}
+
+		outWriter, err := newTestSSTableStreamWriter()
+		require.NoError(t, err)
+		defer cleanWriterDir(t, outWriter)
+		require.NoError(t, outWriter.Open())
+		require.NoError(t, NewSSTableMerger(skiplist.BytesComparator{}, opts...).MergeLatestBySeq(iterators, outWriter))
+		require.NoError(t, outWriter.Close())
+
+		reader, it := getFullScanIterator(t, outWriter.opts.basePath)
+		for _, r := range expected {
+			k, v, err := it.Next()
+			require.NoError(t, err)
+			assert.Equal(t, intToByteSlice(r.key), k)
+			assert.Equal(t, r.value, v)
+			assert.Equal(t, r.seq, it.(SequencedIteratorI).SequenceNumber())
+		}
+		_, _, err = it.Next()
+		assert.Equal(t, Done, err)
+		closeReader(t, reader)
+	}
+}
+
+func TestMergeLatestBySeqGCHorizon(t *testing.T) {
+	type record struct {
+		key   int
+		value []byte
+		seq   uint64
+	}
+	tables := [][]record{
+		{{1, []byte("a"), 1}, {7, []byte("a"), 1}, {8, []byte("a"), 2}},
+		// the tombstone of 7 is below the horizon, the one of 8 is still visible to the snapshot at 5
+		{{7, nil, 3}, {8, nil, 5}, {9, nil, 4}},
+	}
+
+	merge := func(opts ...MergeOption) []record {
+		var iterators []SSTableMergeIteratorContext
+		for i, table := range tables {
+			writer, err := newTestSSTableStreamWriter()
+			require.NoError(t, err)
+			defer cleanWriterDir(t, writer)
+			require.NoError(t, writer.Open())
+			for _, r := range table {
+				require.NoError(t, writer.WriteNextWithSeq(intToByteSlice(r.key), r.value, r.seq))
+			}
+			require.NoError(t, writer.Close())
+
+			reader, iterator := getFullScanIterator(t, writer.opts.basePath)
+			defer closeReader(t, reader)
+			iterators = append(iterators, NewMergeIteratorContext(i, iterator))
+		}
+
+		outWriter, err := newTestSSTableStreamWriter()
+		require.NoError(t, err)
+		defer cleanWriterDir(t, outWriter)
+		require.NoError(t, outWriter.Open())
+		require.NoError(t, NewSSTableMerger(skiplist.BytesComparator{}, opts...).MergeLatestBySeq(iterators, outWriter))
+		require.NoError(t, outWriter.Close())
+
+		reader, it := getFullScanIterator(t, outWriter.opts.basePath)
+		defer closeReader(t, reader)
+		var actual []record
+		for {
+			k, v, err := it.Next()
+			if err == Done {
+				return actual
+			}
+			require.NoError(t, err)
+			actual = append(actual, record{int(binary.BigEndian.Uint32(k)), v, it.(SequencedIteratorI).SequenceNumber()})
+		}
+	}
+
+	assert.Equal(t, []record{{1, []byte("a"), 1}, {8, nil, 5}}, merge(MergeGCHorizon(5), MergeIncludesOldestTable()))
+
+	// without the confirmation that the oldest table is included, all tombstones are kept
+	withTombstones := []record{{1, []byte("a"), 1}, {7, nil, 3}, {8, nil, 5}, {9, nil, 4}}
+	assert.Equal(t, withTombstones, merge(MergeGCHorizon(5)))
+	assert.Equal(t, withTombstones, merge(MergeIncludesOldestTable()))
+}
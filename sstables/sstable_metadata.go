@@ -0,0 +1,57 @@
+package sstables
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/crc64"
+
+	"github.com/thomasjungblut/go-sstables/sstables/proto"
+	pb "google.golang.org/protobuf/proto"
+)
+
+// metaDataMagic starts every metadata file with a header. Its first byte is a protobuf tag with the invalid wire type
+// 7, so it never starts the bare metadata files written by older versions, which are read without a header.
+const metaDataMagic = uint32(0x53544D07)
+
+// metaDataHeaderSize is the size of the header in front of the metadata: magic, length and CRC-64 of the message
+const metaDataHeaderSize = 4 + 4 + 8
+
+// marshalMetaData returns the metadata prefixed with a header that allows the reader to detect truncation and
+// corruption of the metadata file.
+func marshalMetaData(md *proto.MetaData) ([]byte, error) {
+	payload, err := pb.Marshal(md)
+	if err != nil {
+		return nil, err
+	}
+
+	content := make([]byte, metaDataHeaderSize, metaDataHeaderSize+len(payload))
+	binary.LittleEndian.PutUint32(content[0:4], metaDataMagic)
+	binary.LittleEndian.PutUint32(content[4:8], uint32(len(payload)))
+	binary.LittleEndian.PutUint64(content[8:16], crc64.Checksum(payload, crc64.MakeTable(crc64.ISO)))
+	return append(content, payload...), nil
+}
+
+// unmarshalMetaData parses the content of a metadata file into md. Content with a header that doesn't match its
+// message fails with ErrMetaDataCorrupt, content without a header is a bare message of an older version.
+func unmarshalMetaData(content []byte, md *proto.MetaData) error {
+	if len(content) == 0 || content[0] != byte(metaDataMagic&0xFF) {
+		return pb.Unmarshal(content, md)
+	}
+
+	if len(content) < metaDataHeaderSize || binary.LittleEndian.Uint32(content[0:4]) != metaDataMagic {
+		return fmt.Errorf("truncated header of %d bytes: %w", len(content), ErrMetaDataCorrupt)
+	}
+
+	payload := content[metaDataHeaderSize:]
+	length := binary.LittleEndian.Uint32(content[4:8])
+	if uint64(len(payload)) != uint64(length) {
+		return fmt.Errorf("expected %d bytes of metadata, but found %d: %w", length, len(payload), ErrMetaDataCorrupt)
+	}
+
+	checksum := binary.LittleEndian.Uint64(content[8:16])
+	if actual := crc64.Checksum(payload, crc64.MakeTable(crc64.ISO)); actual != checksum {
+		return fmt.Errorf("checksum mismatch, expected %d but was %d: %w", checksum, actual, ErrMetaDataCorrupt)
+	}
+
+	return pb.Unmarshal(payload, md)
+}
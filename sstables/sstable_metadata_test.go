@@ -0,0 +1,72 @@
+package sstables
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thomasjungblut/go-sstables/sstables/proto"
+	pb "google.golang.org/protobuf/proto"
+)
+
+func TestMetaDataRoundTrip(t *testing.T) {
+	md := &proto.MetaData{Version: Version, NumRecords: 42, MinKey: []byte{1}, MaxKey: []byte{2}}
+	content, err := marshalMetaData(md)
+	require.NoError(t, err)
+
+	actual := &proto.MetaData{}
+	require.NoError(t, unmarshalMetaData(content, actual))
+	assert.True(t, pb.Equal(md, actual))
+}
+
+func TestMetaDataWithoutHeader(t *testing.T) {
+	md := &proto.MetaData{Version: 1, NumRecords: 42, MinKey: []byte{1}, MaxKey: []byte{2}}
+	content, err := pb.Marshal(md)
+	require.NoError(t, err)
+
+	actual := &proto.MetaData{}
+	require.NoError(t, unmarshalMetaData(content, actual))
+	assert.True(t, pb.Equal(md, actual))
+
+	actual = &proto.MetaData{}
+	require.NoError(t, unmarshalMetaData([]byte{}, actual))
+	assert.True(t, pb.Equal(&proto.MetaData{}, actual))
+}
+
+func TestMetaDataCorrupt(t *testing.T) {
+	content, err := marshalMetaData(&proto.MetaData{Version: Version, NumRecords: 42, MinKey: []byte{1}, MaxKey: []byte{2}})
+	require.NoError(t, err)
+
+	flipped := append([]byte{}, content...)
+	flipped[len(flipped)-1] ^= 0xFF
+
+	for name, corrupt := range map[string][]byte{
+		"magic only":      content[:1],
+		"truncated":       content[:len(content)-1],
+		"header only":     content[:metaDataHeaderSize],
+		"trailing":        append(append([]byte{}, content...), 0),
+		"flipped payload": flipped,
+	} {
+		err := unmarshalMetaData(corrupt, &proto.MetaData{})
+		assert.ErrorIs(t, err, ErrMetaDataCorrupt, name)
+	}
+}
+
+func TestReadTruncatedMetaData(t *testing.T) {
+	writer, err := newTestSSTableStreamWriter()
+	require.NoError(t, err)
+	defer cleanWriterDir(t, writer)
+	streamedWriteAscendingIntegers(t, writer, 100)
+
+	metaPath := filepath.Join(writer.opts.basePath, MetaFileName)
+	content, err := os.ReadFile(metaPath)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(metaPath, content[:len(content)/2], 0666))
+
+	_, err = NewSSTableReader(ReadBasePath(writer.opts.basePath))
+	assert.ErrorIs(t, err, ErrMetaDataCorrupt)
+	_, err = ReadMetaData(ReadBasePath(writer.opts.basePath))
+	assert.ErrorIs(t, err, ErrMetaDataCorrupt)
+}
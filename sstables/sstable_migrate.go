@@ -0,0 +1,98 @@
+package sstables
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/thomasjungblut/go-sstables/skiplist"
+)
+
+// MigrateTable rewrites the table in srcPath in the format of targetVersion into dstPath, which preserves all keys,
+// values, nil values (tombstones) and the sequence numbers of versioned tables. Only the current Version can be written,
+// older formats are read like with NewSSTableReader and the values are verified against their checksums.
+// The writer options apply to the new table, for example to change its compression. Tables that are sorted by another
+// comparator than the skiplist.BytesComparator need to pass it with WithKeyComparator.
+func MigrateTable(srcPath string, dstPath string, targetVersion uint32, writerOptions ...WriterOption) (err error) {
+	if targetVersion != Version {
+		return fmt.Errorf("error while migrating sstable in '%s': unsupported target version %d, max supported %d",
+			srcPath, targetVersion, Version)
+	}
+
+	// the slice index keeps the order of the index file, which doesn't depend on the comparator and supports versions
+	r, err := NewSSTableReader(ReadBasePath(srcPath), ReadIndexLoader(&SliceKeyIndexLoader{ReadBufferSize: 4096}),
+		ReadIgnoreComparatorCheck())
+	if err != nil {
+		return fmt.Errorf("error while migrating sstable in '%s': %w", srcPath, err)
+	}
+	reader := r.(*SSTableReader)
+	defer func() {
+		err = errors.Join(err, reader.Close())
+	}()
+
+	metaData := reader.MetaData()
+	opts := []WriterOption{
+		WriteBasePath(dstPath),
+		WithKeyComparator(skiplist.BytesComparator{}),
+		BloomExpectedNumberOfElements(max(1, metaData.NumRecords)),
+		WithUserTag(metaData.UserTag),
+	}
+	if metaData.CreatedAtUnixMillis != 0 {
+		opts = append(opts, WithCreatedAt(time.UnixMilli(metaData.CreatedAtUnixMillis)))
+	}
+	if metaData.Versioned {
+		opts = append(opts, WithVersioning())
+	}
+	if metaData.ComparatorName != "" {
+		opts = append(opts, WithComparatorName(metaData.ComparatorName))
+	}
+
+	writer, err := NewSSTableStreamWriter(append(opts, writerOptions...)...)
+	if err != nil {
+		return fmt.Errorf("error while migrating sstable in '%s': %w", srcPath, err)
+	}
+
+	err = writer.Open()
+	if err != nil {
+		return fmt.Errorf("error while migrating sstable in '%s': %w", srcPath, err)
+	}
+	defer func() {
+		cErr := writer.Close()
+		// a partially migrated table must not look complete to readers
+		if err != nil && cErr == nil {
+			cErr = os.Remove(filepath.Join(dstPath, writer.opts.committedFileName))
+		}
+		err = errors.Join(err, cErr)
+	}()
+
+	it, err := reader.IndexIterator()
+	if err != nil {
+		return fmt.Errorf("error while migrating sstable in '%s': %w", srcPath, err)
+	}
+
+	for {
+		entry, err := it.Next()
+		if errors.Is(err, Done) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("error while migrating sstable in '%s': %w", srcPath, err)
+		}
+
+		value, err := reader.getValueAtOffset(entry.IndexVal, false)
+		if err != nil {
+			return fmt.Errorf("error while migrating sstable in '%s': %w", srcPath, err)
+		}
+
+		if metaData.Versioned {
+			err = writer.WriteNextWithSeq(entry.Key, value, entry.SequenceNumber)
+		} else {
+			err = writer.WriteNext(entry.Key, value)
+		}
+		if err != nil {
+			return fmt.Errorf("error while migrating sstable in '%s' into '%s': %w", srcPath, dstPath, err)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package sstables
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thomasjungblut/go-sstables/recordio"
+	"github.com/thomasjungblut/go-sstables/skiplist"
+	"github.com/thomasjungblut/go-sstables/sstables/proto"
+	pb "google.golang.org/protobuf/proto"
+)
+
+func TestMigrateOlderTables(t *testing.T) {
+	for _, src := range []string{
+		"test_files/v0_compat/SimpleWriteHappyPathSSTable",
+		"test_files/v0_compat/SimpleWriteHappyPathSSTableWithMetaData",
+		"test_files/SimpleWriteHappyPathSSTableWithMetaData",
+	} {
+		t.Run(filepath.Base(src), func(t *testing.T) {
+			dst, err := os.MkdirTemp("", "sstables_Migrate")
+			require.NoError(t, err)
+			defer func() { require.NoError(t, os.RemoveAll(dst)) }()
+
+			require.NoError(t, MigrateTable(src, dst, Version, DataCompressionType(recordio.CompressionTypeSnappy)))
+
+			reader, err := NewSSTableReader(ReadBasePath(dst))
+			require.NoError(t, err)
+			defer closeReader(t, reader)
+			assert.Equal(t, Version, reader.MetaData().Version)
+			assert.Equal(t, uint64(7), reader.MetaData().NumRecords)
+			assertContentMatchesSkipList(t, reader, TEST_ONLY_NewSkipListMapWithElements([]int{1, 2, 3, 4, 5, 6, 7}))
+		})
+	}
+}
+
+func TestMigrateVersionedTableWithNilValues(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "sstables_Migrate")
+	require.NoError(t, err)
+	defer func() { require.NoError(t, os.RemoveAll(tmpDir)) }()
+
+	src := filepath.Join(tmpDir, "src")
+	dst := filepath.Join(tmpDir, "dst")
+	writer, err := NewSSTableStreamWriter(WriteBasePath(src), WithKeyComparator(skiplist.BytesComparator{}), WithVersioning(), WriteAtomic())
+	require.NoError(t, err)
+	require.NoError(t, writer.Open())
+	require.NoError(t, writer.WriteNextWithSeq([]byte{1}, []byte{1}, 1))
+	require.NoError(t, writer.WriteNextWithSeq([]byte{1}, nil, 2))
+	require.NoError(t, writer.WriteNextWithSeq([]byte{2}, []byte{}, 3))
+	require.NoError(t, writer.Close())
+
+	require.NoError(t, MigrateTable(src, dst, Version, WriteAtomic()))
+
+	for _, path := range []string{src, dst} {
+		r, err := NewSSTableReader(ReadBasePath(path))
+		require.NoError(t, err)
+		reader := r.(*SSTableReader)
+		assert.True(t, reader.MetaData().Versioned)
+
+		it, err := reader.IndexIterator()
+		require.NoError(t, err)
+		var entries []IndexEntryView
+		for {
+			entry, err := it.Next()
+			if errors.Is(err, Done) {
+				break
+			}
+			require.NoError(t, err)
+			entries = append(entries, entry)
+		}
+		require.Len(t, entries, 3)
+		assert.Equal(t, []uint64{1, 2, 3}, []uint64{entries[0].SequenceNumber, entries[1].SequenceNumber, entries[2].SequenceNumber})
+		assert.Equal(t, []bool{false, true, false}, []bool{entries[0].NullValue, entries[1].NullValue, entries[2].NullValue})
+
+		v, err := reader.Get([]byte{2})
+		require.NoError(t, err)
+		assert.Equal(t, []byte{}, v)
+		closeReader(t, reader)
+	}
+}
+
+func TestMigrateUnsupportedTargetVersion(t *testing.T) {
+	dst, err := os.MkdirTemp("", "sstables_Migrate")
+	require.NoError(t, err)
+	defer func() { require.NoError(t, os.RemoveAll(dst)) }()
+
+	err = MigrateTable("test_files/SimpleWriteHappyPathSSTableWithMetaData", dst, 1)
+	require.ErrorContains(t, err, "unsupported target version 1")
+}
+
+func TestReadUnsupportedVersion(t *testing.T) {
+	writer, err := newTestSSTableStreamWriter()
+	require.NoError(t, err)
+	defer cleanWriterDir(t, writer)
+	streamedWriteAscendingIntegers(t, writer, 10)
+
+	bytes, err := pb.Marshal(&proto.MetaData{Version: Version + 1})
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(writer.opts.basePath, MetaFileName), bytes, 0666))
+
+	_, err = NewSSTableReader(ReadBasePath(writer.opts.basePath))
+	require.ErrorContains(t, err, fmt.Sprintf("unsupported version %d, max supported %d", Version+1, Version))
+}
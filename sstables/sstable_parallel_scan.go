@@ -0,0 +1,171 @@
+package sstables
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/thomasjungblut/go-sstables/skiplist"
+	"golang.org/x/exp/slices"
+)
+
+// ParallelScan splits the table by its index into up to k contiguous shards of about the same number of records and
+// scans them concurrently, each with its own Clone of this reader. fn is called for every record with the id of its
+// shard: shard 0 contains the smallest keys, and all keys of a shard are lower than the keys of the next one. fn is
+// called concurrently for different shards, but sequentially and in key order within a shard. All versions of a key
+// are always in the same shard, tables with fewer distinct keys than k are split into fewer shards.
+// The first error, of fn or while reading, stops all shards and is returned. The keys and values are only valid during
+// the call of fn when the reader was opened with ReadReuseScanBuffers.
+func (reader *SSTableReader) ParallelScan(k int, fn func(shard int, key []byte, value []byte) error) error {
+	if k <= 0 {
+		return fmt.Errorf("error in sstable '%s' in ParallelScan: unexpected number of shards, was: %d", reader.opts.basePath, k)
+	}
+
+	starts, err := reader.shardStartKeys(k)
+	if err != nil {
+		return fmt.Errorf("error in sstable '%s' in ParallelScan: %w", reader.opts.basePath, err)
+	}
+
+	var wg sync.WaitGroup
+	var stop atomic.Bool
+	errs := make([]error, len(starts)+1)
+	for shard := 0; shard <= len(starts); shard++ {
+		var lower, upper []byte
+		if shard > 0 {
+			lower = starts[shard-1]
+		}
+		if shard < len(starts) {
+			upper = starts[shard]
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[shard] = reader.scanShard(shard, lower, upper, &stop, fn)
+			if errs[shard] != nil {
+				stop.Store(true)
+			}
+		}()
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// shardStartKeys returns the first key of every shard but the first one. The shards are split at about every n/k
+// records of the index, the split moves to the next key when it would fall between two versions of the same key.
+func (reader *SSTableReader) shardStartKeys(k int) ([][]byte, error) {
+	n := reader.metaData.NumRecords
+	if n == 0 {
+		// older tables don't know their number of records
+		it, err := reader.index.Iterator()
+		if err != nil {
+			return nil, err
+		}
+		for {
+			_, _, err := it.Next()
+			if errors.Is(err, skiplist.Done) {
+				break
+			}
+			if err != nil {
+				return nil, err
+			}
+			n++
+		}
+	}
+
+	it, err := reader.index.Iterator()
+	if err != nil {
+		return nil, err
+	}
+
+	var starts [][]byte
+	var prev []byte
+	next := uint64(1)
+	for pos := uint64(0); next < uint64(k); pos++ {
+		key, _, err := it.Next()
+		if errors.Is(err, skiplist.Done) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if pos >= next*n/uint64(k) && pos > 0 && reader.opts.keyComparator.Compare(prev, key) != 0 {
+			starts = append(starts, slices.Clone(key))
+			// a split that moved past the following splits replaces them
+			for next < uint64(k) && pos >= next*n/uint64(k) {
+				next++
+			}
+		}
+		prev = key
+	}
+	return starts, nil
+}
+
+// scanShard calls fn for all records from lower (inclusive, nil for the start of the table) up to upper (exclusive,
+// nil for the end of the table)
+func (reader *SSTableReader) scanShard(shard int, lower []byte, upper []byte, stop *atomic.Bool,
+	fn func(shard int, key []byte, value []byte) error) (err error) {
+	clone, err := reader.Clone()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = errors.Join(err, clone.Close())
+	}()
+
+	var keyIterator skiplist.IteratorI[[]byte, IndexVal]
+	if lower == nil {
+		keyIterator, err = clone.index.Iterator()
+	} else {
+		keyIterator, err = clone.index.IteratorStartingAt(lower)
+	}
+	if err != nil {
+		return fmt.Errorf("error in sstable '%s' in ParallelScan: %w", reader.opts.basePath, err)
+	}
+	if upper != nil {
+		keyIterator = &beforeKeyIterator{it: keyIterator, keyBefore: upper, cmp: clone.opts.keyComparator}
+	}
+
+	it := clone.filterVersions(&SSTableIterator{reader: clone, keyIterator: keyIterator, reuseBuffers: clone.reusesScanBuffers()})
+	for !stop.Load() {
+		key, value, err := it.Next()
+		if errors.Is(err, Done) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		err = fn(shard, key, value)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// beforeKeyIterator ends the wrapped iterator before the first key that is not lower than keyBefore
+type beforeKeyIterator struct {
+	it        skiplist.IteratorI[[]byte, IndexVal]
+	keyBefore []byte
+	cmp       skiplist.Comparator[[]byte]
+}
+
+func (it *beforeKeyIterator) Next() ([]byte, IndexVal, error) {
+	key, iVal, err := it.it.Next()
+	if err != nil {
+		return nil, IndexVal{}, err
+	}
+	if it.cmp.Compare(key, it.keyBefore) >= 0 {
+		return nil, IndexVal{}, skiplist.Done
+	}
+	return key, iVal, nil
+}
@@ -0,0 +1,124 @@
+package sstables
+
+import (
+	"errors"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type scannedRecord struct {
+	key   []byte
+	value []byte
+}
+
+// parallelScan returns the records of every shard in the order they were passed to the callback
+func parallelScan(t *testing.T, reader *SSTableReader, k int) [][]scannedRecord {
+	var mu sync.Mutex
+	shards := map[int][]scannedRecord{}
+	require.NoError(t, reader.ParallelScan(k, func(shard int, key []byte, value []byte) error {
+		mu.Lock()
+		defer mu.Unlock()
+		shards[shard] = append(shards[shard], scannedRecord{key, value})
+		return nil
+	}))
+
+	result := make([][]scannedRecord, len(shards))
+	for shard, records := range shards {
+		require.Less(t, shard, len(shards), "shards are numbered without gaps")
+		result[shard] = records
+	}
+	return result
+}
+
+// scanRecords returns all records of a regular Scan
+func scanRecords(t *testing.T, reader SSTableReaderI) []scannedRecord {
+	it, err := reader.Scan()
+	require.NoError(t, err)
+	var records []scannedRecord
+	for {
+		k, v, err := it.Next()
+		if errors.Is(err, Done) {
+			return records
+		}
+		require.NoError(t, err)
+		records = append(records, scannedRecord{k, v})
+	}
+}
+
+func TestParallelScan(t *testing.T) {
+	writer, err := newTestSSTableStreamWriter()
+	require.NoError(t, err)
+	defer cleanWriterDir(t, writer)
+	streamedWriteAscendingIntegers(t, writer, 1000)
+
+	r, err := NewSSTableReader(ReadBasePath(writer.opts.basePath))
+	require.NoError(t, err)
+	defer closeReader(t, r)
+	reader := r.(*SSTableReader)
+	expected := scanRecords(t, reader)
+
+	for _, test := range []struct{ k, shards int }{{1, 1}, {3, 3}, {4, 4}, {7, 7}, {2000, 1000}} {
+		shards := parallelScan(t, reader, test.k)
+		assert.Equal(t, test.shards, len(shards), "k=%d", test.k)
+
+		// concatenating the shards in their order yields the regular scan
+		var actual []scannedRecord
+		for _, records := range shards {
+			assert.NotEmpty(t, records)
+			actual = append(actual, records...)
+		}
+		assert.Equal(t, expected, actual, "k=%d", test.k)
+		if test.k == 4 {
+			for _, records := range shards {
+				assert.Equal(t, 250, len(records))
+			}
+		}
+	}
+
+	assert.Error(t, reader.ParallelScan(0, func(int, []byte, []byte) error { return nil }))
+}
+
+func TestParallelScanVersioned(t *testing.T) {
+	path := writeVersionedTable(t)
+	defer func() { require.NoError(t, os.RemoveAll(path)) }()
+
+	for _, opts := range [][]ReadOption{nil, {ReadAsOfSeq(20)}} {
+		r, err := NewSSTableReader(append(opts, ReadBasePath(path))...)
+		require.NoError(t, err)
+		reader := r.(*SSTableReader)
+		expected := scanRecords(t, reader)
+
+		for _, k := range []int{2, 3, 7} {
+			var actual []scannedRecord
+			for _, records := range parallelScan(t, reader, k) {
+				actual = append(actual, records...)
+			}
+			assert.Equal(t, expected, actual, "k=%d", k)
+		}
+		closeReader(t, reader)
+	}
+}
+
+func TestParallelScanStopsOnError(t *testing.T) {
+	writer, err := newTestSSTableStreamWriter()
+	require.NoError(t, err)
+	defer cleanWriterDir(t, writer)
+	streamedWriteAscendingIntegers(t, writer, 1000)
+
+	r, err := NewSSTableReader(ReadBasePath(writer.opts.basePath))
+	require.NoError(t, err)
+	defer closeReader(t, r)
+
+	failure := errors.New("failure")
+	err = r.(*SSTableReader).ParallelScan(4, func(shard int, key []byte, value []byte) error {
+		if shard == 2 {
+			return failure
+		}
+		return nil
+	})
+	assert.ErrorIs(t, err, failure)
+}
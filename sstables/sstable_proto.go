@@ -0,0 +1,182 @@
+package sstables
+
+import (
+	"fmt"
+
+	"google.golang.org/protobuf/encoding/protowire"
+	pb "google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// SSTableProtoReader wraps a byte-oriented SSTableReaderI and unmarshals every value into the proto message T.
+type SSTableProtoReader[T pb.Message] struct {
+	reader SSTableReaderI
+}
+
+// Get returns the value associated with the given key unmarshalled into a new T, ErrKeyNotFound as the error otherwise.
+func (r *SSTableProtoReader[T]) Get(key []byte) (T, error) {
+	var zero T
+	value, err := r.reader.Get(key)
+	if err != nil {
+		return zero, err
+	}
+
+	return unmarshalValue[T](r.reader.BasePath(), value)
+}
+
+// Contains returns true when the given key exists, false otherwise
+func (r *SSTableProtoReader[T]) Contains(key []byte) (bool, error) {
+	return r.reader.Contains(key)
+}
+
+// Scan returns an iterator over the whole sorted sequence with its values unmarshalled into T.
+func (r *SSTableProtoReader[T]) Scan() (*SSTableProtoIterator[T], error) {
+	it, err := r.reader.Scan()
+	if err != nil {
+		return nil, err
+	}
+
+	return &SSTableProtoIterator[T]{basePath: r.reader.BasePath(), iterator: it}, nil
+}
+
+// ScanRaw returns an iterator over the whole sorted sequence with the undecoded value bytes, which allows to selectively
+// parse only the values or parts of values that are needed.
+func (r *SSTableProtoReader[T]) ScanRaw() (SSTableIteratorI, error) {
+	return r.reader.Scan()
+}
+
+// ScanFields is like Scan, but only decodes the given top-level fields of T and leaves all others unset. The wire
+// format of the other fields is skipped without decoding it, which saves most of the unmarshalling of wide messages
+// when only a few small fields are needed. Unknown field names return an error.
+func (r *SSTableProtoReader[T]) ScanFields(fields ...protoreflect.Name) (*SSTableProtoIterator[T], error) {
+	var zero T
+	descriptor := zero.ProtoReflect().Descriptor()
+	numbers := make(map[protowire.Number]struct{}, len(fields))
+	for _, name := range fields {
+		field := descriptor.Fields().ByName(name)
+		if field == nil {
+			return nil, fmt.Errorf("message '%s' has no field '%s'", descriptor.FullName(), name)
+		}
+		numbers[field.Number()] = struct{}{}
+	}
+
+	it, err := r.reader.Scan()
+	if err != nil {
+		return nil, err
+	}
+
+	return &SSTableProtoIterator[T]{basePath: r.reader.BasePath(), iterator: it, fields: numbers}, nil
+}
+
+// Reader returns the underlying byte-oriented reader.
+func (r *SSTableProtoReader[T]) Reader() SSTableReaderI {
+	return r.reader
+}
+
+// Close closes the underlying reader.
+func (r *SSTableProtoReader[T]) Close() error {
+	return r.reader.Close()
+}
+
+type SSTableProtoIterator[T pb.Message] struct {
+	basePath string
+	iterator SSTableIteratorI
+	// fields restricts the decoded fields to the given numbers when set, see ScanFields
+	fields map[protowire.Number]struct{}
+	buf    []byte
+}
+
+// Next returns the next key and its value unmarshalled into a new T.
+// Returns Done as the error when the iterator is exhausted
+func (it *SSTableProtoIterator[T]) Next() ([]byte, T, error) {
+	var zero T
+	key, value, err := it.iterator.Next()
+	if err != nil {
+		return nil, zero, err
+	}
+
+	if it.fields != nil {
+		it.buf, err = selectFields(it.buf[:0], value, it.fields)
+		if err != nil {
+			return nil, zero, fmt.Errorf("error in sstable '%s' while selecting proto fields: %w", it.basePath, err)
+		}
+		value = it.buf
+	}
+
+	msg, err := unmarshalValue[T](it.basePath, value)
+	if err != nil {
+		return nil, zero, err
+	}
+
+	return key, msg, nil
+}
+
+// SSTableProtoWriter wraps a byte-oriented SSTableStreamWriterI and marshals every value from the proto message T.
+type SSTableProtoWriter[T pb.Message] struct {
+	writer SSTableStreamWriterI
+}
+
+// Open opens the underlying writer.
+func (w *SSTableProtoWriter[T]) Open() error {
+	return w.writer.Open()
+}
+
+// WriteNext marshals the value and writes it as the next record, expects keys to be ordered.
+func (w *SSTableProtoWriter[T]) WriteNext(key []byte, value T) error {
+	bytes, err := pb.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("error while marshalling proto value for key [%v]: %w", key, err)
+	}
+
+	return w.writer.WriteNext(key, bytes)
+}
+
+// Close closes the underlying writer.
+func (w *SSTableProtoWriter[T]) Close() error {
+	return w.writer.Close()
+}
+
+// selectFields appends the wire format of all occurrences of the given fields in value to dst, the values of all other
+// fields are skipped by their length without decoding them
+func selectFields(dst []byte, value []byte, fields map[protowire.Number]struct{}) ([]byte, error) {
+	for len(value) > 0 {
+		num, typ, n := protowire.ConsumeTag(value)
+		if n < 0 {
+			return nil, protowire.ParseError(n)
+		}
+		m := protowire.ConsumeFieldValue(num, typ, value[n:])
+		if m < 0 {
+			return nil, protowire.ParseError(m)
+		}
+
+		if _, ok := fields[num]; ok {
+			dst = append(dst, value[:n+m]...)
+		}
+		value = value[n+m:]
+	}
+	return dst, nil
+}
+
+func unmarshalValue[T pb.Message](basePath string, value []byte) (T, error) {
+	var zero T
+	// generated messages return their type also on a nil pointer, which allows us to create a new instance of T
+	msg := zero.ProtoReflect().Type().New().Interface().(T)
+	err := pb.Unmarshal(value, msg)
+	if err != nil {
+		return zero, fmt.Errorf("error in sstable '%s' while unmarshalling proto value: %w", basePath, err)
+	}
+
+	return msg, nil
+}
+
+// NewSSTableProtoReader wraps the given reader to return values unmarshalled into the proto message T:
+// > sstables.NewSSTableProtoReader[*proto.SomeMessage](reader)
+func NewSSTableProtoReader[T pb.Message](reader SSTableReaderI) *SSTableProtoReader[T] {
+	return &SSTableProtoReader[T]{reader: reader}
+}
+
+// NewSSTableProtoWriter wraps the given writer to marshal values from the proto message T:
+// > sstables.NewSSTableProtoWriter[*proto.SomeMessage](writer)
+func NewSSTableProtoWriter[T pb.Message](writer SSTableStreamWriterI) *SSTableProtoWriter[T] {
+	return &SSTableProtoWriter[T]{writer: writer}
+}
@@ -0,0 +1,131 @@
+package sstables
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thomasjungblut/go-sstables/sstables/proto"
+	pb "google.golang.org/protobuf/proto"
+)
+
+func TestProtoReaderWriterEndToEnd(t *testing.T) {
+	streamWriter, err := newTestSSTableStreamWriter()
+	require.NoError(t, err)
+	defer cleanWriterDir(t, streamWriter)
+
+	writer := NewSSTableProtoWriter[*proto.MetaData](streamWriter)
+	require.NoError(t, writer.Open())
+	for i := 0; i < 10; i++ {
+		require.NoError(t, writer.WriteNext(intToByteSlice(i), &proto.MetaData{NumRecords: uint64(i), MinKey: intToByteSlice(i)}))
+	}
+	require.NoError(t, writer.Close())
+
+	r, err := NewSSTableReader(ReadBasePath(streamWriter.opts.basePath))
+	require.NoError(t, err)
+	reader := NewSSTableProtoReader[*proto.MetaData](r)
+	defer func() { require.NoError(t, reader.Close()) }()
+
+	for i := 0; i < 10; i++ {
+		msg, err := reader.Get(intToByteSlice(i))
+		require.NoError(t, err)
+		assert.True(t, pb.Equal(&proto.MetaData{NumRecords: uint64(i), MinKey: intToByteSlice(i)}, msg))
+	}
+
+	msg, err := reader.Get(intToByteSlice(42))
+	assert.Equal(t, NotFound, err)
+	assert.Nil(t, msg)
+
+	it, err := reader.Scan()
+	require.NoError(t, err)
+	i := 0
+	for {
+		k, msg, err := it.Next()
+		if err == Done {
+			break
+		}
+		require.NoError(t, err)
+		assert.Equal(t, intToByteSlice(i), k)
+		assert.Equal(t, uint64(i), msg.NumRecords)
+		i++
+	}
+	assert.Equal(t, 10, i)
+}
+
+func TestProtoReaderUnmarshalError(t *testing.T) {
+	streamWriter, err := newTestSSTableStreamWriter()
+	require.NoError(t, err)
+	defer cleanWriterDir(t, streamWriter)
+
+	require.NoError(t, streamWriter.Open())
+	require.NoError(t, streamWriter.WriteNext(intToByteSlice(1), []byte{0xff, 0xff, 0xff}))
+	require.NoError(t, streamWriter.Close())
+
+	r, err := NewSSTableReader(ReadBasePath(streamWriter.opts.basePath))
+	require.NoError(t, err)
+	reader := NewSSTableProtoReader[*proto.MetaData](r)
+	defer func() { require.NoError(t, reader.Close()) }()
+
+	_, err = reader.Get(intToByteSlice(1))
+	assert.ErrorContains(t, err, "while unmarshalling proto value")
+}
+
+func TestProtoReaderScanRawAndFields(t *testing.T) {
+	streamWriter, err := newTestSSTableStreamWriter()
+	require.NoError(t, err)
+	defer cleanWriterDir(t, streamWriter)
+
+	newMessage := func(i int) *proto.MetaData {
+		return &proto.MetaData{
+			NumRecords:         uint64(i),
+			MinKey:             intToByteSlice(i),
+			MaxKey:             intToByteSlice(i + 1),
+			ValueSizeHistogram: []uint64{uint64(i), 1, 2},
+			UserTag:            []byte("some tag"),
+		}
+	}
+
+	writer := NewSSTableProtoWriter[*proto.MetaData](streamWriter)
+	require.NoError(t, writer.Open())
+	for i := 0; i < 10; i++ {
+		require.NoError(t, writer.WriteNext(intToByteSlice(i), newMessage(i)))
+	}
+	require.NoError(t, writer.Close())
+
+	r, err := NewSSTableReader(ReadBasePath(streamWriter.opts.basePath))
+	require.NoError(t, err)
+	reader := NewSSTableProtoReader[*proto.MetaData](r)
+	defer func() { require.NoError(t, reader.Close()) }()
+
+	raw, err := reader.ScanRaw()
+	require.NoError(t, err)
+	for i := 0; i < 10; i++ {
+		k, v, err := raw.Next()
+		require.NoError(t, err)
+		assert.Equal(t, intToByteSlice(i), k)
+		msg := &proto.MetaData{}
+		require.NoError(t, pb.Unmarshal(v, msg))
+		assert.True(t, pb.Equal(newMessage(i), msg))
+	}
+	_, _, err = raw.Next()
+	assert.Equal(t, Done, err)
+
+	it, err := reader.ScanFields("numRecords", "maxKey", "valueSizeHistogram")
+	require.NoError(t, err)
+	for i := 0; i < 10; i++ {
+		k, msg, err := it.Next()
+		require.NoError(t, err)
+		assert.Equal(t, intToByteSlice(i), k)
+		expected := &proto.MetaData{
+			NumRecords:         uint64(i),
+			MaxKey:             intToByteSlice(i + 1),
+			ValueSizeHistogram: []uint64{uint64(i), 1, 2},
+		}
+		assert.True(t, pb.Equal(expected, msg), "%v", msg)
+	}
+	_, _, err = it.Next()
+	assert.Equal(t, Done, err)
+
+	_, err = reader.ScanFields("numRecords", "doesNotExist")
+	assert.ErrorContains(t, err, "has no field 'doesNotExist'")
+}
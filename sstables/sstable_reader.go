@@ -1,22 +1,26 @@
 package sstables
 
 import (
+	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"io"
 	"os"
 
+	"hash"
 	"hash/crc64"
 	"hash/fnv"
 
 	"path/filepath"
+	"time"
 
 	"github.com/steakknife/bloomfilter"
 	"github.com/thomasjungblut/go-sstables/recordio"
 	rProto "github.com/thomasjungblut/go-sstables/recordio/proto"
 	"github.com/thomasjungblut/go-sstables/skiplist"
 	"github.com/thomasjungblut/go-sstables/sstables/proto"
-	pb "google.golang.org/protobuf/proto"
+	"golang.org/x/exp/slices"
 )
 
 type ChecksumError struct {
@@ -34,9 +38,27 @@ func (e ChecksumError) Error() string {
 	return fmt.Sprintf("Checksum mismatch: expected %x, got %x", e.expectedChecksum, e.checksum)
 }
 
+// ScanChecksumError is returned by the iterators of readers with VerifyChecksumsOnScan when a value doesn't match the
+// checksum in the index. It identifies the offending key and the offset of its value in the data file.
+type ScanChecksumError struct {
+	Key    []byte
+	Offset uint64
+	Err    ChecksumError
+}
+
+func (e ScanChecksumError) Error() string {
+	return fmt.Sprintf("corrupted value for key [%v] at data offset %d: %v", e.Key, e.Offset, e.Err)
+}
+
+func (e ScanChecksumError) Unwrap() error {
+	return e.Err
+}
+
 type SSTableReader struct {
 	opts        *SSTableReaderOptions
 	bloomFilter *bloomfilter.Filter
+	// bloomPartitions replace the bloomFilter for tables written with BloomPartitionEveryNthKey
+	bloomPartitions []bloomPartition
 
 	// key (as []byte) to a struct containing the uint64 value file offset
 	index        SortedKeyIndex
@@ -44,39 +66,348 @@ type SSTableReader struct {
 	dataReader   recordio.ReadAtI
 	metaData     *proto.MetaData
 	miscClosers  []recordio.CloseableI
+	// isClone is true for readers created with Clone, which don't own the index
+	isClone bool
+	// negativeCache contains keys that were not found in the index, nil without ReadWithNegativeCache
+	negativeCache *negativeCache
+	// tempDir contains a copy of the table that is removed on Close, see NewSSTableReaderFromFS
+	tempDir string
 }
 
+// Contains returns true when the given key exists, see SSTableReaderI. Only the bloom filter and the index are
+// consulted, the data file is never read.
 func (reader *SSTableReader) Contains(key []byte) (bool, error) {
 	// short-cut for the bloom filter to tell whether it's not in the set (if available)
-	if reader.bloomFilter != nil {
-		fnvHash := fnv.New64()
-		_, err := fnvHash.Write(key)
-		if err != nil {
-			return false, err
-		}
+	if !reader.bloomMightContain(key) {
+		return false, nil
+	}
 
-		if !reader.bloomFilter.Contains(fnvHash) {
+	// go back to the index/disk to see if the key is available
+	if !reader.filtersVersions() && reader.negativeCache == nil {
+		return reader.index.Contains(key)
+	}
+
+	_, err := reader.getIndexVal(key)
+	if err != nil {
+		if errors.Is(err, skiplist.NotFound) {
 			return false, nil
 		}
+		return false, err
 	}
+	return true, nil
+}
 
-	// go back to the index/disk to see if the key is available
-	return reader.index.Contains(key)
+// MightContain returns false when the bloom filter rules out that the table contains the key, true otherwise and for
+// tables without a bloom filter. Tables written with BloomKeyTransform expect the transformed key, for example a row
+// prefix, so a single probe can rule out all keys that share it.
+func (reader *SSTableReader) MightContain(key []byte) bool {
+	filter := reader.bloomFilter
+	if reader.bloomPartitions != nil {
+		filter = findBloomPartition(reader.bloomPartitions, key, reader.opts.keyComparator)
+		if filter == nil {
+			// the key is lower than the first key of the table
+			return false
+		}
+	}
+
+	if filter == nil {
+		return true
+	}
+
+	fnvHash := fnv.New64()
+	_, _ = fnvHash.Write(key)
+	return filter.Contains(fnvHash)
+}
+
+// bloomMightContain checks the bloom filter for a full key. Tables written with BloomKeyTransform need the same
+// transform supplied with ReadBloomKeyTransform, otherwise the bloom filter can't be used for full keys.
+func (reader *SSTableReader) bloomMightContain(key []byte) bool {
+	if reader.metaData.BloomKeyTransformed {
+		if reader.opts.bloomKeyTransform == nil {
+			return true
+		}
+		key = reader.opts.bloomKeyTransform(key)
+	}
+	return reader.MightContain(key)
 }
 
+// Get returns the value of the given key, see SSTableReaderI. With ReadWithTracer, the span ends with whether the key
+// was found and the number of bytes of its value.
 func (reader *SSTableReader) Get(key []byte) ([]byte, error) {
-	iVal, err := reader.index.Get(key)
+	if reader.opts.tracer == nil {
+		return reader.get(key)
+	}
+
+	span := reader.opts.tracer.StartSpan("sstables.Get", "path", reader.opts.basePath)
+	v, err := reader.get(key)
+	if errors.Is(err, ErrKeyNotFound) {
+		span.End("found", false, "bytes", 0)
+	} else {
+		endSpan(span, err, "found", err == nil, "bytes", len(v))
+	}
+	return v, err
+}
+
+func (reader *SSTableReader) get(key []byte) ([]byte, error) {
+	// short-cut for the bloom filter to tell whether it's not in the set (if available)
+	if !reader.bloomMightContain(key) {
+		return nil, ErrKeyNotFound
+	}
+
+	iVal, err := reader.getIndexVal(key)
+	if err != nil {
+		if errors.Is(err, skiplist.NotFound) {
+			return nil, ErrKeyNotFound
+		}
+		return nil, fmt.Errorf("error in sstable '%s' on getting key from index: %w", reader.opts.basePath, err)
+	}
+
+	v, err := reader.getValueAtOffset(iVal, reader.opts.skipHashCheckOnRead)
+	if err != nil && reader.treatAsMissing(key, err) {
+		return nil, ErrKeyNotFound
+	}
+	return v, err
+}
+
+// treatAsMissing returns true when err is a checksum mismatch that the ChecksumMismatchPolicy treats as a missing
+// record, after calling its callback
+func (reader *SSTableReader) treatAsMissing(key []byte, err error) bool {
+	policy := reader.opts.checksumMismatchPolicy
+	if !policy.treatAsMissing || !errors.Is(err, ChecksumError{}) {
+		return false
+	}
+	if policy.callback != nil {
+		policy.callback(key, err)
+	}
+	return true
+}
+
+// GetInto works like Get, but reads the value into dst to avoid allocating a new value on every call. dst is grown when
+// its capacity is too small. The returned slice aliases dst whenever it fits, so it is only valid until dst is reused
+// by the caller. Nil values return nil. Tables of version 0 always allocate.
+func (reader *SSTableReader) GetInto(key []byte, dst []byte) ([]byte, error) {
+	if !reader.bloomMightContain(key) {
+		return nil, ErrKeyNotFound
+	}
+
+	iVal, err := reader.getIndexVal(key)
 	if err != nil {
 		if errors.Is(err, skiplist.NotFound) {
-			return nil, NotFound
+			return nil, ErrKeyNotFound
 		}
 		return nil, fmt.Errorf("error in sstable '%s' on getting key from index: %w", reader.opts.basePath, err)
 	}
 
-	return reader.getValueAtOffset(iVal, reader.opts.skipHashCheckOnRead)
+	v, err := reader.getValueAtOffsetInto(iVal, reader.opts.skipHashCheckOnRead, dst)
+	if err != nil && reader.treatAsMissing(key, err) {
+		return nil, ErrKeyNotFound
+	}
+	return v, err
+}
+
+// GetOrDefault works like Get, but returns def when the key is not in the table. Only errors while reading, for example
+// IO errors or a ChecksumError, are returned.
+func (reader *SSTableReader) GetOrDefault(key []byte, def []byte) ([]byte, error) {
+	v, err := reader.Get(key)
+	if errors.Is(err, ErrKeyNotFound) {
+		return def, nil
+	}
+	return v, err
+}
+
+// GetWithChecksum returns the value associated with the given key along with the checksum that was recorded in the
+// index when the value was written, ErrKeyNotFound as the error otherwise. The value is never verified against the checksum,
+// this is left to the caller. The checksum is a CRC-64 of the raw value bytes using the ISO polynomial, as in
+// crc64.Checksum(value, crc64.MakeTable(crc64.ISO)), unless the table was written with WithChecksumFunc.
+// Tables written without checksums (e.g. version 0 or with DisableValueChecksums) return zero.
+func (reader *SSTableReader) GetWithChecksum(key []byte) ([]byte, uint64, error) {
+	iVal, err := reader.getIndexVal(key)
+	if err != nil {
+		if errors.Is(err, skiplist.NotFound) {
+			return nil, 0, ErrKeyNotFound
+		}
+		return nil, 0, fmt.Errorf("error in sstable '%s' on getting key from index: %w", reader.opts.basePath, err)
+	}
+
+	v, err := reader.getValueAtOffset(iVal, true)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return v, iVal.Checksum, nil
+}
+
+// GetStreaming returns a reader over the value associated with the given key, ErrKeyNotFound as the error otherwise.
+// Values are read directly from the data file without buffering them, compressed values are decompressed on the fly
+// while they are read. The value is always verified against its checksum while it's consumed: on a mismatch, Read
+// returns an error wrapping ChecksumError instead of io.EOF. The reader must not be used after the table is closed.
+func (reader *SSTableReader) GetStreaming(key []byte) (io.ReadCloser, error) {
+	// short-cut for the bloom filter to tell whether it's not in the set (if available)
+	if !reader.bloomMightContain(key) {
+		return nil, ErrKeyNotFound
+	}
+
+	iVal, err := reader.getIndexVal(key)
+	if err != nil {
+		if errors.Is(err, skiplist.NotFound) {
+			return nil, ErrKeyNotFound
+		}
+		return nil, fmt.Errorf("error in sstable '%s' on getting key from index: %w", reader.opts.basePath, err)
+	}
+
+	streamingReader, ok := reader.dataReader.(recordio.StreamingReadAtI)
+	if !ok {
+		v, err := reader.getValueAtOffset(iVal, false)
+		if err != nil {
+			return nil, err
+		}
+		return io.NopCloser(bytes.NewReader(v)), nil
+	}
+
+	var valueReader io.Reader = bytes.NewReader(nil)
+	if !iVal.NullValue {
+		valueReader, err = streamingReader.ReadNextAtStreaming(iVal.Offset)
+		if err != nil {
+			return nil, fmt.Errorf("error in sstable '%s' while getting value at offset %d: %w",
+				reader.opts.basePath, iVal.Offset, err)
+		}
+	}
+
+	return &checksumValueReader{
+		reader:   valueReader,
+		crc:      reader.opts.newChecksum(),
+		iVal:     iVal,
+		basePath: reader.opts.basePath,
+		logger:   reader.opts.logger,
+	}, nil
+}
+
+// checksumValueReader computes the checksum of the value while it's read and verifies it once the end is reached
+type checksumValueReader struct {
+	reader   io.Reader
+	crc      hash.Hash64
+	iVal     IndexVal
+	basePath string
+	logger   Logger
+	closed   bool
+}
+
+func (v *checksumValueReader) Read(p []byte) (int, error) {
+	if v.closed {
+		return 0, fmt.Errorf("sstables.GetStreaming '%s': value was already closed", v.basePath)
+	}
+
+	n, err := v.reader.Read(p)
+	_, _ = v.crc.Write(p[:n])
+	// a zero checksum could come from default values, reading older formats
+	if errors.Is(err, io.EOF) && v.iVal.Checksum != 0 && v.crc.Sum64() != v.iVal.Checksum {
+		v.logger.Warn("sstable checksum mismatch", "path", v.basePath, "offset", v.iVal.Offset)
+		return n, fmt.Errorf("error in sstable '%s' while hashing value at offset [%d]: %w",
+			v.basePath, v.iVal.Offset, ChecksumError{v.crc.Sum64(), v.iVal.Checksum})
+	}
+
+	return n, err
+}
+
+func (v *checksumValueReader) Close() error {
+	v.closed = true
+	return nil
+}
+
+// filtersVersions is true when more than a single version of a key can be in the index, or versions need to be hidden
+func (reader *SSTableReader) filtersVersions() bool {
+	return reader.metaData.Versioned || reader.opts.readAsOfSeq
+}
+
+// reusesScanBuffers is only true without version filtering, which holds on to the previous value while reading ahead
+func (reader *SSTableReader) reusesScanBuffers() bool {
+	return reader.opts.reuseScanBuffers && !reader.filtersVersions()
+}
+
+// getIndexVal returns the index entry of the newest version of the key that is visible with ReadAsOfSeq,
+// skiplist.NotFound as the error otherwise. Absent keys are remembered in the negative cache, if enabled.
+func (reader *SSTableReader) getIndexVal(key []byte) (IndexVal, error) {
+	if reader.negativeCache == nil {
+		return reader.lookupIndexVal(key)
+	}
+
+	if reader.negativeCache.contains(key) {
+		return IndexVal{}, skiplist.NotFound
+	}
+
+	iVal, err := reader.lookupIndexVal(key)
+	if errors.Is(err, skiplist.NotFound) {
+		reader.negativeCache.add(key)
+	}
+	return iVal, err
+}
+
+// lookupIndexVal searches the index for the newest visible version of the key, see getIndexVal
+func (reader *SSTableReader) lookupIndexVal(key []byte) (IndexVal, error) {
+	if !reader.metaData.Versioned {
+		iVal, err := reader.index.Get(key)
+		if err != nil {
+			return IndexVal{}, err
+		}
+		if !reader.isVisible(iVal.SequenceNumber) {
+			return IndexVal{}, skiplist.NotFound
+		}
+		return iVal, nil
+	}
+
+	// versions of a key are next to each other in the index, ordered by ascending sequence numbers
+	it, err := reader.index.IteratorStartingAt(key)
+	if err != nil {
+		return IndexVal{}, err
+	}
+
+	found := false
+	var newest IndexVal
+	for {
+		k, iVal, err := it.Next()
+		if errors.Is(err, skiplist.Done) {
+			break
+		}
+		if err != nil {
+			return IndexVal{}, err
+		}
+		if reader.opts.keyComparator.Compare(k, key) != 0 {
+			break
+		}
+		if reader.isVisible(iVal.SequenceNumber) {
+			newest, found = iVal, true
+		}
+	}
+
+	if !found {
+		return IndexVal{}, skiplist.NotFound
+	}
+	return newest, nil
+}
+
+// isVisible returns whether a record with the given sequence number can be read with ReadAsOfSeq
+func (reader *SSTableReader) isVisible(seq uint64) bool {
+	return !reader.opts.readAsOfSeq || seq <= reader.opts.asOfSeq
+}
+
+// filterVersions only returns the newest visible version of every key when the reader filters versions. All scans go
+// through it, so it also skips corrupt records when the ChecksumMismatchPolicy treats them as missing.
+func (reader *SSTableReader) filterVersions(it SSTableIteratorI) SSTableIteratorI {
+	if reader.opts.checksumMismatchPolicy.treatAsMissing {
+		it = &skipCorruptIterator{it: it, reader: reader}
+	}
+	if !reader.filtersVersions() {
+		return it
+	}
+	return newVersionFilterIterator(it.(SequencedIteratorI), reader.opts.keyComparator, reader.isVisible)
 }
 
 func (reader *SSTableReader) getValueAtOffset(iVal IndexVal, skipHashCheck bool) (v []byte, err error) {
+	return reader.getValueAtOffsetInto(iVal, skipHashCheck, nil)
+}
+
+// getValueAtOffsetInto reads the value into dst if the data reader supports it, a nil dst allocates a new value
+func (reader *SSTableReader) getValueAtOffsetInto(iVal IndexVal, skipHashCheck bool, dst []byte) (v []byte, err error) {
 	if reader.v0DataReader != nil {
 		value := &proto.DataEntry{}
 		_, err := reader.v0DataReader.ReadNextAt(value, iVal.Offset)
@@ -87,20 +418,30 @@ func (reader *SSTableReader) getValueAtOffset(iVal IndexVal, skipHashCheck bool)
 
 		v = value.Value
 	} else {
-		v, err = reader.dataReader.ReadNextAt(iVal.Offset)
+		if intoReader, ok := reader.dataReader.(recordio.IntoReadAtI); ok && dst != nil {
+			v, err = intoReader.ReadNextAtInto(iVal.Offset, dst)
+		} else {
+			v, err = reader.dataReader.ReadNextAt(iVal.Offset)
+		}
 		if err != nil && err != io.EOF {
 			return nil, fmt.Errorf("error in sstable '%s' while getting value at offset %d: %w",
 				reader.opts.basePath, iVal.Offset, err)
 		}
+
+		// the index is the authority on nil values, older tables without the flag rely on the recordio nil marker
+		if iVal.NullValue {
+			v = nil
+		}
 	}
 
 	if skipHashCheck {
 		return v, nil
 	}
 
-	valChecksum, err := checksumValue(v)
+	valChecksum, err := checksumValue(reader.opts.newChecksum, v)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("error in sstable '%s' while hashing value at offset [%d]: %w",
+			reader.opts.basePath, iVal.Offset, err)
 	}
 
 	if valChecksum != iVal.Checksum {
@@ -109,6 +450,7 @@ func (reader *SSTableReader) getValueAtOffset(iVal IndexVal, skipHashCheck bool)
 			return v, nil
 		}
 
+		reader.opts.logger.Warn("sstable checksum mismatch", "path", reader.opts.basePath, "offset", iVal.Offset)
 		return v, fmt.Errorf("error in sstable '%s' while hashing value at offset [%d]: %w",
 			reader.opts.basePath, iVal.Offset, ChecksumError{valChecksum, iVal.Checksum})
 	}
@@ -116,9 +458,25 @@ func (reader *SSTableReader) getValueAtOffset(iVal IndexVal, skipHashCheck bool)
 	return v, nil
 }
 
+// Scan returns an iterator over the whole table, see SSTableReaderI. With ReadWithTracer, the span ends once the
+// iterator returned Done or failed, it stays open when the iterator is abandoned before.
 func (reader *SSTableReader) Scan() (SSTableIteratorI, error) {
+	if reader.opts.tracer == nil {
+		return reader.scan()
+	}
+
+	span := reader.opts.tracer.StartSpan("sstables.Scan", "path", reader.opts.basePath)
+	it, err := reader.scan()
+	if err != nil {
+		endSpan(span, err)
+		return nil, err
+	}
+	return &tracedScanIterator{it: it, span: span}, nil
+}
+
+func (reader *SSTableReader) scan() (SSTableIteratorI, error) {
 	if reader.v0DataReader != nil {
-		dataReader, err := rProto.NewReader(rProto.ReaderPath(filepath.Join(reader.opts.basePath, DataFileName)))
+		dataReader, err := rProto.NewReader(rProto.ReaderPath(filepath.Join(reader.opts.basePath, reader.opts.dataFileName)))
 		if err != nil {
 			return nil, fmt.Errorf("error in sstable '%s' while creating a scanner: %w", reader.opts.basePath, err)
 		}
@@ -134,12 +492,28 @@ func (reader *SSTableReader) Scan() (SSTableIteratorI, error) {
 		if err != nil {
 			return nil, fmt.Errorf("error in sstable '%s' while creating a scanner iterator: %w", reader.opts.basePath, err)
 		}
-		return newV0SStableFullScanIterator(it, dataReader)
+		v0It, err := newV0SStableFullScanIterator(it, dataReader)
+		if err != nil {
+			return nil, err
+		}
+		return reader.filterVersions(v0It), nil
+	} else if reader.opts.dataFile != nil && !reader.metaData.ScanOnly {
+		// the supplied file is shared, so its values are read with random access instead of moving its offset
+		it, err := reader.index.Iterator()
+		if err != nil {
+			return nil, fmt.Errorf("error in sstable '%s' while creating a scanner iterator: %w", reader.opts.basePath, err)
+		}
+		return reader.filterVersions(&SSTableIterator{reader: reader, keyIterator: it, reuseBuffers: reader.reusesScanBuffers()}), nil
 	} else {
-		dataReader, err := recordio.NewFileReader(
-			recordio.ReaderPath(filepath.Join(reader.opts.basePath, DataFileName)),
+		scanOpts := []recordio.FileReaderOption{
+			recordio.ReaderPath(filepath.Join(reader.opts.basePath, reader.opts.dataFileName)),
 			recordio.ReaderBufferSizeBytes(reader.opts.readBufferSizeBytes),
-		)
+			recordio.ReaderReadAheadBytes(reader.opts.readAheadBytes),
+		}
+		if reader.opts.adviseSequentialScan {
+			scanOpts = append(scanOpts, recordio.ReaderAdviseSequential())
+		}
+		dataReader, err := recordio.NewFileReader(scanOpts...)
 		if err != nil {
 			return nil, fmt.Errorf("error in sstable '%s' while creating a scanner: %w", reader.opts.basePath, err)
 		}
@@ -150,11 +524,31 @@ func (reader *SSTableReader) Scan() (SSTableIteratorI, error) {
 
 		reader.miscClosers = append(reader.miscClosers, dataReader)
 
+		var throttle *scanThrottle
+		if fileReader, ok := dataReader.(*recordio.FileReader); ok && reader.opts.rateLimiter != nil {
+			throttle = newScanThrottle(reader.opts.rateLimiterCtx, reader.opts.rateLimiter, fileReader)
+		}
+
+		if reader.metaData.ScanOnly {
+			return reader.filterVersions(&scanOnlyIterator{
+				dataReader:      dataReader,
+				skipHashCheck:   reader.opts.skipHashCheckOnRead,
+				verifyChecksums: reader.opts.verifyChecksumsOnScan,
+				newChecksum:     reader.opts.newChecksum,
+				reuseBuffers:    reader.reusesScanBuffers(),
+				throttle:        throttle,
+			}), nil
+		}
+
 		it, err := reader.index.Iterator()
 		if err != nil {
 			return nil, fmt.Errorf("error in sstable '%s' while creating a scanner iterator: %w", reader.opts.basePath, err)
 		}
-		return newSStableFullScanIterator(it, dataReader, reader.opts.skipHashCheckOnRead)
+		scanIt, err := newSStableFullScanIterator(it, dataReader, reader.opts.skipHashCheckOnRead, reader.opts.verifyChecksumsOnScan, reader.opts.newChecksum, reader.reusesScanBuffers(), throttle)
+		if err != nil {
+			return nil, err
+		}
+		return reader.filterVersions(scanIt), nil
 	}
 }
 
@@ -163,15 +557,247 @@ func (reader *SSTableReader) ScanStartingAt(key []byte) (SSTableIteratorI, error
 	if err != nil {
 		return nil, fmt.Errorf("error in sstable '%s' in ScanStartingAt: %w", reader.opts.basePath, err)
 	}
-	return &SSTableIterator{reader: reader, keyIterator: it}, nil
+	return reader.filterVersions(&SSTableIterator{reader: reader, keyIterator: it, reuseBuffers: reader.reusesScanBuffers()}), nil
 }
 
 func (reader *SSTableReader) ScanRange(keyLower []byte, keyHigher []byte) (SSTableIteratorI, error) {
+	it, err := reader.rangeKeyIterator(keyLower, keyHigher)
+	if err != nil {
+		return nil, err
+	}
+	return reader.filterVersions(&SSTableIterator{reader: reader, keyIterator: it, reuseBuffers: reader.reusesScanBuffers()}), nil
+}
+
+// ScanFilter scans the whole table, but only returns the records whose key matches the predicate. The predicate is
+// evaluated on the keys of the index while traversing it, values are only read from the data file, and decompressed,
+// for keys that match. Unlike Scan, the values are read with random access. See ScanRangeFilter to narrow the scan.
+func (reader *SSTableReader) ScanFilter(pred func(key []byte) bool) (SSTableIteratorI, error) {
+	it, err := reader.index.Iterator()
+	if err != nil {
+		return nil, fmt.Errorf("error in sstable '%s' in ScanFilter: %w", reader.opts.basePath, err)
+	}
+	keyIterator := &filterKeyIterator{it: it, pred: pred}
+	return reader.filterVersions(&SSTableIterator{reader: reader, keyIterator: keyIterator, reuseBuffers: reader.reusesScanBuffers()}), nil
+}
+
+// ScanRangeFilter works like ScanFilter, but only evaluates the predicate for the keys between keyLower and keyHigher
+// (both inclusive), as in ScanRange.
+func (reader *SSTableReader) ScanRangeFilter(keyLower []byte, keyHigher []byte, pred func(key []byte) bool) (SSTableIteratorI, error) {
+	it, err := reader.rangeKeyIterator(keyLower, keyHigher)
+	if err != nil {
+		return nil, err
+	}
+	keyIterator := &filterKeyIterator{it: it, pred: pred}
+	return reader.filterVersions(&SSTableIterator{reader: reader, keyIterator: keyIterator, reuseBuffers: reader.reusesScanBuffers()}), nil
+}
+
+func (reader *SSTableReader) rangeKeyIterator(keyLower []byte, keyHigher []byte) (skiplist.IteratorI[[]byte, IndexVal], error) {
+	if reader.metaData.Versioned {
+		return reader.versionedRangeKeyIterator(keyLower, keyHigher)
+	}
+
 	it, err := reader.index.IteratorBetween(keyLower, keyHigher)
 	if err != nil {
 		return nil, fmt.Errorf("error in sstable '%s' in ScanRange: %w", reader.opts.basePath, err)
 	}
-	return &SSTableIterator{reader: reader, keyIterator: it}, nil
+	return it, nil
+}
+
+// versionedRangeKeyIterator bounds the range itself, the indices end their ranges at the first version of keyHigher
+func (reader *SSTableReader) versionedRangeKeyIterator(keyLower []byte, keyHigher []byte) (skiplist.IteratorI[[]byte, IndexVal], error) {
+	if reader.opts.keyComparator.Compare(keyLower, keyHigher) > 0 {
+		return nil, fmt.Errorf("error in sstable '%s' in ScanRange: keyHigher is lower than keyLower", reader.opts.basePath)
+	}
+
+	it, err := reader.index.IteratorStartingAt(keyLower)
+	if err != nil {
+		return nil, fmt.Errorf("error in sstable '%s' in ScanRange: %w", reader.opts.basePath, err)
+	}
+	return &boundedKeyIterator{it: it, keyHigher: keyHigher, cmp: reader.opts.keyComparator}, nil
+}
+
+// openDataFile opens the data file of this reader, it's not shared between clones
+func (reader *SSTableReader) openDataFile() error {
+	if reader.metaData.Version == 0 {
+		v0DataReader, err := rProto.NewMMapProtoReaderWithPath(filepath.Join(reader.opts.basePath, reader.opts.dataFileName))
+		if err != nil {
+			return fmt.Errorf("error while creating proto data reader of sstable in '%s': %w", reader.opts.basePath, err)
+		}
+
+		err = v0DataReader.Open()
+		if err != nil {
+			return fmt.Errorf("error while opening proto data reader of sstable in '%s': %w", reader.opts.basePath, err)
+		}
+
+		reader.v0DataReader = v0DataReader
+	} else {
+		dataPath := filepath.Join(reader.opts.basePath, reader.opts.dataFileName)
+		var dataReader recordio.ReadAtI
+		var err error
+		if reader.opts.dataFile != nil {
+			dataReader, err = recordio.NewReadAtReaderWithFile(reader.opts.dataFile, reader.opts.bufferPool)
+		} else if reader.opts.bufferPool != nil {
+			dataReader, err = recordio.NewMemoryMappedReaderWithPool(dataPath, reader.opts.bufferPool)
+		} else {
+			dataReader, err = recordio.NewMemoryMappedReaderWithPath(dataPath)
+		}
+		if err != nil {
+			return fmt.Errorf("error while creating data reader of sstable in '%s': %w", reader.opts.basePath, err)
+		}
+
+		err = dataReader.Open()
+		if err != nil {
+			return fmt.Errorf("error while opening data reader of sstable in '%s': %w", reader.opts.basePath, err)
+		}
+
+		reader.dataReader = dataReader
+	}
+
+	return nil
+}
+
+// Preload reads the data file once, so that the first lookups after opening the table don't wait for the disk. The
+// pages of the memory mapped data file are faulted in, the index file is read into the page cache when it's read from
+// disk by the DiskIndexLoader. In-memory indices were already loaded when the table was opened. It returns the number
+// of bytes read, along with the error of ctx when it was done before everything was read.
+func (reader *SSTableReader) Preload(ctx context.Context) (uint64, error) {
+	var n uint64
+	var err error
+	if preloader, ok := reader.dataReader.(recordio.PreloadI); ok {
+		n, err = preloader.Preload(ctx)
+	} else {
+		n, err = recordio.LoadIntoPageCache(ctx, filepath.Join(reader.opts.basePath, reader.opts.dataFileName))
+	}
+	if err != nil {
+		return n, fmt.Errorf("error in sstable '%s' while preloading the data file: %w", reader.opts.basePath, err)
+	}
+
+	if _, ok := reader.index.(*DiskKeyIndex); ok {
+		indexBytes, err := recordio.LoadIntoPageCache(ctx, filepath.Join(reader.opts.basePath, reader.opts.indexFileName))
+		n += indexBytes
+		if err != nil {
+			return n, fmt.Errorf("error in sstable '%s' while preloading the index file: %w", reader.opts.basePath, err)
+		}
+	}
+
+	return n, nil
+}
+
+// IndexIterator returns an iterator over the raw entries of the index in key order, for example for inspection tools.
+// No values are read and all versions of a key are returned, independent of ReadAsOfSeq.
+func (reader *SSTableReader) IndexIterator() (*IndexEntryIterator, error) {
+	it, err := reader.index.Iterator()
+	if err != nil {
+		return nil, fmt.Errorf("error in sstable '%s' while creating an index iterator: %w", reader.opts.basePath, err)
+	}
+	return &IndexEntryIterator{it: it}, nil
+}
+
+// GetByOrdinal returns the record at the given 0-based position in key order, ErrOrdinalOutOfRange if the table has
+// fewer records. Indices that implement OrdinalIndex, like the slice, arena and map indices, find the entry in constant
+// time. All other indices, for example the skip list and the disk index, iterate over the index up to the position.
+// Positions count every record in the table, including nil values and all versions of a key independent of ReadAsOfSeq.
+func (reader *SSTableReader) GetByOrdinal(n uint64) ([]byte, []byte, error) {
+	key, iVal, err := reader.indexEntryAt(n)
+	if err != nil {
+		if errors.Is(err, ErrOrdinalOutOfRange) {
+			return nil, nil, err
+		}
+		return nil, nil, fmt.Errorf("error in sstable '%s' on getting ordinal %d from index: %w", reader.opts.basePath, n, err)
+	}
+
+	value, err := reader.getValueAtOffset(iVal, reader.opts.skipHashCheckOnRead)
+	if err != nil {
+		return nil, nil, err
+	}
+	return slices.Clone(key), value, nil
+}
+
+// FirstKey returns the smallest key of the table from the MinKey of the metadata without touching the index. Tables
+// whose metadata lacks the key range, for example those written by older versions, fall back to the first entry of
+// the index. Empty tables return ErrEmptyTable.
+func (reader *SSTableReader) FirstKey() ([]byte, error) {
+	if reader.metaData.MinKey != nil {
+		return slices.Clone(reader.metaData.MinKey), nil
+	}
+
+	it, err := reader.index.Iterator()
+	if err != nil {
+		return nil, fmt.Errorf("error in sstable '%s' while creating an index iterator: %w", reader.opts.basePath, err)
+	}
+	key, _, err := it.Next()
+	if errors.Is(err, skiplist.Done) {
+		return nil, ErrEmptyTable
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error in sstable '%s' while reading the first key of the index: %w", reader.opts.basePath, err)
+	}
+	return slices.Clone(key), nil
+}
+
+// LastKey returns the largest key of the table from the MaxKey of the metadata, with the same fallback as FirstKey.
+// The fallback needs to iterate the whole index, unless the index implements OrdinalIndex and NumRecords is known.
+func (reader *SSTableReader) LastKey() ([]byte, error) {
+	if reader.metaData.MaxKey != nil {
+		return slices.Clone(reader.metaData.MaxKey), nil
+	}
+
+	if _, ok := reader.index.(OrdinalIndex); ok && reader.metaData.NumRecords > 0 {
+		key, _, err := reader.indexEntryAt(reader.metaData.NumRecords - 1)
+		if err != nil {
+			return nil, fmt.Errorf("error in sstable '%s' while reading the last key of the index: %w", reader.opts.basePath, err)
+		}
+		return slices.Clone(key), nil
+	}
+
+	it, err := reader.index.Iterator()
+	if err != nil {
+		return nil, fmt.Errorf("error in sstable '%s' while creating an index iterator: %w", reader.opts.basePath, err)
+	}
+	var last []byte
+	for {
+		key, _, err := it.Next()
+		if errors.Is(err, skiplist.Done) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error in sstable '%s' while reading the last key of the index: %w", reader.opts.basePath, err)
+		}
+		last = key
+	}
+	if last == nil {
+		return nil, ErrEmptyTable
+	}
+	return slices.Clone(last), nil
+}
+
+func (reader *SSTableReader) indexEntryAt(n uint64) ([]byte, IndexVal, error) {
+	if ordinalIndex, ok := reader.index.(OrdinalIndex); ok {
+		return ordinalIndex.EntryAt(n)
+	}
+
+	it, err := reader.index.Iterator()
+	if err != nil {
+		return nil, IndexVal{}, err
+	}
+
+	for i := uint64(0); ; i++ {
+		key, iVal, err := it.Next()
+		if errors.Is(err, skiplist.Done) {
+			return nil, IndexVal{}, ErrOrdinalOutOfRange
+		}
+		if err != nil {
+			return nil, IndexVal{}, err
+		}
+		if i == n {
+			return key, iVal, nil
+		}
+	}
+}
+
+// IndexMemoryEstimate returns the estimated memory in bytes of the loaded index, see EstimateIndexMemoryBytes.
+func (reader *SSTableReader) IndexMemoryEstimate() (uint64, bool) {
+	return EstimateIndexMemoryBytes(reader.opts.indexLoader, reader.metaData)
 }
 
 func (reader *SSTableReader) Close() (err error) {
@@ -187,17 +813,67 @@ func (reader *SSTableReader) Close() (err error) {
 		err = errors.Join(err, reader.dataReader.Close())
 	}
 
-	if reader.index != nil {
+	// the index is shared with the clones and only closed by the reader that loaded it
+	if reader.index != nil && !reader.isClone {
 		err = errors.Join(err, reader.index.Close())
 	}
 
+	if reader.tempDir != "" {
+		err = errors.Join(err, os.RemoveAll(reader.tempDir))
+	}
+
 	return err
 }
 
+// Clone returns a new reader that shares the already loaded index, bloom filter and metadata with this reader, but
+// opens its own data file. This allows independent and concurrent scans without loading the index again for each of them.
+// Closing a clone only closes its data file, the shared index is closed with this reader. Hence, this reader must
+// outlive all of its clones and must not be closed while they are still in use.
+func (reader *SSTableReader) Clone() (*SSTableReader, error) {
+	clone := &SSTableReader{
+		opts:        reader.opts,
+		bloomFilter: reader.bloomFilter,
+		// the partitions are immutable after loading, like the bloom filter
+		bloomPartitions: reader.bloomPartitions,
+		index:           reader.index,
+		metaData:        reader.metaData,
+		isClone:         true,
+		// the table is the same, so are its absent keys
+		negativeCache: reader.negativeCache,
+	}
+
+	err := clone.openDataFile()
+	if err != nil {
+		return nil, err
+	}
+	return clone, nil
+}
+
 func (reader *SSTableReader) MetaData() *proto.MetaData {
 	return reader.metaData
 }
 
+// SchemaID returns the id supplied with WithSchemaID when the table was written, false if it was written without one.
+func (reader *SSTableReader) SchemaID() (uint32, bool) {
+	if r, ok := reader.dataReader.(recordio.SchemaIDReaderI); ok {
+		return r.SchemaID()
+	}
+	return 0, false
+}
+
+// KeyComparator returns the comparator supplied with ReadWithKeyComparator, which defaults to skiplist.BytesComparator.
+// It can be used to order keys consistently with the table, for example when merging the results of several readers.
+func (reader *SSTableReader) KeyComparator() skiplist.Comparator[[]byte] {
+	return reader.opts.keyComparator
+}
+
+// ContentHash returns a fingerprint over all keys and value checksums in their sorted order, which is independent of
+// the compression settings. Two tables with the same logical content have the same hash. Tables written before the
+// hash was introduced return zero.
+func (reader *SSTableReader) ContentHash() uint64 {
+	return reader.metaData.ContentHash
+}
+
 func (reader *SSTableReader) BasePath() string {
 	return reader.opts.basePath
 }
@@ -212,33 +888,98 @@ func (reader *SSTableReader) validateDataFile() error {
 		return nil
 	}
 
-	iterator, err := reader.index.Iterator()
+	if reader.metaData.ScanOnly {
+		return reader.validateScanOnlyDataFile()
+	}
+
+	iterator, err := reader.index.Iterator()
+	if err != nil {
+		return err
+	}
+
+	indexChecksum := crc64.New(crc64.MakeTable(crc64.ISO))
+	for {
+		k, iv, err := iterator.Next()
+		if err != nil {
+			if errors.Is(err, skiplist.Done) {
+				break
+			}
+
+			return fmt.Errorf("validateDataFile error iterating sstable '%s' at key [%v]: %w",
+				reader.opts.basePath, k, err)
+		}
+
+		updateIndexChecksum(indexChecksum, k, iv)
+		// the values are still read to make sure they are in the data file, even if there are no checksums to verify
+		if _, err := reader.getValueAtOffset(iv, reader.metaData.ValueChecksumsDisabled); err != nil {
+			return fmt.Errorf("validateDataFile error loading value '%s' at key [%v]: %w",
+				reader.opts.basePath, k, err)
+		}
+	}
+
+	// tables written before the index checksum was introduced will have it set to zero
+	if reader.metaData.IndexChecksum != 0 && reader.metaData.IndexChecksum != indexChecksum.Sum64() {
+		reader.opts.logger.Warn("sstable index checksum mismatch", "path", reader.opts.basePath)
+		return fmt.Errorf("validateDataFile error index checksum mismatch in sstable '%s': %w",
+			reader.opts.basePath, ChecksumError{indexChecksum.Sum64(), reader.metaData.IndexChecksum})
+	}
+
+	return nil
+}
+
+// validateKeyRange compares the first and the last key of the index with the MinKey and MaxKey of the metadata. The last
+// key is found by seeking to MaxKey, so it only needs a single lookup instead of iterating the whole index.
+func validateKeyRange(index SortedKeyIndex, metaData *proto.MetaData, cmp skiplist.Comparator[[]byte]) error {
+	it, err := index.Iterator()
+	if err != nil {
+		return err
+	}
+	first, _, err := it.Next()
+	if errors.Is(err, skiplist.Done) {
+		if metaData.NumRecords > 0 {
+			return fmt.Errorf("index is empty, but the metadata has %d records: %w", metaData.NumRecords, ErrKeyRangeMismatch)
+		}
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if cmp.Compare(first, metaData.MinKey) != 0 {
+		return fmt.Errorf("first key %v does not match the min key %v: %w", first, metaData.MinKey, ErrKeyRangeMismatch)
+	}
+
+	it, err = index.IteratorStartingAt(metaData.MaxKey)
+	if err != nil {
+		return err
+	}
+	last, _, err := it.Next()
+	if errors.Is(err, skiplist.Done) {
+		return fmt.Errorf("max key %v is not in the index: %w", metaData.MaxKey, ErrKeyRangeMismatch)
+	}
 	if err != nil {
 		return err
 	}
+	if cmp.Compare(last, metaData.MaxKey) != 0 {
+		return fmt.Errorf("max key %v is not in the index: %w", metaData.MaxKey, ErrKeyRangeMismatch)
+	}
 
+	// versions of the max key can follow it, any greater key can't
 	for {
-		k, iv, err := iterator.Next()
+		k, _, err := it.Next()
+		if errors.Is(err, skiplist.Done) {
+			return nil
+		}
 		if err != nil {
-			if errors.Is(err, skiplist.Done) {
-				break
-			}
-
-			return fmt.Errorf("validateDataFile error iterating sstable '%s' at key [%v]: %w",
-				reader.opts.basePath, k, err)
+			return err
 		}
-
-		if _, err := reader.getValueAtOffset(iv, false); err != nil {
-			return fmt.Errorf("validateDataFile error loading value '%s' at key [%v]: %w",
-				reader.opts.basePath, k, err)
+		if cmp.Compare(k, metaData.MaxKey) != 0 {
+			return fmt.Errorf("key %v is greater than the max key %v: %w", k, metaData.MaxKey, ErrKeyRangeMismatch)
 		}
 	}
-
-	return nil
 }
 
-func checksumValue(value []byte) (uint64, error) {
-	crc := crc64.New(crc64.MakeTable(crc64.ISO))
+func checksumValue(newChecksum func() hash.Hash64, value []byte) (uint64, error) {
+	crc := newChecksum()
 	_, err := crc.Write(value)
 	if err != nil {
 		return 0, err
@@ -250,14 +991,22 @@ func checksumValue(value []byte) (uint64, error) {
 // NewSSTableReader creates a new reader. The sstable base path is mandatory:
 // > sstables.NewSSTableReader(sstables.ReadBasePath("some_path"))
 // This function will check hashes and validity of the datafile matching the index file.
-func NewSSTableReader(readerOptions ...ReadOption) (SSTableReaderI, error) {
+func NewSSTableReader(readerOptions ...ReadOption) (opened SSTableReaderI, err error) {
+	start := time.Now()
 	opts := &SSTableReaderOptions{
 		basePath: "",
 		// by default, we validate the integrity on loading and never checking when reading.
 		// Other use cases might want to rather check the integrity at runtime while reading key / value pairs.
-		skipHashCheckOnLoad: false,
-		skipHashCheckOnRead: true,
-		readBufferSizeBytes: 4 * 1024 * 1024,
+		skipHashCheckOnLoad:     false,
+		skipHashCheckOnRead:     true,
+		readBufferSizeBytes:     4 * 1024 * 1024,
+		indexFileName:           IndexFileName,
+		dataFileName:            DataFileName,
+		bloomFileName:           BloomFileName,
+		bloomPartitionsFileName: BloomPartitionsFileName,
+		metaFileName:            MetaFileName,
+		summaryFileName:         SummaryFileName,
+		committedFileName:       CommittedFileName,
 	}
 
 	for _, readOption := range readerOptions {
@@ -268,23 +1017,130 @@ func NewSSTableReader(readerOptions ...ReadOption) (SSTableReaderI, error) {
 		return nil, errors.New("SSTableReader: basePath was not supplied")
 	}
 
+	if opts.negativeCacheSize < 0 {
+		return nil, fmt.Errorf("SSTableReader: unexpected negative cache size, was: %d", opts.negativeCacheSize)
+	}
+
+	if opts.rateLimiter != nil && opts.rateLimiterCtx == nil {
+		opts.rateLimiterCtx = context.Background()
+	}
+
+	if opts.logger == nil {
+		opts.logger = noopLogger{}
+	}
+
+	if opts.tracer != nil {
+		span := opts.tracer.StartSpan("sstables.Open", "path", opts.basePath)
+		defer func() {
+			if err != nil {
+				endSpan(span, err)
+				return
+			}
+			md := opened.(*SSTableReader).metaData
+			span.End("records", md.NumRecords, "indexBytes", md.IndexBytes)
+		}()
+	}
+
+	if err := validateFileNames(opts.indexFileName, opts.dataFileName, opts.bloomFileName, opts.bloomPartitionsFileName, opts.metaFileName, opts.summaryFileName, opts.committedFileName); err != nil {
+		return nil, fmt.Errorf("SSTableReader: %w", err)
+	}
+
 	if opts.keyComparator == nil {
 		opts.keyComparator = skiplist.BytesComparator{}
 	}
 
-	if opts.indexLoader == nil {
-		opts.indexLoader = &SkipListIndexLoader{
-			KeyComparator:  opts.keyComparator,
+	metaData, err := readMetaDataIfExists(filepath.Join(opts.basePath, opts.metaFileName))
+	if err != nil {
+		return nil, fmt.Errorf("error while reading metadata of sstable in '%s': %w", opts.basePath, err)
+	}
+
+	if metaData.Version > Version {
+		return nil, fmt.Errorf("error while opening sstable in '%s': unsupported version %d, max supported %d",
+			opts.basePath, metaData.Version, Version)
+	}
+
+	if metaData.ValueChecksumsDisabled {
+		// all checksums in the index are zero, there's nothing to verify the values against
+		opts.skipHashCheckOnRead = true
+		opts.verifyChecksumsOnScan = false
+	} else if metaData.ValueChecksumName != opts.checksumName && metaData.ValueChecksumName != "" {
+		return nil, fmt.Errorf("error while opening sstable in '%s', its values were checksummed with '%s': %w",
+			opts.basePath, metaData.ValueChecksumName, ErrChecksumFuncMismatch)
+	}
+	// tables without a name use the default, even when the reader was supplied another function
+	if metaData.ValueChecksumName == "" || opts.newChecksum == nil {
+		opts.newChecksum = newCRC64ISO
+	}
+
+	if !opts.ignoreComparatorCheck {
+		name := comparatorName(opts.keyComparator, opts.comparatorName)
+		// tables written by older versions or with unnamed comparators can't be verified
+		if name != "" && metaData.ComparatorName != "" && name != metaData.ComparatorName {
+			return nil, fmt.Errorf("error while opening sstable in '%s', it was written with comparator '%s' but is read with '%s': %w",
+				opts.basePath, metaData.ComparatorName, name, ErrComparatorMismatch)
+		}
+	}
+
+	if !opts.allowUncommitted {
+		committed, err := isCommitted(opts, metaData)
+		if err != nil {
+			return nil, fmt.Errorf("error while checking commit marker of sstable in '%s': %w", opts.basePath, err)
+		}
+		if !committed {
+			return nil, fmt.Errorf("error while opening sstable in '%s': %w", opts.basePath, ErrTableNotCommitted)
+		}
+	}
+
+	if opts.dataFile != nil && metaData.Version == 0 {
+		return nil, fmt.Errorf("error while opening sstable in '%s', tables of version 0 can't be read from a supplied data file", opts.basePath)
+	}
+
+	if opts.indexLoaderName != "" && opts.indexLoader != nil {
+		return nil, errors.New("SSTableReader: ReadIndexLoader and ReadIndexLoaderName can't be combined")
+	}
+
+	if metaData.ScanOnly {
+		// the index file is empty, any configured loader would fail to find the keys
+		opts.indexLoader = scanOnlyIndexLoader{}
+	} else if opts.indexLoaderName != "" {
+		opts.indexLoader, err = newNamedIndexLoader(opts.indexLoaderName, metaData, IndexLoaderOptions{
+			BasePath:       opts.basePath,
 			ReadBufferSize: opts.readBufferSizeBytes,
+			KeyComparator:  opts.keyComparator,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error while opening sstable in '%s': %w", opts.basePath, err)
+		}
+	} else if opts.indexLoader == nil {
+		if metaData.Versioned {
+			// the skip list can't hold multiple versions of the same key
+			opts.indexLoader = &SliceKeyIndexLoader{ReadBufferSize: opts.readBufferSizeBytes}
+		} else {
+			opts.indexLoader = &SkipListIndexLoader{
+				KeyComparator:  opts.keyComparator,
+				ReadBufferSize: opts.readBufferSizeBytes,
+			}
 		}
 	}
 
-	metaData, err := readMetaDataIfExists(filepath.Join(opts.basePath, MetaFileName))
-	if err != nil {
-		return nil, fmt.Errorf("error while reading metadata of sstable in '%s': %w", opts.basePath, err)
+	if metaData.Versioned {
+		switch opts.indexLoader.(type) {
+		case *SliceKeyIndexLoader, *ArenaKeyIndexLoader, *DiskIndexLoader, scanOnlyIndexLoader:
+		default:
+			return nil, fmt.Errorf("sstable in '%s' is versioned, index loader %T does not support multiple versions of a key",
+				opts.basePath, opts.indexLoader)
+		}
 	}
 
-	index, err := opts.indexLoader.Load(filepath.Join(opts.basePath, IndexFileName), metaData)
+	if opts.maxIndexMemoryBytes > 0 {
+		estimate, ok := EstimateIndexMemoryBytes(opts.indexLoader, metaData)
+		if ok && estimate > opts.maxIndexMemoryBytes {
+			return nil, fmt.Errorf("error while loading index of sstable in '%s': %w", opts.basePath,
+				IndexMemoryLimitError{EstimatedBytes: estimate, LimitBytes: opts.maxIndexMemoryBytes})
+		}
+	}
+
+	index, err := opts.indexLoader.Load(filepath.Join(opts.basePath, opts.indexFileName), metaData)
 	if err != nil {
 		return nil, fmt.Errorf("error while reading index of sstable in '%s': %w", opts.basePath, err)
 	}
@@ -294,37 +1150,57 @@ func NewSSTableReader(readerOptions ...ReadOption) (SSTableReaderI, error) {
 		return nil, fmt.Errorf("error while opening index of sstable in '%s': %w", opts.basePath, err)
 	}
 
-	filter, err := readFilterIfExists(filepath.Join(opts.basePath, BloomFileName))
-	if err != nil {
-		return nil, fmt.Errorf("error while reading filter of sstable in '%s': %w", opts.basePath, err)
-	}
-
-	reader := &SSTableReader{opts: opts, bloomFilter: filter, index: index, metaData: metaData}
-
-	if metaData.Version == 0 {
-		v0DataReader, err := rProto.NewMMapProtoReaderWithPath(filepath.Join(opts.basePath, DataFileName))
-		if err != nil {
-			return nil, fmt.Errorf("error while creating proto data reader of sstable in '%s': %w", opts.basePath, err)
+	// the summary only narrows down binary searches on disk, in-memory indices don't need it
+	if diskIndex, ok := index.(*DiskKeyIndex); ok {
+		summary, err := readSummaryIfExists(filepath.Join(opts.basePath, opts.summaryFileName))
+		if err != nil && opts.toleratePartialFiles {
+			// the summary is optional, without it the whole index is searched
+			opts.logger.Warn("sstable summary unreadable, searching the whole index", "path", opts.basePath, "error", err)
+			summary, err = nil, nil
 		}
-
-		err = v0DataReader.Open()
 		if err != nil {
-			return nil, fmt.Errorf("error while opening proto data reader of sstable in '%s': %w", opts.basePath, err)
+			return nil, errors.Join(fmt.Errorf("error while reading summary of sstable in '%s': %w", opts.basePath, err), index.Close())
 		}
+		diskIndex.summary = summary
+	}
 
-		reader.v0DataReader = v0DataReader
-	} else {
-		dataReader, err := recordio.NewMemoryMappedReaderWithPath(filepath.Join(opts.basePath, DataFileName))
+	if opts.validateKeyRange && !metaData.ScanOnly {
+		err = validateKeyRange(index, metaData, opts.keyComparator)
 		if err != nil {
-			return nil, fmt.Errorf("error while creating data reader of sstable in '%s': %w", opts.basePath, err)
+			return nil, errors.Join(fmt.Errorf("error while validating key range of sstable in '%s': %w", opts.basePath, err), index.Close())
 		}
+	}
 
-		err = dataReader.Open()
+	filter, err := readFilterIfExists(filepath.Join(opts.basePath, opts.bloomFileName))
+	if err != nil && opts.toleratePartialFiles {
+		// without a filter all lookups go to the index
+		opts.logger.Warn("sstable bloom filter unreadable, lookups go to the index", "path", opts.basePath, "error", err)
+		filter, err = nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error while reading filter of sstable in '%s': %w", opts.basePath, err)
+	}
+
+	var partitions []bloomPartition
+	if metaData.BloomPartitions > 0 {
+		partitions, err = readBloomPartitionsIfExists(filepath.Join(opts.basePath, opts.bloomPartitionsFileName))
+		if err != nil && opts.toleratePartialFiles {
+			opts.logger.Warn("sstable bloom partitions unreadable, lookups go to the index", "path", opts.basePath, "error", err)
+			partitions, err = nil, nil
+		}
 		if err != nil {
-			return nil, fmt.Errorf("error while opening data reader of sstable in '%s': %w", opts.basePath, err)
+			return nil, fmt.Errorf("error while reading bloom partitions of sstable in '%s': %w", opts.basePath, err)
 		}
+	}
 
-		reader.dataReader = dataReader
+	reader := &SSTableReader{opts: opts, bloomFilter: filter, bloomPartitions: partitions, index: index, metaData: metaData}
+	if opts.negativeCacheSize > 0 {
+		reader.negativeCache = newNegativeCache(opts.negativeCacheSize)
+	}
+
+	err = reader.openDataFile()
+	if err != nil {
+		return nil, err
 	}
 
 	err = reader.validateDataFile()
@@ -338,6 +1214,8 @@ func NewSSTableReader(readerOptions ...ReadOption) (SSTableReaderI, error) {
 		return nil, err
 	}
 
+	opts.logger.Info("sstable opened", "path", opts.basePath, "records", metaData.NumRecords,
+		"indexBytes", metaData.IndexBytes, "loadTime", time.Since(start))
 	return reader, nil
 }
 
@@ -354,6 +1232,95 @@ func readFilterIfExists(filterPath string) (*bloomfilter.Filter, error) {
 	return filter, nil
 }
 
+func readSummaryIfExists(summaryPath string) (summary []*proto.SummaryEntry, err error) {
+	if _, err := os.Stat(summaryPath); os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	reader, err := rProto.NewProtoReaderWithPath(summaryPath)
+	if err != nil {
+		return nil, fmt.Errorf("error while creating summary reader in '%s': %w", summaryPath, err)
+	}
+
+	err = reader.Open()
+	if err != nil {
+		return nil, fmt.Errorf("error while opening summary in '%s': %w", summaryPath, err)
+	}
+
+	defer func() {
+		err = errors.Join(err, reader.Close())
+	}()
+
+	for {
+		entry := &proto.SummaryEntry{}
+		_, err := reader.ReadNext(entry)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, fmt.Errorf("error while reading summary in '%s': %w", summaryPath, err)
+		}
+		summary = append(summary, entry)
+	}
+
+	return summary, nil
+}
+
+// isCommitted returns true when the table contains the commit marker. Tables written before the marker was
+// introduced are committed when they have complete metadata of Version 1, or no metadata file at all with Version 0.
+// The metadata file is empty while a table is still written.
+func isCommitted(opts *SSTableReaderOptions, metaData *proto.MetaData) (bool, error) {
+	_, err := os.Stat(filepath.Join(opts.basePath, opts.committedFileName))
+	if err == nil {
+		return true, nil
+	}
+	if !os.IsNotExist(err) {
+		return false, err
+	}
+
+	switch metaData.Version {
+	case 0:
+		stat, err := os.Stat(filepath.Join(opts.basePath, opts.metaFileName))
+		if os.IsNotExist(err) {
+			return true, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		return stat.Size() > 0, nil
+	case 1:
+		return true, nil
+	default:
+		return false, nil
+	}
+}
+
+// ReadMetaData only reads the metadata of the table in ReadBasePath, without loading its index, bloom filter or data
+// file. This is the cheap way to get the key range of many tables for routing, see FirstKey and LastKey for a fallback
+// on tables without a key range in their metadata. ReadMetaFileName is respected, all other options are ignored.
+// Tables without a metadata file return an error that matches os.ErrNotExist.
+func ReadMetaData(readerOptions ...ReadOption) (*proto.MetaData, error) {
+	opts := &SSTableReaderOptions{metaFileName: MetaFileName}
+	for _, readOption := range readerOptions {
+		readOption(opts)
+	}
+
+	if opts.basePath == "" {
+		return nil, errors.New("ReadMetaData: basePath was not supplied")
+	}
+
+	metaPath := filepath.Join(opts.basePath, opts.metaFileName)
+	if _, err := os.Stat(metaPath); err != nil {
+		return nil, fmt.Errorf("error while reading metadata of sstable in '%s': %w", opts.basePath, err)
+	}
+
+	metaData, err := readMetaDataIfExists(metaPath)
+	if err != nil {
+		return nil, fmt.Errorf("error while reading metadata of sstable in '%s': %w", opts.basePath, err)
+	}
+	return metaData, nil
+}
+
 func readMetaDataIfExists(metaPath string) (md *proto.MetaData, err error) {
 	md = &proto.MetaData{}
 
@@ -375,7 +1342,7 @@ func readMetaDataIfExists(metaPath string) (md *proto.MetaData, err error) {
 		return nil, fmt.Errorf("error while reading metadata in '%s': %w", metaPath, err)
 	}
 
-	err = pb.Unmarshal(content, md)
+	err = unmarshalMetaData(content, md)
 	if err != nil {
 		return nil, fmt.Errorf("error while parsing metadata in '%s': %w", metaPath, err)
 	}
@@ -390,12 +1357,60 @@ type SSTableReaderOptions struct {
 	basePath            string
 	readBufferSizeBytes int
 	indexLoader         IndexLoader
+	// indexLoaderName selects a loader of RegisterIndexLoader instead of indexLoader
+	indexLoaderName string
 
 	// TODO(thomas): this is a special case of the skiplist index, which could go into the loader implementation
-	keyComparator skiplist.Comparator[[]byte]
+	keyComparator         skiplist.Comparator[[]byte]
+	comparatorName        string
+	ignoreComparatorCheck bool
 
 	skipHashCheckOnLoad bool
 	skipHashCheckOnRead bool
+	// checksumMismatchPolicy decides whether reads fail on corrupt values or treat them as missing
+	checksumMismatchPolicy ChecksumMismatchPolicy
+	// verifyChecksumsOnScan forces checks during scans, independent of skipHashCheckOnRead
+	verifyChecksumsOnScan bool
+	// checksumName and newChecksum are supplied with ReadWithChecksumFunc, newChecksum is the function of the table after opening
+	checksumName string
+	newChecksum  func() hash.Hash64
+	// validateKeyRange compares the key range of the index with the metadata on open
+	validateKeyRange bool
+	// toleratePartialFiles ignores unreadable optional files
+	toleratePartialFiles bool
+	// readAsOfSeq hides all records with a sequence number greater than asOfSeq
+	readAsOfSeq bool
+	asOfSeq     uint64
+	// readAheadBytes is only used by Scan, which reads the data file sequentially
+	readAheadBytes       int
+	adviseSequentialScan bool
+	maxIndexMemoryBytes  uint64
+	// reuseScanBuffers returns values of scans that are only valid until the next call to Next
+	reuseScanBuffers bool
+	// bufferPool provides the scratch buffers for random reads of the data file, nil uses a pool per reader
+	bufferPool recordio.BufferPool
+	// negativeCacheSize is the number of absent keys that are remembered, zero disables the cache
+	negativeCacheSize int
+	// bloomKeyTransform must be the transform the table was written with, see BloomKeyTransform
+	bloomKeyTransform func(key []byte) []byte
+	// dataFile is owned by the caller and never closed, see ReadDataFile
+	dataFile *os.File
+	// rateLimiter throttles Scan, see ReadWithRateLimiter
+	rateLimiter    RateLimiter
+	rateLimiterCtx context.Context
+	logger         Logger
+	// tracer is nil without ReadWithTracer
+	tracer Tracer
+
+	indexFileName           string
+	dataFileName            string
+	bloomFileName           string
+	bloomPartitionsFileName string
+	metaFileName            string
+	summaryFileName         string
+
+	committedFileName string
+	allowUncommitted  bool
 }
 
 type ReadOption func(*SSTableReaderOptions)
@@ -413,6 +1428,84 @@ func ReadWithKeyComparator(cmp skiplist.Comparator[[]byte]) ReadOption {
 	}
 }
 
+// ReadWithNegativeCache remembers up to size keys that were looked up but don't exist in the table, so repeated
+// lookups of absent keys, for example bloom filter false positives, don't search the index again. The least recently
+// used keys are evicted first. As tables are immutable, the cache never needs to be invalidated. It applies to Get,
+// GetInto, GetWithChecksum and Contains, see Stats for the hit counts. Zero disables the cache, which is the default.
+func ReadWithNegativeCache(size int) ReadOption {
+	return func(args *SSTableReaderOptions) {
+		args.negativeCacheSize = size
+	}
+}
+
+// ReadDataFile reads the values from the given already opened data file instead of memory mapping the data file at the
+// base path, for example to pool the file descriptors of tables that are opened by many short-lived readers. The file is
+// owned by the caller: Close releases the buffers of the reader but never closes the file, which must stay open until
+// all readers and their clones are closed. It's read with pread, so it can be shared by concurrent readers, and Scan
+// reads the values with random access as well.
+// The other files are still owned by the reader. The metadata, bloom filter and in-memory indices are read from the
+// base path while opening, a DiskIndexLoader keeps the index file open until Close. Tables written with ScanOnly are
+// validated and scanned from the data file at the base path. Tables of version 0 are not supported.
+func ReadDataFile(file *os.File) ReadOption {
+	return func(args *SSTableReaderOptions) {
+		args.dataFile = file
+	}
+}
+
+// ReadWithRateLimiter limits the bytes per second that Scan reads from the data file, for example to keep a scrub or a
+// compaction from saturating the disk. The bytes are counted as stored in the data file, before the decompression.
+// When ctx is done while waiting, Next returns its error. Get and the other point lookups are exempt, as well as the
+// Scan* functions that read the values with random access. A nil context defaults to context.Background.
+func ReadWithRateLimiter(ctx context.Context, limiter RateLimiter) ReadOption {
+	return func(args *SSTableReaderOptions) {
+		args.rateLimiter = limiter
+		args.rateLimiterCtx = ctx
+	}
+}
+
+// ReadWithLogger logs when the table was opened, with its path, number of records, index bytes and load time, and
+// warns about checksum mismatches and optional files that were skipped with ReadToleratePartialFiles. Clones log to
+// the same logger. By default, nothing is logged.
+func ReadWithLogger(logger Logger) ReadOption {
+	return func(args *SSTableReaderOptions) {
+		args.logger = logger
+	}
+}
+
+// ReadWithTracer starts a span around opening the table, including loading its index, every Get and every Scan. The
+// spans carry the base path, the number of records and the bytes read, see Tracer. Clones trace to the same tracer.
+func ReadWithTracer(tracer Tracer) ReadOption {
+	return func(args *SSTableReaderOptions) {
+		args.tracer = tracer
+	}
+}
+
+// ReadBloomKeyTransform supplies the transform a table was written with using BloomKeyTransform, so Get, Contains and the
+// other point lookups can still use the bloom filter to rule out keys. Without it, tables with transformed bloom keys
+// skip the bloom filter for point lookups, while MightContain works with the transformed keys either way. The
+// transform is ignored for tables that were written without one.
+func ReadBloomKeyTransform(transform func(key []byte) []byte) ReadOption {
+	return func(args *SSTableReaderOptions) {
+		args.bloomKeyTransform = transform
+	}
+}
+
+// ReadWithComparatorName sets the name that is verified against the comparator name recorded by the writer, defaults to
+// the name of a NamedComparator. Tables without a recorded name and unnamed comparators are not verified.
+func ReadWithComparatorName(name string) ReadOption {
+	return func(args *SSTableReaderOptions) {
+		args.comparatorName = name
+	}
+}
+
+// ReadIgnoreComparatorCheck opens tables that were written with a different comparator than the reader's, which
+// otherwise fails with ErrComparatorMismatch. Lookups on such tables can silently return wrong results.
+func ReadIgnoreComparatorCheck() ReadOption {
+	return func(args *SSTableReaderOptions) {
+		args.ignoreComparatorCheck = true
+	}
+}
+
 // SkipHashCheckOnLoad will not check hashes against data read from the datafile when loading.
 func SkipHashCheckOnLoad() ReadOption {
 	return func(args *SSTableReaderOptions) {
@@ -428,6 +1521,129 @@ func EnableHashCheckOnReads() ReadOption {
 	}
 }
 
+// VerifyChecksumsOnScan checks the data integrity of every value returned by Scan, ScanStartingAt and ScanRange,
+// independent of EnableHashCheckOnReads, which keeps Get unaffected. This is useful for periodic integrity sweeps.
+// A mismatch is returned as a ScanChecksumError along with the key and value, the scan can continue with the next key.
+func VerifyChecksumsOnScan() ReadOption {
+	return func(args *SSTableReaderOptions) {
+		args.verifyChecksumsOnScan = true
+	}
+}
+
+// ChecksumMismatchPolicy decides what reads do with values that don't match their checksum, see ReadOnChecksumMismatch.
+type ChecksumMismatchPolicy struct {
+	treatAsMissing bool
+	callback       func(key []byte, err error)
+}
+
+var (
+	// ChecksumMismatchError returns an error wrapping ChecksumError, which is the default.
+	ChecksumMismatchError = ChecksumMismatchPolicy{}
+	// ChecksumMismatchTreatAsMissing treats corrupt records as if they weren't in the table: Get returns ErrKeyNotFound
+	// and the scans skip them.
+	ChecksumMismatchTreatAsMissing = ChecksumMismatchPolicy{treatAsMissing: true}
+)
+
+// ChecksumMismatchCallback treats corrupt records as missing like ChecksumMismatchTreatAsMissing, but calls the
+// callback with the key and the ChecksumError of every skipped record first, for example to log or repair them.
+func ChecksumMismatchCallback(callback func(key []byte, err error)) ChecksumMismatchPolicy {
+	return ChecksumMismatchPolicy{treatAsMissing: true, callback: callback}
+}
+
+// ReadOnChecksumMismatch sets the policy for values that don't match their checksum in Get, GetInto, GetBatch and all
+// scans, for example to treat isolated corruption in a best-effort cache as misses. It only applies when the values
+// are verified at all, which needs EnableHashCheckOnReads or VerifyChecksumsOnScan. Defaults to ChecksumMismatchError.
+func ReadOnChecksumMismatch(policy ChecksumMismatchPolicy) ReadOption {
+	return func(args *SSTableReaderOptions) {
+		args.checksumMismatchPolicy = policy
+	}
+}
+
+// ReadWithChecksumFunc supplies the function of WithChecksumFunc for tables whose values were checksummed with it under
+// the given name. Opening such a table without the function fails with ErrChecksumFuncMismatch, tables written with
+// the default CRC-64 are still read with the default.
+func ReadWithChecksumFunc(name string, newHash func() hash.Hash64) ReadOption {
+	return func(args *SSTableReaderOptions) {
+		args.checksumName = name
+		args.newChecksum = newHash
+	}
+}
+
+// ReadValidateKeyRange checks on open that the first and the last key of the index match the MinKey and MaxKey of the
+// metadata, which catches truncated indices and corrupted metadata early. A mismatch fails with ErrKeyRangeMismatch.
+// This only costs two lookups in the index, tables written with ScanOnly have no index and aren't checked.
+func ReadValidateKeyRange() ReadOption {
+	return func(args *SSTableReaderOptions) {
+		args.validateKeyRange = true
+	}
+}
+
+// ReadToleratePartialFiles opens tables whose optional files are damaged, for example after an interrupted copy.
+// A truncated or corrupt bloom filter or summary file is ignored, lookups then always consult the index. Missing optional
+// files are always tolerated, a missing or damaged data or index file is still an error.
+func ReadToleratePartialFiles() ReadOption {
+	return func(args *SSTableReaderOptions) {
+		args.toleratePartialFiles = true
+	}
+}
+
+// ReadAsOfSeq reads the table as of the given sequence number: Get, Contains and the scans only return the newest version
+// of each key whose sequence number is lower or equal to seq, later writes are ignored. This allows point-in-time reads
+// of tables written with WithVersioning, records written without a sequence number are always visible.
+func ReadAsOfSeq(seq uint64) ReadOption {
+	return func(args *SSTableReaderOptions) {
+		args.readAsOfSeq = true
+		args.asOfSeq = seq
+	}
+}
+
+// ReadAhead prefetches up to the given number of bytes of the data file in the background during Scan, so that the
+// decompression of values isn't blocked on IO. Get and the other scans use random access and are not affected.
+// Disabled by default with zero.
+func ReadAhead(bytes int) ReadOption {
+	return func(args *SSTableReaderOptions) {
+		args.readAheadBytes = bytes
+	}
+}
+
+// ReadAdviseSequentialScan advises the kernel with posix_fadvise(POSIX_FADV_SEQUENTIAL) that Scan reads the data file
+// from start to end, which increases its read-ahead. Get and the other scans read through a memory mapping where
+// posix_fadvise has no effect, which is why there is no equivalent for random access.
+// This is a no-op on operating systems other than Linux.
+func ReadAdviseSequentialScan() ReadOption {
+	return func(args *SSTableReaderOptions) {
+		args.adviseSequentialScan = true
+	}
+}
+
+// ReadMaxIndexMemoryBytes limits the memory the index may use. Before loading, the memory of the index is estimated
+// from the metadata with EstimateIndexMemoryBytes and NewSSTableReader returns an IndexMemoryLimitError when it exceeds
+// the limit, the DiskIndexLoader can be used instead for such tables. Loaders that can't estimate their memory usage are
+// not limited. Disabled by default with zero.
+func ReadMaxIndexMemoryBytes(n uint64) ReadOption {
+	return func(args *SSTableReaderOptions) {
+		args.maxIndexMemoryBytes = n
+	}
+}
+
+// ReadReuseScanBuffers makes Scan, ScanStartingAt and ScanRange read every value into a buffer that is reused by the
+// iterator, which avoids allocating a new value per record. The key and value returned by Next are then only valid until
+// the next call to Next, callers that retain them have to copy them. Tables that are versioned or read with ReadAsOfSeq
+// still allocate, since filtering the versions needs to read ahead.
+func ReadReuseScanBuffers() ReadOption {
+	return func(args *SSTableReaderOptions) {
+		args.reuseScanBuffers = true
+	}
+}
+
+// ReadWithBufferPool sets the pool for the scratch buffers that Get, GetInto and the range scans use to read and
+// decompress values from the data file. A pool can be shared across readers, clones always share the pool.
+func ReadWithBufferPool(pool recordio.BufferPool) ReadOption {
+	return func(args *SSTableReaderOptions) {
+		args.bufferPool = pool
+	}
+}
+
 func ReadBufferSizeBytes(size int) ReadOption {
 	return func(args *SSTableReaderOptions) {
 		args.readBufferSizeBytes = size
@@ -440,3 +1656,69 @@ func ReadIndexLoader(il IndexLoader) ReadOption {
 		args.indexLoader = il
 	}
 }
+
+// ReadIndexLoaderName selects the loader that was registered under the given name with RegisterIndexLoader, for example
+// "disk" for the DiskIndexLoader. It can't be combined with ReadIndexLoader.
+func ReadIndexLoaderName(name string) ReadOption {
+	return func(args *SSTableReaderOptions) {
+		args.indexLoaderName = name
+	}
+}
+
+// ReadIndexFileName overrides the name of the index file, must match the name given by WithIndexFileName.
+func ReadIndexFileName(name string) ReadOption {
+	return func(args *SSTableReaderOptions) {
+		args.indexFileName = name
+	}
+}
+
+// ReadDataFileName overrides the name of the data file, must match the name given by WithDataFileName.
+func ReadDataFileName(name string) ReadOption {
+	return func(args *SSTableReaderOptions) {
+		args.dataFileName = name
+	}
+}
+
+// ReadBloomFileName overrides the name of the bloom filter file, must match the name given by WithBloomFileName.
+func ReadBloomFileName(name string) ReadOption {
+	return func(args *SSTableReaderOptions) {
+		args.bloomFileName = name
+	}
+}
+
+// ReadBloomPartitionsFileName overrides the name of the partitioned bloom filter file, must match the name given by
+// WithBloomPartitionsFileName.
+func ReadBloomPartitionsFileName(name string) ReadOption {
+	return func(args *SSTableReaderOptions) {
+		args.bloomPartitionsFileName = name
+	}
+}
+
+// ReadCommittedFileName overrides the name of the commit marker file, must match the name given by WithCommittedFileName.
+func ReadCommittedFileName(name string) ReadOption {
+	return func(args *SSTableReaderOptions) {
+		args.committedFileName = name
+	}
+}
+
+// ReadSummaryFileName overrides the name of the summary file, must match the name given by WithSummaryFileName.
+func ReadSummaryFileName(name string) ReadOption {
+	return func(args *SSTableReaderOptions) {
+		args.summaryFileName = name
+	}
+}
+
+// ReadUncommitted opens tables without the commit marker, which are still being written or whose writer
+// failed. By default, NewSSTableReader returns ErrTableNotCommitted for them.
+func ReadUncommitted() ReadOption {
+	return func(args *SSTableReaderOptions) {
+		args.allowUncommitted = true
+	}
+}
+
+// ReadMetaFileName overrides the name of the metadata file, must match the name given by WithMetaFileName.
+func ReadMetaFileName(name string) ReadOption {
+	return func(args *SSTableReaderOptions) {
+		args.metaFileName = name
+	}
+}
@@ -1,10 +1,23 @@
 package sstables
 
 import (
+	"bytes"
+	"context"
+	"encoding/binary"
 	"errors"
+	"fmt"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/thomasjungblut/go-sstables/recordio"
 	"github.com/thomasjungblut/go-sstables/skiplist"
+	"github.com/thomasjungblut/go-sstables/sstables/proto"
+	pb "google.golang.org/protobuf/proto"
+	"hash/crc64"
+	"io"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sync"
 	"testing"
 )
 
@@ -182,6 +195,354 @@ func TestCRCHashEmptyValues(t *testing.T) {
 	require.Equal(t, []byte{0, 0, 0, 0}, get)
 }
 
+func TestIndexChecksumTruncatedIndex(t *testing.T) {
+	writer, err := newTestSSTableStreamWriter()
+	require.NoError(t, err)
+	defer cleanWriterDir(t, writer)
+	streamedWriteAscendingIntegers(t, writer, 10)
+
+	shortWriter, err := newTestSSTableStreamWriter()
+	require.NoError(t, err)
+	defer cleanWriterDir(t, shortWriter)
+	streamedWriteAscendingIntegers(t, shortWriter, 9)
+
+	reader, err := NewSSTableReader(ReadBasePath(writer.opts.basePath))
+	require.NoError(t, err)
+	assert.NotZero(t, reader.MetaData().IndexChecksum)
+	closeReader(t, reader)
+
+	// the shorter index still points to valid data, only the index checksum can detect the missing entry
+	shortIndex, err := os.ReadFile(filepath.Join(shortWriter.opts.basePath, IndexFileName))
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(writer.opts.basePath, IndexFileName), shortIndex, 0666))
+
+	_, err = NewSSTableReader(ReadBasePath(writer.opts.basePath))
+	require.ErrorIs(t, err, ChecksumError{})
+	require.ErrorContains(t, err, "index checksum mismatch")
+
+	reader, err = NewSSTableReader(ReadBasePath(writer.opts.basePath), SkipHashCheckOnLoad())
+	require.NoError(t, err)
+	closeReader(t, reader)
+}
+
+func TestVerifyChecksumsOnScan(t *testing.T) {
+	reader, err := NewSSTableReader(
+		ReadBasePath("test_files/SimpleWriteHappyPathSSTableWithCRCHashesMismatch"),
+		SkipHashCheckOnLoad(),
+		VerifyChecksumsOnScan())
+	require.Nil(t, err)
+	defer closeReader(t, reader)
+
+	// Get is not affected by the scan verification
+	_, err = reader.Get(intToByteSlice(4))
+	require.Nil(t, err)
+
+	scanFull, err := reader.Scan()
+	require.Nil(t, err)
+	scanFrom, err := reader.ScanStartingAt([]byte{})
+	require.Nil(t, err)
+	for _, it := range []SSTableIteratorI{scanFull, scanFrom} {
+		var keys []int
+		for {
+			k, _, err := it.Next()
+			if errors.Is(err, Done) {
+				break
+			}
+			if err != nil {
+				var scanErr ScanChecksumError
+				require.ErrorAs(t, err, &scanErr)
+				assert.ErrorIs(t, err, ChecksumError{})
+				assert.Equal(t, intToByteSlice(4), scanErr.Key)
+				assert.Equal(t, uint64(41), scanErr.Offset)
+				assert.Equal(t, k, scanErr.Key)
+				assert.Contains(t, err.Error(), "at data offset 41")
+				continue
+			}
+			keys = append(keys, int(binary.BigEndian.Uint32(k)))
+		}
+		assert.Equal(t, []int{1, 2, 3, 5, 6, 7}, keys)
+	}
+}
+
+func TestReadOnChecksumMismatch(t *testing.T) {
+	var callbackKeys [][]byte
+	callback := ChecksumMismatchCallback(func(key []byte, err error) {
+		assert.ErrorIs(t, err, ChecksumError{})
+		callbackKeys = append(callbackKeys, key)
+	})
+
+	for _, policy := range []ChecksumMismatchPolicy{ChecksumMismatchTreatAsMissing, callback} {
+		callbackKeys = nil
+		r, err := NewSSTableReader(
+			ReadBasePath("test_files/SimpleWriteHappyPathSSTableWithCRCHashesMismatch"),
+			SkipHashCheckOnLoad(),
+			EnableHashCheckOnReads(),
+			ReadOnChecksumMismatch(policy))
+		require.NoError(t, err)
+		reader := r.(*SSTableReader)
+
+		for _, i := range []int{1, 2, 3, 5, 6, 7} {
+			v, err := reader.Get(intToByteSlice(i))
+			require.NoError(t, err)
+			assert.Equal(t, intToByteSlice(i+1), v)
+		}
+		_, err = reader.Get(intToByteSlice(4))
+		assert.ErrorIs(t, err, ErrKeyNotFound)
+		_, err = reader.GetInto(intToByteSlice(4), nil)
+		assert.ErrorIs(t, err, ErrKeyNotFound)
+		v, err := reader.GetOrDefault(intToByteSlice(4), []byte{42})
+		require.NoError(t, err)
+		assert.Equal(t, []byte{42}, v)
+
+		results, err := reader.GetBatch([][]byte{intToByteSlice(3), intToByteSlice(4), intToByteSlice(5)})
+		require.NoError(t, err)
+		assert.Equal(t, []BatchResult{{Value: intToByteSlice(4), Found: true}, {}, {Value: intToByteSlice(6), Found: true}}, results)
+
+		scanFull, err := reader.Scan()
+		require.NoError(t, err)
+		scanFrom, err := reader.ScanStartingAt([]byte{})
+		require.NoError(t, err)
+		for _, it := range []SSTableIteratorI{scanFull, scanFrom} {
+			var keys []int
+			for {
+				k, _, err := it.Next()
+				if errors.Is(err, Done) {
+					break
+				}
+				require.NoError(t, err)
+				keys = append(keys, int(binary.BigEndian.Uint32(k)))
+			}
+			assert.Equal(t, []int{1, 2, 3, 5, 6, 7}, keys)
+		}
+
+		if policy.callback != nil {
+			// Get, GetInto, GetOrDefault, GetBatch and both scans
+			assert.Equal(t, 6, len(callbackKeys))
+			for _, key := range callbackKeys {
+				assert.Equal(t, intToByteSlice(4), key)
+			}
+		}
+		closeReader(t, reader)
+	}
+}
+
+func TestGetWithChecksum(t *testing.T) {
+	r, err := NewSSTableReader(
+		ReadBasePath("test_files/SimpleWriteHappyPathSSTableWithCRCHashesMismatch"),
+		SkipHashCheckOnLoad())
+	require.Nil(t, err)
+	defer closeReader(t, r)
+	reader := r.(*SSTableReader)
+
+	// the checksum is returned as recorded, even for the corrupted value
+	for _, i := range []int{1, 2, 3, 4, 5, 6, 7} {
+		key := intToByteSlice(i)
+		v, checksum, err := reader.GetWithChecksum(key)
+		require.Nil(t, err)
+		expected, err := reader.index.Get(key)
+		require.Nil(t, err)
+		require.Equal(t, expected.Checksum, checksum)
+		if i != 4 {
+			require.Equal(t, crc64.Checksum(v, crc64.MakeTable(crc64.ISO)), checksum)
+		} else {
+			require.NotEqual(t, crc64.Checksum(v, crc64.MakeTable(crc64.ISO)), checksum)
+		}
+	}
+
+	_, _, err = reader.GetWithChecksum([]byte{1, 2, 3})
+	require.Equal(t, NotFound, err)
+}
+
+func TestGetStreaming(t *testing.T) {
+	for _, path := range []string{"test_files/SimpleWriteHappyPathSSTableWithCRCHashes", "test_files/SimpleWriteHappyPathSSTableWithBloom"} {
+		r, err := NewSSTableReader(ReadBasePath(path))
+		require.Nil(t, err)
+		reader := r.(*SSTableReader)
+
+		for _, i := range []int{1, 2, 3, 4, 5, 6, 7} {
+			expected, err := reader.Get(intToByteSlice(i))
+			require.Nil(t, err)
+			value, err := reader.GetStreaming(intToByteSlice(i))
+			require.Nil(t, err)
+			actual, err := io.ReadAll(value)
+			require.Nil(t, err)
+			assert.Equal(t, expected, actual)
+			require.Nil(t, value.Close())
+		}
+
+		_, err = reader.GetStreaming(intToByteSlice(42))
+		assert.Equal(t, ErrKeyNotFound, err)
+		closeReader(t, reader)
+	}
+}
+
+func TestGetStreamingChecksumMismatch(t *testing.T) {
+	r, err := NewSSTableReader(
+		ReadBasePath("test_files/SimpleWriteHappyPathSSTableWithCRCHashesMismatch"),
+		SkipHashCheckOnLoad())
+	require.Nil(t, err)
+	defer closeReader(t, r)
+	reader := r.(*SSTableReader)
+
+	value, err := reader.GetStreaming(intToByteSlice(3))
+	require.Nil(t, err)
+	_, err = io.ReadAll(value)
+	require.Nil(t, err)
+
+	// the mismatch is only detected once the whole value was read
+	value, err = reader.GetStreaming(intToByteSlice(4))
+	require.Nil(t, err)
+	_, err = io.ReadAll(value)
+	assert.ErrorIs(t, err, ChecksumError{})
+	assert.ErrorContains(t, err, "offset [41]")
+	require.Nil(t, value.Close())
+	_, err = value.Read(make([]byte, 1))
+	assert.ErrorContains(t, err, "value was already closed")
+}
+
+func TestGetStreamingCompressedChecksumMismatch(t *testing.T) {
+	writer, err := newTestSSTableStreamWriterWithDataCompression(recordio.CompressionTypeSnappy)
+	require.NoError(t, err)
+	defer cleanWriterDir(t, writer)
+	require.NoError(t, writer.Open())
+	value := make([]byte, 256*1024)
+	_, err = rand.New(rand.NewSource(42)).Read(value)
+	require.NoError(t, err)
+	require.NoError(t, writer.WriteNext(intToByteSlice(1), value))
+	require.NoError(t, writer.Close())
+
+	// random bytes are stored as snappy literals, so flipping one of them still decompresses
+	dataPath := filepath.Join(writer.opts.basePath, DataFileName)
+	stat, err := os.Stat(dataPath)
+	require.NoError(t, err)
+	f, err := os.OpenFile(dataPath, os.O_RDWR, 0)
+	require.NoError(t, err)
+	_, err = f.WriteAt([]byte{0xFF, 0x00, 0xFF, 0x00}, stat.Size()/2)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	reader, err := NewSSTableReader(ReadBasePath(writer.opts.basePath), SkipHashCheckOnLoad())
+	require.NoError(t, err)
+	defer closeReader(t, reader)
+	streamed, err := reader.(*SSTableReader).GetStreaming(intToByteSlice(1))
+	require.NoError(t, err)
+	_, err = io.ReadAll(streamed)
+	assert.ErrorIs(t, err, ChecksumError{})
+}
+
+func TestGetOrDefault(t *testing.T) {
+	r, err := NewSSTableReader(
+		ReadBasePath("test_files/SimpleWriteHappyPathSSTableWithCRCHashesMismatch"),
+		SkipHashCheckOnLoad(),
+		EnableHashCheckOnReads())
+	require.Nil(t, err)
+	defer closeReader(t, r)
+	reader := r.(*SSTableReader)
+
+	def := []byte("default")
+	v, err := reader.GetOrDefault(intToByteSlice(3), def)
+	require.Nil(t, err)
+	assert.Equal(t, intToByteSlice(4), v)
+	v, err = reader.GetOrDefault(intToByteSlice(42), def)
+	require.Nil(t, err)
+	assert.Equal(t, def, v)
+
+	// genuine read errors are returned instead of the default
+	_, err = reader.GetOrDefault(intToByteSlice(4), def)
+	assert.ErrorIs(t, err, ChecksumError{})
+}
+
+func TestContainsDoesNotReadDataFile(t *testing.T) {
+	r, err := NewSSTableReader(ReadBasePath("test_files/SimpleWriteHappyPathSSTableWithBloom"))
+	require.Nil(t, err)
+	defer closeReader(t, r)
+	reader := r.(*SSTableReader)
+
+	// with a closed data file any read of a value fails
+	require.Nil(t, reader.dataReader.Close())
+	_, err = reader.Get(intToByteSlice(3))
+	require.Error(t, err)
+
+	ok, err := reader.Contains(intToByteSlice(3))
+	require.Nil(t, err)
+	assert.True(t, ok)
+	ok, err = reader.Contains(intToByteSlice(42))
+	require.Nil(t, err)
+	assert.False(t, ok)
+}
+
+func TestGetStreamingLargeValue(t *testing.T) {
+	for _, compType := range []int{recordio.CompressionTypeNone, recordio.CompressionTypeSnappy, recordio.CompressionTypeGZIP} {
+		writer, err := newTestSSTableStreamWriterWithDataCompression(compType)
+		require.NoError(t, err)
+		require.NoError(t, writer.Open())
+
+		require.NoError(t, writer.WriteNext(intToByteSlice(1), nil))
+		value, err := writer.WriteNextStreaming(intToByteSlice(2))
+		require.NoError(t, err)
+		chunk := make([]byte, 64*1024)
+		for i := 0; i < 32; i++ {
+			for j := range chunk {
+				chunk[j] = byte(i + j)
+			}
+			_, err = value.Write(chunk)
+			require.NoError(t, err)
+		}
+		require.NoError(t, value.Close())
+		require.NoError(t, writer.Close())
+
+		r, err := NewSSTableReader(ReadBasePath(writer.opts.basePath))
+		require.NoError(t, err)
+		reader := r.(*SSTableReader)
+
+		nullValue, err := reader.GetStreaming(intToByteSlice(1))
+		require.NoError(t, err)
+		v, err := io.ReadAll(nullValue)
+		require.NoError(t, err)
+		assert.Empty(t, v)
+
+		largeValue, err := reader.GetStreaming(intToByteSlice(2))
+		require.NoError(t, err)
+		for i := 0; i < 32; i++ {
+			_, err = io.ReadFull(largeValue, chunk)
+			require.NoError(t, err)
+			for j := range chunk {
+				require.Equal(t, byte(i+j), chunk[j])
+			}
+		}
+		_, err = largeValue.Read(chunk)
+		assert.Equal(t, io.EOF, err)
+		require.NoError(t, largeValue.Close())
+
+		closeReader(t, reader)
+		cleanWriterDir(t, writer)
+	}
+}
+
+func TestGetErrorKinds(t *testing.T) {
+	for _, path := range []string{"test_files/SimpleWriteHappyPathSSTable", "test_files/SimpleWriteHappyPathSSTableWithBloom"} {
+		reader, err := NewSSTableReader(ReadBasePath(path))
+		require.Nil(t, err)
+
+		_, err = reader.Get(intToByteSlice(42))
+		assert.Equal(t, ErrKeyNotFound, err)
+		assert.ErrorIs(t, err, NotFound)
+		assert.NotErrorIs(t, err, Done)
+		closeReader(t, reader)
+	}
+
+	reader, err := NewSSTableReader(
+		ReadBasePath("test_files/SimpleWriteHappyPathSSTableWithCRCHashesMismatch"),
+		SkipHashCheckOnLoad(),
+		EnableHashCheckOnReads())
+	require.Nil(t, err)
+	defer closeReader(t, reader)
+
+	_, err = reader.Get(intToByteSlice(4))
+	assert.ErrorIs(t, err, ChecksumError{})
+	assert.NotErrorIs(t, err, ErrKeyNotFound)
+}
+
 func TestNegativeContainsHappyPath(t *testing.T) {
 	reader, err := NewSSTableReader(
 		ReadBasePath("test_files/SimpleWriteHappyPathSSTable"),
@@ -322,3 +683,1121 @@ func assertNegativeContains(t *testing.T, reader SSTableReaderI) {
 	_, err = reader.Get([]byte{1, 2, 3})
 	assert.Equal(t, errors.New("key was not found"), err)
 }
+
+func TestSummaryNarrowsDiskIndexSearch(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "sstables_Summary")
+	require.NoError(t, err)
+	defer func() { require.NoError(t, os.RemoveAll(tmpDir)) }()
+
+	writer, err := NewSSTableStreamWriter(
+		WriteBasePath(tmpDir),
+		WithKeyComparator(skiplist.BytesComparator{}),
+		SummaryEveryNthKey(16))
+	require.NoError(t, err)
+	require.NoError(t, writer.Open())
+	// only even keys are written to be able to look up missing keys in between
+	for i := 0; i < 2000; i += 2 {
+		k, v := getKeyValueAsBytes(i)
+		require.NoError(t, writer.WriteNext(k, v))
+	}
+	require.NoError(t, writer.Close())
+
+	r, err := NewSSTableReader(ReadBasePath(tmpDir), ReadIndexLoader(&DiskIndexLoader{}))
+	require.NoError(t, err)
+	defer closeReader(t, r)
+	reader := r.(*SSTableReader)
+	summary := reader.index.(*DiskKeyIndex).summary
+	require.Equal(t, 63, len(summary))
+	assert.Equal(t, intToByteSlice(0), summary[0].Key)
+	assert.Equal(t, intToByteSlice(32), summary[1].Key)
+
+	for i := -1; i < 2002; i++ {
+		v, err := reader.Get(intToByteSlice(i))
+		if i >= 0 && i < 2000 && i%2 == 0 {
+			require.NoError(t, err)
+			assert.Equal(t, intToByteSlice(i+1), v)
+		} else {
+			assert.ErrorIs(t, err, ErrKeyNotFound)
+		}
+	}
+
+	it, err := reader.ScanStartingAt(intToByteSlice(33))
+	require.NoError(t, err)
+	k, _, err := it.Next()
+	require.NoError(t, err)
+	assert.Equal(t, intToByteSlice(34), k)
+
+	// the default in-memory index doesn't need the summary
+	r, err = NewSSTableReader(ReadBasePath(tmpDir))
+	require.NoError(t, err)
+	defer closeReader(t, r)
+	_, err = r.Get(intToByteSlice(42))
+	require.NoError(t, err)
+}
+
+func TestToleratePartialFiles(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "sstables_PartialFiles")
+	require.NoError(t, err)
+	defer func() { require.NoError(t, os.RemoveAll(tmpDir)) }()
+
+	writer, err := NewSSTableStreamWriter(
+		WriteBasePath(tmpDir),
+		WithKeyComparator(skiplist.BytesComparator{}),
+		EnableBloomFilter(),
+		SummaryEveryNthKey(4))
+	require.NoError(t, err)
+	streamedWriteAscendingIntegers(t, writer, 100)
+
+	// simulate an interrupted copy of the optional files
+	for _, name := range []string{BloomFileName, SummaryFileName} {
+		require.NoError(t, os.Truncate(filepath.Join(tmpDir, name), 10))
+	}
+
+	_, err = NewSSTableReader(ReadBasePath(tmpDir), ReadIndexLoader(&DiskIndexLoader{}))
+	require.Error(t, err)
+
+	r, err := NewSSTableReader(ReadBasePath(tmpDir), ReadIndexLoader(&DiskIndexLoader{}), ReadToleratePartialFiles())
+	require.NoError(t, err)
+	reader := r.(*SSTableReader)
+	assert.Nil(t, reader.bloomFilter)
+	assert.Empty(t, reader.index.(*DiskKeyIndex).summary)
+	for i := 0; i < 100; i++ {
+		k, v := getKeyValueAsBytes(i)
+		contains, err := reader.Contains(k)
+		require.NoError(t, err)
+		assert.True(t, contains)
+		actual, err := reader.Get(k)
+		require.NoError(t, err)
+		assert.Equal(t, v, actual)
+	}
+	_, err = reader.Get(intToByteSlice(1000))
+	assert.Equal(t, ErrKeyNotFound, err)
+	closeReader(t, reader)
+
+	// the data file is never optional
+	require.NoError(t, os.Remove(filepath.Join(tmpDir, DataFileName)))
+	_, err = NewSSTableReader(ReadBasePath(tmpDir), ReadToleratePartialFiles())
+	assert.ErrorContains(t, err, "error while creating data reader")
+}
+
+func TestReaderKeyComparator(t *testing.T) {
+	reader, err := NewSSTableReader(ReadBasePath("test_files/SimpleWriteHappyPathSSTable"))
+	require.Nil(t, err)
+	assert.Equal(t, skiplist.BytesComparator{}, reader.(*SSTableReader).KeyComparator())
+	closeReader(t, reader)
+
+	cmp := &skiplist.BytesComparator{}
+	reader, err = NewSSTableReader(ReadBasePath("test_files/SimpleWriteHappyPathSSTable"), ReadWithKeyComparator(cmp))
+	require.Nil(t, err)
+	assert.Same(t, cmp, reader.(*SSTableReader).KeyComparator())
+	closeReader(t, reader)
+}
+
+func TestReaderComparatorCheck(t *testing.T) {
+	writer, err := newTestSSTableStreamWriter()
+	require.NoError(t, err)
+	defer cleanWriterDir(t, writer)
+	streamedWriteAscendingIntegers(t, writer, 10)
+
+	reader, err := NewSSTableReader(ReadBasePath(writer.opts.basePath))
+	require.NoError(t, err)
+	assert.Equal(t, "bytes", reader.MetaData().ComparatorName)
+	closeReader(t, reader)
+
+	_, err = NewSSTableReader(ReadBasePath(writer.opts.basePath), ReadWithComparatorName("uint64be"))
+	assert.ErrorIs(t, err, ErrComparatorMismatch)
+	assert.ErrorContains(t, err, "written with comparator 'bytes' but is read with 'uint64be'")
+
+	reader, err = NewSSTableReader(ReadBasePath(writer.opts.basePath), ReadWithComparatorName("uint64be"), ReadIgnoreComparatorCheck())
+	require.NoError(t, err)
+	closeReader(t, reader)
+
+	// unnamed comparators can't be verified
+	cmp, err := skiplist.NewCompositeComparator([]skiplist.FieldSpec{{Offset: 0}})
+	require.NoError(t, err)
+	reader, err = NewSSTableReader(ReadBasePath(writer.opts.basePath), ReadWithKeyComparator(cmp))
+	require.NoError(t, err)
+	closeReader(t, reader)
+
+	// tables written before the name was recorded can't be verified either
+	reader, err = NewSSTableReader(ReadBasePath("test_files/SimpleWriteHappyPathSSTable"), ReadWithComparatorName("uint64be"))
+	require.NoError(t, err)
+	closeReader(t, reader)
+}
+
+func TestReaderComparatorCheckWithExplicitName(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "sstables_ReaderComparatorName")
+	require.NoError(t, err)
+	defer func() { require.NoError(t, os.RemoveAll(tmpDir)) }()
+	writer, err := NewSSTableStreamWriter(WriteBasePath(tmpDir), WithKeyComparator(skiplist.BytesComparator{}),
+		WithComparatorName("uint64be"))
+	require.NoError(t, err)
+	streamedWriteAscendingIntegers(t, writer, 10)
+
+	_, err = NewSSTableReader(ReadBasePath(tmpDir))
+	assert.ErrorIs(t, err, ErrComparatorMismatch)
+
+	reader, err := NewSSTableReader(ReadBasePath(tmpDir), ReadWithComparatorName("uint64be"))
+	require.NoError(t, err)
+	assert.Equal(t, "uint64be", reader.MetaData().ComparatorName)
+	closeReader(t, reader)
+
+	// the migrated table keeps the name of the source
+	dstDir, err := os.MkdirTemp("", "sstables_ReaderComparatorNameMigrated")
+	require.NoError(t, err)
+	defer func() { require.NoError(t, os.RemoveAll(dstDir)) }()
+	require.NoError(t, MigrateTable(tmpDir, dstDir, Version))
+	_, err = NewSSTableReader(ReadBasePath(dstDir))
+	assert.ErrorIs(t, err, ErrComparatorMismatch)
+}
+
+// writeVersionedTable writes keys 0-9 with the versions at seq 10, 20 and 30, the value is the key followed by the seq.
+// Key 5 only exists as of seq 30.
+func writeVersionedTable(t *testing.T) string {
+	tmpDir, err := os.MkdirTemp("", "sstables_ReaderVersioned")
+	require.NoError(t, err)
+	writer, err := NewSSTableStreamWriter(WriteBasePath(tmpDir), WithKeyComparator(skiplist.BytesComparator{}), WithVersioning())
+	require.NoError(t, err)
+	require.NoError(t, writer.Open())
+	for i := 0; i < 10; i++ {
+		for _, seq := range []uint64{10, 20, 30} {
+			if i == 5 && seq < 30 {
+				continue
+			}
+			require.NoError(t, writer.WriteNextWithSeq(intToByteSlice(i), []byte{byte(i), byte(seq)}, seq))
+		}
+	}
+	require.NoError(t, writer.Close())
+	return tmpDir
+}
+
+func TestReadAsOfSeq(t *testing.T) {
+	path := writeVersionedTable(t)
+	defer func() { require.NoError(t, os.RemoveAll(path)) }()
+
+	loaders := []func() IndexLoader{
+		func() IndexLoader { return nil },
+		func() IndexLoader { return &SliceKeyIndexLoader{ReadBufferSize: 4096} },
+		func() IndexLoader { return &DiskIndexLoader{} },
+	}
+
+	tests := []struct {
+		opts        []ReadOption
+		expectedSeq uint64
+	}{
+		{nil, 30},
+		{[]ReadOption{ReadAsOfSeq(30)}, 30},
+		{[]ReadOption{ReadAsOfSeq(25)}, 20},
+		{[]ReadOption{ReadAsOfSeq(10)}, 10},
+		{[]ReadOption{ReadAsOfSeq(5)}, 0},
+	}
+
+	for _, loaderFunc := range loaders {
+		for _, test := range tests {
+			opts := append([]ReadOption{ReadBasePath(path)}, test.opts...)
+			if loader := loaderFunc(); loader != nil {
+				opts = append(opts, ReadIndexLoader(loader))
+			}
+			reader, err := NewSSTableReader(opts...)
+			require.NoError(t, err)
+
+			var expected [][]byte
+			for i := 0; i < 10; i++ {
+				visible := test.expectedSeq > 0 && (i != 5 || test.expectedSeq == 30)
+				contains, err := reader.Contains(intToByteSlice(i))
+				require.NoError(t, err)
+				assert.Equal(t, visible, contains)
+
+				v, err := reader.Get(intToByteSlice(i))
+				if !visible {
+					assert.ErrorIs(t, err, ErrKeyNotFound)
+					continue
+				}
+				require.NoError(t, err)
+				assert.Equal(t, []byte{byte(i), byte(test.expectedSeq)}, v)
+				expected = append(expected, v)
+			}
+
+			scan, err := reader.Scan()
+			require.NoError(t, err)
+			assert.Equal(t, expected, collectValues(t, scan))
+
+			startingAt, err := reader.ScanStartingAt(intToByteSlice(3))
+			require.NoError(t, err)
+			rangeScan, err := reader.ScanRange(intToByteSlice(3), intToByteSlice(6))
+			require.NoError(t, err)
+			var expectedFrom3, expectedRange [][]byte
+			for _, v := range expected {
+				if v[0] >= 3 {
+					expectedFrom3 = append(expectedFrom3, v)
+				}
+				if v[0] >= 3 && v[0] <= 6 {
+					expectedRange = append(expectedRange, v)
+				}
+			}
+			assert.Equal(t, expectedFrom3, collectValues(t, startingAt))
+			assert.Equal(t, expectedRange, collectValues(t, rangeScan))
+
+			closeReader(t, reader)
+		}
+	}
+}
+
+func TestReadVersionedTableRejectsSkipListIndex(t *testing.T) {
+	path := writeVersionedTable(t)
+	defer func() { require.NoError(t, os.RemoveAll(path)) }()
+
+	_, err := NewSSTableReader(ReadBasePath(path), ReadIndexLoader(&SkipListIndexLoader{
+		KeyComparator:  skiplist.BytesComparator{},
+		ReadBufferSize: 4096,
+	}))
+	require.ErrorContains(t, err, "does not support multiple versions")
+}
+
+func TestReadAsOfSeqUnversionedTable(t *testing.T) {
+	writer, err := newTestSSTableStreamWriter()
+	require.NoError(t, err)
+	defer cleanWriterDir(t, writer)
+	require.NoError(t, writer.Open())
+	for i := 0; i < 10; i++ {
+		k, v := getKeyValueAsBytes(i)
+		require.NoError(t, writer.WriteNextWithSeq(k, v, uint64(i)))
+	}
+	require.NoError(t, writer.Close())
+
+	reader, err := NewSSTableReader(ReadBasePath(writer.opts.basePath), ReadAsOfSeq(4))
+	require.NoError(t, err)
+	defer closeReader(t, reader)
+
+	for i := 0; i < 10; i++ {
+		k, _ := getKeyValueAsBytes(i)
+		_, err := reader.Get(k)
+		if i <= 4 {
+			require.NoError(t, err)
+		} else {
+			assert.ErrorIs(t, err, ErrKeyNotFound)
+		}
+	}
+
+	it, err := reader.Scan()
+	require.NoError(t, err)
+	assert.Len(t, collectValues(t, it), 5)
+}
+
+func collectValues(t *testing.T, it SSTableIteratorI) [][]byte {
+	var values [][]byte
+	for {
+		_, v, err := it.Next()
+		if errors.Is(err, Done) {
+			return values
+		}
+		require.NoError(t, err)
+		values = append(values, v)
+	}
+}
+
+func TestScanWithReadAhead(t *testing.T) {
+	writer, err := newTestSSTableStreamWriter()
+	require.NoError(t, err)
+	defer cleanWriterDir(t, writer)
+	expected := streamedWriteAscendingIntegers(t, writer, 1000)
+
+	for _, readAheadBytes := range []int{1, 512, 1024 * 1024} {
+		reader, err := NewSSTableReader(ReadBasePath(writer.opts.basePath), ReadAhead(readAheadBytes))
+		require.NoError(t, err)
+
+		it, err := reader.Scan()
+		require.NoError(t, err)
+		for _, e := range expected {
+			k, v, err := it.Next()
+			require.NoError(t, err)
+			expectedKey, expectedValue := getKeyValueAsBytes(e)
+			assert.Equal(t, expectedKey, k)
+			assert.Equal(t, expectedValue, v)
+		}
+		_, _, err = it.Next()
+		assert.ErrorIs(t, err, Done)
+		closeReader(t, reader)
+	}
+}
+
+func TestScanFilter(t *testing.T) {
+	writer, err := newTestSSTableStreamWriter()
+	require.NoError(t, err)
+	defer cleanWriterDir(t, writer)
+	streamedWriteAscendingIntegers(t, writer, 100)
+
+	isEven := func(key []byte) bool {
+		return binary.BigEndian.Uint32(key)%2 == 0
+	}
+	for _, loaderFunc := range indexLoaders {
+		r, err := NewSSTableReader(ReadBasePath(writer.opts.basePath), ReadIndexLoader(loaderFunc()))
+		require.NoError(t, err)
+		reader := r.(*SSTableReader)
+		counting := &countingReadAt{ReadAtI: reader.dataReader}
+		reader.dataReader = counting
+
+		it, err := reader.ScanFilter(isEven)
+		require.NoError(t, err)
+		var expected []int
+		for i := 0; i < 100; i += 2 {
+			expected = append(expected, i)
+		}
+		assertIteratorMatchesSlice(t, it, expected)
+		// only the values of matching keys are read
+		assert.Equal(t, len(expected), counting.reads)
+
+		it, err = reader.ScanRangeFilter(intToByteSlice(10), intToByteSlice(20), isEven)
+		require.NoError(t, err)
+		assertIteratorMatchesSlice(t, it, []int{10, 12, 14, 16, 18, 20})
+
+		it, err = reader.ScanFilter(func(key []byte) bool { return false })
+		require.NoError(t, err)
+		_, _, err = it.Next()
+		assert.ErrorIs(t, err, Done)
+
+		_, err = reader.ScanRangeFilter(intToByteSlice(20), intToByteSlice(10), isEven)
+		assert.Error(t, err)
+		closeReader(t, reader)
+	}
+}
+
+type countingReadAt struct {
+	recordio.ReadAtI
+	reads int
+}
+
+func (c *countingReadAt) ReadNextAt(offset uint64) ([]byte, error) {
+	c.reads++
+	return c.ReadAtI.ReadNextAt(offset)
+}
+
+func TestScanWithReuseScanBuffers(t *testing.T) {
+	writer, err := newTestSSTableStreamWriter()
+	require.NoError(t, err)
+	defer cleanWriterDir(t, writer)
+	expected := streamedWriteAscendingIntegers(t, writer, 100)
+
+	reader, err := NewSSTableReader(ReadBasePath(writer.opts.basePath), ReadReuseScanBuffers())
+	require.NoError(t, err)
+	defer closeReader(t, reader)
+
+	lower, _ := getKeyValueAsBytes(0)
+	higher, _ := getKeyValueAsBytes(99)
+	scans := []func() (SSTableIteratorI, error){
+		reader.Scan,
+		func() (SSTableIteratorI, error) { return reader.ScanStartingAt(lower) },
+		func() (SSTableIteratorI, error) { return reader.ScanRange(lower, higher) },
+	}
+	for _, scan := range scans {
+		it, err := scan()
+		require.NoError(t, err)
+		var first []byte
+		for _, e := range expected {
+			k, v, err := it.Next()
+			require.NoError(t, err)
+			expectedKey, expectedValue := getKeyValueAsBytes(e)
+			assert.Equal(t, expectedKey, k)
+			assert.Equal(t, expectedValue, v)
+			// all values are of the same size and share the same buffer
+			if first == nil {
+				first = v
+			}
+			assert.Equal(t, &first[0], &v[0])
+		}
+		_, _, err = it.Next()
+		assert.ErrorIs(t, err, Done)
+	}
+}
+
+func TestScanWithReuseScanBuffersVersioned(t *testing.T) {
+	path := writeVersionedTable(t)
+	defer func() { require.NoError(t, os.RemoveAll(path)) }()
+
+	reader, err := NewSSTableReader(ReadBasePath(path), ReadReuseScanBuffers())
+	require.NoError(t, err)
+	defer closeReader(t, reader)
+
+	// versions are filtered with a read ahead, the values can't share a buffer
+	it, err := reader.Scan()
+	require.NoError(t, err)
+	var values [][]byte
+	for {
+		_, v, err := it.Next()
+		if errors.Is(err, Done) {
+			break
+		}
+		require.NoError(t, err)
+		values = append(values, v)
+	}
+	require.Len(t, values, 10)
+	for i, v := range values {
+		assert.Equal(t, []byte{byte(i), 30}, v)
+	}
+}
+
+func TestScanWithAdviseSequential(t *testing.T) {
+	writer, err := newTestSSTableStreamWriter()
+	require.NoError(t, err)
+	defer cleanWriterDir(t, writer)
+	expected := streamedWriteAscendingIntegers(t, writer, 100)
+
+	reader, err := NewSSTableReader(ReadBasePath(writer.opts.basePath), ReadAdviseSequentialScan())
+	require.NoError(t, err)
+	defer closeReader(t, reader)
+
+	it, err := reader.Scan()
+	require.NoError(t, err)
+	for _, e := range expected {
+		k, v, err := it.Next()
+		require.NoError(t, err)
+		expectedKey, expectedValue := getKeyValueAsBytes(e)
+		assert.Equal(t, expectedKey, k)
+		assert.Equal(t, expectedValue, v)
+	}
+	_, _, err = it.Next()
+	assert.ErrorIs(t, err, Done)
+}
+
+func TestReaderClone(t *testing.T) {
+	writer, err := newTestSSTableStreamWriter()
+	require.NoError(t, err)
+	defer cleanWriterDir(t, writer)
+	expected := streamedWriteAscendingIntegers(t, writer, 1000)
+
+	for _, loaderFunc := range indexLoaders {
+		r, err := NewSSTableReader(ReadBasePath(writer.opts.basePath), ReadIndexLoader(loaderFunc()))
+		require.NoError(t, err)
+		reader := r.(*SSTableReader)
+
+		var clones []*SSTableReader
+		for i := 0; i < 4; i++ {
+			clone, err := reader.Clone()
+			require.NoError(t, err)
+			assert.Same(t, reader.index, clone.index)
+			clones = append(clones, clone)
+		}
+
+		var wg sync.WaitGroup
+		errs := make([]error, len(clones))
+		for i, clone := range clones {
+			wg.Add(1)
+			go func(i int, clone *SSTableReader) {
+				defer wg.Done()
+				it, err := clone.Scan()
+				if err != nil {
+					errs[i] = err
+					return
+				}
+				for _, e := range expected {
+					k, v, err := it.Next()
+					if err != nil {
+						errs[i] = err
+						return
+					}
+					expectedKey, expectedValue := getKeyValueAsBytes(e)
+					if !bytes.Equal(expectedKey, k) || !bytes.Equal(expectedValue, v) {
+						errs[i] = fmt.Errorf("unexpected record at %d", e)
+						return
+					}
+				}
+			}(i, clone)
+		}
+		wg.Wait()
+		for _, err := range errs {
+			require.NoError(t, err)
+		}
+
+		for _, clone := range clones {
+			require.NoError(t, clone.Close())
+		}
+
+		// closing the clones leaves the shared index and the parent usable
+		for _, e := range expected {
+			k, v := getKeyValueAsBytes(e)
+			actual, err := reader.Get(k)
+			require.NoError(t, err)
+			assert.Equal(t, v, actual)
+		}
+		closeReader(t, reader)
+	}
+}
+
+func TestReaderIndexIterator(t *testing.T) {
+	writer, err := newTestSSTableStreamWriter()
+	require.NoError(t, err)
+	defer cleanWriterDir(t, writer)
+	require.NoError(t, writer.Open())
+	for i := 0; i < 10; i++ {
+		k, v := getKeyValueAsBytes(i)
+		if i == 3 {
+			v = nil
+		}
+		require.NoError(t, writer.WriteNextWithSeq(k, v, uint64(i+1)))
+	}
+	require.NoError(t, writer.Close())
+
+	for _, loaderFunc := range indexLoaders {
+		r, err := NewSSTableReader(ReadBasePath(writer.opts.basePath), ReadIndexLoader(loaderFunc()))
+		require.NoError(t, err)
+		reader := r.(*SSTableReader)
+
+		it, err := reader.IndexIterator()
+		require.NoError(t, err)
+		for i := 0; i < 10; i++ {
+			entry, err := it.Next()
+			require.NoError(t, err)
+			k, v := getKeyValueAsBytes(i)
+			if i == 3 {
+				v = nil
+			}
+			assert.Equal(t, k, entry.Key)
+			assert.Equal(t, i == 3, entry.NullValue)
+			assert.Equal(t, uint64(i+1), entry.SequenceNumber)
+			assert.Equal(t, crc64.Checksum(v, crc64.MakeTable(crc64.ISO)), entry.Checksum)
+
+			value, err := reader.dataReader.ReadNextAt(entry.Offset)
+			require.NoError(t, err)
+			assert.Equal(t, v, value)
+
+			// the key is a copy, modifying it doesn't affect the index
+			entry.Key[0] = 0xFF
+		}
+		_, err = it.Next()
+		assert.ErrorIs(t, err, Done)
+
+		for i := 0; i < 10; i++ {
+			k, _ := getKeyValueAsBytes(i)
+			contains, err := reader.Contains(k)
+			require.NoError(t, err)
+			assert.True(t, contains)
+		}
+		closeReader(t, reader)
+	}
+}
+
+func TestGetByOrdinal(t *testing.T) {
+	writer, err := newTestSSTableStreamWriter()
+	require.NoError(t, err)
+	defer cleanWriterDir(t, writer)
+	expected := streamedWriteAscendingIntegers(t, writer, 100)
+
+	for _, loaderFunc := range indexLoaders {
+		r, err := NewSSTableReader(ReadBasePath(writer.opts.basePath), ReadIndexLoader(loaderFunc()))
+		require.NoError(t, err)
+		reader := r.(*SSTableReader)
+
+		for i, e := range expected {
+			expectedKey, expectedValue := getKeyValueAsBytes(e)
+			key, value, err := reader.GetByOrdinal(uint64(i))
+			require.NoError(t, err)
+			assert.Equal(t, expectedKey, key)
+			assert.Equal(t, expectedValue, value)
+		}
+
+		_, _, err = reader.GetByOrdinal(uint64(len(expected)))
+		assert.ErrorIs(t, err, ErrOrdinalOutOfRange)
+		_, _, err = reader.GetByOrdinal(uint64(len(expected) * 2))
+		assert.ErrorIs(t, err, ErrOrdinalOutOfRange)
+		closeReader(t, reader)
+	}
+}
+
+func TestGetInto(t *testing.T) {
+	writer, err := newTestSSTableStreamWriter()
+	require.NoError(t, err)
+	defer cleanWriterDir(t, writer)
+	require.NoError(t, writer.Open())
+	for i := 0; i < 10; i++ {
+		k, v := getKeyValueAsBytes(i)
+		if i == 3 {
+			v = nil
+		}
+		require.NoError(t, writer.WriteNext(k, v))
+	}
+	require.NoError(t, writer.Close())
+
+	pool := &countingBufferPool{}
+	r, err := NewSSTableReader(ReadBasePath(writer.opts.basePath), EnableHashCheckOnReads(), ReadWithBufferPool(pool))
+	require.NoError(t, err)
+	reader := r.(*SSTableReader)
+	defer closeReader(t, reader)
+
+	buf := make([]byte, 0, 16)
+	for i := 0; i < 10; i++ {
+		k, v := getKeyValueAsBytes(i)
+		actual, err := reader.GetInto(k, buf)
+		require.NoError(t, err)
+		if i == 3 {
+			assert.Nil(t, actual)
+			continue
+		}
+		assert.Equal(t, v, actual)
+		// the value is read into the buffer of the caller
+		assert.Equal(t, &buf[:1][0], &actual[0])
+	}
+	assert.Greater(t, pool.gets, 0)
+
+	// a buffer that is too small is grown
+	k, v := getKeyValueAsBytes(5)
+	actual, err := reader.GetInto(k, nil)
+	require.NoError(t, err)
+	assert.Equal(t, v, actual)
+
+	_, err = reader.GetInto([]byte("not there"), buf)
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+}
+
+type countingBufferPool struct {
+	gets int
+}
+
+func (p *countingBufferPool) Get(size int) []byte {
+	p.gets++
+	return make([]byte, size)
+}
+
+func (p *countingBufferPool) Put([]byte) {}
+
+func TestReadMaxIndexMemoryBytes(t *testing.T) {
+	writer, err := newTestSSTableStreamWriter()
+	require.NoError(t, err)
+	defer cleanWriterDir(t, writer)
+	expected := streamedWriteAscendingIntegers(t, writer, 100)
+
+	for _, loaderFunc := range indexLoaders {
+		loader := loaderFunc()
+		r, err := NewSSTableReader(ReadBasePath(writer.opts.basePath), ReadIndexLoader(loader))
+		require.NoError(t, err)
+		estimate, ok := r.(*SSTableReader).IndexMemoryEstimate()
+		require.True(t, ok)
+		require.NoError(t, r.Close())
+
+		if estimate == 0 {
+			// the disk index keeps nothing in memory, so it can always be opened
+			r, err = NewSSTableReader(ReadBasePath(writer.opts.basePath), ReadIndexLoader(loader), ReadMaxIndexMemoryBytes(1))
+			require.NoError(t, err)
+			require.NoError(t, r.Close())
+			continue
+		}
+		assert.GreaterOrEqual(t, estimate, r.MetaData().IndexBytes)
+
+		_, err = NewSSTableReader(ReadBasePath(writer.opts.basePath), ReadIndexLoader(loader), ReadMaxIndexMemoryBytes(estimate-1))
+		var limitErr IndexMemoryLimitError
+		require.ErrorAs(t, err, &limitErr)
+		assert.Equal(t, estimate, limitErr.EstimatedBytes)
+		assert.Equal(t, estimate-1, limitErr.LimitBytes)
+
+		r, err = NewSSTableReader(ReadBasePath(writer.opts.basePath), ReadIndexLoader(loader), ReadMaxIndexMemoryBytes(estimate))
+		require.NoError(t, err)
+		require.NoError(t, r.Close())
+	}
+
+	assertRandomAndSequentialRead(t, writer.opts.basePath, expected)
+}
+
+func TestReadRequiresCommitMarker(t *testing.T) {
+	writer, err := newTestSSTableStreamWriter()
+	require.NoError(t, err)
+	defer cleanWriterDir(t, writer)
+
+	require.NoError(t, writer.Open())
+	k, v := getKeyValueAsBytes(1)
+	require.NoError(t, writer.WriteNext(k, v))
+	// the table is still being written
+	_, err = NewSSTableReader(ReadBasePath(writer.opts.basePath))
+	require.ErrorIs(t, err, ErrTableNotCommitted)
+
+	require.NoError(t, writer.Close())
+	assert.FileExists(t, filepath.Join(writer.opts.basePath, CommittedFileName))
+	r, err := NewSSTableReader(ReadBasePath(writer.opts.basePath))
+	require.NoError(t, err)
+	closeReader(t, r)
+
+	require.NoError(t, os.Remove(filepath.Join(writer.opts.basePath, CommittedFileName)))
+	_, err = NewSSTableReader(ReadBasePath(writer.opts.basePath))
+	require.ErrorIs(t, err, ErrTableNotCommitted)
+
+	r, err = NewSSTableReader(ReadBasePath(writer.opts.basePath), ReadUncommitted())
+	require.NoError(t, err)
+	assertContentMatchesSlice(t, r, []int{1})
+	closeReader(t, r)
+}
+
+func TestReaderSchemaID(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "sstables_SchemaID")
+	require.NoError(t, err)
+	writer, err := NewSSTableStreamWriter(WriteBasePath(tmpDir), WithKeyComparator(skiplist.BytesComparator{}), WithSchemaID(42))
+	require.NoError(t, err)
+	defer cleanWriterDir(t, writer)
+	expected := streamedWriteAscendingIntegers(t, writer, 100)
+
+	reader, err := NewSSTableReader(ReadBasePath(tmpDir))
+	require.NoError(t, err)
+	defer closeReader(t, reader)
+	id, ok := reader.(*SSTableReader).SchemaID()
+	assert.True(t, ok)
+	assert.Equal(t, uint32(42), id)
+
+	k, v := getKeyValueAsBytes(7)
+	val, err := reader.Get(k)
+	require.NoError(t, err)
+	assert.Equal(t, v, val)
+	it, err := reader.Scan()
+	require.NoError(t, err)
+	assertIteratorMatchesSlice(t, it, expected)
+
+	// the estimate accounts for the larger header
+	estimator, err := NewSSTableStreamWriter(EstimateOnly(), WithKeyComparator(skiplist.BytesComparator{}), WithSchemaID(42))
+	require.NoError(t, err)
+	require.NoError(t, estimator.Open())
+	for i := 0; i < 100; i++ {
+		k, v := getKeyValueAsBytes(i)
+		require.NoError(t, estimator.WriteNext(k, v))
+	}
+	estimate, err := estimator.Estimate()
+	require.NoError(t, err)
+	require.NoError(t, estimator.Close())
+	assert.Equal(t, reader.MetaData().DataBytes, estimate.DataBytes)
+}
+
+func TestReaderWithoutSchemaID(t *testing.T) {
+	writer, err := newTestSSTableStreamWriter()
+	require.NoError(t, err)
+	defer cleanWriterDir(t, writer)
+	streamedWriteAscendingIntegers(t, writer, 10)
+
+	reader, err := NewSSTableReader(ReadBasePath(writer.opts.basePath))
+	require.NoError(t, err)
+	defer closeReader(t, reader)
+	_, ok := reader.(*SSTableReader).SchemaID()
+	assert.False(t, ok)
+}
+
+func TestReaderBloomKeyTransform(t *testing.T) {
+	// the first byte of the key is the row, the second the column
+	row := func(key []byte) []byte { return key[:1] }
+	tmpDir, err := os.MkdirTemp("", "sstables_BloomKeyTransform")
+	require.NoError(t, err)
+	writer, err := NewSSTableStreamWriter(WriteBasePath(tmpDir), WithKeyComparator(skiplist.BytesComparator{}),
+		EnableBloomFilter(), BloomKeyTransform(row))
+	require.NoError(t, err)
+	defer cleanWriterDir(t, writer)
+	require.NoError(t, writer.Open())
+	for r := byte(0); r < 100; r += 2 {
+		for c := byte(0); c < 10; c++ {
+			require.NoError(t, writer.WriteNext([]byte{r, c}, []byte{r, c}))
+		}
+	}
+	require.NoError(t, writer.Close())
+
+	for _, opts := range [][]ReadOption{nil, {ReadBloomKeyTransform(row)}} {
+		reader, err := NewSSTableReader(append(opts, ReadBasePath(tmpDir))...)
+		require.NoError(t, err)
+		assert.True(t, reader.MetaData().BloomKeyTransformed)
+
+		sReader := reader.(*SSTableReader)
+		falsePositives := 0
+		for r := byte(0); r < 100; r++ {
+			if r%2 == 0 {
+				assert.True(t, sReader.MightContain([]byte{r}))
+			} else if sReader.MightContain([]byte{r}) {
+				falsePositives++
+			}
+		}
+		assert.Less(t, falsePositives, 5)
+
+		// point lookups of full keys work with and without the transform
+		val, err := reader.Get([]byte{4, 2})
+		require.NoError(t, err)
+		assert.Equal(t, []byte{4, 2}, val)
+		contains, err := reader.Contains([]byte{4, 11})
+		require.NoError(t, err)
+		assert.False(t, contains)
+		_, err = reader.Get([]byte{5, 2})
+		assert.ErrorIs(t, err, ErrKeyNotFound)
+		closeReader(t, reader)
+	}
+}
+
+func TestReaderMightContainWithoutTransform(t *testing.T) {
+	writer, err := newTestSSTableStreamWriter()
+	require.NoError(t, err)
+	defer cleanWriterDir(t, writer)
+	streamedWriteAscendingIntegers(t, writer, 100)
+
+	// the transform is ignored for tables written without one
+	reader, err := NewSSTableReader(ReadBasePath(writer.opts.basePath), ReadBloomKeyTransform(func(key []byte) []byte { return nil }))
+	require.NoError(t, err)
+	defer closeReader(t, reader)
+	assert.False(t, reader.MetaData().BloomKeyTransformed)
+
+	k, _ := getKeyValueAsBytes(42)
+	assert.True(t, reader.(*SSTableReader).MightContain(k))
+	contains, err := reader.Contains(k)
+	require.NoError(t, err)
+	assert.True(t, contains)
+}
+
+func TestReadWithRateLimiter(t *testing.T) {
+	for _, scanOnly := range []bool{false, true} {
+		tmpDir, err := os.MkdirTemp("", "sstables_ReadRateLimiter")
+		require.NoError(t, err)
+		opts := []WriterOption{WriteBasePath(tmpDir), WithKeyComparator(skiplist.BytesComparator{}), DataCompressionType(recordio.CompressionTypeSnappy)}
+		if scanOnly {
+			opts = append(opts, ScanOnly())
+		}
+		writer, err := NewSSTableStreamWriter(opts...)
+		require.NoError(t, err)
+		expected := streamedWriteAscendingIntegers(t, writer, 1000)
+
+		limiter := &countingRateLimiter{}
+		reader, err := NewSSTableReader(ReadBasePath(tmpDir), ReadWithRateLimiter(nil, limiter))
+		require.NoError(t, err)
+		if !scanOnly {
+			k, v := getKeyValueAsBytes(42)
+			val, err := reader.Get(k)
+			require.NoError(t, err)
+			assert.Equal(t, v, val)
+			assert.Equal(t, int64(0), limiter.calls.Load())
+		}
+
+		it, err := reader.Scan()
+		require.NoError(t, err)
+		assertIteratorMatchesSlice(t, it, expected)
+		// the limiter saw every byte of the data file except for its header, as stored on disk
+		assert.Equal(t, int64(1000), limiter.calls.Load())
+		assert.Equal(t, int64(reader.MetaData().DataBytes-recordio.FileHeaderSizeBytes), limiter.bytes.Load())
+		closeReader(t, reader)
+		cleanWriterDir(t, writer)
+	}
+}
+
+func TestReadWithRateLimiterContextDone(t *testing.T) {
+	writer, err := newTestSSTableStreamWriter()
+	require.NoError(t, err)
+	defer cleanWriterDir(t, writer)
+	streamedWriteAscendingIntegers(t, writer, 10)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	reader, err := NewSSTableReader(ReadBasePath(writer.opts.basePath), ReadWithRateLimiter(ctx, NewTokenBucketRateLimiter(1)))
+	require.NoError(t, err)
+	defer closeReader(t, reader)
+
+	it, err := reader.Scan()
+	require.NoError(t, err)
+	_, _, err = it.Next()
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestPreload(t *testing.T) {
+	writer, err := newTestSSTableStreamWriter()
+	require.NoError(t, err)
+	defer cleanWriterDir(t, writer)
+	streamedWriteAscendingIntegers(t, writer, 1000)
+
+	for _, diskIndex := range []bool{false, true} {
+		opts := []ReadOption{ReadBasePath(writer.opts.basePath)}
+		if diskIndex {
+			opts = append(opts, ReadIndexLoader(&DiskIndexLoader{}))
+		}
+		r, err := NewSSTableReader(opts...)
+		require.NoError(t, err)
+		reader := r.(*SSTableReader)
+
+		n, err := reader.Preload(context.Background())
+		require.NoError(t, err)
+		// the index file is only read with the disk index, in-memory indices were loaded while opening
+		expected := reader.MetaData().DataBytes
+		if diskIndex {
+			expected += reader.MetaData().IndexBytes
+		}
+		assert.Equal(t, expected, n)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		n, err = reader.Preload(ctx)
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, uint64(0), n)
+		closeReader(t, reader)
+	}
+}
+
+func TestReadDataFile(t *testing.T) {
+	writer, err := newTestSSTableStreamWriter()
+	require.NoError(t, err)
+	defer cleanWriterDir(t, writer)
+	expected := streamedWriteAscendingIntegers(t, writer, 1000)
+
+	dataFile, err := os.Open(filepath.Join(writer.opts.basePath, DataFileName))
+	require.NoError(t, err)
+	defer func() { require.NoError(t, dataFile.Close()) }()
+
+	// many short-lived readers can share the same file, none of them closes it
+	for i := 0; i < 3; i++ {
+		reader, err := NewSSTableReader(ReadBasePath(writer.opts.basePath), ReadDataFile(dataFile), EnableHashCheckOnReads())
+		require.NoError(t, err)
+
+		k, v := getKeyValueAsBytes(i * 100)
+		val, err := reader.Get(k)
+		require.NoError(t, err)
+		assert.Equal(t, v, val)
+
+		it, err := reader.Scan()
+		require.NoError(t, err)
+		assertIteratorMatchesSlice(t, it, expected)
+
+		clone, err := reader.(*SSTableReader).Clone()
+		require.NoError(t, err)
+		val, err = clone.Get(k)
+		require.NoError(t, err)
+		assert.Equal(t, v, val)
+		require.NoError(t, clone.Close())
+		closeReader(t, reader)
+	}
+
+	_, err = dataFile.ReadAt(make([]byte, recordio.FileHeaderSizeBytes), 0)
+	require.NoError(t, err)
+}
+
+func TestReadDataFileRejectsV0(t *testing.T) {
+	dataFile, err := os.Open("test_files/v0_compat/SimpleWriteHappyPathSSTable/" + DataFileName)
+	require.NoError(t, err)
+	defer func() { require.NoError(t, dataFile.Close()) }()
+
+	_, err = NewSSTableReader(ReadBasePath("test_files/v0_compat/SimpleWriteHappyPathSSTable"), ReadDataFile(dataFile))
+	assert.Error(t, err)
+}
+
+func TestReadValidateKeyRange(t *testing.T) {
+	writer, err := newTestSSTableStreamWriter()
+	require.NoError(t, err)
+	defer cleanWriterDir(t, writer)
+	streamedWriteAscendingIntegers(t, writer, 100)
+	basePath := writer.opts.basePath
+
+	loaders := []IndexLoader{
+		nil,
+		&SliceKeyIndexLoader{ReadBufferSize: 4096},
+		&ArenaKeyIndexLoader{ReadBufferSize: 4096},
+		&DiskIndexLoader{},
+	}
+	for _, loader := range loaders {
+		opts := []ReadOption{ReadBasePath(basePath), ReadValidateKeyRange()}
+		if loader != nil {
+			opts = append(opts, ReadIndexLoader(loader))
+		}
+		reader, err := NewSSTableReader(opts...)
+		require.NoError(t, err, "loader %T", loader)
+		closeReader(t, reader)
+	}
+
+	versioned := writeVersionedTable(t)
+	defer func() { require.NoError(t, os.RemoveAll(versioned)) }()
+	reader, err := NewSSTableReader(ReadBasePath(versioned), ReadValidateKeyRange())
+	require.NoError(t, err)
+	closeReader(t, reader)
+
+	tests := []func(md *proto.MetaData){
+		func(md *proto.MetaData) { md.MinKey = intToByteSlice(1) },
+		func(md *proto.MetaData) { md.MaxKey = intToByteSlice(98) },
+		func(md *proto.MetaData) { md.MaxKey = intToByteSlice(1000) },
+	}
+	original, err := readMetaDataIfExists(filepath.Join(basePath, MetaFileName))
+	require.NoError(t, err)
+	for _, modify := range tests {
+		md := pb.Clone(original).(*proto.MetaData)
+		modify(md)
+		writeMetaData(t, basePath, md)
+
+		for _, loader := range loaders {
+			opts := []ReadOption{ReadBasePath(basePath), ReadValidateKeyRange()}
+			if loader != nil {
+				opts = append(opts, ReadIndexLoader(loader))
+			}
+			_, err = NewSSTableReader(opts...)
+			assert.ErrorIs(t, err, ErrKeyRangeMismatch, "loader %T", loader)
+		}
+
+		// the check is opt-in
+		reader, err := NewSSTableReader(ReadBasePath(basePath))
+		require.NoError(t, err)
+		closeReader(t, reader)
+	}
+}
+
+// writeMetaData replaces the metadata of the table in basePath
+func writeMetaData(t *testing.T, basePath string, md *proto.MetaData) {
+	content, err := marshalMetaData(md)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(basePath, MetaFileName), content, 0666))
+}
+
+func TestFirstAndLastKey(t *testing.T) {
+	writer, err := newTestSSTableStreamWriter()
+	require.NoError(t, err)
+	defer cleanWriterDir(t, writer)
+	streamedWriteAscendingIntegersWithStart(t, writer, 5, 100)
+	basePath := writer.opts.basePath
+
+	md, err := ReadMetaData(ReadBasePath(basePath))
+	require.NoError(t, err)
+	assert.Equal(t, intToByteSlice(5), md.MinKey)
+	assert.Equal(t, intToByteSlice(99), md.MaxKey)
+	assert.Equal(t, uint64(95), md.NumRecords)
+
+	assertFirstAndLastKey := func() {
+		for _, loaderFunc := range indexLoaders {
+			r, err := NewSSTableReader(ReadBasePath(basePath), ReadIndexLoader(loaderFunc()))
+			require.NoError(t, err)
+			reader := r.(*SSTableReader)
+
+			first, err := reader.FirstKey()
+			require.NoError(t, err)
+			assert.Equal(t, intToByteSlice(5), first)
+			last, err := reader.LastKey()
+			require.NoError(t, err)
+			assert.Equal(t, intToByteSlice(99), last)
+			closeReader(t, reader)
+		}
+	}
+	assertFirstAndLastKey()
+
+	// without the key range in the metadata the keys are read from the index
+	md.MinKey = nil
+	md.MaxKey = nil
+	writeMetaData(t, basePath, md)
+	assertFirstAndLastKey()
+
+	// the ordinal shortcut is only taken with the number of records
+	md.NumRecords = 0
+	writeMetaData(t, basePath, md)
+	assertFirstAndLastKey()
+}
+
+func TestFirstAndLastKeyEmptyTable(t *testing.T) {
+	writer, err := newTestSSTableStreamWriter()
+	require.NoError(t, err)
+	defer cleanWriterDir(t, writer)
+	require.NoError(t, writer.Open())
+	require.NoError(t, writer.Close())
+
+	r, err := NewSSTableReader(ReadBasePath(writer.opts.basePath))
+	require.NoError(t, err)
+	defer closeReader(t, r)
+	reader := r.(*SSTableReader)
+
+	_, err = reader.FirstKey()
+	assert.ErrorIs(t, err, ErrEmptyTable)
+	_, err = reader.LastKey()
+	assert.ErrorIs(t, err, ErrEmptyTable)
+}
+
+func TestReadMetaDataMissing(t *testing.T) {
+	_, err := ReadMetaData()
+	assert.Error(t, err)
+
+	_, err = ReadMetaData(ReadBasePath(t.TempDir()))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
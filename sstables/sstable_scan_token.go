@@ -0,0 +1,95 @@
+package sstables
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+
+	"github.com/thomasjungblut/go-sstables/skiplist"
+	"golang.org/x/exp/slices"
+)
+
+// ErrInvalidScanToken is returned by ScanFrom when the continuation token can't be decoded.
+var ErrInvalidScanToken = errors.New("invalid scan token")
+
+const scanTokenVersion = byte(1)
+
+// a token is the version byte, the big endian crc32 (IEEE) of the key and the key itself
+const scanTokenHeaderSize = 1 + 4
+
+func encodeScanToken(key []byte) []byte {
+	token := make([]byte, scanTokenHeaderSize+len(key))
+	token[0] = scanTokenVersion
+	binary.BigEndian.PutUint32(token[1:scanTokenHeaderSize], crc32.ChecksumIEEE(key))
+	copy(token[scanTokenHeaderSize:], key)
+	return token
+}
+
+func decodeScanToken(token []byte) ([]byte, error) {
+	if len(token) < scanTokenHeaderSize {
+		return nil, fmt.Errorf("%w: expected at least %d bytes, but was %d", ErrInvalidScanToken, scanTokenHeaderSize, len(token))
+	}
+	if token[0] != scanTokenVersion {
+		return nil, fmt.Errorf("%w: unsupported version %d", ErrInvalidScanToken, token[0])
+	}
+	key := token[scanTokenHeaderSize:]
+	if binary.BigEndian.Uint32(token[1:scanTokenHeaderSize]) != crc32.ChecksumIEEE(key) {
+		return nil, fmt.Errorf("%w: checksum mismatch", ErrInvalidScanToken)
+	}
+	return key, nil
+}
+
+// ScanFrom returns a page of up to limit records in key order together with an opaque token to fetch the next page.
+// An empty token starts at the first key of the table, any other token continues at the first key after the last key
+// of the page that returned it. The returned token is nil once the table has no more records. Tokens only encode
+// the key, they stay valid across reopens of the table and can be used with any reader on a table with the same key
+// order. Malformed tokens return an error wrapping ErrInvalidScanToken.
+func (reader *SSTableReader) ScanFrom(token []byte, limit int) ([]KV, []byte, error) {
+	if limit <= 0 {
+		return nil, nil, fmt.Errorf("error in sstable '%s' in ScanFrom: limit must be positive, but was %d", reader.opts.basePath, limit)
+	}
+
+	// unlike Scan, this reads through the shared data file and doesn't open a new file for every page
+	var lastKey []byte
+	var keyIterator skiplist.IteratorI[[]byte, IndexVal]
+	var err error
+	if len(token) == 0 {
+		keyIterator, err = reader.index.Iterator()
+	} else {
+		lastKey, err = decodeScanToken(token)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error in sstable '%s' in ScanFrom: %w", reader.opts.basePath, err)
+		}
+		keyIterator, err = reader.index.IteratorStartingAt(lastKey)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("error in sstable '%s' in ScanFrom: %w", reader.opts.basePath, err)
+	}
+	it := reader.filterVersions(&SSTableIterator{reader: reader, keyIterator: keyIterator})
+
+	var page []KV
+	for len(page) < limit {
+		key, value, err := it.Next()
+		if errors.Is(err, Done) {
+			return page, nil, nil
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		// the page before already returned the key of the token
+		if lastKey != nil && reader.opts.keyComparator.Compare(key, lastKey) <= 0 {
+			continue
+		}
+		page = append(page, KV{Key: slices.Clone(key), Value: value})
+	}
+
+	// don't hand out a token when the page ended exactly at the end of the table
+	if _, _, err := it.Next(); errors.Is(err, Done) {
+		return page, nil, nil
+	} else if err != nil {
+		return nil, nil, err
+	}
+
+	return page, encodeScanToken(page[len(page)-1].Key), nil
+}
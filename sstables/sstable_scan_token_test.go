@@ -0,0 +1,118 @@
+package sstables
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScanFromPages(t *testing.T) {
+	writer, err := newTestSSTableStreamWriter()
+	require.NoError(t, err)
+	defer cleanWriterDir(t, writer)
+	expected := streamedWriteAscendingIntegers(t, writer, 95)
+
+	for _, loaderFunc := range indexLoaders {
+		var actual []int
+		var token []byte
+		for pages := 0; ; pages++ {
+			// every page gets a new reader to make sure the token survives a reopen
+			r, err := NewSSTableReader(ReadBasePath(writer.opts.basePath), ReadIndexLoader(loaderFunc()))
+			require.NoError(t, err)
+			page, next, err := r.(*SSTableReader).ScanFrom(token, 10)
+			require.NoError(t, err)
+			closeReader(t, r)
+
+			assert.LessOrEqual(t, len(page), 10)
+			for _, kv := range page {
+				_, v := getKeyValueAsBytes(len(actual))
+				assert.Equal(t, v, kv.Value)
+				actual = append(actual, len(actual))
+			}
+			if next == nil {
+				assert.Equal(t, 9, pages)
+				break
+			}
+			token = next
+		}
+		assert.Equal(t, expected, actual)
+	}
+}
+
+func TestScanFromPageEndsAtLastKey(t *testing.T) {
+	writer, err := newTestSSTableStreamWriter()
+	require.NoError(t, err)
+	defer cleanWriterDir(t, writer)
+	streamedWriteAscendingIntegers(t, writer, 20)
+
+	r, err := NewSSTableReader(ReadBasePath(writer.opts.basePath))
+	require.NoError(t, err)
+	defer closeReader(t, r)
+	reader := r.(*SSTableReader)
+
+	page, next, err := reader.ScanFrom(nil, 10)
+	require.NoError(t, err)
+	require.Len(t, page, 10)
+	require.NotNil(t, next)
+
+	page, next, err = reader.ScanFrom(next, 10)
+	require.NoError(t, err)
+	require.Len(t, page, 10)
+	k, _ := getKeyValueAsBytes(19)
+	assert.Equal(t, k, page[9].Key)
+	assert.Nil(t, next)
+
+	// a token after the last key returns an empty page
+	page, next, err = reader.ScanFrom(encodeScanToken(k), 10)
+	require.NoError(t, err)
+	assert.Empty(t, page)
+	assert.Nil(t, next)
+}
+
+func TestScanFromTokenBetweenKeys(t *testing.T) {
+	writer, err := newTestSSTableStreamWriter()
+	require.NoError(t, err)
+	defer cleanWriterDir(t, writer)
+	streamedWriteAscendingIntegers(t, writer, 20)
+
+	r, err := NewSSTableReader(ReadBasePath(writer.opts.basePath))
+	require.NoError(t, err)
+	defer closeReader(t, r)
+
+	k, _ := getKeyValueAsBytes(4)
+	page, _, err := r.(*SSTableReader).ScanFrom(encodeScanToken(append(k, 0)), 2)
+	require.NoError(t, err)
+	require.Len(t, page, 2)
+	k5, _ := getKeyValueAsBytes(5)
+	k6, _ := getKeyValueAsBytes(6)
+	assert.Equal(t, k5, page[0].Key)
+	assert.Equal(t, k6, page[1].Key)
+}
+
+func TestScanFromInvalidInput(t *testing.T) {
+	writer, err := newTestSSTableStreamWriter()
+	require.NoError(t, err)
+	defer cleanWriterDir(t, writer)
+	streamedWriteAscendingIntegers(t, writer, 20)
+
+	r, err := NewSSTableReader(ReadBasePath(writer.opts.basePath))
+	require.NoError(t, err)
+	defer closeReader(t, r)
+	reader := r.(*SSTableReader)
+
+	_, _, err = reader.ScanFrom(nil, 0)
+	assert.Error(t, err)
+
+	k, _ := getKeyValueAsBytes(4)
+	valid := encodeScanToken(k)
+	wrongVersion := append([]byte{}, valid...)
+	wrongVersion[0] = 42
+	corrupted := append([]byte{}, valid...)
+	corrupted[len(corrupted)-1] ^= 0xFF
+
+	for _, token := range [][]byte{{1}, {1, 2, 3, 4}, wrongVersion, corrupted, valid[:len(valid)-1]} {
+		_, _, err := reader.ScanFrom(token, 10)
+		assert.ErrorIs(t, err, ErrInvalidScanToken)
+	}
+}
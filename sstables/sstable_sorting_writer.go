@@ -0,0 +1,185 @@
+package sstables
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/thomasjungblut/go-sstables/recordio"
+	"golang.org/x/exp/slices"
+)
+
+type sortingRecord struct {
+	key   []byte
+	value []byte
+}
+
+// SortingSSTableWriter writes a table from records in any order, which is an external sort with bounded memory.
+// Records are buffered until SpillThresholdBytes is reached, then they are sorted and spilled as a temporary table into
+// TempDir. Closing the writer merges all of those runs into the output table and removes them. When the records fit
+// into the buffer, they are sorted in memory and written directly. Like with the SSTableStreamWriter, keys must be unique
+// unless a combiner is set with WithCombiner.
+type SortingSSTableWriter struct {
+	writer *SSTableStreamWriter
+
+	buffer      []sortingRecord
+	bufferBytes int
+	// runs are the base paths of the spilled temporary tables
+	runs []string
+}
+
+func (s *SortingSSTableWriter) Open() error {
+	return s.writer.Open()
+}
+
+// WriteNext buffers the record, the key and value are copied so the caller can reuse them.
+func (s *SortingSSTableWriter) WriteNext(key []byte, value []byte) error {
+	record := sortingRecord{key: slices.Clone(key)}
+	if value != nil {
+		record.value = slices.Clone(value)
+	}
+	s.buffer = append(s.buffer, record)
+	s.bufferBytes += len(key) + len(value)
+
+	if s.bufferBytes >= s.writer.opts.spillThresholdBytes {
+		return s.spill()
+	}
+	return nil
+}
+
+// spill sorts the buffer and writes it as a new temporary table
+func (s *SortingSSTableWriter) spill() (err error) {
+	runPath, err := os.MkdirTemp(s.writer.opts.tempDir, "sstables_sort_run")
+	if err != nil {
+		return fmt.Errorf("error while creating sort run directory for '%s': %w", s.writer.opts.basePath, err)
+	}
+	s.runs = append(s.runs, runPath)
+
+	runWriter, err := NewSSTableStreamWriter(
+		WriteBasePath(runPath),
+		WithKeyComparator(s.writer.opts.keyComparator),
+		DataCompressionType(recordio.CompressionTypeNone),
+		BloomExpectedNumberOfElements(uint64(len(s.buffer))))
+	if err != nil {
+		return fmt.Errorf("error while creating sort run in '%s': %w", runPath, err)
+	}
+
+	err = runWriter.Open()
+	if err != nil {
+		return fmt.Errorf("error while opening sort run in '%s': %w", runPath, err)
+	}
+
+	defer func() {
+		err = errors.Join(err, runWriter.Close())
+	}()
+
+	return s.writeSortedBuffer(runWriter)
+}
+
+// writeSortedBuffer sorts the buffer, writes it into the given writer and resets the buffer
+func (s *SortingSSTableWriter) writeSortedBuffer(writer SSTableStreamWriterI) error {
+	cmp := s.writer.opts.keyComparator
+	slices.SortStableFunc(s.buffer, func(a, b sortingRecord) int {
+		return cmp.Compare(a.key, b.key)
+	})
+
+	combiner := s.writer.opts.combiner
+	for i := 0; i < len(s.buffer); i++ {
+		record := s.buffer[i]
+		// the sort is stable, so the values of a key are combined in the order they were written
+		for combiner != nil && i+1 < len(s.buffer) && cmp.Compare(record.key, s.buffer[i+1].key) == 0 {
+			i++
+			record.value = combiner(record.key, record.value, s.buffer[i].value)
+		}
+
+		if err := writer.WriteNext(record.key, record.value); err != nil {
+			return err
+		}
+	}
+
+	s.buffer = s.buffer[:0]
+	s.bufferBytes = 0
+	return nil
+}
+
+// Close writes all records into the output table and closes it, the temporary tables are always removed.
+func (s *SortingSSTableWriter) Close() (err error) {
+	defer func() {
+		for _, run := range s.runs {
+			err = errors.Join(err, os.RemoveAll(run))
+		}
+		s.runs = nil
+		err = errors.Join(err, s.writer.Close())
+	}()
+
+	if len(s.runs) == 0 {
+		return s.writeSortedBuffer(s.writer)
+	}
+
+	if len(s.buffer) > 0 {
+		if err := s.spill(); err != nil {
+			return err
+		}
+	}
+
+	var readers []SSTableReaderI
+	defer func() {
+		for _, reader := range readers {
+			err = errors.Join(err, reader.Close())
+		}
+	}()
+
+	var iterators []SSTableMergeIteratorContext
+	for i, run := range s.runs {
+		reader, err := NewSSTableReader(ReadBasePath(run), ReadWithKeyComparator(s.writer.opts.keyComparator))
+		if err != nil {
+			return fmt.Errorf("error while opening sort run in '%s': %w", run, err)
+		}
+		readers = append(readers, reader)
+
+		it, err := reader.Scan()
+		if err != nil {
+			return fmt.Errorf("error while scanning sort run in '%s': %w", run, err)
+		}
+		iterators = append(iterators, NewMergeIteratorContext(i, it))
+	}
+
+	merger := NewSSTableMerger(s.writer.opts.keyComparator)
+	if s.writer.opts.combiner == nil {
+		return merger.Merge(iterators, s.writer)
+	}
+	return merger.MergeCompact(iterators, s.writer, s.combineRuns)
+}
+
+// combineRuns combines the values of a key across runs in the order the runs were spilled
+func (s *SortingSSTableWriter) combineRuns(key []byte, values [][]byte, context []int) ([]byte, []byte) {
+	order := make([]int, len(values))
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortFunc(order, func(a, b int) int {
+		return context[a] - context[b]
+	})
+
+	value := values[order[0]]
+	for _, i := range order[1:] {
+		value = s.writer.opts.combiner(key, value, values[i])
+	}
+	return key, value
+}
+
+// NewSortingSSTableWriter creates a writer that accepts records in any order, see SortingSSTableWriter.
+// The writer options apply to the output table, the buffer and the temporary tables are configured with
+// SpillThresholdBytes and TempDir.
+func NewSortingSSTableWriter(writerOptions ...WriterOption) (*SortingSSTableWriter, error) {
+	writer, err := NewSSTableStreamWriter(writerOptions...)
+	if err != nil {
+		return nil, err
+	}
+
+	if writer.opts.spillThresholdBytes <= 0 {
+		return nil, fmt.Errorf("unexpected spill threshold, was: %d", writer.opts.spillThresholdBytes)
+	}
+
+	return &SortingSSTableWriter{writer: writer}, nil
+}
@@ -0,0 +1,177 @@
+package sstables
+
+import (
+	"encoding/binary"
+	"math/rand"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thomasjungblut/go-sstables/skiplist"
+	"golang.org/x/exp/slices"
+)
+
+func newTestSortingSSTableWriter(t *testing.T, spillThresholdBytes int) (*SortingSSTableWriter, string) {
+	tmpDir, err := os.MkdirTemp("", "sstables_SortingWriter")
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, os.RemoveAll(tmpDir)) })
+	runDir := t.TempDir()
+
+	writer, err := NewSortingSSTableWriter(
+		WriteBasePath(tmpDir),
+		WithKeyComparator(skiplist.BytesComparator{}),
+		SpillThresholdBytes(spillThresholdBytes),
+		TempDir(runDir))
+	require.NoError(t, err)
+	return writer, runDir
+}
+
+func TestSortingWriterExternalSort(t *testing.T) {
+	for _, threshold := range []int{64 * 1024 * 1024, 1024, 1} {
+		writer, runDir := newTestSortingSSTableWriter(t, threshold)
+		require.NoError(t, writer.Open())
+
+		n := 1000
+		for _, i := range rand.New(rand.NewSource(42)).Perm(n) {
+			k, v := getKeyValueAsBytes(i)
+			require.NoError(t, writer.WriteNext(k, v))
+			if threshold == 1024 {
+				assert.LessOrEqual(t, writer.bufferBytes, threshold)
+			}
+		}
+		if threshold < 64*1024*1024 {
+			assert.NotEmpty(t, writer.runs)
+		}
+		require.NoError(t, writer.Close())
+
+		entries, err := os.ReadDir(runDir)
+		require.NoError(t, err)
+		assert.Empty(t, entries)
+
+		reader, it := getFullScanIterator(t, writer.writer.opts.basePath)
+		assert.Equal(t, uint64(n), reader.MetaData().NumRecords)
+		for i := 0; i < n; i++ {
+			actualKey, actualValue, err := it.Next()
+			require.NoError(t, err)
+			expectedKey, expectedValue := getKeyValueAsBytes(i)
+			assert.Equal(t, expectedKey, actualKey)
+			assert.Equal(t, expectedValue, actualValue)
+		}
+		_, _, err = it.Next()
+		assert.ErrorIs(t, err, Done)
+		closeReader(t, reader)
+	}
+}
+
+func TestSortingWriterCopiesRecords(t *testing.T) {
+	writer, _ := newTestSortingSSTableWriter(t, 1024)
+	require.NoError(t, writer.Open())
+
+	buf := make([]byte, 4)
+	for i := 3; i >= 0; i-- {
+		copy(buf, intToByteSlice(i))
+		require.NoError(t, writer.WriteNext(buf, buf))
+	}
+	require.NoError(t, writer.Close())
+
+	reader, it := getFullScanIterator(t, writer.writer.opts.basePath)
+	defer closeReader(t, reader)
+	for i := 0; i < 4; i++ {
+		k, v, err := it.Next()
+		require.NoError(t, err)
+		assert.Equal(t, intToByteSlice(i), k)
+		assert.Equal(t, intToByteSlice(i), v)
+	}
+}
+
+func TestSortingWriterDuplicateKeys(t *testing.T) {
+	for _, threshold := range []int{64 * 1024 * 1024, 1} {
+		writer, runDir := newTestSortingSSTableWriter(t, threshold)
+		require.NoError(t, writer.Open())
+		require.NoError(t, writer.WriteNext([]byte{2}, []byte{1}))
+		require.NoError(t, writer.WriteNext([]byte{1}, []byte{1}))
+		require.NoError(t, writer.WriteNext([]byte{2}, []byte{2}))
+		require.ErrorContains(t, writer.Close(), "the same key cannot be written more than once")
+
+		entries, err := os.ReadDir(runDir)
+		require.NoError(t, err)
+		assert.Empty(t, entries)
+	}
+}
+
+func TestSortingWriterRejectsInvalidThreshold(t *testing.T) {
+	_, err := NewSortingSSTableWriter(WriteBasePath("a"), WithKeyComparator(skiplist.BytesComparator{}), SpillThresholdBytes(0))
+	require.Error(t, err)
+}
+
+func TestSortingWriterCombinesDuplicateKeys(t *testing.T) {
+	sum := func(key, a, b []byte) []byte {
+		return binary.BigEndian.AppendUint64(nil, binary.BigEndian.Uint64(a)+binary.BigEndian.Uint64(b))
+	}
+
+	for _, threshold := range []int{64 * 1024 * 1024, 256, 1} {
+		tmpDir, err := os.MkdirTemp("", "sstables_SortingWriterCombiner")
+		require.NoError(t, err)
+		writer, err := NewSortingSSTableWriter(
+			WriteBasePath(tmpDir),
+			WithKeyComparator(skiplist.BytesComparator{}),
+			SpillThresholdBytes(threshold),
+			TempDir(t.TempDir()),
+			WithCombiner(sum))
+		require.NoError(t, err)
+		require.NoError(t, writer.Open())
+
+		expected := make(map[int]uint64)
+		r := rand.New(rand.NewSource(42))
+		for i := 0; i < 1000; i++ {
+			key := r.Intn(50)
+			count := uint64(r.Intn(10))
+			expected[key] += count
+			require.NoError(t, writer.WriteNext(intToByteSlice(key), binary.BigEndian.AppendUint64(nil, count)))
+		}
+		require.NoError(t, writer.Close())
+
+		reader, it := getFullScanIterator(t, tmpDir)
+		assert.Equal(t, uint64(len(expected)), reader.MetaData().NumRecords)
+		for i := 0; i < 50; i++ {
+			k, v, err := it.Next()
+			require.NoError(t, err)
+			assert.Equal(t, intToByteSlice(i), k)
+			assert.Equal(t, expected[i], binary.BigEndian.Uint64(v))
+		}
+		closeReader(t, reader)
+		require.NoError(t, os.RemoveAll(tmpDir))
+	}
+}
+
+func TestSortingWriterCombinesInWriteOrder(t *testing.T) {
+	concat := func(key, a, b []byte) []byte {
+		return append(slices.Clone(a), b...)
+	}
+
+	for _, threshold := range []int{64 * 1024 * 1024, 1} {
+		tmpDir, err := os.MkdirTemp("", "sstables_SortingWriterCombiner")
+		require.NoError(t, err)
+		writer, err := NewSortingSSTableWriter(
+			WriteBasePath(tmpDir),
+			WithKeyComparator(skiplist.BytesComparator{}),
+			SpillThresholdBytes(threshold),
+			TempDir(t.TempDir()),
+			WithCombiner(concat))
+		require.NoError(t, err)
+		require.NoError(t, writer.Open())
+		for _, v := range []string{"a", "b", "c", "d", "e"} {
+			require.NoError(t, writer.WriteNext([]byte{1}, []byte(v)))
+		}
+		require.NoError(t, writer.Close())
+
+		reader, err := NewSSTableReader(ReadBasePath(tmpDir))
+		require.NoError(t, err)
+		v, err := reader.Get([]byte{1})
+		require.NoError(t, err)
+		assert.Equal(t, []byte("abcde"), v)
+		closeReader(t, reader)
+		require.NoError(t, os.RemoveAll(tmpDir))
+	}
+}
@@ -0,0 +1,183 @@
+package sstables
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/thomasjungblut/go-sstables/recordio"
+	"github.com/thomasjungblut/go-sstables/sstables/proto"
+	"golang.org/x/exp/slices"
+	pb "google.golang.org/protobuf/proto"
+)
+
+// ManifestFileName is the file in the base path of a SplittingSSTableWriter that lists all of its tables
+var ManifestFileName = "manifest.pb.bin"
+
+// SplittingSSTableWriter writes ascending records into a sequence of tables, each of them a valid standalone table in a
+// numbered directory below the base path: table_000, table_001 and so on. A new table is started before a record
+// would grow the data file of the current table beyond WriteMaxBytesPerTable, so every key is in exactly one table.
+// The size of a record is estimated from its uncompressed value, a single record that exceeds the limit on its own is
+// written into a table of its own. Close writes the manifest with the key range of every table into ManifestFileName.
+type SplittingSSTableWriter struct {
+	opts          *SSTableWriterOptions
+	writerOptions []WriterOption
+
+	current  *SSTableStreamWriter
+	manifest *proto.Manifest
+}
+
+func (s *SplittingSSTableWriter) Open() error {
+	s.manifest = &proto.Manifest{}
+	return s.openNextTable()
+}
+
+// WriteNext writes the record into the current table, or into a new table when it would exceed the size limit.
+func (s *SplittingSSTableWriter) WriteNext(key []byte, value []byte) error {
+	if s.current == nil {
+		return fmt.Errorf("sstables.WriteNext '%s': writer is not open", s.opts.basePath)
+	}
+
+	// the order is checked against the previous table before rolling over
+	err := s.current.checkKeyOrder(key, 0)
+	if err != nil {
+		return err
+	}
+
+	recordBytes := uint64(len(value) + recordio.RecordHeaderV3MaxSizeBytes)
+	if s.current.metaData.NumRecords > 0 && s.current.dataWriter.Size()+recordBytes > uint64(s.opts.maxBytesPerTable) {
+		err = s.closeCurrentTable()
+		if err != nil {
+			return err
+		}
+		err = s.openNextTable()
+		if err != nil {
+			return err
+		}
+	}
+
+	return s.current.WriteNext(key, value)
+}
+
+// Close closes the last table and writes the manifest.
+func (s *SplittingSSTableWriter) Close() error {
+	if s.current == nil {
+		return fmt.Errorf("sstables.Close '%s': writer is not open", s.opts.basePath)
+	}
+
+	err := s.closeCurrentTable()
+	if err != nil {
+		return err
+	}
+
+	content, err := pb.Marshal(s.manifest)
+	if err != nil {
+		return fmt.Errorf("error while marshalling manifest in '%s': %w", s.opts.basePath, err)
+	}
+
+	err = os.WriteFile(filepath.Join(s.opts.basePath, ManifestFileName), content, 0666)
+	if err != nil {
+		return fmt.Errorf("error while writing manifest in '%s': %w", s.opts.basePath, err)
+	}
+	return nil
+}
+
+// Manifest returns the tables that were closed so far, all of them once the writer is closed.
+func (s *SplittingSSTableWriter) Manifest() *proto.Manifest {
+	return s.manifest
+}
+
+func (s *SplittingSSTableWriter) openNextTable() error {
+	name := fmt.Sprintf("table_%03d", len(s.manifest.Tables))
+	path := filepath.Join(s.opts.basePath, name)
+	err := os.Mkdir(path, 0755)
+	if err != nil {
+		return fmt.Errorf("error while creating table directory '%s': %w", path, err)
+	}
+
+	writer, err := NewSSTableStreamWriter(append(slices.Clone(s.writerOptions), WriteBasePath(path))...)
+	if err != nil {
+		return fmt.Errorf("error while creating writer for table '%s': %w", path, err)
+	}
+
+	err = writer.Open()
+	if err != nil {
+		return err
+	}
+
+	s.current = writer
+	s.manifest.Tables = append(s.manifest.Tables, &proto.ManifestEntry{Path: name})
+	return nil
+}
+
+// closeCurrentTable closes the current table and records its key range in the manifest
+func (s *SplittingSSTableWriter) closeCurrentTable() error {
+	writer := s.current
+	s.current = nil
+	err := writer.Close()
+	if err != nil {
+		return err
+	}
+
+	entry := s.manifest.Tables[len(s.manifest.Tables)-1]
+	entry.MinKey = writer.metaData.MinKey
+	entry.MaxKey = writer.metaData.MaxKey
+	entry.NumRecords = writer.metaData.NumRecords
+	entry.DataBytes = writer.metaData.DataBytes
+	return nil
+}
+
+// ReadManifest reads the manifest that a SplittingSSTableWriter wrote into the given base path.
+func ReadManifest(basePath string) (manifest *proto.Manifest, err error) {
+	path := filepath.Join(basePath, ManifestFileName)
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("error while opening manifest in '%s': %w", path, err)
+	}
+	defer func() {
+		err = errors.Join(err, f.Close())
+	}()
+
+	content, err := io.ReadAll(f)
+	if err != nil {
+		return nil, fmt.Errorf("error while reading manifest in '%s': %w", path, err)
+	}
+
+	manifest = &proto.Manifest{}
+	err = pb.Unmarshal(content, manifest)
+	if err != nil {
+		return nil, fmt.Errorf("error while parsing manifest in '%s': %w", path, err)
+	}
+	return manifest, nil
+}
+
+// NewSplittingSSTableWriter creates a writer that splits its records into tables of at most WriteMaxBytesPerTable,
+// see SplittingSSTableWriter. The base path must exist, the writer options apply to every table.
+func NewSplittingSSTableWriter(writerOptions ...WriterOption) (*SplittingSSTableWriter, error) {
+	// the options are validated once upfront, every table is created with the same options and its own base path
+	writer, err := NewSSTableStreamWriter(writerOptions...)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := writer.opts
+	if opts.maxBytesPerTable <= 0 {
+		return nil, fmt.Errorf("unexpected max bytes per table, was: %d", opts.maxBytesPerTable)
+	}
+
+	if opts.estimateOnly {
+		return nil, errors.New("split tables can't be estimated")
+	}
+
+	supplied := &SSTableWriterOptions{}
+	for _, writeOption := range writerOptions {
+		writeOption(supplied)
+	}
+	if supplied.stagingPath != "" {
+		return nil, errors.New("split tables can't share a staging path, use WriteAtomic without WriteStagingPath")
+	}
+
+	return &SplittingSSTableWriter{opts: opts, writerOptions: writerOptions}, nil
+}
@@ -0,0 +1,117 @@
+package sstables
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thomasjungblut/go-sstables/skiplist"
+	pb "google.golang.org/protobuf/proto"
+)
+
+func newTestSplittingSSTableWriter(t *testing.T, opts ...WriterOption) *SplittingSSTableWriter {
+	tmpDir, err := os.MkdirTemp("", "sstables_SplitWriter")
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, os.RemoveAll(tmpDir)) })
+
+	writer, err := NewSplittingSSTableWriter(append([]WriterOption{
+		WriteBasePath(tmpDir), WithKeyComparator(skiplist.BytesComparator{})}, opts...)...)
+	require.NoError(t, err)
+	return writer
+}
+
+func TestSplittingWriter(t *testing.T) {
+	const maxBytes = 2048
+	writer := newTestSplittingSSTableWriter(t, WriteMaxBytesPerTable(maxBytes), EnableBloomFilter())
+	require.NoError(t, writer.Open())
+	for i := 0; i < 1000; i++ {
+		k, v := getKeyValueAsBytes(i)
+		require.NoError(t, writer.WriteNext(k, v))
+	}
+	require.NoError(t, writer.Close())
+
+	manifest, err := ReadManifest(writer.opts.basePath)
+	require.NoError(t, err)
+	assert.True(t, pb.Equal(writer.Manifest(), manifest))
+	require.Greater(t, len(manifest.Tables), 1)
+
+	next := 0
+	for i, entry := range manifest.Tables {
+		assert.Equal(t, fmt.Sprintf("table_%03d", i), entry.Path)
+		assert.LessOrEqual(t, entry.DataBytes, uint64(maxBytes))
+		if i > 0 {
+			assert.Less(t, bytes.Compare(manifest.Tables[i-1].MaxKey, entry.MinKey), 0)
+		}
+
+		reader, err := NewSSTableReader(ReadBasePath(filepath.Join(writer.opts.basePath, entry.Path)))
+		require.NoError(t, err)
+		assert.Equal(t, entry.NumRecords, reader.MetaData().NumRecords)
+		assert.Equal(t, entry.MinKey, reader.MetaData().MinKey)
+		assert.Equal(t, entry.MaxKey, reader.MetaData().MaxKey)
+
+		var expected []int
+		for j := 0; j < int(entry.NumRecords); j++ {
+			expected = append(expected, next+j)
+		}
+		next += int(entry.NumRecords)
+		it, err := reader.Scan()
+		require.NoError(t, err)
+		assertIteratorMatchesSlice(t, it, expected)
+		closeReader(t, reader)
+	}
+	assert.Equal(t, 1000, next)
+}
+
+func TestSplittingWriterLargeRecord(t *testing.T) {
+	writer := newTestSplittingSSTableWriter(t, WriteMaxBytesPerTable(64))
+	require.NoError(t, writer.Open())
+	require.NoError(t, writer.WriteNext([]byte{1}, []byte{1}))
+	require.NoError(t, writer.WriteNext([]byte{2}, make([]byte, 1024)))
+	require.NoError(t, writer.WriteNext([]byte{3}, []byte{1}))
+	require.NoError(t, writer.Close())
+
+	manifest := writer.Manifest()
+	require.Len(t, manifest.Tables, 3)
+	for i, entry := range manifest.Tables {
+		assert.Equal(t, uint64(1), entry.NumRecords)
+		assert.Equal(t, []byte{byte(i + 1)}, entry.MinKey)
+		assert.Equal(t, []byte{byte(i + 1)}, entry.MaxKey)
+	}
+}
+
+func TestSplittingWriterKeyOrderAcrossTables(t *testing.T) {
+	writer := newTestSplittingSSTableWriter(t, WriteMaxBytesPerTable(64))
+	require.NoError(t, writer.Open())
+	require.NoError(t, writer.WriteNext([]byte{5}, make([]byte, 32)))
+	// the record would start a new table, but the key is lower than the last one
+	assert.ErrorContains(t, writer.WriteNext([]byte{3}, make([]byte, 32)), "non-ascending key")
+	assert.ErrorContains(t, writer.WriteNext([]byte{5}, make([]byte, 32)), "more than once")
+	require.NoError(t, writer.Close())
+	assert.Len(t, writer.Manifest().Tables, 1)
+}
+
+func TestSplittingWriterEmpty(t *testing.T) {
+	writer := newTestSplittingSSTableWriter(t, WriteMaxBytesPerTable(64))
+	require.NoError(t, writer.Open())
+	require.NoError(t, writer.Close())
+
+	manifest, err := ReadManifest(writer.opts.basePath)
+	require.NoError(t, err)
+	require.Len(t, manifest.Tables, 1)
+	assert.Equal(t, uint64(0), manifest.Tables[0].NumRecords)
+}
+
+func TestSplittingWriterOptions(t *testing.T) {
+	_, err := NewSplittingSSTableWriter(WriteBasePath("a"), WithKeyComparator(skiplist.BytesComparator{}))
+	assert.ErrorContains(t, err, "unexpected max bytes per table")
+	_, err = NewSplittingSSTableWriter(WriteBasePath("a"), WithKeyComparator(skiplist.BytesComparator{}),
+		WriteMaxBytesPerTable(64), WriteStagingPath("b"))
+	assert.ErrorContains(t, err, "staging path")
+
+	_, err = ReadManifest("test_files/SimpleWriteHappyPathSSTable")
+	assert.Error(t, err)
+}
@@ -49,7 +49,7 @@ func TestReadStreamedWriteEndToEndCheckMetadata(t *testing.T) {
 	defer closeReader(t, reader)
 
 	// check the metadata is accurate
-	assert.Equal(t, 1, int(reader.MetaData().Version))
+	assert.Equal(t, 2, int(reader.MetaData().Version))
 	assert.Equal(t, len(expectedNumbers), int(reader.MetaData().NumRecords))
 	assert.Equal(t, 12008, int(reader.MetaData().DataBytes))
 	// depending on how well protobuf can vint compress the checksums, we end up with more or less bytes
@@ -145,6 +145,219 @@ func TestNilEmptyReadAndWrites(t *testing.T) {
 	require.Equal(t, Done, err)
 }
 
+func TestNilEmptyAndNonEmptyValuesRoundTrip(t *testing.T) {
+	for _, compressionType := range []int{recordio.CompressionTypeNone, recordio.CompressionTypeSnappy, recordio.CompressionTypeGZIP} {
+		writer, err := newTestSSTableStreamWriterWithDataCompression(compressionType)
+		require.NoError(t, err)
+		defer cleanWriterDir(t, writer)
+
+		require.NoError(t, writer.Open())
+		require.NoError(t, writer.WriteNext([]byte("akey"), nil))
+		require.NoError(t, writer.WriteNext([]byte("bkey"), []byte{}))
+		require.NoError(t, writer.WriteNext([]byte("ckey"), []byte{1}))
+		require.NoError(t, writer.Close())
+
+		for _, loaderFunc := range indexLoaders {
+			loader := loaderFunc()
+			t.Run(fmt.Sprintf("%d_%s", compressionType, reflect.TypeOf(loader).String()), func(t *testing.T) {
+				reader, err := NewSSTableReader(
+					ReadBasePath(writer.opts.basePath),
+					ReadIndexLoader(loader))
+				require.NoError(t, err)
+				defer closeReader(t, reader)
+				assert.Equal(t, uint64(1), reader.MetaData().NullValues)
+
+				idx := reader.(*SSTableReader).index
+				iv, err := idx.Get([]byte("akey"))
+				require.NoError(t, err)
+				assert.True(t, iv.NullValue)
+				iv, err = idx.Get([]byte("bkey"))
+				require.NoError(t, err)
+				assert.False(t, iv.NullValue)
+
+				v, err := reader.Get([]byte("akey"))
+				require.NoError(t, err)
+				require.Nil(t, v)
+				v, err = reader.Get([]byte("bkey"))
+				require.NoError(t, err)
+				require.NotNil(t, v)
+				require.Equal(t, []byte{}, v)
+				v, err = reader.Get([]byte("ckey"))
+				require.NoError(t, err)
+				require.Equal(t, []byte{1}, v)
+
+				it, err := reader.Scan()
+				require.NoError(t, err)
+				assertNilEmptyNonEmptyIterator(t, it)
+
+				it, err = reader.ScanRange([]byte("akey"), []byte("ckey"))
+				require.NoError(t, err)
+				assertNilEmptyNonEmptyIterator(t, it)
+			})
+		}
+	}
+}
+
+func TestContentHashIndependentOfCompression(t *testing.T) {
+	writeWithValues := func(compressionType int, values map[int][]byte) *SSTableReader {
+		writer, err := newTestSSTableStreamWriterWithDataCompression(compressionType)
+		require.NoError(t, err)
+		t.Cleanup(func() { cleanWriterDir(t, writer) })
+		require.NoError(t, writer.Open())
+		for i := 0; i < 100; i++ {
+			key, value := getKeyValueAsBytes(i)
+			if v, ok := values[i]; ok {
+				value = v
+			}
+			require.NoError(t, writer.WriteNext(key, value))
+		}
+		require.NoError(t, writer.Close())
+
+		reader, err := NewSSTableReader(ReadBasePath(writer.opts.basePath))
+		require.NoError(t, err)
+		t.Cleanup(func() { closeReader(t, reader) })
+		return reader.(*SSTableReader)
+	}
+
+	none := writeWithValues(recordio.CompressionTypeNone, nil)
+	snappy := writeWithValues(recordio.CompressionTypeSnappy, nil)
+	gzip := writeWithValues(recordio.CompressionTypeGZIP, nil)
+	assert.NotZero(t, none.ContentHash())
+	assert.Equal(t, none.ContentHash(), snappy.ContentHash())
+	assert.Equal(t, none.ContentHash(), gzip.ContentHash())
+
+	changedValue := writeWithValues(recordio.CompressionTypeNone, map[int][]byte{50: {1}})
+	assert.NotEqual(t, none.ContentHash(), changedValue.ContentHash())
+
+	nilValue := writeWithValues(recordio.CompressionTypeNone, map[int][]byte{50: nil})
+	emptyValue := writeWithValues(recordio.CompressionTypeNone, map[int][]byte{50: {}})
+	assert.NotEqual(t, nilValue.ContentHash(), emptyValue.ContentHash())
+}
+
+func TestCustomFileNamesShareDirectory(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "sstables_FileNames")
+	require.NoError(t, err)
+	defer func() { require.NoError(t, os.RemoveAll(tmpDir)) }()
+
+	for i, prefix := range []string{"a_", "b_"} {
+		writer, err := NewSSTableStreamWriter(
+			WriteBasePath(tmpDir),
+			WithKeyComparator(skiplist.BytesComparator{}),
+			WithIndexFileName(prefix+IndexFileName),
+			WithDataFileName(prefix+DataFileName),
+			WithBloomFileName(prefix+BloomFileName),
+			WithMetaFileName(prefix+MetaFileName),
+			WithCommittedFileName(prefix+CommittedFileName))
+		require.NoError(t, err)
+		streamedWriteAscendingIntegersWithStart(t, writer, i*10, i*10+10)
+	}
+
+	entries, err := os.ReadDir(tmpDir)
+	require.NoError(t, err)
+	assert.Equal(t, 10, len(entries))
+
+	for i, prefix := range []string{"a_", "b_"} {
+		reader, err := NewSSTableReader(
+			ReadBasePath(tmpDir),
+			ReadIndexFileName(prefix+IndexFileName),
+			ReadDataFileName(prefix+DataFileName),
+			ReadBloomFileName(prefix+BloomFileName),
+			ReadMetaFileName(prefix+MetaFileName),
+			ReadCommittedFileName(prefix+CommittedFileName))
+		require.NoError(t, err)
+		var expected []int
+		for j := i * 10; j < i*10+10; j++ {
+			expected = append(expected, j)
+		}
+		assertContentMatchesSlice(t, reader, expected)
+		assert.Equal(t, uint64(10), reader.MetaData().NumRecords)
+		closeReader(t, reader)
+	}
+}
+
+func TestCustomFileNamesCollisions(t *testing.T) {
+	_, err := NewSSTableStreamWriter(
+		WriteBasePath("abc"),
+		WithKeyComparator(skiplist.BytesComparator{}),
+		WithDataFileName(IndexFileName))
+	assert.ErrorContains(t, err, "file name 'index.rio' is used more than once")
+
+	_, err = NewSSTableStreamWriter(
+		WriteBasePath("abc"),
+		WithKeyComparator(skiplist.BytesComparator{}),
+		WithMetaFileName(""))
+	assert.ErrorContains(t, err, "file names must not be empty")
+
+	_, err = NewSSTableReader(ReadBasePath("abc"), ReadBloomFileName(MetaFileName))
+	assert.ErrorContains(t, err, "file name 'meta.pb.bin' is used more than once")
+}
+
+func TestCompositeComparatorRoundTrip(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "sstables_CompositeComparator")
+	require.NoError(t, err)
+	defer func() { require.NoError(t, os.RemoveAll(tmpDir)) }()
+
+	// a one byte prefix ascending, followed by a four byte timestamp descending
+	cmp, err := skiplist.NewCompositeComparator([]skiplist.FieldSpec{
+		{Offset: 0, Length: 1},
+		{Offset: 1, Length: 4, Descending: true},
+	})
+	require.NoError(t, err)
+	key := func(prefix byte, ts uint32) []byte {
+		return binary.BigEndian.AppendUint32([]byte{prefix}, ts)
+	}
+
+	writer, err := NewSSTableStreamWriter(
+		WriteBasePath(tmpDir),
+		WithKeyComparator(cmp),
+		WithWriteValidator(func(key []byte, value []byte) error {
+			return cmp.Validate(key)
+		}))
+	require.NoError(t, err)
+	require.NoError(t, writer.Open())
+	expected := [][]byte{key(1, 3), key(1, 2), key(1, 1), key(2, 5), key(2, 4)}
+	for _, k := range expected {
+		require.NoError(t, writer.WriteNext(k, k))
+	}
+	assert.Error(t, writer.WriteNext([]byte{3}, nil))
+	require.NoError(t, writer.Close())
+
+	reader, err := NewSSTableReader(ReadBasePath(tmpDir), ReadWithKeyComparator(cmp))
+	require.NoError(t, err)
+	defer closeReader(t, reader)
+
+	for _, k := range expected {
+		v, err := reader.Get(k)
+		require.NoError(t, err)
+		assert.Equal(t, k, v)
+	}
+
+	it, err := reader.ScanRange(key(1, 2), key(2, 5))
+	require.NoError(t, err)
+	for _, e := range expected[1:4] {
+		k, _, err := it.Next()
+		require.NoError(t, err)
+		assert.Equal(t, e, k)
+	}
+	_, _, err = it.Next()
+	assert.ErrorIs(t, err, Done)
+}
+
+func assertNilEmptyNonEmptyIterator(t *testing.T, it SSTableIteratorI) {
+	_, v, err := it.Next()
+	require.NoError(t, err)
+	require.Nil(t, v)
+	_, v, err = it.Next()
+	require.NoError(t, err)
+	require.NotNil(t, v)
+	require.Equal(t, []byte{}, v)
+	_, v, err = it.Next()
+	require.NoError(t, err)
+	require.Equal(t, []byte{1}, v)
+	_, _, err = it.Next()
+	require.Equal(t, Done, err)
+}
+
 func streamedWrite1kElements(t *testing.T, writer *SSTableStreamWriter) []int {
 	return streamedWriteElements(t, writer, 1000)
 }
@@ -0,0 +1,44 @@
+package sstables
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/thomasjungblut/go-sstables/skiplist"
+)
+
+// VerifyBloomFilter checks every key of the index against the bloom filter, which must never rule out a key the table
+// contains. A false negative means the bloom file doesn't belong to the index, for example after a botched rebuild or
+// copy, and makes Get and Contains miss the key. It fails with ErrBloomFilterMismatch and the first offending key.
+// This iterates the whole index, tables without a bloom filter pass. Tables written with BloomKeyTransform can only be
+// verified when the transform is supplied with ReadBloomKeyTransform.
+func (reader *SSTableReader) VerifyBloomFilter() error {
+	if reader.bloomFilter == nil && reader.bloomPartitions == nil {
+		return nil
+	}
+
+	if reader.metaData.BloomKeyTransformed && reader.opts.bloomKeyTransform == nil {
+		return fmt.Errorf("error in sstable '%s' in VerifyBloomFilter: the bloom filter was written with a key transform, "+
+			"which needs to be supplied with ReadBloomKeyTransform", reader.opts.basePath)
+	}
+
+	it, err := reader.index.Iterator()
+	if err != nil {
+		return fmt.Errorf("error in sstable '%s' in VerifyBloomFilter: %w", reader.opts.basePath, err)
+	}
+
+	for {
+		key, _, err := it.Next()
+		if errors.Is(err, skiplist.Done) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("error in sstable '%s' in VerifyBloomFilter: %w", reader.opts.basePath, err)
+		}
+
+		if !reader.bloomMightContain(key) {
+			return fmt.Errorf("error in sstable '%s' in VerifyBloomFilter: key %v is ruled out: %w",
+				reader.opts.basePath, key, ErrBloomFilterMismatch)
+		}
+	}
+}
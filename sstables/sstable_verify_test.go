@@ -0,0 +1,71 @@
+package sstables
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thomasjungblut/go-sstables/skiplist"
+)
+
+func TestVerifyBloomFilter(t *testing.T) {
+	for _, opts := range [][]WriterOption{nil, {BloomPartitionEveryNthKey(100)}, {ScanOnly()}} {
+		writer, err := NewSSTableStreamWriter(append(opts, WriteBasePath(t.TempDir()),
+			WithKeyComparator(skiplist.BytesComparator{}))...)
+		require.NoError(t, err)
+		streamedWriteAscendingIntegers(t, writer, 1000)
+
+		reader, err := NewSSTableReader(ReadBasePath(writer.opts.basePath))
+		require.NoError(t, err)
+		assert.NoError(t, reader.(*SSTableReader).VerifyBloomFilter())
+		closeReader(t, reader)
+	}
+}
+
+func TestVerifyBloomFilterMismatch(t *testing.T) {
+	writer, err := newTestSSTableStreamWriter()
+	require.NoError(t, err)
+	defer cleanWriterDir(t, writer)
+	streamedWriteAscendingIntegers(t, writer, 1000)
+
+	other, err := newTestSSTableStreamWriter()
+	require.NoError(t, err)
+	defer cleanWriterDir(t, other)
+	streamedWriteAscendingIntegersWithStart(t, other, 5000, 6000)
+
+	// replace the bloom filter with the one of another table, as a botched rebuild would
+	bloom, err := os.ReadFile(filepath.Join(other.opts.basePath, BloomFileName))
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(writer.opts.basePath, BloomFileName), bloom, 0644))
+
+	reader, err := NewSSTableReader(ReadBasePath(writer.opts.basePath))
+	require.NoError(t, err)
+	defer closeReader(t, reader)
+	assert.ErrorIs(t, reader.(*SSTableReader).VerifyBloomFilter(), ErrBloomFilterMismatch)
+}
+
+func TestVerifyBloomFilterKeyTransform(t *testing.T) {
+	row := func(key []byte) []byte { return key[:1] }
+	writer, err := NewSSTableStreamWriter(WriteBasePath(t.TempDir()), WithKeyComparator(skiplist.BytesComparator{}),
+		BloomKeyTransform(row))
+	require.NoError(t, err)
+	require.NoError(t, writer.Open())
+	for r := byte(0); r < 100; r += 2 {
+		for c := byte(0); c < 10; c++ {
+			require.NoError(t, writer.WriteNext([]byte{r, c}, []byte{r, c}))
+		}
+	}
+	require.NoError(t, writer.Close())
+
+	reader, err := NewSSTableReader(ReadBasePath(writer.opts.basePath))
+	require.NoError(t, err)
+	assert.ErrorContains(t, reader.(*SSTableReader).VerifyBloomFilter(), "ReadBloomKeyTransform")
+	closeReader(t, reader)
+
+	reader, err = NewSSTableReader(ReadBasePath(writer.opts.basePath), ReadBloomKeyTransform(row))
+	require.NoError(t, err)
+	defer closeReader(t, reader)
+	assert.NoError(t, reader.(*SSTableReader).VerifyBloomFilter())
+}
@@ -1,13 +1,20 @@
 package sstables
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"hash"
 	"hash/crc64"
 	"hash/fnv"
+	"io"
 	"os"
 	"path/filepath"
+	"runtime"
+	"syscall"
+	"time"
 
+	"github.com/ncw/directio"
 	"github.com/steakknife/bloomfilter"
 	"github.com/thomasjungblut/go-sstables/recordio"
 	rProto "github.com/thomasjungblut/go-sstables/recordio/proto"
@@ -19,26 +26,54 @@ import (
 type SSTableStreamWriter struct {
 	opts *SSTableWriterOptions
 
+	// dirPath is the directory the files are written into, which is the staging path with WriteAtomic
+	dirPath       string
 	indexFilePath string
 	dataFilePath  string
 	metaFilePath  string
 
-	indexWriter  rProto.WriterI
-	dataWriter   recordio.WriterI
-	metaDataFile *os.File
+	indexWriter   rProto.WriterI
+	dataWriter    recordio.WriterI
+	summaryWriter rProto.WriterI
+	metaDataFile  *os.File
 
 	bloomFilter *bloomfilter.Filter
-	metaData    *sProto.MetaData
+	// bloomPartitions are only written with BloomPartitionEveryNthKey, bloomFilter is the last one of them
+	bloomPartitions   []bloomPartition
+	bloomPartitionErr error
+	metaData          *sProto.MetaData
+	indexChecksum     hash.Hash64
+	contentHash       hash.Hash64
 
-	lastKey []byte
+	lastKey      []byte
+	lastIndexKey []byte
+	// lastSeq is the sequence number of the last key, only used to order the versions of a key
+	lastSeq uint64
+	// streamingValue is true while a value of WriteNextStreaming is still open
+	streamingValue bool
 }
 
 func (writer *SSTableStreamWriter) Open() error {
-	writer.indexFilePath = filepath.Join(writer.opts.basePath, IndexFileName)
-	iWriter, err := rProto.NewWriter(
-		rProto.Path(writer.indexFilePath),
-		rProto.CompressionType(writer.opts.indexCompressionType),
-		rProto.WriteBufferSizeBytes(writer.opts.writeBufferSizeBytes))
+	writer.dirPath = writer.opts.basePath
+	if writer.opts.atomic && !writer.opts.estimateOnly {
+		if err := writer.prepareStaging(); err != nil {
+			return err
+		}
+		writer.dirPath = writer.opts.stagingPath
+	}
+
+	writer.indexFilePath = filepath.Join(writer.dirPath, writer.opts.indexFileName)
+	var iWriter rProto.WriterI
+	var err error
+	if writer.opts.estimateOnly {
+		iWriter, err = rProto.NewSizeEstimatorWithCompressionLevel(writer.opts.indexCompressionType, writer.opts.compressionLevel)
+	} else {
+		iWriter, err = rProto.NewWriter(
+			rProto.Path(writer.indexFilePath),
+			rProto.CompressionType(writer.opts.indexCompressionType),
+			rProto.CompressionLevel(writer.opts.compressionLevel),
+			rProto.WriteBufferSizeBytes(writer.opts.indexWriteBufferSizeBytes))
+	}
 	if err != nil {
 		return fmt.Errorf("error while creating index writer in '%s': %w", writer.opts.basePath, err)
 	}
@@ -49,12 +84,31 @@ func (writer *SSTableStreamWriter) Open() error {
 		return fmt.Errorf("error while opening index writer in '%s': %w", writer.opts.basePath, err)
 	}
 
-	writer.dataFilePath = filepath.Join(writer.opts.basePath, DataFileName)
-	dWriter, err := recordio.NewFileWriter(
-		recordio.Path(writer.dataFilePath),
+	writer.dataFilePath = filepath.Join(writer.dirPath, writer.opts.dataFileName)
+	var dWriter recordio.WriterI
+	dataOpts := []recordio.FileWriterOption{
 		recordio.CompressionType(writer.opts.dataCompressionType),
-		recordio.BufferSizeBytes(writer.opts.writeBufferSizeBytes))
+		recordio.CompressionLevel(writer.opts.compressionLevel),
+		recordio.MinCompressSizeBytes(writer.opts.minCompressSizeBytes),
+	}
+	if writer.opts.hasSchemaID {
+		dataOpts = append(dataOpts, recordio.SchemaID(writer.opts.schemaID))
+	}
+	if writer.opts.estimateOnly {
+		dWriter, err = recordio.NewSizeEstimatorWithOptions(dataOpts...)
+	} else {
+		dataOpts = append(dataOpts,
+			recordio.Path(writer.dataFilePath),
+			recordio.BufferSizeBytes(writer.opts.dataWriteBufferSizeBytes))
+		if writer.useDirectIO() {
+			dataOpts = append(dataOpts, recordio.DirectIO())
+		}
+		dWriter, err = recordio.NewFileWriter(dataOpts...)
+	}
 	if err != nil {
+		if writer.useDirectIO() && errors.Is(err, syscall.EINVAL) {
+			return fmt.Errorf("error while creating data writer in '%s', the filesystem does not support direct IO (O_DIRECT): %w", writer.opts.basePath, err)
+		}
 		return fmt.Errorf("error while creating data writer in '%s': %w", writer.opts.basePath, err)
 	}
 
@@ -65,17 +119,37 @@ func (writer *SSTableStreamWriter) Open() error {
 		return fmt.Errorf("error while opening data writer in '%s': %w", writer.opts.basePath, err)
 	}
 
-	writer.metaFilePath = filepath.Join(writer.opts.basePath, MetaFileName)
-	metaFile, err := os.OpenFile(writer.metaFilePath, os.O_WRONLY|os.O_CREATE, 0666)
-	if err != nil {
-		return fmt.Errorf("error while opening metadata file in '%s': %w", writer.opts.basePath, err)
+	if writer.opts.summaryEveryNthKey > 0 && !writer.opts.estimateOnly {
+		sWriter, err := rProto.NewWriter(
+			rProto.Path(filepath.Join(writer.dirPath, writer.opts.summaryFileName)),
+			rProto.WriteBufferSizeBytes(writer.opts.writeBufferSizeBytes))
+		if err != nil {
+			return fmt.Errorf("error while creating summary writer in '%s': %w", writer.opts.basePath, err)
+		}
+		writer.summaryWriter = sWriter
+
+		err = writer.summaryWriter.Open()
+		if err != nil {
+			return fmt.Errorf("error while opening summary writer in '%s': %w", writer.opts.basePath, err)
+		}
+	}
+
+	writer.metaFilePath = filepath.Join(writer.dirPath, writer.opts.metaFileName)
+	if !writer.opts.estimateOnly {
+		metaFile, err := os.OpenFile(writer.metaFilePath, os.O_WRONLY|os.O_CREATE, 0666)
+		if err != nil {
+			return fmt.Errorf("error while opening metadata file in '%s': %w", writer.opts.basePath, err)
+		}
+		writer.metaDataFile = metaFile
 	}
-	writer.metaDataFile = metaFile
 	writer.metaData = &sProto.MetaData{
 		Version: Version,
 	}
+	writer.indexChecksum = crc64.New(crc64.MakeTable(crc64.ISO))
+	writer.contentHash = crc64.New(crc64.MakeTable(crc64.ISO))
 
-	if writer.opts.enableBloomFilter {
+	// the partitions are created while writing
+	if writer.opts.enableBloomFilter && writer.opts.bloomPartitionEveryNthKey == 0 {
 		bf, err := bloomfilter.NewOptimal(writer.opts.bloomExpectedNumberOfElements, writer.opts.bloomFpProbability)
 		if err != nil {
 			return fmt.Errorf("error while creating bloomfilter in '%s': %w", writer.opts.basePath, err)
@@ -87,83 +161,389 @@ func (writer *SSTableStreamWriter) Open() error {
 }
 
 func (writer *SSTableStreamWriter) WriteNext(key []byte, value []byte) error {
+	return writer.writeNext(key, value, nil, 0)
+}
+
+// WriteNextWithSeq is the same as WriteNext, but stores the given sequence number as the version of the record in the
+// index. Mergers like SSTableMerger.MergeLatestBySeq use it to decide which version of a key is the newest.
+func (writer *SSTableStreamWriter) WriteNextWithSeq(key []byte, value []byte, seq uint64) error {
+	return writer.writeNext(key, value, nil, seq)
+}
+
+// WriteNextWithChecksum is the same as WriteNext, but stores the given checksum in the index instead of computing it.
+// This saves hashing values whose checksum is known already, for example when merging tables. The checksum is trusted
+// and must be computed with the function of WithChecksumFunc, the CRC-64 of the value using the ISO polynomial by default,
+// otherwise reads fail with a ChecksumError. With DisableValueChecksums the checksum is ignored and zero is stored instead.
+func (writer *SSTableStreamWriter) WriteNextWithChecksum(key []byte, value []byte, checksum uint64) error {
+	return writer.writeNext(key, value, &checksum, 0)
+}
+
+// writeNext writes the key and value, the checksum of the value is only computed when none is given
+func (writer *SSTableStreamWriter) writeNext(key []byte, value []byte, checksum *uint64, seq uint64) error {
+	err := writer.checkKeyOrder(key, seq)
+	if err != nil {
+		return err
+	}
+
+	// the validator runs before any state is modified, so a rejected record leaves the writer usable
+	if writer.opts.writeValidator != nil {
+		if err := writer.opts.writeValidator(key, value); err != nil {
+			return fmt.Errorf("sstables.WriteNext '%s': validation failed: %w", writer.opts.basePath, err)
+		}
+	}
+
+	writer.trackKey(key)
+	writer.lastSeq = seq
+
+	if writer.opts.disableValueChecksums {
+		var zero uint64
+		checksum = &zero
+	} else if checksum == nil {
+		crc := writer.opts.newChecksum()
+		_, err = crc.Write(value)
+		if err != nil {
+			return fmt.Errorf("error while writing crc64 hash in '%s': %w", writer.opts.basePath, err)
+		}
+		sum := crc.Sum64()
+		checksum = &sum
+	}
+
+	preWriteOffset := writer.dataWriter.Size()
+	recordOffset, err := writer.dataWriter.Write(value)
+	if err != nil {
+		return fmt.Errorf("error writeNext data writer error in '%s': %w", writer.opts.basePath, err)
+	}
+
+	return writer.appendIndexEntry(key, recordOffset, preWriteOffset, *checksum, len(value), value == nil, seq)
+}
+
+// WriteNextStreaming writes the next key, its value is written incrementally through the returned io.WriteCloser.
+// This allows to write very large values without holding them in memory, the checksum is computed on the fly.
+// Closing the returned writer completes the record and its index entry, no other writes are allowed until then.
+// The key needs to be ascending like with WriteNext. Compressed values are streamed through the compressor, none of
+// them is held in memory as a whole. The WriteValidator and UseDirectIO can't be used with streaming writes.
+func (writer *SSTableStreamWriter) WriteNextStreaming(key []byte) (io.WriteCloser, error) {
+	if writer.streamingValue {
+		return nil, fmt.Errorf("sstables.WriteNextStreaming '%s': a streamed value is still being written", writer.opts.basePath)
+	}
+
+	err := writer.checkKeyOrder(key, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	if writer.opts.writeValidator != nil {
+		return nil, fmt.Errorf("sstables.WriteNextStreaming '%s': streamed values can't be validated", writer.opts.basePath)
+	}
+
+	streamingWriter, ok := writer.dataWriter.(recordio.StreamingWriterI)
+	if !ok {
+		return nil, fmt.Errorf("sstables.WriteNextStreaming '%s': data writer does not support streaming", writer.opts.basePath)
+	}
+
+	preWriteOffset := writer.dataWriter.Size()
+	recordWriter, err := streamingWriter.WriteStreaming()
+	if err != nil {
+		return nil, fmt.Errorf("error writeNextStreaming data writer error in '%s': %w", writer.opts.basePath, err)
+	}
+
+	writer.trackKey(key)
+	writer.streamingValue = true
+	valueWriter := &streamingValueWriter{
+		writer:         writer,
+		key:            append([]byte{}, key...),
+		recordWriter:   recordWriter,
+		preWriteOffset: preWriteOffset,
+	}
+	if !writer.opts.disableValueChecksums {
+		valueWriter.crc = writer.opts.newChecksum()
+	}
+	return valueWriter, nil
+}
+
+type streamingValueWriter struct {
+	writer *SSTableStreamWriter
+	key    []byte
+	// crc is nil with DisableValueChecksums
+	crc            hash.Hash64
+	recordWriter   recordio.RecordWriterI
+	preWriteOffset uint64
+	size           int
+	closed         bool
+}
+
+func (v *streamingValueWriter) Write(p []byte) (int, error) {
+	if v.closed {
+		return 0, fmt.Errorf("sstables.WriteNextStreaming '%s': value was already closed", v.writer.opts.basePath)
+	}
+
+	n, err := v.recordWriter.Write(p)
+	if v.crc != nil {
+		_, _ = v.crc.Write(p[:n])
+	}
+	v.size += n
+	return n, err
+}
+
+func (v *streamingValueWriter) Close() error {
+	if v.closed {
+		return fmt.Errorf("sstables.WriteNextStreaming '%s': value was already closed", v.writer.opts.basePath)
+	}
+	v.closed = true
+	v.writer.streamingValue = false
+
+	err := v.recordWriter.Close()
+	if err != nil {
+		return fmt.Errorf("error writeNextStreaming data writer error in '%s': %w", v.writer.opts.basePath, err)
+	}
+
+	var checksum uint64
+	if v.crc != nil {
+		checksum = v.crc.Sum64()
+	}
+	return v.writer.appendIndexEntry(v.key, v.recordWriter.Offset(), v.preWriteOffset, checksum, v.size, false, 0)
+}
+
+func (writer *SSTableStreamWriter) checkKeyOrder(key []byte, seq uint64) error {
+	if writer.streamingValue {
+		return fmt.Errorf("sstables.WriteNext '%s': a streamed value is still being written", writer.opts.basePath)
+	}
+
 	if writer.lastKey != nil {
+		if writer.opts.skipOrderCheck && !debugOrderCheck {
+			return nil
+		}
 		cmpResult := writer.opts.keyComparator.Compare(writer.lastKey, key)
 		if cmpResult == 0 {
-			return fmt.Errorf("sstables.WriteNext '%s': the same key cannot be written more than once", writer.opts.basePath)
+			if !writer.opts.versioning {
+				return fmt.Errorf("sstables.WriteNext '%s': the same key cannot be written more than once", writer.opts.basePath)
+			}
+			if seq <= writer.lastSeq {
+				return fmt.Errorf("sstables.WriteNext '%s': versions of the same key need strictly increasing sequence numbers, was %d after %d",
+					writer.opts.basePath, seq, writer.lastSeq)
+			}
 		} else if cmpResult > 0 {
 			return fmt.Errorf("sstables.WriteNext '%s': non-ascending key cannot be written", writer.opts.basePath)
 		}
+	} else if writer.metaData == nil {
+		return fmt.Errorf("sstables.writeNext '%s': no metadata available to write into, table might not be opened yet", writer.opts.basePath)
+	}
 
-		// the size of the key may be variable, that's why we might allocate a new buffer for the last key
-		if len(writer.lastKey) != len(key) {
-			writer.lastKey = make([]byte, len(key))
-		}
-	} else {
-		if writer.metaData == nil {
-			return fmt.Errorf("sstables.writeNext '%s': no metadata available to write into, table might not be opened yet", writer.opts.basePath)
-		}
+	return nil
+}
+
+// useDirectIO is true when the data file is written with O_DIRECT, which is only supported on Linux
+func (writer *SSTableStreamWriter) useDirectIO() bool {
+	return writer.opts.directIO && runtime.GOOS == "linux"
+}
 
+// trackKey updates the last key, the min key and the bloom filter with the given key
+func (writer *SSTableStreamWriter) trackKey(key []byte) {
+	if writer.lastKey == nil {
 		writer.metaData.MinKey = make([]byte, len(key))
 		writer.lastKey = make([]byte, len(key))
 		copy(writer.metaData.MinKey, key)
+	} else if len(writer.lastKey) != len(key) {
+		// the size of the key may be variable, that's why we might allocate a new buffer for the last key
+		writer.lastKey = make([]byte, len(key))
 	}
 
 	copy(writer.lastKey, key)
 
-	if writer.opts.enableBloomFilter {
+	if writer.opts.enableBloomFilter && writer.opts.bloomPartitionEveryNthKey > 0 {
+		// a new filter can't be created without an error, which is returned when closing the writer
+		writer.bloomPartitionErr = errors.Join(writer.bloomPartitionErr, writer.addToBloomPartition(key))
+	} else if writer.opts.enableBloomFilter {
 		fnvHash := fnv.New64()
-		_, _ = fnvHash.Write(key)
+		if writer.opts.bloomKeyTransform != nil {
+			_, _ = fnvHash.Write(writer.opts.bloomKeyTransform(key))
+		} else {
+			_, _ = fnvHash.Write(key)
+		}
 		writer.bloomFilter.Add(fnvHash)
 	}
+}
 
-	crc := crc64.New(crc64.MakeTable(crc64.ISO))
-	_, err := crc.Write(value)
-	if err != nil {
-		return fmt.Errorf("error while writing crc64 hash in '%s': %w", writer.opts.basePath, err)
+// appendIndexEntry writes the index entry for a value that was written at recordOffset into the data file
+func (writer *SSTableStreamWriter) appendIndexEntry(key []byte, recordOffset uint64, preWriteOffset uint64, checksum uint64, valueSize int, nullValue bool, seq uint64) error {
+	// the shared prefix is computed against the last key that made it into the index, which differs from lastKey after failed writes
+	sharedPrefix := 0
+	if writer.opts.indexRestartInterval > 0 && !writer.isIndexRestart() {
+		sharedPrefix = sharedPrefixLength(writer.lastIndexKey, key)
 	}
 
-	preWriteOffset := writer.dataWriter.Size()
-	recordOffset, err := writer.dataWriter.Write(value)
-	if err != nil {
-		return fmt.Errorf("error writeNext data writer error in '%s': %w", writer.opts.basePath, err)
+	entry := &sProto.IndexEntry{
+		Key:                key[sharedPrefix:],
+		SharedPrefixLength: uint32(sharedPrefix),
+		ValueOffset:        recordOffset,
+		Checksum:           checksum,
+		NullValue:          nullValue,
+		SequenceNumber:     seq,
 	}
 
-	_, err = writer.indexWriter.Write(&sProto.IndexEntry{Key: key, ValueOffset: recordOffset, Checksum: crc.Sum64()})
+	var indexOffset uint64
+	var err error
+	if writer.opts.scanOnly {
+		// the entry follows its value in the data file, the offset isn't needed to read it
+		entry.ValueOffset = 0
+		recordOffset = 0
+		err = writer.writeScanOnlyEntry(entry)
+	} else {
+		indexOffset, err = writer.indexWriter.Write(entry)
+	}
 	if err != nil {
 		// in case of failures we need to try to rewind the data writer's offset to preWriteOffset
 		seekErr := writer.dataWriter.Seek(preWriteOffset)
 		return fmt.Errorf("error writeNext index writer/seeker error in '%s': %w", writer.opts.basePath, errors.Join(err, seekErr))
 	}
 
+	if writer.opts.indexRestartInterval > 0 {
+		writer.lastIndexKey = append(writer.lastIndexKey[:0], key...)
+	}
+
+	if writer.summaryWriter != nil && writer.isSummaryKey() {
+		_, err = writer.summaryWriter.Write(&sProto.SummaryEntry{Key: key, IndexOffset: indexOffset})
+		if err != nil {
+			return fmt.Errorf("error writeNext summary writer error in '%s': %w", writer.opts.basePath, err)
+		}
+	}
+
+	updateIndexChecksum(writer.indexChecksum, key, IndexVal{Offset: recordOffset, Checksum: checksum, NullValue: nullValue, SequenceNumber: seq})
+	// the content hash only depends on the logical content, the offsets change with the compression
+	updateIndexChecksum(writer.contentHash, key, IndexVal{Checksum: checksum, NullValue: nullValue, SequenceNumber: seq})
+
 	writer.metaData.NumRecords += 1
-	if value == nil {
+	if nullValue {
 		writer.metaData.NullValues += 1
 	}
 
+	bucket := valueSizeBucket(valueSize)
+	for len(writer.metaData.ValueSizeHistogram) <= bucket {
+		writer.metaData.ValueSizeHistogram = append(writer.metaData.ValueSizeHistogram, 0)
+	}
+	writer.metaData.ValueSizeHistogram[bucket] += 1
+
+	if writer.opts.progress != nil && writer.metaData.NumRecords%uint64(writer.opts.progressEveryNRecords) == 0 {
+		writer.reportProgress()
+	}
+
+	if writer.opts.rateLimiter != nil && !writer.opts.estimateOnly {
+		// the limit applies to the bytes that end up in the data file, after the compression
+		err = writer.opts.rateLimiter.WaitN(writer.opts.rateLimiterCtx, int(writer.dataWriter.Size()-preWriteOffset))
+		if err != nil {
+			return fmt.Errorf("error writeNext rate limiter error in '%s': %w", writer.opts.basePath, err)
+		}
+	}
+
 	return nil
 }
 
-func (writer *SSTableStreamWriter) Close() (err error) {
+// writeScanOnlyEntry writes the index entry into the data file, right after its value
+func (writer *SSTableStreamWriter) writeScanOnlyEntry(entry *sProto.IndexEntry) error {
+	bytes, err := proto.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	_, err = writer.dataWriter.Write(bytes)
+	return err
+}
+
+// reportProgress calls the progress function with the records and the bytes of the data and index files written so far
+func (writer *SSTableStreamWriter) reportProgress() {
+	writer.opts.progress(writer.metaData.NumRecords, writer.dataWriter.Size()+writer.indexWriter.Size())
+}
+
+func (writer *SSTableStreamWriter) isSummaryKey() bool {
+	return writer.opts.summaryEveryNthKey > 0 && writer.metaData.NumRecords%uint64(writer.opts.summaryEveryNthKey) == 0
+}
+
+// isIndexRestart returns true when the current key needs to be written in full to the index, binary searches on disk
+// depend on these restarts to find full keys. Keys in the summary always need to be restarts.
+func (writer *SSTableStreamWriter) isIndexRestart() bool {
+	return writer.metaData.NumRecords%uint64(writer.opts.indexRestartInterval) == 0 || writer.isSummaryKey()
+}
+
+// Close writes the bloom filter and metadata and closes all files. Once all files are synced to disk, the
+// CommittedFileName marker is written to tell readers that the table is complete. With WriteAtomic, the staging
+// directory is renamed to the base path when everything was written successfully, otherwise it is removed.
+func (writer *SSTableStreamWriter) Close() error {
+	err := writer.closeFiles()
+	if err == nil && !writer.opts.estimateOnly {
+		err = writer.writeCommitMarker()
+	}
+
+	if writer.opts.atomic && !writer.opts.estimateOnly && writer.dirPath == writer.opts.stagingPath {
+		err = writer.commitStaging(err)
+	}
+
+	if err == nil && !writer.opts.estimateOnly && writer.metaData != nil {
+		writer.opts.logger.Info("sstable written", "path", writer.opts.basePath,
+			"records", writer.metaData.NumRecords, "dataBytes", writer.metaData.DataBytes,
+			"indexBytes", writer.metaData.IndexBytes)
+	}
+	return err
+}
+
+func (writer *SSTableStreamWriter) closeFiles() (err error) {
 	err = errors.Join(writer.indexWriter.Close(), writer.dataWriter.Close())
+	if writer.summaryWriter != nil {
+		err = errors.Join(err, writer.summaryWriter.Close())
+	}
 
-	if writer.opts.enableBloomFilter && writer.bloomFilter != nil {
-		_, bErr := writer.bloomFilter.WriteFile(filepath.Join(writer.opts.basePath, BloomFileName))
+	if err == nil && writer.opts.fadviseDontNeedOnClose && !writer.opts.estimateOnly {
+		err = recordio.DropFromPageCache(writer.dataFilePath)
+	}
+
+	if writer.opts.bloomPartitionEveryNthKey > 0 {
+		err = errors.Join(err, writer.bloomPartitionErr)
+		if len(writer.bloomPartitions) > 0 && !writer.opts.estimateOnly {
+			bErr := writeBloomPartitions(filepath.Join(writer.dirPath, writer.opts.bloomPartitionsFileName), writer.bloomPartitions)
+			if bErr != nil {
+				err = errors.Join(err, fmt.Errorf("error in writing bloom partitions in '%s': %w", writer.opts.basePath, bErr))
+			}
+		}
+	} else if writer.opts.enableBloomFilter && writer.bloomFilter != nil && !writer.opts.estimateOnly {
+		_, bErr := writer.bloomFilter.WriteFile(filepath.Join(writer.dirPath, writer.opts.bloomFileName))
 		if bErr != nil {
 			err = errors.Join(err, fmt.Errorf("error in writing bloom filter  in '%s': %w", writer.opts.basePath, bErr))
 		}
 	}
 
-	if writer.metaData != nil && writer.metaDataFile != nil {
-		defer func() {
-			err = errors.Join(err, writer.metaDataFile.Close())
-		}()
+	// the last records since the previous report are reported once more with the final sizes
+	if err == nil && writer.opts.progress != nil && writer.metaData != nil &&
+		writer.metaData.NumRecords%uint64(writer.opts.progressEveryNRecords) != 0 {
+		writer.reportProgress()
+	}
 
+	if writer.metaData != nil {
 		writer.metaData.MaxKey = writer.lastKey
 		writer.metaData.DataBytes = writer.dataWriter.Size()
 		writer.metaData.IndexBytes = writer.indexWriter.Size()
 		writer.metaData.TotalBytes = writer.metaData.DataBytes + writer.metaData.IndexBytes
-		bytes, mErr := proto.Marshal(writer.metaData)
+		writer.metaData.IndexChecksum = writer.indexChecksum.Sum64()
+		writer.metaData.ContentHash = writer.contentHash.Sum64()
+		createdAt := writer.opts.createdAt
+		if createdAt.IsZero() {
+			createdAt = time.Now()
+		}
+		writer.metaData.CreatedAtUnixMillis = createdAt.UnixMilli()
+		writer.metaData.UserTag = writer.opts.userTag
+		writer.metaData.IndexRestartInterval = uint32(writer.opts.indexRestartInterval)
+		writer.metaData.Versioned = writer.opts.versioning
+		writer.metaData.ComparatorName = comparatorName(writer.opts.keyComparator, writer.opts.comparatorName)
+		writer.metaData.ScanOnly = writer.opts.scanOnly
+		writer.metaData.BloomKeyTransformed = writer.opts.enableBloomFilter && writer.opts.bloomKeyTransform != nil
+		writer.metaData.BloomPartitions = uint32(len(writer.bloomPartitions))
+		writer.metaData.ValueChecksumsDisabled = writer.opts.disableValueChecksums
+		writer.metaData.ValueChecksumName = writer.opts.checksumName
+	}
+
+	if writer.metaData != nil && writer.metaDataFile != nil {
+		defer func() {
+			err = errors.Join(err, writer.metaDataFile.Close())
+		}()
+
+		bytes, mErr := marshalMetaData(writer.metaData)
 		if mErr != nil {
 			return errors.Join(err, fmt.Errorf("error in serializing metadata in '%s': %w", writer.opts.basePath, mErr))
 		}
@@ -177,6 +557,143 @@ func (writer *SSTableStreamWriter) Close() (err error) {
 	return err
 }
 
+// writeCommitMarker syncs all files of the table and then durably writes the commit marker
+func (writer *SSTableStreamWriter) writeCommitMarker() error {
+	err := syncDirectory(writer.dirPath)
+	if err != nil {
+		return fmt.Errorf("error while syncing sstable in '%s': %w", writer.opts.basePath, err)
+	}
+
+	markerPath := filepath.Join(writer.dirPath, writer.opts.committedFileName)
+	err = os.WriteFile(markerPath, []byte{}, 0666)
+	if err == nil {
+		err = errors.Join(syncFile(markerPath), syncFile(writer.dirPath))
+	}
+	if err != nil {
+		return fmt.Errorf("error while writing commit marker in '%s': %w", writer.opts.basePath, err)
+	}
+	return nil
+}
+
+// prepareStaging creates an empty staging directory, a leftover of an earlier failed write is removed.
+func (writer *SSTableStreamWriter) prepareStaging() error {
+	entries, err := os.ReadDir(writer.opts.basePath)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("error while checking base path '%s': %w", writer.opts.basePath, err)
+	}
+	if len(entries) > 0 {
+		return fmt.Errorf("error while opening atomic writer, base path '%s' is not empty", writer.opts.basePath)
+	}
+
+	err = errors.Join(os.RemoveAll(writer.opts.stagingPath), os.MkdirAll(writer.opts.stagingPath, 0700))
+	if err != nil {
+		return fmt.Errorf("error while creating staging path '%s' for '%s': %w", writer.opts.stagingPath, writer.opts.basePath, err)
+	}
+	return nil
+}
+
+// commitStaging renames the synced staging directory to the base path. The rename of a directory is atomic, so readers
+// either see the complete table or none at all. On any error the staging directory is removed.
+func (writer *SSTableStreamWriter) commitStaging(err error) error {
+	if err == nil {
+		// the base path may only exist as an empty directory, which is removed to make the rename portable
+		if rErr := os.Remove(writer.opts.basePath); rErr != nil && !errors.Is(rErr, os.ErrNotExist) {
+			err = fmt.Errorf("error while removing empty base path '%s': %w", writer.opts.basePath, rErr)
+		}
+	}
+
+	if err == nil {
+		err = os.Rename(writer.opts.stagingPath, writer.opts.basePath)
+		if err == nil {
+			return syncDirectory(filepath.Dir(filepath.Clean(writer.opts.basePath)))
+		}
+		err = fmt.Errorf("error while renaming staging path '%s' to '%s': %w", writer.opts.stagingPath, writer.opts.basePath, err)
+	}
+
+	return errors.Join(err, os.RemoveAll(writer.opts.stagingPath))
+}
+
+// syncDirectory syncs all regular files in the directory and the directory itself.
+func syncDirectory(path string) error {
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if entry.Type().IsRegular() {
+			if err := syncFile(filepath.Join(path, entry.Name())); err != nil {
+				return err
+			}
+		}
+	}
+	return syncFile(path)
+}
+
+func syncFile(path string) (err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = errors.Join(err, f.Close())
+	}()
+	return f.Sync()
+}
+
+// SizeEstimate contains the projected sizes of a table in bytes, see EstimateOnly.
+type SizeEstimate struct {
+	DataBytes  uint64
+	IndexBytes uint64
+	BloomBytes uint64
+	TotalBytes uint64
+}
+
+// Estimate returns the sizes in bytes of the records written so far, the writer needs to be opened first.
+// The data and index sizes include the compression and are exact. The bloom filter size is the size of its compressed
+// serialization, which depends on BloomExpectedNumberOfElements rather than on the number of records written.
+func (writer *SSTableStreamWriter) Estimate() (SizeEstimate, error) {
+	if writer.dataWriter == nil || writer.indexWriter == nil {
+		return SizeEstimate{}, fmt.Errorf("sstables.Estimate '%s': table might not be opened yet", writer.opts.basePath)
+	}
+
+	estimate := SizeEstimate{
+		DataBytes:  writer.dataWriter.Size(),
+		IndexBytes: writer.indexWriter.Size(),
+	}
+
+	if len(writer.bloomPartitions) > 0 {
+		// the partitions are stored uncompressed, the framing of the file isn't included
+		for _, p := range writer.bloomPartitions {
+			filter, err := p.filter.MarshalBinary()
+			if err != nil {
+				return SizeEstimate{}, fmt.Errorf("error while estimating bloom partition size in '%s': %w", writer.opts.basePath, err)
+			}
+			estimate.BloomBytes += uint64(len(p.firstKey) + len(filter))
+		}
+	} else if writer.bloomFilter != nil {
+		counter := &countingWriter{}
+		_, err := writer.bloomFilter.WriteTo(counter)
+		if err != nil {
+			return SizeEstimate{}, fmt.Errorf("error while estimating bloom filter size in '%s': %w", writer.opts.basePath, err)
+		}
+		estimate.BloomBytes = counter.n
+	}
+
+	estimate.TotalBytes = estimate.DataBytes + estimate.IndexBytes + estimate.BloomBytes
+	return estimate, nil
+}
+
+// countingWriter discards everything written to it and only counts the bytes
+type countingWriter struct {
+	n uint64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	c.n += uint64(len(p))
+	return len(p), nil
+}
+
 type SSTableSimpleWriter struct {
 	streamWriter *SSTableStreamWriter
 }
@@ -218,17 +735,27 @@ func NewSSTableStreamWriter(writerOptions ...WriterOption) (*SSTableStreamWriter
 		enableBloomFilter:             true,
 		indexCompressionType:          recordio.CompressionTypeNone,
 		dataCompressionType:           recordio.CompressionTypeSnappy,
+		compressionLevel:              recordio.DefaultCompressionLevel,
 		bloomFpProbability:            0.01,
 		bloomExpectedNumberOfElements: 1000,
 		writeBufferSizeBytes:          1024 * 1024 * 4,
 		keyComparator:                 nil,
+		indexFileName:                 IndexFileName,
+		dataFileName:                  DataFileName,
+		bloomFileName:                 BloomFileName,
+		bloomPartitionsFileName:       BloomPartitionsFileName,
+		metaFileName:                  MetaFileName,
+		summaryFileName:               SummaryFileName,
+		committedFileName:             CommittedFileName,
+		spillThresholdBytes:           64 * 1024 * 1024,
+		progressEveryNRecords:         10000,
 	}
 
 	for _, writeOption := range writerOptions {
 		writeOption(opts)
 	}
 
-	if opts.basePath == "" {
+	if opts.basePath == "" && !opts.estimateOnly {
 		return nil, errors.New("basePath was not supplied")
 	}
 
@@ -236,6 +763,87 @@ func NewSSTableStreamWriter(writerOptions ...WriterOption) (*SSTableStreamWriter
 		return nil, errors.New("no key comparator supplied")
 	}
 
+	if err := validateFileNames(opts.indexFileName, opts.dataFileName, opts.bloomFileName, opts.bloomPartitionsFileName, opts.metaFileName, opts.summaryFileName, opts.committedFileName); err != nil {
+		return nil, err
+	}
+
+	if opts.indexRestartInterval < 0 {
+		return nil, fmt.Errorf("unexpected index restart interval, was: %d", opts.indexRestartInterval)
+	}
+
+	if opts.minCompressSizeBytes < 0 {
+		return nil, fmt.Errorf("unexpected min compress size, was: %d", opts.minCompressSizeBytes)
+	}
+
+	if opts.progressEveryNRecords <= 0 {
+		return nil, fmt.Errorf("unexpected progress interval, was: %d", opts.progressEveryNRecords)
+	}
+
+	if opts.summaryEveryNthKey < 0 {
+		return nil, fmt.Errorf("unexpected summary interval, was: %d", opts.summaryEveryNthKey)
+	}
+
+	if opts.rateLimiter != nil && opts.rateLimiterCtx == nil {
+		opts.rateLimiterCtx = context.Background()
+	}
+
+	if opts.logger == nil {
+		opts.logger = noopLogger{}
+	}
+
+	if opts.newChecksum == nil {
+		if opts.checksumName != "" {
+			return nil, fmt.Errorf("no checksum function supplied for checksum '%s'", opts.checksumName)
+		}
+		opts.newChecksum = newCRC64ISO
+	} else if opts.checksumName == "" {
+		return nil, errors.New("checksum function was supplied without a name")
+	}
+
+	if opts.bloomPartitionEveryNthKey < 0 {
+		return nil, fmt.Errorf("unexpected bloom partition interval, was: %d", opts.bloomPartitionEveryNthKey)
+	}
+
+	if opts.bloomPartitionEveryNthKey > 0 && opts.bloomKeyTransform != nil {
+		return nil, errors.New("partitioned bloom filters can't be combined with a bloom key transform")
+	}
+
+	if opts.scanOnly {
+		if opts.summaryEveryNthKey > 0 || opts.indexRestartInterval > 0 {
+			return nil, errors.New("scan only tables can't be combined with a summary or index key prefix compression")
+		}
+		// keys can't be looked up, so there is nothing to filter
+		opts.enableBloomFilter = false
+	}
+
+	// both binary search for the last entry lower or equal to a key, which can skip over older versions of it
+	if opts.versioning && (opts.summaryEveryNthKey > 0 || opts.indexRestartInterval > 0) {
+		return nil, errors.New("versioning can't be combined with a summary or index key prefix compression")
+	}
+
+	// the index and data buffers fall back to the shared buffer size when they are not set explicitly
+	if opts.indexWriteBufferSizeBytes <= 0 {
+		opts.indexWriteBufferSizeBytes = opts.writeBufferSizeBytes
+	}
+
+	if opts.dataWriteBufferSizeBytes <= 0 {
+		opts.dataWriteBufferSizeBytes = opts.writeBufferSizeBytes
+	}
+
+	if opts.directIO && opts.dataWriteBufferSizeBytes%directio.BlockSize != 0 {
+		return nil, fmt.Errorf("the data write buffer size needs to be a multiple of %d bytes with direct IO, was: %d",
+			directio.BlockSize, opts.dataWriteBufferSizeBytes)
+	}
+
+	if opts.atomic && !opts.estimateOnly {
+		if opts.stagingPath == "" {
+			opts.stagingPath = filepath.Clean(opts.basePath) + ".tmp"
+		}
+		if filepath.Clean(opts.stagingPath) == filepath.Clean(opts.basePath) {
+			return nil, fmt.Errorf("the staging path can't be the base path '%s'", opts.basePath)
+		}
+	}
+
 	if opts.bloomExpectedNumberOfElements <= 0 {
 		return nil, fmt.Errorf("unexpected number of bloom filter elements, was: %d",
 			opts.bloomExpectedNumberOfElements)
@@ -259,11 +867,52 @@ type SSTableWriterOptions struct {
 	basePath                      string
 	indexCompressionType          int
 	dataCompressionType           int
+	compressionLevel              int
+	minCompressSizeBytes          int
 	enableBloomFilter             bool
 	bloomExpectedNumberOfElements uint64
 	bloomFpProbability            float64
 	writeBufferSizeBytes          int
+	indexWriteBufferSizeBytes     int
+	dataWriteBufferSizeBytes      int
 	keyComparator                 skiplist.Comparator[[]byte]
+	comparatorName                string
+	writeValidator                func(key []byte, value []byte) error
+	createdAt                     time.Time
+	userTag                       []byte
+	indexFileName                 string
+	dataFileName                  string
+	bloomFileName                 string
+	bloomPartitionsFileName       string
+	metaFileName                  string
+	estimateOnly                  bool
+	summaryEveryNthKey            int
+	summaryFileName               string
+	committedFileName             string
+	indexRestartInterval          int
+	versioning                    bool
+	spillThresholdBytes           int
+	maxBytesPerTable              int
+	tempDir                       string
+	combiner                      func(key, a, b []byte) []byte
+	directIO                      bool
+	fadviseDontNeedOnClose        bool
+	atomic                        bool
+	stagingPath                   string
+	progress                      func(recordsWritten uint64, bytesWritten uint64)
+	progressEveryNRecords         int
+	scanOnly                      bool
+	disableValueChecksums         bool
+	checksumName                  string
+	newChecksum                   func() hash.Hash64
+	hasSchemaID                   bool
+	schemaID                      uint32
+	bloomKeyTransform             func(key []byte) []byte
+	bloomPartitionEveryNthKey     int
+	rateLimiter                   RateLimiter
+	rateLimiterCtx                context.Context
+	logger                        Logger
+	skipOrderCheck                bool
 }
 
 type WriterOption func(*SSTableWriterOptions)
@@ -286,12 +935,82 @@ func DataCompressionType(p int) WriterOption {
 	}
 }
 
+// CompressionLevel sets the level of the index and data compression, which only applies to recordio.CompressionTypeGZIP
+// with the levels of compress/gzip currently, where zero is gzip.NoCompression. It defaults to
+// recordio.DefaultCompressionLevel, the readers detect the compression type from the file header and don't need to know
+// the level.
+func CompressionLevel(level int) WriterOption {
+	return func(args *SSTableWriterOptions) {
+		args.compressionLevel = level
+	}
+}
+
+// MinCompressSizeBytes stores values smaller than n bytes uncompressed in the data file, because compressing them
+// often costs more CPU on reads than it saves space. Each record is flagged, so the readers handle mixed data files
+// transparently. Zero compresses every value, which is the default. The index is not affected.
+func MinCompressSizeBytes(n int) WriterOption {
+	return func(args *SSTableWriterOptions) {
+		args.minCompressSizeBytes = n
+	}
+}
+
+// WithSchemaID embeds the given id in the header of the data file, where it can be read back with
+// SSTableReader.SchemaID before reading any records. This allows generic tooling to dispatch on the value format of a
+// table.
+func WithSchemaID(id uint32) WriterOption {
+	return func(args *SSTableWriterOptions) {
+		args.hasSchemaID = true
+		args.schemaID = id
+	}
+}
+
 func EnableBloomFilter() WriterOption {
 	return func(args *SSTableWriterOptions) {
 		args.enableBloomFilter = true
 	}
 }
 
+// WithRateLimiter limits the bytes per second written to the data file, for example to keep background compactions from
+// starving foreground reads. The bytes are counted after the compression, every write blocks until the limiter allows
+// the bytes it wrote. When ctx is done while waiting, the write returns its error, the record was written regardless.
+// The index and the other files are not throttled. A nil context defaults to context.Background.
+func WithRateLimiter(ctx context.Context, limiter RateLimiter) WriterOption {
+	return func(args *SSTableWriterOptions) {
+		args.rateLimiter = limiter
+		args.rateLimiterCtx = ctx
+	}
+}
+
+// WithLogger logs the path, the number of records and the sizes of the table once it was written successfully.
+// By default, nothing is logged.
+func WithLogger(logger Logger) WriterOption {
+	return func(args *SSTableWriterOptions) {
+		args.logger = logger
+	}
+}
+
+// BloomPartitionEveryNthKey splits the bloom filter into partitions of n consecutive keys, each sized for n keys with
+// the configured false positive probability. A probe only touches the partition that covers the key, which the reader
+// finds by binary searching the first keys of the partitions. This keeps the probes of tables with many millions of
+// keys cache friendly. The partitions are stored together in BloomPartitionsFileName instead of the bloom file, their
+// number is recorded in MetaData.BloomPartitions. BloomExpectedNumberOfElements is ignored and it can't be combined
+// with BloomKeyTransform. Zero writes a single bloom filter, which is the default.
+func BloomPartitionEveryNthKey(n int) WriterOption {
+	return func(args *SSTableWriterOptions) {
+		args.bloomPartitionEveryNthKey = n
+	}
+}
+
+// BloomKeyTransform adds transform(key) to the bloom filter instead of the key itself, for example only the row prefix of
+// a composite key. SSTableReader.MightContain then rules out whole rows with a single probe of the transformed key.
+// The transform must be deterministic, readers need the same one with ReadBloomKeyTransform to use the bloom filter
+// for point lookups of full keys. The table records that its bloom filter holds transformed keys.
+func BloomKeyTransform(transform func(key []byte) []byte) WriterOption {
+	return func(args *SSTableWriterOptions) {
+		args.bloomKeyTransform = transform
+	}
+}
+
 func BloomExpectedNumberOfElements(n uint64) WriterOption {
 	return func(args *SSTableWriterOptions) {
 		args.bloomExpectedNumberOfElements = n
@@ -310,8 +1029,277 @@ func WriteBufferSizeBytes(bufSizeBytes int) WriterOption {
 	}
 }
 
+// IndexWriteBufferSizeBytes sets the write buffer size of the index file only, defaults to WriteBufferSizeBytes.
+func IndexWriteBufferSizeBytes(bufSizeBytes int) WriterOption {
+	return func(args *SSTableWriterOptions) {
+		args.indexWriteBufferSizeBytes = bufSizeBytes
+	}
+}
+
+// DataWriteBufferSizeBytes sets the write buffer size of the data file only, defaults to WriteBufferSizeBytes.
+func DataWriteBufferSizeBytes(bufSizeBytes int) WriterOption {
+	return func(args *SSTableWriterOptions) {
+		args.dataWriteBufferSizeBytes = bufSizeBytes
+	}
+}
+
 func WithKeyComparator(cmp skiplist.Comparator[[]byte]) WriterOption {
 	return func(args *SSTableWriterOptions) {
 		args.keyComparator = cmp
 	}
 }
+
+// WithComparatorName sets the name of the key comparator that is recorded in the metadata, readers can verify it with
+// ReadWithComparatorName. Defaults to the name of a NamedComparator, comparators without a name are not recorded.
+func WithComparatorName(name string) WriterOption {
+	return func(args *SSTableWriterOptions) {
+		args.comparatorName = name
+	}
+}
+
+// WithWriteValidator sets a function that is called on every WriteNext after the key ordering was checked.
+// Returning an error aborts the write of that record, the error is returned wrapped by WriteNext.
+// A rejected record does not change the state of the writer, so it can be used for the next record.
+func WithWriteValidator(validator func(key []byte, value []byte) error) WriterOption {
+	return func(args *SSTableWriterOptions) {
+		args.writeValidator = validator
+	}
+}
+
+// WithCreatedAt sets the creation time that is stored in the metadata, defaults to the time the writer is closed.
+func WithCreatedAt(t time.Time) WriterOption {
+	return func(args *SSTableWriterOptions) {
+		args.createdAt = t
+	}
+}
+
+// WithUserTag sets an arbitrary label that is stored in the metadata, for example to identify the job that wrote the table.
+func WithUserTag(tag []byte) WriterOption {
+	return func(args *SSTableWriterOptions) {
+		args.userTag = tag
+	}
+}
+
+// WithIndexFileName overrides the name of the index file, defaults to IndexFileName.
+// Readers need to be configured with ReadIndexFileName accordingly.
+func WithIndexFileName(name string) WriterOption {
+	return func(args *SSTableWriterOptions) {
+		args.indexFileName = name
+	}
+}
+
+// WithDataFileName overrides the name of the data file, defaults to DataFileName.
+// Readers need to be configured with ReadDataFileName accordingly.
+func WithDataFileName(name string) WriterOption {
+	return func(args *SSTableWriterOptions) {
+		args.dataFileName = name
+	}
+}
+
+// WithBloomFileName overrides the name of the bloom filter file, defaults to BloomFileName.
+// Readers need to be configured with ReadBloomFileName accordingly.
+func WithBloomFileName(name string) WriterOption {
+	return func(args *SSTableWriterOptions) {
+		args.bloomFileName = name
+	}
+}
+
+// WithBloomPartitionsFileName overrides the name of the partitioned bloom filter file, defaults to
+// BloomPartitionsFileName. Readers need to be configured with ReadBloomPartitionsFileName accordingly.
+func WithBloomPartitionsFileName(name string) WriterOption {
+	return func(args *SSTableWriterOptions) {
+		args.bloomPartitionsFileName = name
+	}
+}
+
+// WithMetaFileName overrides the name of the metadata file, defaults to MetaFileName.
+// Readers need to be configured with ReadMetaFileName accordingly.
+func WithMetaFileName(name string) WriterOption {
+	return func(args *SSTableWriterOptions) {
+		args.metaFileName = name
+	}
+}
+
+// WithCommittedFileName overrides the name of the commit marker file, defaults to CommittedFileName.
+// Readers need to be configured with ReadCommittedFileName accordingly.
+func WithCommittedFileName(name string) WriterOption {
+	return func(args *SSTableWriterOptions) {
+		args.committedFileName = name
+	}
+}
+
+// WithSummaryFileName overrides the name of the summary file, defaults to SummaryFileName.
+// Readers need to be configured with ReadSummaryFileName accordingly.
+func WithSummaryFileName(name string) WriterOption {
+	return func(args *SSTableWriterOptions) {
+		args.summaryFileName = name
+	}
+}
+
+// EstimateOnly runs every write through the same pipeline including the compression, but discards the output instead of
+// writing any files, a base path is not required in this mode. The projected sizes can be retrieved with Estimate at any point after opening the writer.
+func EstimateOnly() WriterOption {
+	return func(args *SSTableWriterOptions) {
+		args.estimateOnly = true
+	}
+}
+
+// SummaryEveryNthKey writes a summary file next to the index that contains every nth key and the offset of its index
+// record. Readers using the DiskIndexLoader keep the summary in memory and only binary search the index region between
+// two summary keys on disk, which reduces the IO for very large indices. Disabled by default with a value of zero.
+func SummaryEveryNthKey(n int) WriterOption {
+	return func(args *SSTableWriterOptions) {
+		args.summaryEveryNthKey = n
+	}
+}
+
+// IndexKeyPrefixCompression stores the keys in the index prefix compressed: each entry only contains the suffix that
+// differs from the previous key and the length of the shared prefix. Every restartInterval keys, a key is stored in full
+// to allow binary searches on disk. This is worth it for keys with long common prefixes, for example hierarchical paths.
+// Disabled by default with a value of zero.
+func IndexKeyPrefixCompression(restartInterval int) WriterOption {
+	return func(args *SSTableWriterOptions) {
+		args.indexRestartInterval = restartInterval
+	}
+}
+
+// WithVersioning allows to write the same key more than once with WriteNextWithSeq, as long as the sequence numbers of
+// its versions strictly increase. Readers return the newest version of a key by default, ReadAsOfSeq reads the table
+// as of an older sequence number. Versioned tables can only be read with the SliceKeyIndexLoader, the default for them,
+// the ArenaKeyIndexLoader and the DiskIndexLoader. This can't be combined with SummaryEveryNthKey or IndexKeyPrefixCompression.
+func WithVersioning() WriterOption {
+	return func(args *SSTableWriterOptions) {
+		args.versioning = true
+	}
+}
+
+// SpillThresholdBytes sets the size of the keys and values the SortingSSTableWriter buffers in memory before they are
+// sorted and spilled to a temporary table, defaults to 64 MiB.
+func SpillThresholdBytes(n int) WriterOption {
+	return func(args *SSTableWriterOptions) {
+		args.spillThresholdBytes = n
+	}
+}
+
+// WriteMaxBytesPerTable sets the size of the data file of a single table of the SplittingSSTableWriter, the writer
+// starts a new table before a record would exceed it. It's required for the SplittingSSTableWriter and ignored otherwise.
+func WriteMaxBytesPerTable(n int) WriterOption {
+	return func(args *SSTableWriterOptions) {
+		args.maxBytesPerTable = n
+	}
+}
+
+// TempDir sets the directory in which the SortingSSTableWriter creates its temporary tables, defaults to os.TempDir.
+func TempDir(dir string) WriterOption {
+	return func(args *SSTableWriterOptions) {
+		args.tempDir = dir
+	}
+}
+
+// WithCombiner sets a function that the SortingSSTableWriter uses to merge the values of duplicate keys instead of
+// returning an error. It's called with the combined value of the earlier writes as a and the value of the later write
+// as b, both within and across the spilled runs. Values returned as nil are dropped when they are combined across runs.
+func WithCombiner(combiner func(key, a, b []byte) []byte) WriterOption {
+	return func(args *SSTableWriterOptions) {
+		args.combiner = combiner
+	}
+}
+
+// UseDirectIO writes the data file with O_DIRECT on Linux, which bypasses the page cache for large flushes and
+// compactions that are not read again soon. The alignment of buffers and offsets is handled by the recordio writer,
+// the data write buffer size must be a multiple of the block size. The index and metadata files are still buffered.
+// Opening the writer fails when the filesystem rejects O_DIRECT, on other operating systems this is a no-op.
+func UseDirectIO() WriterOption {
+	return func(args *SSTableWriterOptions) {
+		args.directIO = true
+	}
+}
+
+// FadviseDontNeedOnClose syncs the data file on Close and advises the kernel with posix_fadvise(POSIX_FADV_DONTNEED)
+// to drop its pages from the page cache, so that writing a huge table doesn't push out other cached data.
+// This is a no-op on operating systems other than Linux.
+func FadviseDontNeedOnClose() WriterOption {
+	return func(args *SSTableWriterOptions) {
+		args.fadviseDontNeedOnClose = true
+	}
+}
+
+// WriteAtomic writes the table into a staging directory and renames it to the base path on a successful Close, so
+// readers watching a directory never see a partially written table. The base path must be empty or not exist yet, it
+// is replaced as a whole. The staging directory defaults to the base path with a ".tmp" suffix, see WriteStagingPath.
+// On any error in Close the staging directory is removed. The files are synced before the rename, the rename itself is
+// only atomic when the staging directory is on the same filesystem as the base path.
+func WriteAtomic() WriterOption {
+	return func(args *SSTableWriterOptions) {
+		args.atomic = true
+	}
+}
+
+// WriteStagingPath sets the staging directory of WriteAtomic and enables it, it must be on the same filesystem as the
+// base path.
+func WriteStagingPath(p string) WriterOption {
+	return func(args *SSTableWriterOptions) {
+		args.atomic = true
+		args.stagingPath = p
+	}
+}
+
+// WithProgress sets a function that is called every ProgressEveryNRecords records with the number of records and the
+// bytes written so far, which includes the bytes that are still buffered. Close calls it once more with the totals of
+// the data and index files, unless the last write already reported them. The function runs synchronously on the
+// writing goroutine, so it should return quickly.
+func WithProgress(progress func(recordsWritten uint64, bytesWritten uint64)) WriterOption {
+	return func(args *SSTableWriterOptions) {
+		args.progress = progress
+	}
+}
+
+// ProgressEveryNRecords sets how often the function of WithProgress is called, defaults to every 10000 records.
+func ProgressEveryNRecords(n int) WriterOption {
+	return func(args *SSTableWriterOptions) {
+		args.progressEveryNRecords = n
+	}
+}
+
+// ScanOnly writes a table that can only be read with Scan, for example for archival data that is never looked up by key.
+// The index entries are stored right after their values in the data file, the index file stays empty and no bloom
+// filter is written. Readers detect such tables from the metadata and fail all other lookups, like Get, Contains and
+// the other Scan* functions, with ErrScanOnlyTable. This can't be combined with SummaryEveryNthKey or
+// IndexKeyPrefixCompression.
+func ScanOnly() WriterOption {
+	return func(args *SSTableWriterOptions) {
+		args.scanOnly = true
+	}
+}
+
+// UnsafeSkipOrderCheck disables the comparison of every key with the previous one in WriteNext and the other writes,
+// which saves a call of the comparator per record on hot write loops. WARNING: the caller is trusted to write strictly
+// ascending keys, keys that are out of order or written twice are not detected and silently corrupt the table, lookups
+// and scans return wrong results without any error. Only use it when the keys come from an already sorted source that
+// checks the order itself, like the flush of a memstore. The check still runs in binaries built with the
+// sstables_debug build tag, so tests can verify the order of a trusted source.
+func UnsafeSkipOrderCheck() WriterOption {
+	return func(args *SSTableWriterOptions) {
+		args.skipOrderCheck = true
+	}
+}
+
+// DisableValueChecksums skips computing the CRC-64 of every value and stores a zero checksum in the index instead, which
+// speeds up writing tables whose storage is trusted, for example short-lived tables. The metadata records that the
+// table has no value checksums, so readers never verify its values, independent of EnableHashCheckOnReads.
+// The checksum over the index entries is still written.
+func DisableValueChecksums() WriterOption {
+	return func(args *SSTableWriterOptions) {
+		args.disableValueChecksums = true
+	}
+}
+
+// WithChecksumFunc computes the value checksums with newHash instead of a CRC-64 with the ISO polynomial, for example
+// to use a faster 64-bit hash on the platform. The name is recorded in the metadata, readers need to supply the same
+// function under that name with ReadWithChecksumFunc, otherwise they fail with ErrChecksumFuncMismatch.
+func WithChecksumFunc(name string, newHash func() hash.Hash64) WriterOption {
+	return func(args *SSTableWriterOptions) {
+		args.checksumName = name
+		args.newChecksum = newHash
+	}
+}
@@ -1,16 +1,24 @@
 package sstables
 
 import (
+	"bytes"
+	"compress/gzip"
+	"context"
 	"encoding/binary"
 	"errors"
+	"fmt"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/thomasjungblut/go-sstables/recordio"
 	rProto "github.com/thomasjungblut/go-sstables/recordio/proto"
 	"github.com/thomasjungblut/go-sstables/skiplist"
 	"google.golang.org/protobuf/proto"
+	"hash/crc64"
+	"hash/fnv"
 	"os"
+	"path/filepath"
 	"testing"
+	"time"
 )
 
 func TestSkipListSimpleWriteHappyPath(t *testing.T) {
@@ -80,6 +88,26 @@ func TestSkipListStreamedWriteKeyComparisonAdjustsBufferSize(t *testing.T) {
 	assert.Contains(t, err.Error(), "non-ascending key cannot be written")
 }
 
+func TestUnsafeSkipOrderCheck(t *testing.T) {
+	tmpDir := t.TempDir()
+	writer, err := NewSSTableStreamWriter(WriteBasePath(tmpDir), WithKeyComparator(skiplist.BytesComparator{}),
+		UnsafeSkipOrderCheck())
+	require.NoError(t, err)
+	assert.Contains(t, writer.WriteNext([]byte{1}, []byte{1}).Error(), "table might not be opened yet")
+
+	require.NoError(t, writer.Open())
+	require.NoError(t, writer.WriteNext([]byte{2}, []byte{2}))
+	require.NoError(t, writer.WriteNext([]byte{3}, []byte{3}))
+	err = writer.WriteNext([]byte{1}, []byte{1})
+	if debugOrderCheck {
+		assert.ErrorContains(t, err, "non-ascending key cannot be written")
+	} else {
+		// the caller is trusted, the out of order key is written without any check
+		assert.NoError(t, err)
+	}
+	require.NoError(t, writer.Close())
+}
+
 func TestComparatorNotSupplied(t *testing.T) {
 	_, err := NewSSTableSimpleWriter(WriteBasePath("abc"))
 	assert.Equal(t, errors.New("no key comparator supplied"), err)
@@ -201,6 +229,106 @@ func TestFailedIndexAppendShortWrite(t *testing.T) {
 	require.Equal(t, []byte{}, v)
 }
 
+func TestWriteValidatorRejectsRecords(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "sstables_WriterValidator")
+	require.NoError(t, err)
+	defer func() { require.NoError(t, os.RemoveAll(tmpDir)) }()
+
+	tooLong := errors.New("key too long")
+	writer, err := NewSSTableStreamWriter(
+		WriteBasePath(tmpDir),
+		WithKeyComparator(skiplist.BytesComparator{}),
+		WithWriteValidator(func(key []byte, value []byte) error {
+			if len(key) > 4 {
+				return tooLong
+			}
+			return nil
+		}))
+	require.NoError(t, err)
+	require.NoError(t, writer.Open())
+
+	require.NoError(t, writer.WriteNext(intToByteSlice(1), intToByteSlice(2)))
+	err = writer.WriteNext([]byte{0, 0, 0, 2, 0}, intToByteSlice(3))
+	require.ErrorIs(t, err, tooLong)
+	// the ordering check comes first
+	err = writer.WriteNext(intToByteSlice(0), intToByteSlice(3))
+	require.ErrorContains(t, err, "non-ascending key cannot be written")
+	// a rejected key must not be recorded as the last key
+	require.NoError(t, writer.WriteNext(intToByteSlice(2), intToByteSlice(3)))
+	require.NoError(t, writer.Close())
+
+	reader, err := NewSSTableReader(ReadBasePath(tmpDir))
+	require.NoError(t, err)
+	defer closeReader(t, reader)
+	assert.Equal(t, 2, int(reader.MetaData().NumRecords))
+	assertContentMatchesSlice(t, reader, []int{1, 2})
+}
+
+func TestWriteValidatorRejectsFirstRecord(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "sstables_WriterValidator")
+	require.NoError(t, err)
+	defer func() { require.NoError(t, os.RemoveAll(tmpDir)) }()
+
+	writer, err := NewSSTableStreamWriter(
+		WriteBasePath(tmpDir),
+		WithKeyComparator(skiplist.BytesComparator{}),
+		WithWriteValidator(func(key []byte, value []byte) error {
+			if key[3] == 5 {
+				return errors.New("rejected")
+			}
+			return nil
+		}))
+	require.NoError(t, err)
+	require.NoError(t, writer.Open())
+	require.Error(t, writer.WriteNext(intToByteSlice(5), intToByteSlice(6)))
+	require.NoError(t, writer.WriteNext(intToByteSlice(3), intToByteSlice(4)))
+	require.NoError(t, writer.Close())
+
+	reader, err := NewSSTableReader(ReadBasePath(tmpDir))
+	require.NoError(t, err)
+	defer closeReader(t, reader)
+	assert.Equal(t, intToByteSlice(3), reader.MetaData().MinKey)
+	assert.Equal(t, intToByteSlice(3), reader.MetaData().MaxKey)
+}
+
+func TestWriterMetaDataCreatedAtAndUserTag(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "sstables_WriterMetaData")
+	require.NoError(t, err)
+	defer func() { require.NoError(t, os.RemoveAll(tmpDir)) }()
+
+	createdAt := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	writer, err := NewSSTableStreamWriter(
+		WriteBasePath(tmpDir),
+		WithKeyComparator(skiplist.BytesComparator{}),
+		WithCreatedAt(createdAt),
+		WithUserTag([]byte("job-42")))
+	require.NoError(t, err)
+	streamedWriteAscendingIntegers(t, writer, 10)
+
+	reader, err := NewSSTableReader(ReadBasePath(tmpDir))
+	require.NoError(t, err)
+	defer closeReader(t, reader)
+	assert.Equal(t, createdAt.UnixMilli(), reader.MetaData().CreatedAtUnixMillis)
+	assert.Equal(t, []byte("job-42"), reader.MetaData().UserTag)
+}
+
+func TestWriterMetaDataCreatedAtDefaultsToNow(t *testing.T) {
+	writer, err := newTestSSTableStreamWriter()
+	require.NoError(t, err)
+	defer cleanWriterDir(t, writer)
+
+	before := time.Now().UnixMilli()
+	streamedWriteAscendingIntegers(t, writer, 10)
+	after := time.Now().UnixMilli()
+
+	reader, err := NewSSTableReader(ReadBasePath(writer.opts.basePath))
+	require.NoError(t, err)
+	defer closeReader(t, reader)
+	assert.GreaterOrEqual(t, reader.MetaData().CreatedAtUnixMillis, before)
+	assert.LessOrEqual(t, reader.MetaData().CreatedAtUnixMillis, after)
+	assert.Nil(t, reader.MetaData().UserTag)
+}
+
 type failingRecordIoWriter struct {
 	w        recordio.WriterI
 	failNext bool
@@ -270,3 +398,701 @@ func (f *failingProtoRecordIoWriter) Write(record proto.Message) (uint64, error)
 
 	return f.w.Write(record)
 }
+
+func TestEstimateOnlyMatchesWrittenSizes(t *testing.T) {
+	writer, err := newTestSSTableStreamWriterWithDataCompression(recordio.CompressionTypeSnappy)
+	require.NoError(t, err)
+	defer cleanWriterDir(t, writer)
+	estimator, err := NewSSTableStreamWriter(
+		EstimateOnly(),
+		WithKeyComparator(skiplist.BytesComparator{}),
+		DataCompressionType(recordio.CompressionTypeSnappy))
+	require.NoError(t, err)
+
+	require.NoError(t, writer.Open())
+	require.NoError(t, estimator.Open())
+	for i := 0; i < 1000; i++ {
+		k, v := getKeyValueAsBytes(i)
+		require.NoError(t, writer.WriteNext(k, v))
+		require.NoError(t, estimator.WriteNext(k, v))
+	}
+
+	writtenEstimate, err := writer.Estimate()
+	require.NoError(t, err)
+	estimate, err := estimator.Estimate()
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+	require.NoError(t, estimator.Close())
+
+	reader, err := NewSSTableReader(ReadBasePath(writer.opts.basePath))
+	require.NoError(t, err)
+	defer closeReader(t, reader)
+	assert.Equal(t, reader.MetaData().DataBytes, estimate.DataBytes)
+	assert.Equal(t, reader.MetaData().IndexBytes, estimate.IndexBytes)
+
+	// the bloom filter is seeded randomly, so only the estimate of the same filter matches the file size exactly
+	stat, err := os.Stat(filepath.Join(writer.opts.basePath, BloomFileName))
+	require.NoError(t, err)
+	assert.Equal(t, uint64(stat.Size()), writtenEstimate.BloomBytes)
+	assert.Greater(t, estimate.BloomBytes, uint64(0))
+	assert.Equal(t, estimate.DataBytes+estimate.IndexBytes+estimate.BloomBytes, estimate.TotalBytes)
+
+	// the estimate needs to account for the compression
+	uncompressed, err := NewSSTableStreamWriter(
+		EstimateOnly(),
+		WithKeyComparator(skiplist.BytesComparator{}),
+		DataCompressionType(recordio.CompressionTypeNone))
+	require.NoError(t, err)
+	require.NoError(t, uncompressed.Open())
+	for i := 0; i < 1000; i++ {
+		k, v := getKeyValueAsBytes(i)
+		require.NoError(t, uncompressed.WriteNext(k, v))
+	}
+	uncompressedEstimate, err := uncompressed.Estimate()
+	require.NoError(t, err)
+	require.NoError(t, uncompressed.Close())
+	assert.NotEqual(t, estimate.DataBytes, uncompressedEstimate.DataBytes)
+}
+
+func TestEstimateOnlyWritesNoFiles(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "sstables_Estimate")
+	require.NoError(t, err)
+	defer func() { require.NoError(t, os.RemoveAll(tmpDir)) }()
+
+	writer, err := NewSSTableStreamWriter(WriteBasePath(tmpDir), EstimateOnly(), WithKeyComparator(skiplist.BytesComparator{}))
+	require.NoError(t, err)
+	_, err = writer.Estimate()
+	assert.Error(t, err)
+	streamedWriteAscendingIntegers(t, writer, 100)
+
+	entries, err := os.ReadDir(tmpDir)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestSeparateIndexAndDataWriteBufferSizes(t *testing.T) {
+	writer, err := NewSSTableStreamWriter(
+		WriteBasePath("abc"),
+		WithKeyComparator(skiplist.BytesComparator{}),
+		WriteBufferSizeBytes(8192))
+	require.NoError(t, err)
+	assert.Equal(t, 8192, writer.opts.indexWriteBufferSizeBytes)
+	assert.Equal(t, 8192, writer.opts.dataWriteBufferSizeBytes)
+
+	tmpDir, err := os.MkdirTemp("", "sstables_Writer")
+	require.NoError(t, err)
+	defer func() { require.NoError(t, os.RemoveAll(tmpDir)) }()
+	writer, err = NewSSTableStreamWriter(
+		WriteBasePath(tmpDir),
+		WithKeyComparator(skiplist.BytesComparator{}),
+		IndexWriteBufferSizeBytes(4096),
+		DataWriteBufferSizeBytes(1024*1024))
+	require.NoError(t, err)
+	assert.Equal(t, 4096, writer.opts.indexWriteBufferSizeBytes)
+	assert.Equal(t, 1024*1024, writer.opts.dataWriteBufferSizeBytes)
+
+	expected := streamedWriteAscendingIntegers(t, writer, 1000)
+	reader, err := NewSSTableReader(ReadBasePath(tmpDir))
+	require.NoError(t, err)
+	defer closeReader(t, reader)
+	assertContentMatchesSlice(t, reader, expected)
+}
+
+func TestWriteDisableValueChecksums(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "sstables_WriterNoChecksums")
+	require.NoError(t, err)
+	defer func() { require.NoError(t, os.RemoveAll(tmpDir)) }()
+
+	writer, err := NewSSTableStreamWriter(WriteBasePath(tmpDir), WithKeyComparator(skiplist.BytesComparator{}), DisableValueChecksums())
+	require.NoError(t, err)
+	require.NoError(t, writer.Open())
+	k, v := getKeyValueAsBytes(1)
+	require.NoError(t, writer.WriteNext(k, v))
+	k, v = getKeyValueAsBytes(2)
+	require.NoError(t, writer.WriteNextWithChecksum(k, v, 42))
+	k, v = getKeyValueAsBytes(3)
+	value, err := writer.WriteNextStreaming(k)
+	require.NoError(t, err)
+	_, err = value.Write(v)
+	require.NoError(t, err)
+	require.NoError(t, value.Close())
+	k, v = getKeyValueAsBytes(4)
+	require.NoError(t, NewBatchWriter(writer).WriteBatch([]KV{{Key: k, Value: v}}))
+	require.NoError(t, writer.Close())
+
+	reader, err := NewSSTableReader(ReadBasePath(tmpDir), EnableHashCheckOnReads(), VerifyChecksumsOnScan())
+	require.NoError(t, err)
+	defer closeReader(t, reader)
+	assert.True(t, reader.MetaData().ValueChecksumsDisabled)
+	assert.NotZero(t, reader.MetaData().IndexChecksum)
+	assert.True(t, reader.(*SSTableReader).opts.skipHashCheckOnRead)
+	assert.False(t, reader.(*SSTableReader).opts.verifyChecksumsOnScan)
+
+	it, err := reader.(*SSTableReader).IndexIterator()
+	require.NoError(t, err)
+	for {
+		entry, err := it.Next()
+		if errors.Is(err, Done) {
+			break
+		}
+		require.NoError(t, err)
+		assert.Zero(t, entry.Checksum)
+	}
+	assertContentMatchesSkipList(t, reader, TEST_ONLY_NewSkipListMapWithElements([]int{1, 2, 3, 4}))
+}
+
+func TestWriteWithChecksumFunc(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "sstables_WriterChecksumFunc")
+	require.NoError(t, err)
+	defer func() { require.NoError(t, os.RemoveAll(tmpDir)) }()
+
+	writer, err := NewSSTableStreamWriter(WriteBasePath(tmpDir), WithKeyComparator(skiplist.BytesComparator{}),
+		WithChecksumFunc("fnv64a", fnv.New64a))
+	require.NoError(t, err)
+	expected := streamedWriteAscendingIntegers(t, writer, 10)
+	k, v := getKeyValueAsBytes(3)
+
+	_, err = NewSSTableReader(ReadBasePath(tmpDir))
+	assert.ErrorIs(t, err, ErrChecksumFuncMismatch)
+	_, err = NewSSTableReader(ReadBasePath(tmpDir), ReadWithChecksumFunc("crc64", fnv.New64a))
+	assert.ErrorIs(t, err, ErrChecksumFuncMismatch)
+
+	reader, err := NewSSTableReader(ReadBasePath(tmpDir), ReadWithChecksumFunc("fnv64a", fnv.New64a),
+		EnableHashCheckOnReads(), VerifyChecksumsOnScan())
+	require.NoError(t, err)
+	defer closeReader(t, reader)
+	assert.Equal(t, "fnv64a", reader.MetaData().ValueChecksumName)
+
+	_, checksum, err := reader.(*SSTableReader).GetWithChecksum(k)
+	require.NoError(t, err)
+	h := fnv.New64a()
+	_, _ = h.Write(v)
+	assert.Equal(t, h.Sum64(), checksum)
+	it, err := reader.Scan()
+	require.NoError(t, err)
+	assertIteratorMatchesSlice(t, it, expected)
+
+	// tables with the default checksum ignore the function
+	reader, err = NewSSTableReader(ReadBasePath("test_files/SimpleWriteHappyPathSSTableWithCRCHashes"),
+		ReadWithChecksumFunc("fnv64a", fnv.New64a), EnableHashCheckOnReads())
+	require.NoError(t, err)
+	defer closeReader(t, reader)
+	assertContentMatchesSkipList(t, reader, TEST_ONLY_NewSkipListMapWithElements([]int{1, 2, 3, 4, 5, 6, 7}))
+}
+
+func TestWriteWithChecksumFuncErrors(t *testing.T) {
+	_, err := NewSSTableStreamWriter(WriteBasePath("a"), WithKeyComparator(skiplist.BytesComparator{}),
+		WithChecksumFunc("", fnv.New64a))
+	assert.ErrorContains(t, err, "without a name")
+	_, err = NewSSTableStreamWriter(WriteBasePath("a"), WithKeyComparator(skiplist.BytesComparator{}),
+		WithChecksumFunc("fnv64a", nil))
+	assert.ErrorContains(t, err, "no checksum function supplied")
+}
+
+func TestWriteNextStreaming(t *testing.T) {
+	for _, compType := range []int{recordio.CompressionTypeNone, recordio.CompressionTypeSnappy, recordio.CompressionTypeGZIP} {
+		writer, err := newTestSSTableStreamWriterWithDataCompression(compType)
+		require.NoError(t, err)
+		require.NoError(t, writer.Open())
+
+		require.NoError(t, writer.WriteNext(intToByteSlice(1), intToByteSlice(2)))
+		_, err = writer.WriteNextStreaming(intToByteSlice(1))
+		assert.ErrorContains(t, err, "the same key cannot be written more than once")
+
+		value, err := writer.WriteNextStreaming(intToByteSlice(2))
+		require.NoError(t, err)
+		var expected []byte
+		for i := 0; i < 100; i++ {
+			chunk := []byte(fmt.Sprintf("chunk-%d;", i))
+			expected = append(expected, chunk...)
+			_, err := value.Write(chunk)
+			require.NoError(t, err)
+		}
+		assert.ErrorContains(t, writer.WriteNext(intToByteSlice(3), intToByteSlice(4)), "a streamed value is still being written")
+		require.NoError(t, value.Close())
+		assert.Error(t, value.Close())
+
+		require.NoError(t, writer.WriteNext(intToByteSlice(3), intToByteSlice(4)))
+		require.NoError(t, writer.Close())
+
+		// the checksum of the streamed value is verified on load
+		reader, err := NewSSTableReader(ReadBasePath(writer.opts.basePath), EnableHashCheckOnReads())
+		require.NoError(t, err)
+		assert.Equal(t, uint64(3), reader.MetaData().NumRecords)
+		v, err := reader.Get(intToByteSlice(2))
+		require.NoError(t, err)
+		assert.Equal(t, expected, v)
+		v, err = reader.Get(intToByteSlice(3))
+		require.NoError(t, err)
+		assert.Equal(t, intToByteSlice(4), v)
+		closeReader(t, reader)
+		cleanWriterDir(t, writer)
+	}
+}
+
+func TestWriteNextWithChecksum(t *testing.T) {
+	writer, err := newTestSSTableStreamWriter()
+	require.NoError(t, err)
+	defer cleanWriterDir(t, writer)
+	writer.opts.enableBloomFilter = true
+	require.NoError(t, writer.Open())
+
+	for i := 0; i < 10; i++ {
+		k, v := getKeyValueAsBytes(i)
+		checksum := crc64.Checksum(v, crc64.MakeTable(crc64.ISO))
+		if i == 7 {
+			// the checksum is trusted, a wrong one is only detected when reading
+			checksum++
+		}
+		require.NoError(t, writer.WriteNextWithChecksum(k, v, checksum))
+	}
+	k, v := getKeyValueAsBytes(5)
+	assert.ErrorContains(t, writer.WriteNextWithChecksum(k, v, 0), "non-ascending key cannot be written")
+	require.NoError(t, writer.Close())
+
+	_, err = NewSSTableReader(ReadBasePath(writer.opts.basePath))
+	assert.ErrorIs(t, err, ChecksumError{})
+
+	r, err := NewSSTableReader(ReadBasePath(writer.opts.basePath), SkipHashCheckOnLoad(), EnableHashCheckOnReads())
+	require.NoError(t, err)
+	defer closeReader(t, r)
+	reader := r.(*SSTableReader)
+	assert.NotNil(t, reader.bloomFilter)
+	assert.Equal(t, uint64(10), reader.MetaData().NumRecords)
+	for i := 0; i < 10; i++ {
+		k, v := getKeyValueAsBytes(i)
+		actual, err := reader.Get(k)
+		if i == 7 {
+			assert.ErrorIs(t, err, ChecksumError{})
+		} else {
+			require.NoError(t, err)
+			assert.Equal(t, v, actual)
+		}
+	}
+}
+
+func TestWriteNextWithSeq(t *testing.T) {
+	writer, err := newTestSSTableStreamWriter()
+	require.NoError(t, err)
+	defer cleanWriterDir(t, writer)
+	require.NoError(t, writer.Open())
+	for i := 0; i < 10; i++ {
+		k, v := getKeyValueAsBytes(i)
+		if i%2 == 0 {
+			require.NoError(t, writer.WriteNextWithSeq(k, v, uint64(100-i)))
+		} else {
+			require.NoError(t, writer.WriteNext(k, v))
+		}
+	}
+	require.NoError(t, writer.Close())
+
+	expectedSeq := func(i int) uint64 {
+		if i%2 == 0 {
+			return uint64(100 - i)
+		}
+		return 0
+	}
+
+	for _, loaderFunc := range indexLoaders {
+		// the sequence numbers are part of the index checksum that is verified on load
+		r, err := NewSSTableReader(ReadBasePath(writer.opts.basePath), ReadIndexLoader(loaderFunc()))
+		require.NoError(t, err)
+		reader := r.(*SSTableReader)
+
+		for i := 0; i < 10; i++ {
+			k, _ := getKeyValueAsBytes(i)
+			iv, err := reader.index.Get(k)
+			require.NoError(t, err)
+			assert.Equal(t, expectedSeq(i), iv.SequenceNumber)
+		}
+
+		scan, err := reader.Scan()
+		require.NoError(t, err)
+		rangeScan, err := reader.ScanRange(intToByteSlice(0), intToByteSlice(9))
+		require.NoError(t, err)
+		for _, it := range []SSTableIteratorI{scan, rangeScan} {
+			for i := 0; i < 10; i++ {
+				_, _, err := it.Next()
+				require.NoError(t, err)
+				assert.Equal(t, expectedSeq(i), it.(SequencedIteratorI).SequenceNumber())
+			}
+		}
+		closeReader(t, reader)
+	}
+}
+
+func TestWriteNextStreamingWithValidator(t *testing.T) {
+	writer, err := newTestSSTableStreamWriter()
+	require.NoError(t, err)
+	defer cleanWriterDir(t, writer)
+	writer.opts.writeValidator = func(key []byte, value []byte) error { return nil }
+	require.NoError(t, writer.Open())
+	_, err = writer.WriteNextStreaming(intToByteSlice(1))
+	assert.ErrorContains(t, err, "streamed values can't be validated")
+	require.NoError(t, writer.Close())
+}
+
+func TestWriteWithVersioning(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "sstables_WriterVersioned")
+	require.NoError(t, err)
+	writer, err := NewSSTableStreamWriter(WriteBasePath(tmpDir), WithKeyComparator(skiplist.BytesComparator{}), WithVersioning())
+	require.NoError(t, err)
+	defer cleanWriterDir(t, writer)
+	require.NoError(t, writer.Open())
+
+	require.NoError(t, writer.WriteNextWithSeq([]byte{1}, []byte{1}, 5))
+	require.NoError(t, writer.WriteNextWithSeq([]byte{1}, []byte{2}, 6))
+	require.ErrorContains(t, writer.WriteNextWithSeq([]byte{1}, []byte{3}, 6), "strictly increasing sequence numbers")
+	require.ErrorContains(t, writer.WriteNextWithSeq([]byte{1}, []byte{3}, 2), "strictly increasing sequence numbers")
+	require.ErrorContains(t, writer.WriteNext([]byte{1}, []byte{3}), "strictly increasing sequence numbers")
+	// the sequence numbers of different keys are independent
+	require.NoError(t, writer.WriteNextWithSeq([]byte{2}, []byte{1}, 1))
+	require.ErrorContains(t, writer.WriteNextWithSeq([]byte{1}, []byte{1}, 7), "non-ascending key")
+	require.NoError(t, writer.Close())
+
+	assert.True(t, writer.metaData.Versioned)
+	assert.Equal(t, uint64(3), writer.metaData.NumRecords)
+}
+
+func TestWriteWithVersioningRejectsSummaryAndPrefixCompression(t *testing.T) {
+	_, err := NewSSTableStreamWriter(WriteBasePath("a"), WithKeyComparator(skiplist.BytesComparator{}),
+		WithVersioning(), SummaryEveryNthKey(2))
+	require.Error(t, err)
+
+	_, err = NewSSTableStreamWriter(WriteBasePath("a"), WithKeyComparator(skiplist.BytesComparator{}),
+		WithVersioning(), IndexKeyPrefixCompression(4))
+	require.Error(t, err)
+}
+
+func TestWriteWithDirectIO(t *testing.T) {
+	ok, err := recordio.IsDirectIOAvailable()
+	require.NoError(t, err)
+	if !ok {
+		t.Skip("directio not available here")
+		return
+	}
+
+	for _, compressionType := range []int{recordio.CompressionTypeNone, recordio.CompressionTypeSnappy} {
+		tmpDir, err := os.MkdirTemp("", "sstables_WriterDirectIO")
+		require.NoError(t, err)
+		writer, err := NewSSTableStreamWriter(
+			WriteBasePath(tmpDir),
+			WithKeyComparator(skiplist.BytesComparator{}),
+			DataCompressionType(compressionType),
+			UseDirectIO())
+		require.NoError(t, err)
+		expected := streamedWriteAscendingIntegers(t, writer, 1000)
+
+		assertRandomAndSequentialRead(t, tmpDir, expected)
+		cleanWriterDir(t, writer)
+	}
+}
+
+func TestWriteWithDirectIOUnalignedBuffer(t *testing.T) {
+	_, err := NewSSTableStreamWriter(WriteBasePath("a"), WithKeyComparator(skiplist.BytesComparator{}),
+		UseDirectIO(), DataWriteBufferSizeBytes(1000))
+	require.ErrorContains(t, err, "multiple of")
+}
+
+func TestWriteWithFadviseDontNeedOnClose(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "sstables_WriterFadvise")
+	require.NoError(t, err)
+	writer, err := NewSSTableStreamWriter(WriteBasePath(tmpDir), WithKeyComparator(skiplist.BytesComparator{}), FadviseDontNeedOnClose())
+	require.NoError(t, err)
+	defer cleanWriterDir(t, writer)
+	expected := streamedWriteAscendingIntegers(t, writer, 100)
+
+	assertRandomAndSequentialRead(t, tmpDir, expected)
+}
+
+func TestWriteAtomic(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "sstables_WriterAtomic")
+	require.NoError(t, err)
+	defer func() { require.NoError(t, os.RemoveAll(tmpDir)) }()
+
+	basePath := filepath.Join(tmpDir, "table")
+	writer, err := NewSSTableStreamWriter(WriteBasePath(basePath), WithKeyComparator(skiplist.BytesComparator{}), WriteAtomic())
+	require.NoError(t, err)
+	require.NoError(t, writer.Open())
+	var expected []int
+	for i := 0; i < 100; i++ {
+		k, v := getKeyValueAsBytes(i)
+		require.NoError(t, writer.WriteNext(k, v))
+		expected = append(expected, i)
+	}
+	assert.NoDirExists(t, basePath)
+	assert.DirExists(t, basePath+".tmp")
+
+	require.NoError(t, writer.Close())
+	assert.NoDirExists(t, basePath+".tmp")
+	assertRandomAndSequentialRead(t, basePath, expected)
+}
+
+func TestWriteAtomicIntoEmptyBasePath(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "sstables_WriterAtomic")
+	require.NoError(t, err)
+	defer func() { require.NoError(t, os.RemoveAll(tmpDir)) }()
+
+	basePath := filepath.Join(tmpDir, "table")
+	stagingPath := filepath.Join(tmpDir, "staging")
+	require.NoError(t, os.Mkdir(basePath, 0700))
+	writer, err := NewSSTableStreamWriter(WriteBasePath(basePath), WithKeyComparator(skiplist.BytesComparator{}),
+		WriteStagingPath(stagingPath))
+	require.NoError(t, err)
+	expected := streamedWriteAscendingIntegers(t, writer, 100)
+
+	assert.NoDirExists(t, stagingPath)
+	assertRandomAndSequentialRead(t, basePath, expected)
+}
+
+func TestWriteAtomicRemovesStagingOnError(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "sstables_WriterAtomic")
+	require.NoError(t, err)
+	defer func() { require.NoError(t, os.RemoveAll(tmpDir)) }()
+
+	basePath := filepath.Join(tmpDir, "table")
+	writer, err := NewSSTableStreamWriter(WriteBasePath(basePath), WithKeyComparator(skiplist.BytesComparator{}), WriteAtomic())
+	require.NoError(t, err)
+	require.NoError(t, writer.Open())
+	k, v := getKeyValueAsBytes(1)
+	require.NoError(t, writer.WriteNext(k, v))
+
+	// another table was written into the base path in the meantime, which must not be replaced
+	require.NoError(t, os.Mkdir(basePath, 0700))
+	require.NoError(t, os.WriteFile(filepath.Join(basePath, DataFileName), []byte{1}, 0666))
+	require.Error(t, writer.Close())
+	assert.NoDirExists(t, basePath+".tmp")
+	assert.FileExists(t, filepath.Join(basePath, DataFileName))
+
+	_, err = NewSSTableStreamWriter(WriteBasePath(basePath), WithKeyComparator(skiplist.BytesComparator{}),
+		WriteStagingPath(basePath))
+	require.Error(t, err)
+
+	writer, err = NewSSTableStreamWriter(WriteBasePath(basePath), WithKeyComparator(skiplist.BytesComparator{}), WriteAtomic())
+	require.NoError(t, err)
+	require.ErrorContains(t, writer.Open(), "is not empty")
+}
+
+func TestWriteWithGzipCompressionLevel(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "sstables_WriterGzipLevel")
+	require.NoError(t, err)
+	writer, err := NewSSTableStreamWriter(
+		WriteBasePath(tmpDir),
+		WithKeyComparator(skiplist.BytesComparator{}),
+		DataCompressionType(recordio.CompressionTypeGZIP),
+		IndexCompressionType(recordio.CompressionTypeGZIP),
+		CompressionLevel(gzip.BestCompression))
+	require.NoError(t, err)
+	defer cleanWriterDir(t, writer)
+	estimator, err := NewSSTableStreamWriter(
+		EstimateOnly(),
+		WithKeyComparator(skiplist.BytesComparator{}),
+		DataCompressionType(recordio.CompressionTypeGZIP),
+		IndexCompressionType(recordio.CompressionTypeGZIP),
+		CompressionLevel(gzip.BestCompression))
+	require.NoError(t, err)
+	estimated := streamedWriteAscendingIntegers(t, estimator, 100)
+	expected := streamedWriteAscendingIntegers(t, writer, 100)
+	require.Equal(t, expected, estimated)
+
+	assertRandomAndSequentialRead(t, tmpDir, expected)
+	reader, err := NewSSTableReader(ReadBasePath(tmpDir))
+	require.NoError(t, err)
+	defer closeReader(t, reader)
+	assert.Equal(t, reader.MetaData().DataBytes, estimator.metaData.DataBytes)
+	assert.Equal(t, reader.MetaData().IndexBytes, estimator.metaData.IndexBytes)
+}
+
+func TestWriteWithProgress(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "sstables_WriterProgress")
+	require.NoError(t, err)
+	var records []uint64
+	var bytes []uint64
+	writer, err := NewSSTableStreamWriter(
+		WriteBasePath(tmpDir),
+		WithKeyComparator(skiplist.BytesComparator{}),
+		WithProgress(func(recordsWritten uint64, bytesWritten uint64) {
+			records = append(records, recordsWritten)
+			bytes = append(bytes, bytesWritten)
+		}),
+		ProgressEveryNRecords(10))
+	require.NoError(t, err)
+	defer cleanWriterDir(t, writer)
+	expected := streamedWriteAscendingIntegers(t, writer, 25)
+
+	assert.Equal(t, []uint64{10, 20, 25}, records)
+	for i := 1; i < len(bytes); i++ {
+		assert.Greater(t, bytes[i], bytes[i-1])
+	}
+	assertRandomAndSequentialRead(t, tmpDir, expected)
+	reader, err := NewSSTableReader(ReadBasePath(tmpDir))
+	require.NoError(t, err)
+	defer closeReader(t, reader)
+	assert.Equal(t, reader.MetaData().TotalBytes, bytes[len(bytes)-1])
+}
+
+func TestWriteWithProgressNoFinalReportOnBoundary(t *testing.T) {
+	var records []uint64
+	writer, err := NewSSTableStreamWriter(
+		EstimateOnly(),
+		WithKeyComparator(skiplist.BytesComparator{}),
+		WithProgress(func(recordsWritten uint64, bytesWritten uint64) {
+			records = append(records, recordsWritten)
+		}),
+		ProgressEveryNRecords(5))
+	require.NoError(t, err)
+	streamedWriteAscendingIntegers(t, writer, 20)
+	assert.Equal(t, []uint64{5, 10, 15, 20}, records)
+}
+
+func TestWriteValueSizeHistogram(t *testing.T) {
+	writer, err := newTestSSTableStreamWriter()
+	require.NoError(t, err)
+	defer cleanWriterDir(t, writer)
+	require.NoError(t, writer.Open())
+
+	values := [][]byte{nil, {}, {1}, {1, 2, 3}, {1, 2, 3, 4}, make([]byte, 1000)}
+	for i, v := range values {
+		require.NoError(t, writer.WriteNext(intToByteSlice(i), v))
+	}
+	// streamed values are counted once they are closed
+	value, err := writer.WriteNextStreaming(intToByteSlice(len(values)))
+	require.NoError(t, err)
+	_, err = value.Write([]byte{1, 2})
+	require.NoError(t, err)
+	_, err = value.Write([]byte{3, 4, 5})
+	require.NoError(t, err)
+	require.NoError(t, value.Close())
+	require.NoError(t, writer.Close())
+
+	reader, err := NewSSTableReader(ReadBasePath(writer.opts.basePath))
+	require.NoError(t, err)
+	defer closeReader(t, reader)
+	assert.Equal(t, []uint64{2, 1, 1, 2, 0, 0, 0, 0, 0, 0, 1}, reader.MetaData().ValueSizeHistogram)
+}
+
+func TestValueSizeBuckets(t *testing.T) {
+	assert.Equal(t, 0, valueSizeBucket(0))
+	assert.Equal(t, 1, valueSizeBucket(1))
+	assert.Equal(t, 11, valueSizeBucket(1024))
+	assert.Equal(t, ValueSizeHistogramBuckets-1, valueSizeBucket(1<<40))
+
+	for bucket := 0; bucket < ValueSizeHistogramBuckets; bucket++ {
+		assert.Equal(t, bucket, valueSizeBucket(int(ValueSizeBucketLowerBound(bucket))))
+	}
+	assert.Equal(t, uint64(1024), ValueSizeBucketLowerBound(11))
+}
+
+func TestWriteWithMinCompressSizeBytes(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "sstables_WriterMinCompressSize")
+	require.NoError(t, err)
+	opts := []WriterOption{
+		WithKeyComparator(skiplist.BytesComparator{}),
+		DataCompressionType(recordio.CompressionTypeSnappy),
+		MinCompressSizeBytes(64),
+	}
+	writer, err := NewSSTableStreamWriter(append(opts, WriteBasePath(tmpDir))...)
+	require.NoError(t, err)
+	defer cleanWriterDir(t, writer)
+	estimator, err := NewSSTableStreamWriter(append(opts, EstimateOnly())...)
+	require.NoError(t, err)
+
+	// every other value is large enough to be compressed
+	values := make([][]byte, 100)
+	require.NoError(t, writer.Open())
+	require.NoError(t, estimator.Open())
+	for i := range values {
+		k, v := getKeyValueAsBytes(i)
+		if i%2 == 0 {
+			v = bytes.Repeat(v, 256)
+		}
+		values[i] = v
+		require.NoError(t, writer.WriteNext(k, v))
+		require.NoError(t, estimator.WriteNext(k, v))
+	}
+	require.NoError(t, writer.Close())
+	require.NoError(t, estimator.Close())
+
+	reader, err := NewSSTableReader(ReadBasePath(tmpDir))
+	require.NoError(t, err)
+	defer closeReader(t, reader)
+	assert.Equal(t, reader.MetaData().DataBytes, estimator.metaData.DataBytes)
+	for i, v := range values {
+		k, _ := getKeyValueAsBytes(i)
+		actual, err := reader.Get(k)
+		require.NoError(t, err)
+		assert.Equal(t, v, actual)
+	}
+
+	it, err := reader.Scan()
+	require.NoError(t, err)
+	for _, v := range values {
+		_, actual, err := it.Next()
+		require.NoError(t, err)
+		assert.Equal(t, v, actual)
+	}
+	_, _, err = it.Next()
+	assert.ErrorIs(t, err, Done)
+}
+
+func TestWriteWithInvalidMinCompressSizeBytes(t *testing.T) {
+	_, err := NewSSTableStreamWriter(WriteBasePath("a"), WithKeyComparator(skiplist.BytesComparator{}), MinCompressSizeBytes(-1))
+	require.Error(t, err)
+}
+
+func TestWriteWithInvalidProgressInterval(t *testing.T) {
+	_, err := NewSSTableStreamWriter(WriteBasePath("a"), WithKeyComparator(skiplist.BytesComparator{}), ProgressEveryNRecords(0))
+	require.Error(t, err)
+}
+
+func TestWriteWithRateLimiter(t *testing.T) {
+	for _, compressionType := range []int{recordio.CompressionTypeNone, recordio.CompressionTypeSnappy} {
+		tmpDir, err := os.MkdirTemp("", "sstables_RateLimiter")
+		require.NoError(t, err)
+		limiter := &countingRateLimiter{}
+		writer, err := NewSSTableStreamWriter(WriteBasePath(tmpDir), WithKeyComparator(skiplist.BytesComparator{}),
+			DataCompressionType(compressionType), WithRateLimiter(nil, limiter))
+		require.NoError(t, err)
+		require.NoError(t, writer.Open())
+		k, _ := getKeyValueAsBytes(0)
+		require.NoError(t, writer.WriteNext(k, make([]byte, 4096)))
+		k, _ = getKeyValueAsBytes(1)
+		value, err := writer.WriteNextStreaming(k)
+		require.NoError(t, err)
+		_, err = value.Write(make([]byte, 1024))
+		require.NoError(t, err)
+		require.NoError(t, value.Close())
+		var batch []KV
+		for i := 2; i < 100; i++ {
+			k, v := getKeyValueAsBytes(i)
+			batch = append(batch, KV{Key: k, Value: v})
+		}
+		require.NoError(t, NewBatchWriter(writer).WriteBatch(batch))
+		require.NoError(t, writer.Close())
+
+		// every byte of the data file except for its header went through the limiter
+		assert.Equal(t, int64(100), limiter.calls.Load())
+		assert.Equal(t, int64(writer.metaData.DataBytes-recordio.FileHeaderSizeBytes), limiter.bytes.Load())
+		if compressionType == recordio.CompressionTypeSnappy {
+			assert.Less(t, limiter.bytes.Load(), int64(4096+1024))
+		}
+		cleanWriterDir(t, writer)
+	}
+}
+
+func TestWriteWithRateLimiterContextDone(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "sstables_RateLimiter")
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	writer, err := NewSSTableStreamWriter(WriteBasePath(tmpDir), WithKeyComparator(skiplist.BytesComparator{}),
+		WithRateLimiter(ctx, NewTokenBucketRateLimiter(1)))
+	require.NoError(t, err)
+	defer cleanWriterDir(t, writer)
+	require.NoError(t, writer.Open())
+
+	// a single token isn't enough for any record, so the write has to wait
+	cancel()
+	k, v := getKeyValueAsBytes(0)
+	assert.ErrorIs(t, writer.WriteNext(k, v), context.Canceled)
+	require.NoError(t, writer.Close())
+}
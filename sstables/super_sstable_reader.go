@@ -36,7 +36,7 @@ func (s SuperSSTableReader) Get(key []byte) ([]byte, error) {
 	for i := len(s.readers) - 1; i >= 0; i-- {
 		res, err := s.readers[i].Get(key)
 		if err != nil {
-			if errors.Is(err, NotFound) {
+			if errors.Is(err, ErrKeyNotFound) {
 				continue
 			}
 			return nil, err
@@ -45,7 +45,7 @@ func (s SuperSSTableReader) Get(key []byte) ([]byte, error) {
 		return res, nil
 	}
 
-	return nil, NotFound
+	return nil, ErrKeyNotFound
 }
 
 func (s SuperSSTableReader) Scan() (SSTableIteratorI, error) {
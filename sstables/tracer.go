@@ -0,0 +1,60 @@
+package sstables
+
+import "errors"
+
+// Tracer starts spans around opening a table, every Get and full scans, see ReadWithTracer. The attrs are alternating
+// keys and values like in log/slog, which makes it simple to adapt to OpenTelemetry or any other tracing library.
+type Tracer interface {
+	StartSpan(name string, attrs ...any) Span
+}
+
+// Span is a single operation started by a Tracer.
+type Span interface {
+	// End finishes the span, attrs are the attributes that are only known once the operation is done.
+	End(attrs ...any)
+}
+
+// endSpan ends the span with the given attributes, adding the error unless it's nil
+func endSpan(span Span, err error, attrs ...any) {
+	if err != nil {
+		attrs = append(attrs, "error", err)
+	}
+	span.End(attrs...)
+}
+
+// tracedScanIterator ends the span of a full scan once the iterator is exhausted or fails, with the number of records
+// and the bytes of all keys and values it returned.
+type tracedScanIterator struct {
+	it      SSTableIteratorI
+	span    Span
+	records uint64
+	bytes   uint64
+	ended   bool
+}
+
+func (t *tracedScanIterator) Next() ([]byte, []byte, error) {
+	k, v, err := t.it.Next()
+	var checksumErr ScanChecksumError
+	if err == nil || errors.As(err, &checksumErr) {
+		t.records++
+		t.bytes += uint64(len(k) + len(v))
+		return k, v, err
+	}
+
+	if !t.ended {
+		t.ended = true
+		spanErr := err
+		if errors.Is(err, Done) {
+			spanErr = nil
+		}
+		endSpan(t.span, spanErr, "records", t.records, "bytes", t.bytes)
+	}
+	return k, v, err
+}
+
+func (t *tracedScanIterator) SequenceNumber() uint64 {
+	if it, ok := t.it.(SequencedIteratorI); ok {
+		return it.SequenceNumber()
+	}
+	return 0
+}
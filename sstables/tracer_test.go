@@ -0,0 +1,103 @@
+package sstables
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordedSpan struct {
+	name  string
+	attrs map[string]any
+	ended bool
+}
+
+func (s *recordedSpan) End(attrs ...any) {
+	for i := 0; i+1 < len(attrs); i += 2 {
+		s.attrs[attrs[i].(string)] = attrs[i+1]
+	}
+	s.ended = true
+}
+
+// recordingTracer keeps all spans in memory
+type recordingTracer struct {
+	mu    sync.Mutex
+	spans []*recordedSpan
+}
+
+func (r *recordingTracer) StartSpan(name string, attrs ...any) Span {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	span := &recordedSpan{name: name, attrs: map[string]any{}}
+	for i := 0; i+1 < len(attrs); i += 2 {
+		span.attrs[attrs[i].(string)] = attrs[i+1]
+	}
+	r.spans = append(r.spans, span)
+	return span
+}
+
+func (r *recordingTracer) last() *recordedSpan {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.spans[len(r.spans)-1]
+}
+
+func TestReadWithTracer(t *testing.T) {
+	writer, err := newTestSSTableStreamWriter()
+	require.NoError(t, err)
+	defer cleanWriterDir(t, writer)
+	expected := streamedWriteAscendingIntegers(t, writer, 100)
+
+	tracer := &recordingTracer{}
+	reader, err := NewSSTableReader(ReadBasePath(writer.opts.basePath), ReadWithTracer(tracer))
+	require.NoError(t, err)
+	defer closeReader(t, reader)
+
+	open := tracer.last()
+	assert.Equal(t, "sstables.Open", open.name)
+	assert.True(t, open.ended)
+	assert.Equal(t, writer.opts.basePath, open.attrs["path"])
+	assert.Equal(t, uint64(100), open.attrs["records"])
+	assert.Equal(t, writer.metaData.IndexBytes, open.attrs["indexBytes"])
+
+	k, v := getKeyValueAsBytes(42)
+	_, err = reader.Get(k)
+	require.NoError(t, err)
+	get := tracer.last()
+	assert.Equal(t, "sstables.Get", get.name)
+	assert.True(t, get.ended)
+	assert.Equal(t, true, get.attrs["found"])
+	assert.Equal(t, len(v), get.attrs["bytes"])
+
+	_, err = reader.Get(intToByteSlice(1000))
+	assert.Equal(t, ErrKeyNotFound, err)
+	assert.Equal(t, false, tracer.last().attrs["found"])
+	assert.NotContains(t, tracer.last().attrs, "error")
+
+	it, err := reader.Scan()
+	require.NoError(t, err)
+	scan := tracer.last()
+	assert.Equal(t, "sstables.Scan", scan.name)
+	assert.False(t, scan.ended)
+	_, ok := it.(SequencedIteratorI)
+	assert.True(t, ok)
+	assertIteratorMatchesSlice(t, it, expected)
+	assert.True(t, scan.ended)
+	assert.Equal(t, uint64(100), scan.attrs["records"])
+	assert.Greater(t, scan.attrs["bytes"], uint64(0))
+	assert.Len(t, tracer.spans, 4)
+}
+
+func TestReadWithTracerOpenError(t *testing.T) {
+	tracer := &recordingTracer{}
+	_, err := NewSSTableReader(
+		ReadBasePath("test_files/SimpleWriteHappyPathSSTableWithCRCHashesMismatch"),
+		ReadWithTracer(tracer))
+	require.ErrorIs(t, err, ChecksumError{})
+	open := tracer.last()
+	assert.True(t, open.ended)
+	assert.Equal(t, err, open.attrs["error"])
+	assert.NotContains(t, open.attrs, "records")
+}
@@ -103,6 +103,9 @@ func setupNextWriter(a *Appender) error {
 	return nil
 }
 
+// NewAppender creates an appender that writes into a new WAL file, which is numbered after the existing WAL files and
+// the checkpoint under the base path. Existing files are never overwritten, so they can still be replayed after a restart.
+// Only a torn record at the end of the newest file, which a crash during an append leaves behind, is truncated.
 func NewAppender(walOpts *Options) (WriteAheadLogAppendI, error) {
 	appender := &Appender{
 		walOptions:         walOpts,
@@ -111,7 +114,41 @@ func NewAppender(walOpts *Options) (WriteAheadLogAppendI, error) {
 		currentWriter:      nil,
 	}
 
-	err := setupNextWriter(appender)
+	walFiles, err := listWalFiles(walOpts.basePath)
+	if err != nil {
+		return nil, err
+	}
+
+	checkpoint, err := readCheckpoint(walOpts.basePath)
+	if err != nil {
+		return nil, err
+	}
+
+	// the checkpoint outlives the files it covers, new files must still be numbered after it to be replayed
+	lastName := checkpoint
+	if len(walFiles) > 0 && filepath.Base(walFiles[len(walFiles)-1]) > lastName {
+		lastName = filepath.Base(walFiles[len(walFiles)-1])
+	}
+
+	// a crash during an append leaves a torn record at the end of the newest file, which would no longer be the last
+	// file to replay after the new one is created
+	if len(walFiles) > 0 && filepath.Base(walFiles[len(walFiles)-1]) > checkpoint {
+		_, err = recordio.TruncateTornRecord(walFiles[len(walFiles)-1])
+		if err != nil {
+			return nil, fmt.Errorf("error while truncating the torn record of wal file '%s': %w", walFiles[len(walFiles)-1], err)
+		}
+	}
+
+	if lastName != "" {
+		var lastNumber uint
+		_, err = fmt.Sscanf(lastName, defaultWalFilePattern, &lastNumber)
+		if err != nil {
+			return nil, fmt.Errorf("error while parsing the number of wal file '%s': %w", lastName, err)
+		}
+		appender.nextWriterNumber = lastNumber + 1
+	}
+
+	err = setupNextWriter(appender)
 	if err != nil {
 		return nil, err
 	}
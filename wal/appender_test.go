@@ -153,3 +153,15 @@ func appendAndRecord(t *testing.T, wal WriteAheadLogAppendI, record []byte, reco
 	err := wal.AppendSync(record)
 	require.Nil(t, err)
 }
+
+func TestAppenderContinuesAfterExistingFiles(t *testing.T) {
+	log, recorder := singleRecordWal(t, "wal_appenderContinues")
+
+	restarted, err := NewAppender(log.walOptions)
+	require.Nil(t, err)
+	assert.Equal(t, uint(2), restarted.(*Appender).nextWriterNumber)
+	appendAndRecord(t, restarted, []byte{2}, &recorder)
+	require.Nil(t, restarted.Close())
+
+	assertRecorderMatchesReplay(t, log.walOptions, recorder)
+}
@@ -0,0 +1,68 @@
+package wal
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// checkpointFileName contains the name of the last WAL file whose records were flushed, it doesn't have the WAL suffix
+// to not be replayed itself
+const checkpointFileName = "checkpoint"
+
+// readCheckpoint returns the name of the last flushed WAL file, empty when there is no checkpoint yet
+func readCheckpoint(basePath string) (string, error) {
+	content, err := os.ReadFile(filepath.Join(basePath, checkpointFileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("error while reading wal checkpoint under '%s': %w", basePath, err)
+	}
+	return strings.TrimSpace(string(content)), nil
+}
+
+// writeCheckpoint durably replaces the checkpoint with the name of the given WAL file, the file is written under a
+// temporary name first and then renamed, so a crash leaves either the old or the new checkpoint.
+func writeCheckpoint(basePath string, walPath string) (err error) {
+	tmpPath := filepath.Join(basePath, checkpointFileName+".tmp")
+	f, err := os.OpenFile(tmpPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0666)
+	if err != nil {
+		return fmt.Errorf("error while creating wal checkpoint under '%s': %w", basePath, err)
+	}
+
+	_, err = f.WriteString(filepath.Base(walPath))
+	if err == nil {
+		err = f.Sync()
+	}
+	err = errors.Join(err, f.Close())
+	if err != nil {
+		return fmt.Errorf("error while writing wal checkpoint under '%s': %w", basePath, err)
+	}
+
+	err = os.Rename(tmpPath, filepath.Join(basePath, checkpointFileName))
+	if err != nil {
+		return fmt.Errorf("error while renaming wal checkpoint under '%s': %w", basePath, err)
+	}
+
+	dir, err := os.Open(basePath)
+	if err != nil {
+		return fmt.Errorf("error while syncing wal checkpoint under '%s': %w", basePath, err)
+	}
+	return errors.Join(dir.Sync(), dir.Close())
+}
+
+// Checkpoint marks all records up to and including the WAL file at the given path as flushed and removes those files.
+// The path is usually returned by Rotate right before the memstore is flushed, Checkpoint must only be called once the
+// flushed table is complete. Replays start after the checkpoint, so a crash after the checkpoint was written, but
+// before the files were removed, doesn't replay the flushed records again. A crash between the flush and the checkpoint
+// replays them into the memstore again, from where they are flushed into a newer table.
+func (w *WriteAheadLog) Checkpoint(flushedPath string) error {
+	err := writeCheckpoint(w.opts.basePath, flushedPath)
+	if err != nil {
+		return err
+	}
+	return w.CleanUpTo(flushedPath)
+}
@@ -0,0 +1,80 @@
+package wal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func replayAll(t *testing.T, opts *Options) [][]byte {
+	repl, err := NewReplayer(opts)
+	require.Nil(t, err)
+	var replayed [][]byte
+	require.Nil(t, repl.Replay(func(record []byte) error {
+		replayed = append(replayed, record)
+		return nil
+	}))
+	return replayed
+}
+
+func TestCheckpointCrashBetweenFlushAndTruncate(t *testing.T) {
+	wal := newTestWal(t, "wal_checkpointCrash")
+	require.Nil(t, wal.AppendSync([]byte{1}))
+	require.Nil(t, wal.AppendSync([]byte{2}))
+	flushedPath, err := wal.Rotate()
+	require.Nil(t, err)
+	// the memstore with the first two records is flushed here, while the next record is already acknowledged
+	require.Nil(t, wal.AppendSync([]byte{3}))
+
+	// crash after the checkpoint was written, but before the flushed files were removed
+	require.Nil(t, writeCheckpoint(wal.opts.basePath, flushedPath))
+	require.Nil(t, wal.Close())
+	_, err = os.Stat(flushedPath)
+	require.Nil(t, err)
+
+	// on restart, only the records after the checkpoint are replayed, new appends go into a new file
+	restarted, err := NewWriteAheadLog(wal.opts)
+	require.Nil(t, err)
+	assert.Equal(t, [][]byte{{3}}, replayAll(t, wal.opts))
+	require.Nil(t, restarted.AppendSync([]byte{4}))
+	require.Nil(t, restarted.Close())
+	assert.Equal(t, [][]byte{{3}, {4}}, replayAll(t, wal.opts))
+}
+
+func TestCheckpointRemovesFlushedFiles(t *testing.T) {
+	wal := newTestWal(t, "wal_checkpoint")
+	require.Nil(t, wal.AppendSync([]byte{1}))
+	flushedPath, err := wal.Rotate()
+	require.Nil(t, err)
+	require.Nil(t, wal.AppendSync([]byte{2}))
+	require.Nil(t, wal.Checkpoint(flushedPath))
+
+	_, err = os.Stat(flushedPath)
+	assert.True(t, os.IsNotExist(err))
+	assert.Equal(t, [][]byte{{2}}, replayAll(t, wal.opts))
+
+	secondFlushedPath, err := wal.Rotate()
+	require.Nil(t, err)
+	require.Nil(t, wal.Checkpoint(secondFlushedPath))
+	require.Nil(t, wal.Close())
+
+	// the only remaining file is empty, numbering must continue after it and the checkpoint when it's gone too
+	files, err := listWalFiles(wal.opts.basePath)
+	require.Nil(t, err)
+	for _, f := range files {
+		require.Nil(t, os.Remove(f))
+	}
+	restarted, err := NewAppender(wal.opts)
+	require.Nil(t, err)
+	assert.Equal(t, uint(3), restarted.(*Appender).nextWriterNumber)
+	require.Nil(t, restarted.AppendSync([]byte{3}))
+	require.Nil(t, restarted.Close())
+	assert.Equal(t, [][]byte{{3}}, replayAll(t, wal.opts))
+
+	checkpoint, err := readCheckpoint(wal.opts.basePath)
+	require.Nil(t, err)
+	assert.Equal(t, filepath.Base(secondFlushedPath), checkpoint)
+}
@@ -3,6 +3,7 @@ package wal
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 )
 
 type Cleaner struct {
@@ -17,6 +18,27 @@ func (c *Cleaner) Clean() error {
 	return nil
 }
 
+// CleanUpTo removes the WAL files up to and including the one at the given path, which is usually the path returned by
+// Rotate once the memstore with its records was flushed. The current file of the appender must not be removed.
+func (c *Cleaner) CleanUpTo(path string) error {
+	walFiles, err := listWalFiles(c.walOptions.basePath)
+	if err != nil {
+		return err
+	}
+
+	for _, walFile := range walFiles {
+		if filepath.Base(walFile) > filepath.Base(path) {
+			break
+		}
+
+		err = os.Remove(walFile)
+		if err != nil {
+			return fmt.Errorf("error while cleaning wal file '%s': %w", walFile, err)
+		}
+	}
+	return nil
+}
+
 func NewCleaner(opts *Options) WriteAheadLogCleanI {
 	return &Cleaner{walOptions: opts}
 }
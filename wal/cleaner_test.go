@@ -17,3 +17,23 @@ func TestSimpleDeleteHappyPath(t *testing.T) {
 	_, err = os.Stat(log.walOptions.basePath)
 	assert.NotNil(t, err)
 }
+
+func TestCleanUpToRotatedFile(t *testing.T) {
+	wal := newTestWal(t, "wal_cleanUpTo")
+	require.Nil(t, wal.AppendSync([]byte{1}))
+	flushedPath, err := wal.Rotate()
+	require.Nil(t, err)
+	require.Nil(t, wal.AppendSync([]byte{2}))
+
+	require.Nil(t, wal.CleanUpTo(flushedPath))
+	_, err = os.Stat(flushedPath)
+	assert.True(t, os.IsNotExist(err))
+
+	var replayed [][]byte
+	err = wal.Replay(func(record []byte) error {
+		replayed = append(replayed, record)
+		return nil
+	})
+	require.Nil(t, err)
+	assert.Equal(t, [][]byte{{2}}, replayed)
+}
@@ -15,26 +15,23 @@ type Replayer struct {
 	walOptions *Options
 }
 
+// Replay reads all WAL files after the last checkpoint in order, see WriteAheadLog.Checkpoint. A torn record at the end of the last WAL file, which is left behind by a crash
+// during an append, ends the replay without an error. Torn records in any other file are returned as errors.
 func (r *Replayer) Replay(process func(record []byte) error) (err error) {
-	var walFiles []string
-	err = filepath.Walk(r.walOptions.basePath, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-
-		if !info.IsDir() && strings.HasSuffix(info.Name(), defaultWalSuffix) {
-			walFiles = append(walFiles, path)
-		}
-
-		return nil
-	})
+	walFiles, err := listWalFiles(r.walOptions.basePath)
+	if err != nil {
+		return err
+	}
 
+	checkpoint, err := readCheckpoint(r.walOptions.basePath)
 	if err != nil {
-		return fmt.Errorf("error while walking WAL structure under '%s': %w", r.walOptions.basePath, err)
+		return err
 	}
 
-	// do not rely on the order of the FS, we do an additional sort to make sure we start reading from 0000 to 9999
-	sort.Strings(walFiles)
+	// the files up to the checkpoint were flushed already, they are only left over when their removal didn't complete
+	for len(walFiles) > 0 && filepath.Base(walFiles[0]) <= checkpoint {
+		walFiles = walFiles[1:]
+	}
 
 	var toClose []recordio.ReaderI
 	defer func() {
@@ -43,7 +40,7 @@ func (r *Replayer) Replay(process func(record []byte) error) (err error) {
 		}
 	}()
 
-	for _, path := range walFiles {
+	for i, path := range walFiles {
 		reader, err := r.walOptions.readerFactory(path)
 		if err != nil {
 			return fmt.Errorf("error while creating WAL reader under '%s': %w", path, err)
@@ -62,6 +59,11 @@ func (r *Replayer) Replay(process func(record []byte) error) (err error) {
 				break
 			}
 
+			// the last append didn't complete, everything before it was replayed already
+			if errors.Is(err, io.ErrUnexpectedEOF) && i == len(walFiles)-1 {
+				break
+			}
+
 			if err != nil {
 				return fmt.Errorf("error while reading WAL records under '%s': %w", path, err)
 			}
@@ -76,6 +78,30 @@ func (r *Replayer) Replay(process func(record []byte) error) (err error) {
 	return nil
 }
 
+// listWalFiles returns the paths of all WAL files under the base path in the order they were written
+func listWalFiles(basePath string) ([]string, error) {
+	var walFiles []string
+	err := filepath.Walk(basePath, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !info.IsDir() && strings.HasSuffix(info.Name(), defaultWalSuffix) {
+			walFiles = append(walFiles, path)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("error while walking WAL structure under '%s': %w", basePath, err)
+	}
+
+	// do not rely on the order of the FS, we do an additional sort to make sure we start reading from 0000 to 9999
+	sort.Strings(walFiles)
+	return walFiles, nil
+}
+
 func NewReplayer(walOpts *Options) (WriteAheadLogReplayI, error) {
 	stat, err := os.Stat(walOpts.basePath)
 	if err != nil {
@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/thomasjungblut/go-sstables/recordio"
+	"io"
 	"os"
 	"path/filepath"
 	"testing"
@@ -47,3 +49,56 @@ func TestReplayHonorsCallbackErrors(t *testing.T) {
 	})
 	assert.True(t, errors.Is(err, testErr))
 }
+
+func TestReplayStopsAtTornTrailingRecord(t *testing.T) {
+	log := newTestWalAppender(t, "wal_replaytorntrailing")
+	var recorder [][]byte
+	appendAndRecord(t, log, []byte{1, 2, 3}, &recorder)
+	_, err := log.Rotate()
+	require.Nil(t, err)
+	appendAndRecord(t, log, []byte{4, 5, 6}, &recorder)
+	require.Nil(t, log.AppendSync(make([]byte, 100)))
+	require.Nil(t, log.Close())
+
+	// simulate a crash in the middle of writing the last record
+	stat, err := os.Stat(log.currentWriterPath)
+	require.Nil(t, err)
+	require.Nil(t, os.Truncate(log.currentWriterPath, stat.Size()-10))
+
+	assertRecorderMatchesReplay(t, log.walOptions, recorder)
+}
+
+func TestReplayStopsAtTornTrailingRecordHeader(t *testing.T) {
+	log := newTestWalAppender(t, "wal_replaytorntrailingheader")
+	var recorder [][]byte
+	appendAndRecord(t, log, []byte{1, 2, 3}, &recorder)
+	stat, err := os.Stat(log.currentWriterPath)
+	require.Nil(t, err)
+	require.Nil(t, log.AppendSync([]byte{4, 5, 6}))
+	require.Nil(t, log.Close())
+
+	// the crash happened right after the magic number of the last record header
+	require.Nil(t, os.Truncate(log.currentWriterPath, stat.Size()+int64(len(recordio.MagicNumberSeparatorLongBytes))))
+
+	assertRecorderMatchesReplay(t, log.walOptions, recorder)
+}
+
+func TestReplayFailsOnTornRecordBeforeLastFile(t *testing.T) {
+	log := newTestWalAppender(t, "wal_replaytornnottrailing")
+	require.Nil(t, log.AppendSync(make([]byte, 100)))
+	path, err := log.Rotate()
+	require.Nil(t, err)
+	require.Nil(t, log.AppendSync([]byte{1}))
+	require.Nil(t, log.Close())
+
+	stat, err := os.Stat(path)
+	require.Nil(t, err)
+	require.Nil(t, os.Truncate(path, stat.Size()-10))
+
+	repl, err := NewReplayer(log.walOptions)
+	require.Nil(t, err)
+	err = repl.Replay(func(record []byte) error {
+		return nil
+	})
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+}
@@ -48,6 +48,14 @@ type WriteAheadLog struct {
 	WriteAheadLogAppendI
 	WriteAheadLogReplayI
 	WriteAheadLogCleanI
+
+	opts *Options
+}
+
+// CleanUpTo removes the WAL files up to and including the one at the given path returned by Rotate, see
+// Cleaner.CleanUpTo. This truncates the WAL after the memstore with its records was flushed.
+func (w *WriteAheadLog) CleanUpTo(path string) error {
+	return (&Cleaner{walOptions: w.opts}).CleanUpTo(path)
 }
 
 // NewWriteAheadLog creates a new WAL by supplying options, for example using a base path: wal.NewWriteAheadLogOptions(wal.BasePath("some_directory"))
@@ -64,6 +72,7 @@ func NewWriteAheadLog(opts *Options) (WriteAheadLogI, error) {
 		appender,
 		replayer,
 		NewCleaner(opts),
+		opts,
 	}, nil
 }
 
@@ -62,6 +62,36 @@ func TestWALCrashRecovery(t *testing.T) {
 	}
 }
 
+func TestWALRecoversFromTornRecordAfterRestart(t *testing.T) {
+	wal := newTestWal(t, "wal_e2e_torn_restart")
+	require.Nil(t, wal.AppendSync([]byte{1, 2, 3}))
+	require.Nil(t, wal.AppendSync([]byte{4, 5, 6}))
+	require.Nil(t, wal.Close())
+
+	// simulate a crash in the middle of the last append, then restart twice
+	path := wal.WriteAheadLogAppendI.(*Appender).currentWriterPath
+	stat, err := os.Stat(path)
+	require.Nil(t, err)
+	require.Nil(t, os.Truncate(path, stat.Size()-1))
+
+	expected := [][]byte{{1, 2, 3}}
+	for i := 0; i < 2; i++ {
+		restarted, err := NewWriteAheadLog(wal.opts)
+		require.Nil(t, err)
+		var replayed [][]byte
+		require.Nil(t, restarted.Replay(func(record []byte) error {
+			replayed = append(replayed, record)
+			return nil
+		}))
+		assert.Equal(t, expected, replayed)
+
+		record := []byte{byte(7 + i)}
+		require.Nil(t, restarted.AppendSync(record))
+		expected = append(expected, record)
+		require.Nil(t, restarted.Close())
+	}
+}
+
 func TestOptionMissingBasePath(t *testing.T) {
 	_, err := NewWriteAheadLogOptions(MaximumWalFileSizeBytes(TestMaxWalFileSize))
 	assert.Equal(t, errors.New("basePath was not supplied"), err)